@@ -0,0 +1,111 @@
+// Copyright (c) 2023 Snowflake Computing Inc. All rights reserved.
+
+package gosnowflake
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+)
+
+// ScanArray returns a sql.Scanner scanning an ARRAY column into dest, a *[]int64, *[]string or *[]float64:
+// ARRAY(INTEGER) values are scanned into a []int64, ARRAY(TEXT) values into a []string and ARRAY(FLOAT) or
+// ARRAY(NUMBER(p,s)) values into a []float64. Each element must match the element type of dest, so the scan
+// fails naming the first element that doesn't, e.g. a string or a NULL element. A NULL value sets dest to nil.
+//
+//	var ids []int64
+//	err := rows.Scan(sf.ScanArray(&ids))
+func ScanArray(dest interface{}) sql.Scanner {
+	return &arrayScanner{dest: dest}
+}
+
+type arrayScanner struct {
+	dest interface{}
+}
+
+func (s *arrayScanner) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		return errArrayElement(fmt.Sprintf("%T", s.dest), -1, fmt.Sprintf("cannot scan %T into an ARRAY", src))
+	}
+	var elements []interface{}
+	if data != nil {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		if err := decoder.Decode(&elements); err != nil {
+			return errArrayElement(fmt.Sprintf("%T", s.dest), -1, err.Error())
+		}
+	}
+	switch dest := s.dest.(type) {
+	case *[]int64:
+		if data == nil {
+			*dest = nil
+			return nil
+		}
+		values := make([]int64, len(elements))
+		for i, e := range elements {
+			n, ok := e.(json.Number)
+			if !ok {
+				return errArrayElement(fmt.Sprintf("%T", dest), i, arrayElementMismatch(e, "int64"))
+			}
+			v, err := n.Int64()
+			if err != nil {
+				return errArrayElement(fmt.Sprintf("%T", dest), i, arrayElementMismatch(e, "int64"))
+			}
+			values[i] = v
+		}
+		*dest = values
+	case *[]float64:
+		if data == nil {
+			*dest = nil
+			return nil
+		}
+		values := make([]float64, len(elements))
+		for i, e := range elements {
+			n, ok := e.(json.Number)
+			if !ok {
+				return errArrayElement(fmt.Sprintf("%T", dest), i, arrayElementMismatch(e, "float64"))
+			}
+			v, err := n.Float64()
+			if err != nil {
+				return errArrayElement(fmt.Sprintf("%T", dest), i, arrayElementMismatch(e, "float64"))
+			}
+			values[i] = v
+		}
+		*dest = values
+	case *[]string:
+		if data == nil {
+			*dest = nil
+			return nil
+		}
+		values := make([]string, len(elements))
+		for i, e := range elements {
+			str, ok := e.(string)
+			if !ok {
+				return errArrayElement(fmt.Sprintf("%T", dest), i, arrayElementMismatch(e, "string"))
+			}
+			values[i] = str
+		}
+		*dest = values
+	default:
+		return errArrayElement(fmt.Sprintf("%T", s.dest), -1, "the destination must be a *[]int64, *[]string or *[]float64")
+	}
+	return nil
+}
+
+func arrayElementMismatch(element interface{}, typ string) string {
+	if element == nil {
+		return fmt.Sprintf("cannot convert NULL to %v", typ)
+	}
+	if _, ok := element.(json.Number); ok {
+		return fmt.Sprintf("cannot convert number %v to %v", element, typ)
+	}
+	return fmt.Sprintf("cannot convert %v (%T) to %v", element, element, typ)
+}
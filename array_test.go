@@ -0,0 +1,117 @@
+// Copyright (c) 2023 Snowflake Computing Inc. All rights reserved.
+
+package gosnowflake
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestScanArray(t *testing.T) {
+	ints, texts := "[1, -2, 9007199254740993]", `["a", "b c", ""]`
+	postQueryMock := func(_ context.Context, _ *snowflakeRestful, _ *url.Values,
+		_ map[string]string, _ []byte, _ time.Duration, _ UUID, _ *Config) (*execResponse, error) {
+		return &execResponse{
+			Data: execResponseData{
+				QueryID:           "1",
+				RowType:           []execResponseRowType{{Name: "IDS", Type: "array"}, {Name: "NAMES", Type: "array"}},
+				RowSet:            [][]*string{{&ints, &texts}, {nil, nil}},
+				Total:             2,
+				Returned:          2,
+				QueryResultFormat: "json",
+			},
+			Success: true,
+		}, nil
+	}
+	sc := getPooledSnowflakeConn()
+	sc.rest.FuncPostQuery = postQueryMock
+	db := sql.OpenDB(showConnectorMock{sc: sc})
+	defer db.Close()
+
+	rows, err := db.Query("SELECT ids, names FROM t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		t.Fatal("expected a row")
+	}
+	var ids []int64
+	var names []string
+	err = rows.Scan(ScanArray(&names), ScanArray(&ids))
+	var se *SnowflakeError
+	if !errors.As(err, &se) || se.Number != ErrArrayElement ||
+		!strings.Contains(err.Error(), "element 0: cannot convert number 1 to string") {
+		t.Fatalf("the elements should be checked against the slice type, got: %v", err)
+	}
+	var value interface{}
+	if err = rows.Scan(&value, ScanArray(&names)); err != nil || value != ints {
+		t.Fatalf("an ARRAY should still be scanned as a string. got: %#v, err: %v", value, err)
+	}
+	if err = rows.Scan(ScanArray(&ids), ScanArray(&names)); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ids, []int64{1, -2, 9007199254740993}) {
+		t.Fatalf("unexpected ARRAY(INTEGER) value: %v", ids)
+	}
+	if !reflect.DeepEqual(names, []string{"a", "b c", ""}) {
+		t.Fatalf("unexpected ARRAY(TEXT) value: %v", names)
+	}
+	if !rows.Next() {
+		t.Fatal("expected a row")
+	}
+	if err = rows.Scan(ScanArray(&ids), ScanArray(&names)); err != nil {
+		t.Fatal(err)
+	}
+	if ids != nil || names != nil {
+		t.Fatalf("a NULL ARRAY should be scanned as nil, got: %v, %v", ids, names)
+	}
+}
+
+func TestScanArrayFloats(t *testing.T) {
+	var floats []float64
+	if err := ScanArray(&floats).Scan("[1.5, -2, 3e2]"); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(floats, []float64{1.5, -2, 300}) {
+		t.Fatalf("unexpected ARRAY(FLOAT) value: %v", floats)
+	}
+}
+
+func TestScanArrayElementMismatch(t *testing.T) {
+	var ids []int64
+	var names []string
+	testcases := []struct {
+		dest    interface{}
+		src     interface{}
+		message string
+	}{
+		{&ids, `[1, "two"]`, `cannot scan ARRAY into *[]int64: element 1: cannot convert two (string) to int64`},
+		{&ids, `[1, 2.5]`, `cannot scan ARRAY into *[]int64: element 1: cannot convert number 2.5 to int64`},
+		{&ids, `[null]`, `cannot scan ARRAY into *[]int64: element 0: cannot convert NULL to int64`},
+		{&names, `["a", 2]`, `cannot scan ARRAY into *[]string: element 1: cannot convert number 2 to string`},
+		{&names, `{"a": 1}`, `cannot scan ARRAY into *[]string: json: cannot unmarshal object into Go value of type []interface {}`},
+		{&names, 7, `cannot scan ARRAY into *[]string: cannot scan int into an ARRAY`},
+		{&[]bool{}, `[true]`, `cannot scan ARRAY into *[]bool: the destination must be a *[]int64, *[]string or *[]float64`},
+	}
+	for _, tc := range testcases {
+		err := ScanArray(tc.dest).Scan(tc.src)
+		var se *SnowflakeError
+		if !errors.As(err, &se) || se.Number != ErrArrayElement {
+			t.Fatalf("expected ErrArrayElement for %v, got: %v", tc.src, err)
+		}
+		if expected := fmt.Sprintf("%06d: %s", ErrArrayElement, tc.message); err.Error() != expected {
+			t.Fatalf("unexpected message for %v. expected: %v, got: %v", tc.src, expected, err)
+		}
+	}
+	if ids != nil || names != nil {
+		t.Fatalf("a failed scan should leave the destination unchanged, got: %v, %v", ids, names)
+	}
+}
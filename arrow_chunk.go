@@ -19,7 +19,9 @@ type arrowResultChunk struct {
 	allocator memory.Allocator
 }
 
-func (arc *arrowResultChunk) decodeArrowChunk(rowType []execResponseRowType, highPrec bool) ([]chunkRowType, error) {
+// decodeArrowChunk decodes the rows of the chunk. If projection is not nil, only the columns set in it are
+// decoded and the others are left nil.
+func (arc *arrowResultChunk) decodeArrowChunk(rowType []execResponseRowType, highPrec bool, projection []bool) ([]chunkRowType, error) {
 	logger.Debug("Arrow Decoder")
 	var chunkRows []chunkRowType
 
@@ -35,6 +37,9 @@ func (arc *arrowResultChunk) decodeArrowChunk(rowType []execResponseRowType, hig
 		}
 
 		for colIdx, col := range columns {
+			if projection != nil && !projection[colIdx] {
+				continue
+			}
 			values := make([]snowflakeValue, numRows)
 			if err := arrowToValue(values, rowType[colIdx], col, arc.loc, highPrec); err != nil {
 				return nil, err
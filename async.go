@@ -19,6 +19,7 @@ func (sr *snowflakeRestful) processAsync(
 	cfg *Config) (*execResponse, error) {
 	// placeholder object to return to user while retrieving results
 	rows := new(snowflakeRows)
+	rows.nullToZeroValue = nullToZeroValueEnabled(ctx)
 	res := new(snowflakeResult)
 	switch resType := getResultType(ctx); resType {
 	case execResultType:
@@ -97,7 +98,13 @@ func (sr *snowflakeRestful) getAsync(
 			// Once 5 second backoff is reached it will keep retrying with this sleeptime.
 			sleepTime := time.Millisecond * time.Duration(500*retryPattern[retry])
 			logger.WithContext(ctx).Infof("Query execution still in progress. Sleep for %v ms", sleepTime)
-			time.Sleep(sleepTime)
+			select {
+			case <-ctx.Done():
+				sfError.Message = ctx.Err().Error()
+				errChannel <- sfError
+				return ctx.Err()
+			case <-time.After(sleepTime):
+			}
 		}
 		if retry < len(retryPattern)-1 {
 			retry++
@@ -125,13 +132,21 @@ func (sr *snowflakeRestful) getAsync(
 					res.errChannel <- err
 					return err
 				}
+				res.childIDs = childQueryIDs(&respd.Data)
 			}
 			res.queryID = respd.Data.QueryID
+			res.warnings = respd.Data.Warnings
+			res.stats = respd.Data.Stats
+			res.session = sessionContextOf(&respd.Data)
 			res.errChannel <- nil // mark exec status complete
 		} else {
 			rows.sc = sc
 			rows.queryID = respd.Data.QueryID
+			rows.warnings = respd.Data.Warnings
+			rows.stats = respd.Data.Stats
+			rows.session = sessionContextOf(&respd.Data)
 			if isMultiStmt(&respd.Data) {
+				rows.childIDs = childQueryIDs(&respd.Data)
 				if err = sc.handleMultiQuery(ctx, respd.Data, rows); err != nil {
 					rows.errChannel <- err
 					return err
@@ -6,7 +6,13 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestAsyncMode(t *testing.T) {
@@ -180,3 +186,99 @@ func TestLongRunningAsyncQuery(t *testing.T) {
 		}
 	}
 }
+
+// monitoringStatusMock returns the given monitoring responses one by one, the last one repeatedly.
+func monitoringStatusMock(statuses []string) func(context.Context, *snowflakeRestful, *url.URL, map[string]string, time.Duration) (*http.Response, error) {
+	var mu sync.Mutex
+	i := 0
+	return func(_ context.Context, _ *snowflakeRestful, fullURL *url.URL, _ map[string]string, _ time.Duration) (*http.Response, error) {
+		if !strings.Contains(fullURL.Path, "/monitoring/queries/q1") {
+			return nil, fmt.Errorf("unexpected URL: %v", fullURL)
+		}
+		mu.Lock()
+		status := statuses[i]
+		if i < len(statuses)-1 {
+			i++
+		}
+		mu.Unlock()
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body: io.NopCloser(strings.NewReader(`{"data": {"queries": [` + status + `]},
+				"code": null, "message": null, "success": true}`)),
+		}, nil
+	}
+}
+
+func execAsyncWithStatusUpdates(t *testing.T, statuses []string) []QueryStatusUpdate {
+	backupPollInterval := queryStatusPollInterval
+	queryStatusPollInterval = time.Millisecond
+	defer func() {
+		queryStatusPollInterval = backupPollInterval
+	}()
+	sc := getDefaultSnowflakeConn()
+	sc.rest.FuncGet = monitoringStatusMock(statuses)
+	sc.rest.FuncPostQuery = func(_ context.Context, _ *snowflakeRestful, _ *url.Values,
+		_ map[string]string, _ []byte, _ time.Duration, _ UUID, _ *Config) (*execResponse, error) {
+		return &execResponse{
+			Data: execResponseData{
+				QueryID:   "q1",
+				AsyncRows: &snowflakeRows{queryID: "q1", status: QueryStatusInProgress},
+			},
+			Success: true,
+		}, nil
+	}
+	updates := make(chan QueryStatusUpdate)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	ctx = WithQueryStatusUpdates(WithAsyncMode(ctx), updates)
+	if _, err := sc.exec(ctx, "SELECT 1", false, false, false, nil); err != nil {
+		t.Fatal(err)
+	}
+	var received []QueryStatusUpdate
+	for {
+		select {
+		case update := <-updates:
+			received = append(received, update)
+			if update.Done() {
+				return received
+			}
+		case <-ctx.Done():
+			t.Fatalf("no final status update, received: %+v", received)
+		}
+	}
+}
+
+func TestAsyncQueryStatusUpdates(t *testing.T) {
+	received := execAsyncWithStatusUpdates(t, []string{
+		`{"status": "QUEUED", "errorCode": ""}`,
+		`{"status": "QUEUED", "errorCode": ""}`,
+		`{"status": "RUNNING", "errorCode": "", "stats": {"scanBytes": 1024, "producedRows": 10}}`,
+		`{"status": "RUNNING", "errorCode": "", "stats": {"scanBytes": 2048, "producedRows": 20}}`,
+		`{"status": "SUCCESS", "errorCode": "", "stats": {"scanBytes": 2048, "producedRows": 30}}`,
+	})
+	expected := []QueryStatusUpdate{
+		{QueryID: "q1", State: "QUEUED"},
+		{QueryID: "q1", State: "RUNNING", ScanBytes: 1024, ProducedRows: 10},
+		{QueryID: "q1", State: "RUNNING", ScanBytes: 2048, ProducedRows: 20},
+		{QueryID: "q1", State: "SUCCESS", ScanBytes: 2048, ProducedRows: 30},
+	}
+	if len(received) != len(expected) {
+		t.Fatalf("unexpected updates. expected: %+v, got: %+v", expected, received)
+	}
+	for i := range expected {
+		if received[i] != expected[i] {
+			t.Fatalf("unexpected update %v. expected: %+v, got: %+v", i, expected[i], received[i])
+		}
+	}
+}
+
+func TestAsyncQueryStatusUpdatesFailure(t *testing.T) {
+	received := execAsyncWithStatusUpdates(t, []string{
+		`{"status": "RUNNING", "errorCode": ""}`,
+		`{"status": "FAILED_WITH_ERROR", "errorCode": "100038", "errorMessage": "Numeric value 'a' is not recognized"}`,
+	})
+	if len(received) != 2 || received[0].State != "RUNNING" || received[1].State != "FAILED_WITH_ERROR" ||
+		received[1].ErrorCode != "100038" {
+		t.Fatalf("unexpected updates: %+v", received)
+	}
+}
@@ -29,6 +29,8 @@ const (
 	mfaToken                       = "MFATOKEN"
 	clientStoreTemporaryCredential = "CLIENT_STORE_TEMPORARY_CREDENTIAL"
 	clientRequestMfaToken          = "CLIENT_REQUEST_MFA_TOKEN"
+	clientSessionKeepAlive         = "CLIENT_SESSION_KEEP_ALIVE"
+	sessionTimezone                = "TIMEZONE"
 	idTokenAuthenticator           = "ID_TOKEN"
 )
 
@@ -50,6 +52,8 @@ const (
 	AuthTypeTokenAccessor
 	// AuthTypeUsernamePasswordMFA is to use username and password with mfa
 	AuthTypeUsernamePasswordMFA
+	// AuthTypeOAuthDeviceCode is to obtain an OAuth token with the device authorization grant of an external authorization server
+	AuthTypeOAuthDeviceCode
 )
 
 func determineAuthenticatorType(cfg *Config, value string) error {
@@ -73,9 +77,12 @@ func determineAuthenticatorType(cfg *Config, value string) error {
 	} else if upperCaseValue == AuthTypeTokenAccessor.String() {
 		cfg.Authenticator = AuthTypeTokenAccessor
 		return nil
+	} else if upperCaseValue == AuthTypeOAuthDeviceCode.String() {
+		cfg.Authenticator = AuthTypeOAuthDeviceCode
+		return nil
 	} else {
-		// possibly Okta case
-		oktaURLString, err := url.QueryUnescape(lowerCaseValue)
+		// possibly Okta case. The path keeps its case as it may contain case-sensitive IDs
+		oktaURLString, err := url.QueryUnescape(strings.TrimSpace(value))
 		if err != nil {
 			return &SnowflakeError{
 				Number:      ErrCodeFailedToParseAuthenticator,
@@ -93,7 +100,8 @@ func determineAuthenticatorType(cfg *Config, value string) error {
 			}
 		}
 
-		if oktaURL.Scheme != "https" || !strings.HasSuffix(oktaURL.Host, "okta.com") {
+		oktaURL.Host = strings.ToLower(oktaURL.Host)
+		if oktaURL.Scheme != "https" || !isOktaHost(oktaURL.Hostname()) {
 			return &SnowflakeError{
 				Number:      ErrCodeFailedToParseAuthenticator,
 				Message:     errMsgFailedToParseAuthenticator,
@@ -106,6 +114,11 @@ func determineAuthenticatorType(cfg *Config, value string) error {
 	return nil
 }
 
+// isOktaHost checks if host is an Okta domain, e.g. company.okta.com, and not merely a host ending with okta.com.
+func isOktaHost(host string) bool {
+	return host == "okta.com" || strings.HasSuffix(host, ".okta.com")
+}
+
 func (authType AuthType) String() string {
 	switch authType {
 	case AuthTypeSnowflake:
@@ -122,6 +135,8 @@ func (authType AuthType) String() string {
 		return "TOKENACCESSOR"
 	case AuthTypeUsernamePasswordMFA:
 		return "USERNAME_PASSWORD_MFA"
+	case AuthTypeOAuthDeviceCode:
+		return "OAUTH_DEVICE_CODE"
 	default:
 		return "UNKNOWN"
 	}
@@ -147,7 +162,31 @@ type authRequestClientEnvironment struct {
 	Os          string `json:"OS"`
 	OsVersion   string `json:"OS_VERSION"`
 	OCSPMode    string `json:"OCSP_MODE"`
+
+	custom  map[string]string // Config.ClientEnvironment
+	omitted []string          // Config.OmitClientEnvironment
+}
+
+// MarshalJSON adds the custom entries to the client environment and removes the omitted ones.
+func (env authRequestClientEnvironment) MarshalJSON() ([]byte, error) {
+	type defaultEnvironment authRequestClientEnvironment
+	body, err := json.Marshal(defaultEnvironment(env))
+	if err != nil || (len(env.custom) == 0 && len(env.omitted) == 0) {
+		return body, err
+	}
+	entries := make(map[string]interface{})
+	if err = json.Unmarshal(body, &entries); err != nil {
+		return nil, err
+	}
+	for _, name := range env.omitted {
+		delete(entries, name)
+	}
+	for name, value := range env.custom {
+		entries[name] = value
+	}
+	return json.Marshal(entries)
 }
+
 type authRequestData struct {
 	ClientAppID             string                       `json:"CLIENT_APP_ID"`
 	ClientAppVersion        string                       `json:"CLIENT_APP_VERSION"`
@@ -212,6 +251,32 @@ type authResponse struct {
 	Success bool             `json:"success"`
 }
 
+const (
+	// invalidJWTErrorCode is the login failure of a JWT Snowflake doesn't accept, including one issued
+	// in its future because the clock of the client is ahead.
+	invalidJWTErrorCode = "390144"
+	// jwtClockSkewRetryLeeway is how far the issue time of the JWT is backdated when the login is retried
+	// after Snowflake rejected the JWT.
+	jwtClockSkewRetryLeeway = 60 * time.Second
+)
+
+// permanentLoginErrorCodes are login failures which won't succeed when the request is retried
+var permanentLoginErrorCodes = map[string]bool{
+	"390100": true, // incorrect username or password
+	"390144": true, // invalid JWT token
+	"390303": true, // invalid OAuth access token
+	"390318": true, // expired OAuth access token
+}
+
+// parsePermanentLoginError returns the login response if body contains a permanent login failure.
+func parsePermanentLoginError(body []byte) (*authResponse, bool) {
+	var respd authResponse
+	if err := json.Unmarshal(body, &respd); err != nil {
+		return nil, false
+	}
+	return &respd, !respd.Success && permanentLoginErrorCodes[respd.Code]
+}
+
 func postAuth(
 	ctx context.Context,
 	sr *snowflakeRestful,
@@ -240,6 +305,15 @@ func postAuth(
 		}
 		return &respd, nil
 	}
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		logger.Errorf("failed to extract HTTP response body. err: %v", err)
+		return nil, err
+	}
+	if respd, ok := parsePermanentLoginError(b); ok {
+		// let the caller report the login failure returned by Snowflake
+		return respd, nil
+	}
 	switch resp.StatusCode {
 	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
 		// service availability or connectivity issue. Most likely server side issue.
@@ -258,11 +332,6 @@ func postAuth(
 			MessageArgs: []interface{}{resp.StatusCode, fullURL},
 		}
 	}
-	b, err := io.ReadAll(resp.Body)
-	if err != nil {
-		logger.Errorf("failed to extract HTTP response body. err: %v", err)
-		return nil, err
-	}
 	logger.Infof("HTTP: %v, URL: %v, Body: %v", resp.StatusCode, fullURL, b)
 	logger.Infof("Header: %v", resp.Header)
 	return nil, &SnowflakeError{
@@ -283,6 +352,71 @@ func getHeaders() map[string]string {
 	return headers
 }
 
+// attachToSession uses the tokens of a pre-established session instead of logging in.
+// The session token is validated by a heartbeat, which renews it with the master token
+// if it has expired, so that a stale session fails the connection rather than its first query.
+func attachToSession(ctx context.Context, sc *snowflakeConn) (*authResponseMain, error) {
+	logger.WithContext(ctx).Infof("Attaching to the pre-established session %v", sc.cfg.SessionID)
+	sc.rest.TokenAccessor.SetTokens(sc.cfg.SessionToken, sc.cfg.MasterToken, sc.cfg.SessionID)
+
+	params := &url.Values{}
+	params.Add(requestIDKey, NewUUID().String())
+	params.Add(requestGUIDKey, NewUUID().String())
+	headers := getHeaders()
+	headers[headerAuthorizationKey] = fmt.Sprintf(headerSnowflakeToken, sc.cfg.SessionToken)
+	fullURL := sc.rest.getFullURL(heartBeatPath, params)
+	resp, err := sc.rest.FuncPost(ctx, sc.rest, fullURL, headers, nil, sc.rest.RequestTimeout, false, defaultTimeProvider, sc.cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		logger.WithContext(ctx).Errorf("failed to validate the pre-established session. HTTP: %v, URL: %v", resp.StatusCode, fullURL)
+		sc.rest.TokenAccessor.SetTokens("", "", -1)
+		return nil, (&SnowflakeError{
+			Number:      ErrFailedToHeartbeat,
+			SQLState:    SQLStateConnectionRejected,
+			Message:     errMsgFailedToAuth,
+			MessageArgs: []interface{}{resp.StatusCode, fullURL},
+		}).exceptionTelemetry(sc)
+	}
+	var respd execResponse
+	if err = json.NewDecoder(resp.Body).Decode(&respd); err != nil {
+		logger.WithContext(ctx).Errorf("failed to decode JSON. err: %v", err)
+		return nil, err
+	}
+	if respd.Code == sessionExpiredCode {
+		if err = sc.rest.renewExpiredSessionToken(withSessionRenewReason(ctx, SessionRenewReasonExpiredOnRequest), sc.rest.RequestTimeout, sc.cfg.SessionToken); err != nil {
+			sc.rest.TokenAccessor.SetTokens("", "", -1)
+			return nil, err
+		}
+	} else if !respd.Success {
+		sc.rest.TokenAccessor.SetTokens("", "", -1)
+		code, err := strconv.Atoi(respd.Code)
+		if err != nil {
+			return nil, err
+		}
+		return nil, (&SnowflakeError{
+			Number:   code,
+			SQLState: SQLStateConnectionRejected,
+			Message:  respd.Message,
+		}).exceptionTelemetry(sc)
+	}
+
+	token, masterToken, sessionID := sc.rest.TokenAccessor.GetTokens()
+	return &authResponseMain{
+		Token:       token,
+		MasterToken: masterToken,
+		SessionID:   sessionID,
+		SessionInfo: authResponseSessionInfo{
+			DatabaseName:  sc.cfg.Database,
+			SchemaName:    sc.cfg.Schema,
+			WarehouseName: sc.cfg.Warehouse,
+			RoleName:      sc.cfg.Role,
+		},
+	}, nil
+}
+
 // Used to authenticate the user with Snowflake.
 func authenticate(
 	ctx context.Context,
@@ -290,6 +424,9 @@ func authenticate(
 	samlResponse []byte,
 	proofKey []byte,
 ) (resp *authResponseMain, err error) {
+	if sc.cfg.SessionToken != "" {
+		return attachToSession(ctx, sc)
+	}
 	if sc.cfg.Authenticator == AuthTypeTokenAccessor {
 		logger.Info("Bypass authentication using existing token from token accessor")
 		sessionInfo := authResponseSessionInfo{
@@ -308,11 +445,14 @@ func authenticate(
 	}
 
 	headers := getHeaders()
+	addExtraHeaders(headers, newExtraHeaders(sc.cfg))
 	clientEnvironment := authRequestClientEnvironment{
 		Application: sc.cfg.Application,
 		Os:          operatingSystem,
 		OsVersion:   platform,
 		OCSPMode:    sc.cfg.ocspMode(),
+		custom:      sc.cfg.ClientEnvironment,
+		omitted:     sc.cfg.OmitClientEnvironment,
 	}
 
 	sessionParameters := make(map[string]interface{})
@@ -330,8 +470,20 @@ func authenticate(
 	if sc.cfg.ClientStoreTemporaryCredential == ConfigBoolTrue {
 		sessionParameters[clientStoreTemporaryCredential] = true
 	}
+	if sc.cfg.ClientSessionKeepAlive != configBoolNotSet {
+		sessionParameters[clientSessionKeepAlive] = sc.cfg.ClientSessionKeepAlive == ConfigBoolTrue
+	}
+	switch sc.cfg.ClientTimezone {
+	case "":
+	case ClientTimezoneServerDefault:
+		// the session keeps the default timezone of the account or user
+		delete(sessionParameters, sessionTimezone)
+	default:
+		sessionParameters[sessionTimezone] = sc.cfg.ClientTimezone
+	}
+	jwtLeeway := sc.cfg.JWTLeeway
 	bodyCreator := func() ([]byte, error) {
-		return createRequestBody(sc, sessionParameters, clientEnvironment, proofKey, samlResponse)
+		return createRequestBody(sc, sessionParameters, clientEnvironment, proofKey, samlResponse, jwtLeeway)
 	}
 
 	params := &url.Values{}
@@ -355,6 +507,17 @@ func authenticate(
 	if err != nil {
 		return nil, err
 	}
+	if !respd.Success && sc.cfg.Authenticator == AuthTypeJwt && respd.Code == invalidJWTErrorCode {
+		if retryLeeway := jwtRetryLeeway(sc.cfg); retryLeeway > jwtLeeway {
+			logger.WithContext(sc.ctx).Warnf("the JWT was rejected, possibly because the clock is ahead of Snowflake. "+
+				"retrying with the issue time backdated by %v", retryLeeway)
+			jwtLeeway = retryLeeway
+			respd, err = sc.rest.FuncPostAuth(ctx, sc.rest, sc.rest.getClientFor(sc.cfg.Authenticator), params, headers, bodyCreator, sc.rest.LoginTimeout)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
 	if !respd.Success {
 		logger.Errorln("Authentication FAILED")
 		sc.rest.TokenAccessor.SetTokens("", "", -1)
@@ -389,7 +552,7 @@ func authenticate(
 }
 
 func createRequestBody(sc *snowflakeConn, sessionParameters map[string]interface{},
-	clientEnvironment authRequestClientEnvironment, proofKey []byte, samlResponse []byte,
+	clientEnvironment authRequestClientEnvironment, proofKey []byte, samlResponse []byte, jwtLeeway time.Duration,
 ) ([]byte, error) {
 	requestMain := authRequestData{
 		ClientAppID:       clientType,
@@ -411,7 +574,7 @@ func createRequestBody(sc *snowflakeConn, sessionParameters map[string]interface
 			requestMain.LoginName = sc.cfg.User
 			requestMain.Authenticator = AuthTypeExternalBrowser.String()
 		}
-	case AuthTypeOAuth:
+	case AuthTypeOAuth, AuthTypeOAuthDeviceCode:
 		requestMain.LoginName = sc.cfg.User
 		requestMain.Authenticator = AuthTypeOAuth.String()
 		requestMain.Token = sc.cfg.Token
@@ -420,7 +583,7 @@ func createRequestBody(sc *snowflakeConn, sessionParameters map[string]interface
 	case AuthTypeJwt:
 		requestMain.Authenticator = AuthTypeJwt.String()
 
-		jwtTokenString, err := prepareJWTToken(sc.cfg)
+		jwtTokenString, err := prepareJWTToken(sc.cfg, jwtLeeway)
 		if err != nil {
 			return nil, err
 		}
@@ -455,8 +618,8 @@ func createRequestBody(sc *snowflakeConn, sessionParameters map[string]interface
 	return jsonBody, nil
 }
 
-// Generate a JWT token in string given the configuration
-func prepareJWTToken(config *Config) (string, error) {
+// Generate a JWT token in string given the configuration, with the issue time backdated by leeway
+func prepareJWTToken(config *Config, leeway time.Duration) (string, error) {
 	pubBytes, err := x509.MarshalPKIXPublicKey(config.PrivateKey.Public())
 	if err != nil {
 		return "", err
@@ -466,13 +629,15 @@ func prepareJWTToken(config *Config) (string, error) {
 	accountName := strings.ToUpper(config.Account)
 	userName := strings.ToUpper(config.User)
 
-	issueAtTime := time.Now().UTC()
+	// the issue time is backdated by the leeway so that the token is accepted by Snowflake
+	// even if the clock of the client is ahead
+	now := time.Now().UTC()
 	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
 		"iss": fmt.Sprintf("%s.%s.%s", accountName, userName, "SHA256:"+base64.StdEncoding.EncodeToString(hash[:])),
 		"sub": fmt.Sprintf("%s.%s", accountName, userName),
-		"iat": issueAtTime.Unix(),
+		"iat": now.Add(-leeway).Unix(),
 		"nbf": time.Date(2015, 10, 10, 12, 0, 0, 0, time.UTC).Unix(),
-		"exp": issueAtTime.Add(config.JWTExpireTimeout).Unix(),
+		"exp": now.Add(config.JWTExpireTimeout).Unix(),
 	})
 
 	tokenString, err := token.SignedString(config.PrivateKey)
@@ -484,6 +649,16 @@ func prepareJWTToken(config *Config) (string, error) {
 	return tokenString, err
 }
 
+// jwtRetryLeeway returns the leeway of the JWT sent when the login is retried after a rejected JWT.
+// It is capped so that the lifetime of the JWT stays within the limit of Snowflake.
+func jwtRetryLeeway(config *Config) time.Duration {
+	leeway := jwtClockSkewRetryLeeway
+	if config.JWTExpireTimeout+leeway > maxJWTLifetime {
+		leeway = maxJWTLifetime - config.JWTExpireTimeout
+	}
+	return leeway
+}
+
 // Authenticate with sc.cfg
 func authenticateWithConfig(sc *snowflakeConn) error {
 	var authData *authResponseMain
@@ -510,48 +685,73 @@ func authenticateWithConfig(sc *snowflakeConn) error {
 		}
 	}
 
-	logger.Infof("Authenticating via %v", sc.cfg.Authenticator.String())
-	switch sc.cfg.Authenticator {
-	case AuthTypeExternalBrowser:
-		if sc.cfg.IDToken == "" {
-			samlResponse, proofKey, err = authenticateByExternalBrowser(
-				sc.ctx,
+	// LoginTimeout bounds the whole authentication sequence independently of RequestTimeout.
+	// The user interaction in the external browser is bounded by ExternalBrowserTimeout instead.
+	loginCtx := sc.ctx
+	if sc.cfg.LoginTimeout > 0 {
+		var cancel context.CancelFunc
+		loginCtx, cancel = context.WithTimeout(sc.ctx, sc.cfg.LoginTimeout)
+		defer cancel()
+	}
+	loginCtx = sc.connectTimer.withClientTrace(loginCtx)
+
+	// a pre-established session is attached to in authenticate without an authenticator
+	if sc.cfg.SessionToken == "" {
+		logger.Infof("Authenticating via %v", sc.cfg.Authenticator.String())
+		switch sc.cfg.Authenticator {
+		case AuthTypeExternalBrowser:
+			if sc.cfg.IDToken == "" {
+				samlResponse, proofKey, err = authenticateByExternalBrowser(
+					sc.ctx,
+					sc.rest,
+					sc.cfg.Authenticator.String(),
+					sc.cfg.Application,
+					sc.cfg.Account,
+					sc.cfg.User,
+					sc.cfg.Password,
+					sc.cfg.ExternalBrowserTimeout)
+				if err != nil {
+					sc.cleanup()
+					return err
+				}
+			}
+		case AuthTypeOkta:
+			samlResponse, err = authenticateBySAML(
+				loginCtx,
 				sc.rest,
-				sc.cfg.Authenticator.String(),
+				sc.cfg.OktaURL,
 				sc.cfg.Application,
 				sc.cfg.Account,
 				sc.cfg.User,
-				sc.cfg.Password,
-				sc.cfg.ExternalBrowserTimeout)
+				sc.cfg.Password)
+			if err != nil {
+				sc.cleanup()
+				return err
+			}
+		case AuthTypeOAuthDeviceCode:
+			sc.cfg.Token, err = authenticateByOAuthDeviceCode(sc.ctx, sc.rest.Client, sc.cfg)
 			if err != nil {
 				sc.cleanup()
 				return err
 			}
-		}
-	case AuthTypeOkta:
-		samlResponse, err = authenticateBySAML(
-			sc.ctx,
-			sc.rest,
-			sc.cfg.OktaURL,
-			sc.cfg.Application,
-			sc.cfg.Account,
-			sc.cfg.User,
-			sc.cfg.Password)
-		if err != nil {
-			sc.cleanup()
-			return err
 		}
 	}
+	loginStart := time.Now()
 	authData, err = authenticate(
-		sc.ctx,
+		loginCtx,
 		sc,
 		samlResponse,
 		proofKey)
+	sc.connectTimer.addLogin(time.Since(loginStart))
 	if err != nil {
 		sc.cleanup()
 		return err
 	}
+	if authData.MasterValidity > 0 {
+		sc.masterTokenExpiresAt = time.Now().Add(authData.MasterValidity * time.Second)
+	}
 	sc.populateSessionParameters(authData.Parameters)
+	sc.populateSessionInfo(authData.SessionInfo)
 	sc.ctx = context.WithValue(sc.ctx, SFSessionIDKey, authData.SessionID)
 	return nil
 }
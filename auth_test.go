@@ -55,6 +55,33 @@ func TestUnitPostAuth(t *testing.T) {
 	}
 }
 
+func TestUnitPostAuthPermanentLoginError(t *testing.T) {
+	attempts := 0
+	sr := &snowflakeRestful{
+		TokenAccessor: getSimpleTokenAccessor(),
+		FuncAuthPost: func(_ context.Context, _ *http.Client, _ *url.URL, _ map[string]string, _ bodyCreatorType, _ time.Duration, _ bool) (*http.Response, error) {
+			attempts++
+			return &http.Response{
+				StatusCode: http.StatusServiceUnavailable,
+				Body:       &fakeResponseBody{body: []byte(`{"code":"390100","message":"Incorrect username or password was specified.","success":false}`)},
+			}, nil
+		},
+	}
+	bodyCreator := func() ([]byte, error) {
+		return []byte{0x12, 0x34}, nil
+	}
+	respd, err := postAuth(context.TODO(), sr, sr.Client, &url.Values{}, make(map[string]string), bodyCreator, 0)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if attempts != 1 {
+		t.Fatalf("expected a single attempt, got: %v", attempts)
+	}
+	if respd.Success || respd.Code != "390100" {
+		t.Fatalf("unexpected response: %+v", respd)
+	}
+}
+
 func postAuthFailServiceIssue(_ context.Context, _ *snowflakeRestful, _ *http.Client, _ *url.Values, _ map[string]string, _ bodyCreatorType, _ time.Duration) (*authResponse, error) {
 	return nil, &SnowflakeError{
 		Number: ErrCodeServiceUnavailable,
@@ -391,6 +418,16 @@ func getDefaultSnowflakeConn() *snowflakeConn {
 	return sc
 }
 
+// getPooledSnowflakeConn returns a default connection that database/sql keeps
+// in its pool between statements: it holds a session, so that IsValid reports
+// true, and Close leaves the session open.
+func getPooledSnowflakeConn() *snowflakeConn {
+	sc := getDefaultSnowflakeConn()
+	sc.rest.TokenAccessor.SetTokens("token", "masterToken", 1)
+	sc.cfg.KeepSessionAlive = true
+	return sc
+}
+
 func TestUnitAuthenticateWithTokenAccessor(t *testing.T) {
 	expectedSessionID := int64(123)
 	expectedMasterToken := "master_token"
@@ -609,6 +646,211 @@ func TestUnitAuthenticateJWT(t *testing.T) {
 	}
 }
 
+func TestPrepareJWTTokenClaims(t *testing.T) {
+	cfg := &Config{
+		Account:          "a",
+		User:             "u",
+		PrivateKey:       testPrivKey,
+		JWTExpireTimeout: 20 * time.Minute,
+		JWTLeeway:        5 * time.Minute,
+	}
+	now := time.Now()
+	tokenString, err := prepareJWTToken(cfg, cfg.JWTLeeway)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		return testPrivKey.Public(), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims := token.Claims.(jwt.MapClaims)
+	iat := time.Unix(int64(claims["iat"].(float64)), 0)
+	exp := time.Unix(int64(claims["exp"].(float64)), 0)
+	if d := now.Add(-cfg.JWTLeeway).Sub(iat); d < -time.Second || d > time.Second {
+		t.Fatalf("iat should be backdated by the leeway. now: %v, iat: %v", now, iat)
+	}
+	if d := now.Add(cfg.JWTExpireTimeout).Sub(exp); d < -time.Second || d > time.Second {
+		t.Fatalf("exp should be after the expire timeout. now: %v, exp: %v", now, exp)
+	}
+	if lifetime := exp.Sub(iat); lifetime != cfg.JWTExpireTimeout+cfg.JWTLeeway {
+		t.Fatalf("unexpected lifetime: %v", lifetime)
+	}
+}
+
+func TestUnitAuthenticateJWTRetriesWithLeeway(t *testing.T) {
+	var issueTimes []time.Time
+	sr := &snowflakeRestful{
+		FuncPostAuth: func(_ context.Context, _ *snowflakeRestful, _ *http.Client, _ *url.Values, _ map[string]string, bodyCreator bodyCreatorType, _ time.Duration) (*authResponse, error) {
+			var ar authRequest
+			jsonBody, err := bodyCreator()
+			if err != nil {
+				return nil, err
+			}
+			if err = json.Unmarshal(jsonBody, &ar); err != nil {
+				return nil, err
+			}
+			token, err := jwt.Parse(ar.Data.Token, func(token *jwt.Token) (interface{}, error) {
+				return testPrivKey.Public(), nil
+			})
+			if err != nil {
+				return nil, err
+			}
+			iat := time.Unix(int64(token.Claims.(jwt.MapClaims)["iat"].(float64)), 0)
+			issueTimes = append(issueTimes, iat)
+			// the server clock is 30 seconds behind the client
+			if iat.After(time.Now().Add(-30 * time.Second)) {
+				return &authResponse{Success: false, Code: invalidJWTErrorCode, Message: "JWT token is invalid."}, nil
+			}
+			return &authResponse{
+				Success: true,
+				Data: authResponseMain{
+					Token:       "t",
+					MasterToken: "m",
+				},
+			}, nil
+		},
+		TokenAccessor: getSimpleTokenAccessor(),
+	}
+	sc := getDefaultSnowflakeConn()
+	sc.cfg.Authenticator = AuthTypeJwt
+	sc.cfg.JWTExpireTimeout = defaultJWTTimeout
+	sc.cfg.PrivateKey = testPrivKey
+	sc.rest = sr
+
+	if _, err := authenticate(context.TODO(), sc, []byte{}, []byte{}); err != nil {
+		t.Fatalf("the login should be retried with a backdated JWT. err: %v", err)
+	}
+	if len(issueTimes) != 2 {
+		t.Fatalf("expected a single retry, got %v logins", len(issueTimes))
+	}
+	if d := issueTimes[0].Sub(issueTimes[1]); d < jwtClockSkewRetryLeeway-time.Second {
+		t.Fatalf("the retried JWT should be backdated by %v, got %v", jwtClockSkewRetryLeeway, d)
+	}
+
+	// the JWT isn't retried again once the leeway covers the retry leeway
+	issueTimes = nil
+	sc.cfg.JWTLeeway = jwtClockSkewRetryLeeway
+	sr.FuncPostAuth = func(_ context.Context, _ *snowflakeRestful, _ *http.Client, _ *url.Values, _ map[string]string, _ bodyCreatorType, _ time.Duration) (*authResponse, error) {
+		issueTimes = append(issueTimes, time.Now())
+		return &authResponse{Success: false, Code: invalidJWTErrorCode, Message: "JWT token is invalid."}, nil
+	}
+	_, err := authenticate(context.TODO(), sc, []byte{}, []byte{})
+	if se, ok := err.(*SnowflakeError); !ok || se.Number != 390144 {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(issueTimes) != 1 {
+		t.Fatalf("the login should not be retried, got %v logins", len(issueTimes))
+	}
+}
+
+func TestJWTRetryLeeway(t *testing.T) {
+	if leeway := jwtRetryLeeway(&Config{JWTExpireTimeout: defaultJWTTimeout}); leeway != jwtClockSkewRetryLeeway {
+		t.Fatalf("unexpected leeway: %v", leeway)
+	}
+	if leeway := jwtRetryLeeway(&Config{JWTExpireTimeout: maxJWTLifetime - 10*time.Second}); leeway != 10*time.Second {
+		t.Fatalf("the leeway should be capped by the JWT lifetime, got: %v", leeway)
+	}
+}
+
+func TestUnitAuthenticateClientEnvironment(t *testing.T) {
+	var clientEnvironment map[string]interface{}
+	sr := &snowflakeRestful{
+		FuncPostAuth: func(_ context.Context, _ *snowflakeRestful, _ *http.Client, _ *url.Values, _ map[string]string, bodyCreator bodyCreatorType, _ time.Duration) (*authResponse, error) {
+			jsonBody, err := bodyCreator()
+			if err != nil {
+				return nil, err
+			}
+			var body struct {
+				Data struct {
+					ClientEnvironment map[string]interface{} `json:"CLIENT_ENVIRONMENT"`
+				} `json:"data"`
+			}
+			if err = json.Unmarshal(jsonBody, &body); err != nil {
+				return nil, err
+			}
+			clientEnvironment = body.Data.ClientEnvironment
+			return &authResponse{
+				Success: true,
+				Data: authResponseMain{
+					Token:       "t",
+					MasterToken: "m",
+				},
+			}, nil
+		},
+		TokenAccessor: getSimpleTokenAccessor(),
+	}
+	sc := getDefaultSnowflakeConn()
+	sc.rest = sr
+	if _, err := authenticate(context.TODO(), sc, []byte{}, []byte{}); err != nil {
+		t.Fatalf("failed to run. err: %v", err)
+	}
+	for _, name := range []string{"APPLICATION", "OS", "OS_VERSION", "OCSP_MODE"} {
+		if _, ok := clientEnvironment[name]; !ok {
+			t.Fatalf("%v should be sent by default: %v", name, clientEnvironment)
+		}
+	}
+
+	sc.cfg.ClientEnvironment = map[string]string{"COST_CENTER": "42", "OS": "custom"}
+	sc.cfg.OmitClientEnvironment = []string{"OS_VERSION", "OCSP_MODE"}
+	if _, err := authenticate(context.TODO(), sc, []byte{}, []byte{}); err != nil {
+		t.Fatalf("failed to run. err: %v", err)
+	}
+	if clientEnvironment["COST_CENTER"] != "42" || clientEnvironment["OS"] != "custom" {
+		t.Fatalf("the custom entries should be sent: %v", clientEnvironment)
+	}
+	for _, name := range []string{"OS_VERSION", "OCSP_MODE"} {
+		if _, ok := clientEnvironment[name]; ok {
+			t.Fatalf("%v should be omitted: %v", name, clientEnvironment)
+		}
+	}
+	if clientEnvironment["APPLICATION"] != sc.cfg.Application {
+		t.Fatalf("the other default entries should be kept: %v", clientEnvironment)
+	}
+}
+
+func TestUnitAuthenticateClientSessionKeepAlive(t *testing.T) {
+	var sessionParameters map[string]interface{}
+	sr := &snowflakeRestful{
+		FuncPostAuth: func(_ context.Context, _ *snowflakeRestful, _ *http.Client, _ *url.Values, _ map[string]string, bodyCreator bodyCreatorType, _ time.Duration) (*authResponse, error) {
+			var ar authRequest
+			jsonBody, _ := bodyCreator()
+			if err := json.Unmarshal(jsonBody, &ar); err != nil {
+				return nil, err
+			}
+			sessionParameters = ar.Data.SessionParameters
+			return &authResponse{
+				Success: true,
+				Data: authResponseMain{
+					Token:       "t",
+					MasterToken: "m",
+				},
+			}, nil
+		},
+		TokenAccessor: getSimpleTokenAccessor(),
+	}
+	sc := getDefaultSnowflakeConn()
+	sc.rest = sr
+	if _, err := authenticate(context.TODO(), sc, []byte{}, []byte{}); err != nil {
+		t.Fatalf("failed to run. err: %v", err)
+	}
+	if _, ok := sessionParameters[clientSessionKeepAlive]; ok {
+		t.Fatalf("%v should not be sent by default", clientSessionKeepAlive)
+	}
+
+	sc.cfg.ClientSessionKeepAlive = ConfigBoolTrue
+	if _, err := authenticate(context.TODO(), sc, []byte{}, []byte{}); err != nil {
+		t.Fatalf("failed to run. err: %v", err)
+	}
+	if sessionParameters[clientSessionKeepAlive] != true {
+		t.Fatalf("expected %v to be true but was %v", clientSessionKeepAlive, sessionParameters[clientSessionKeepAlive])
+	}
+	if !sc.isClientSessionKeepAliveEnabled() {
+		t.Fatal("client session keep alive should be enabled")
+	}
+}
+
 func TestUnitAuthenticateUsernamePasswordMfa(t *testing.T) {
 	var err error
 	sr := &snowflakeRestful{
@@ -655,6 +897,127 @@ func TestUnitAuthenticateWithConfigMFA(t *testing.T) {
 	}
 }
 
+func TestUnitAuthenticateWithConfigLoginTimeout(t *testing.T) {
+	postAuthSlow := func(ctx context.Context, _ *snowflakeRestful, _ *http.Client, _ *url.Values, _ map[string]string, _ bodyCreatorType, _ time.Duration) (*authResponse, error) {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(10 * time.Second):
+			return &authResponse{Success: true}, nil
+		}
+	}
+	postQuerySlow := func(_ context.Context, _ *snowflakeRestful, _ *url.Values, _ map[string]string, _ []byte, _ time.Duration, _ UUID, _ *Config) (*execResponse, error) {
+		time.Sleep(500 * time.Millisecond)
+		return &execResponse{Code: "0", Success: true}, nil
+	}
+	sc := getDefaultSnowflakeConn()
+	sc.cfg.LoginTimeout = 200 * time.Millisecond
+	sc.cfg.RequestTimeout = 0
+	sc.rest.FuncPostAuth = postAuthSlow
+	sc.ctx = context.Background()
+
+	start := time.Now()
+	err := authenticateWithConfig(sc)
+	if err != context.DeadlineExceeded {
+		t.Fatalf("login should time out, err: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("login should fail at LoginTimeout, took: %v", elapsed)
+	}
+
+	sc = getDefaultSnowflakeConn()
+	sc.cfg.LoginTimeout = 200 * time.Millisecond
+	sc.rest.FuncPostQuery = postQuerySlow
+	sc.ctx = context.Background()
+	sc.queryContextCache = (&queryContextCache{}).init()
+	if _, err = sc.exec(sc.ctx, "SELECT 1", false, false, false, nil); err != nil {
+		t.Fatalf("query should not be bounded by LoginTimeout, err: %v", err)
+	}
+}
+
+func TestUnitAuthenticateWithClientTimezone(t *testing.T) {
+	testcases := []struct {
+		name           string
+		clientTimezone string
+		paramTimezone  string
+		expected       interface{}
+	}{
+		{"default", "", "Europe/Warsaw", "Europe/Warsaw"},
+		{"default without parameter", "", "", nil},
+		{"configured", "America/New_York", "Europe/Warsaw", "America/New_York"},
+		{"server default", ClientTimezoneServerDefault, "Europe/Warsaw", nil},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			var sessionParameters map[string]interface{}
+			sc := getDefaultSnowflakeConn()
+			sc.cfg.ClientTimezone = tc.clientTimezone
+			if tc.paramTimezone != "" {
+				sc.cfg.Params["timezone"] = &tc.paramTimezone
+			}
+			sc.rest.FuncPostAuth = func(ctx context.Context, sr *snowflakeRestful, client *http.Client, params *url.Values,
+				headers map[string]string, bodyCreator bodyCreatorType, timeout time.Duration) (*authResponse, error) {
+				var ar authRequest
+				jsonBody, err := bodyCreator()
+				if err != nil {
+					return nil, err
+				}
+				if err = json.Unmarshal(jsonBody, &ar); err != nil {
+					return nil, err
+				}
+				sessionParameters = ar.Data.SessionParameters
+				return postAuthSuccess(ctx, sr, client, params, headers, bodyCreator, timeout)
+			}
+			sc.ctx = context.Background()
+			if err := authenticateWithConfig(sc); err != nil {
+				t.Fatal(err)
+			}
+			timezone, ok := sessionParameters[sessionTimezone]
+			if tc.expected == nil && ok {
+				t.Fatalf("no timezone should be sent at login, got: %v", timezone)
+			}
+			if tc.expected != nil && timezone != tc.expected {
+				t.Fatalf("expected timezone %v to be sent at login, got: %v", tc.expected, timezone)
+			}
+		})
+	}
+
+	cfg := &Config{ClientTimezone: "Mars/Olympus_Mons"}
+	if se, ok := cfg.Validate().(*SnowflakeError); !ok || se.Number != ErrCodeInvalidClientTimezone {
+		t.Fatalf("expected error %v for an unknown timezone", ErrCodeInvalidClientTimezone)
+	}
+}
+
+func TestUnitAuthenticateWithUserAgent(t *testing.T) {
+	var sentUserAgent string
+	postAuthCheckUserAgent := func(_ context.Context, _ *snowflakeRestful, _ *http.Client, _ *url.Values, headers map[string]string, _ bodyCreatorType, _ time.Duration) (*authResponse, error) {
+		sentUserAgent = headers[httpHeaderUserAgent]
+		return &authResponse{
+			Success: true,
+			Data: authResponseMain{
+				Token:       "t",
+				MasterToken: "m",
+			},
+		}, nil
+	}
+	sc := getDefaultSnowflakeConn()
+	sc.rest.FuncPostAuth = postAuthCheckUserAgent
+	if _, err := authenticate(context.TODO(), sc, []byte{}, []byte{}); err != nil {
+		t.Fatalf("failed to run. err: %v", err)
+	}
+	if sentUserAgent != userAgent {
+		t.Fatalf("default user agent should be sent. expected: %v, got: %v", userAgent, sentUserAgent)
+	}
+
+	sc.cfg.UserAgent = "custom-agent/1.0"
+	if _, err := authenticate(context.TODO(), sc, []byte{}, []byte{}); err != nil {
+		t.Fatalf("failed to run. err: %v", err)
+	}
+	if sentUserAgent != "custom-agent/1.0" {
+		t.Fatalf("custom user agent should be sent, got: %v", sentUserAgent)
+	}
+}
+
 func TestUnitAuthenticateExternalBrowser(t *testing.T) {
 	var err error
 	sr := &snowflakeRestful{
@@ -793,3 +1156,117 @@ func TestDisableExternalBrowserCaching(t *testing.T) {
 		}
 	}
 }
+
+func TestUnitAttachToPreEstablishedSession(t *testing.T) {
+	var validated string
+	postHeartbeat := func(_ context.Context, _ *snowflakeRestful, fullURL *url.URL, headers map[string]string, _ []byte, _ time.Duration, _ bool, _ currentTimeProvider, _ *Config) (*http.Response, error) {
+		if fullURL.Path != heartBeatPath {
+			t.Fatalf("unexpected request to %v", fullURL.Path)
+		}
+		validated = headers[headerAuthorizationKey]
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakeResponseBody{body: []byte(`{"success": true}`)},
+		}, nil
+	}
+	var queryToken string
+	postQuery := func(_ context.Context, sr *snowflakeRestful, _ *url.Values, _ map[string]string, _ []byte, _ time.Duration, _ UUID, _ *Config) (*execResponse, error) {
+		queryToken, _, _ = sr.TokenAccessor.GetTokens()
+		return &execResponse{Code: "0", Success: true}, nil
+	}
+	sc := getDefaultSnowflakeConn()
+	sc.cfg.User = ""
+	sc.cfg.Password = ""
+	sc.cfg.SessionToken = "session_token"
+	sc.cfg.MasterToken = "master_token"
+	sc.cfg.SessionID = 123
+	if err := fillMissingConfigParameters(sc.cfg); err != nil {
+		t.Fatalf("a pre-established session should not require a user or a password, err: %v", err)
+	}
+	sc.rest.FuncPost = postHeartbeat
+	// the login must not be attempted
+	sc.rest.FuncPostAuth = postAuthFailServiceIssue
+	sc.rest.FuncPostQuery = postQuery
+	sc.ctx = context.Background()
+	sc.queryContextCache = (&queryContextCache{}).init()
+
+	if err := authenticateWithConfig(sc); err != nil {
+		t.Fatalf("failed to attach to the session, err: %v", err)
+	}
+	if expected := fmt.Sprintf(headerSnowflakeToken, "session_token"); validated != expected {
+		t.Fatalf("the session token should be validated, got: %q", validated)
+	}
+	if token, masterToken, sessionID := sc.rest.TokenAccessor.GetTokens(); token != "session_token" || masterToken != "master_token" || sessionID != 123 {
+		t.Fatalf("unexpected tokens: %v, %v, %v", token, masterToken, sessionID)
+	}
+	if sc.cfg.Database != "d" || sc.cfg.Warehouse != "w" {
+		t.Fatalf("unexpected session info: %v, %v", sc.cfg.Database, sc.cfg.Warehouse)
+	}
+	if _, err := sc.exec(sc.ctx, "SELECT 1", false, false, false, nil); err != nil {
+		t.Fatalf("failed to query, err: %v", err)
+	}
+	if queryToken != "session_token" {
+		t.Fatalf("the query should use the session token, got: %q", queryToken)
+	}
+	if !sc.IsValid() {
+		t.Fatal("the attached connection should be kept in the pool")
+	}
+
+	var closed int
+	closeSession := func(_ context.Context, _ *snowflakeRestful, _ time.Duration) error {
+		closed++
+		return nil
+	}
+	for _, closeAttachedSession := range []bool{false, true} {
+		closing := &snowflakeConn{
+			cfg:       &Config{SessionToken: "session_token", MasterToken: "master_token", CloseAttachedSession: closeAttachedSession},
+			rest:      &snowflakeRestful{FuncCloseSession: closeSession},
+			telemetry: testTelemetry,
+		}
+		closed = 0
+		if err := closing.Close(); err != nil {
+			t.Fatal(err)
+		}
+		expected := 0
+		if closeAttachedSession {
+			expected = 1
+		}
+		if closed != expected {
+			t.Fatalf("CloseAttachedSession: %v. unexpected number of closed sessions: %v", closeAttachedSession, closed)
+		}
+	}
+}
+
+func TestUnitAttachToInvalidSession(t *testing.T) {
+	postHeartbeat := func(_ context.Context, _ *snowflakeRestful, _ *url.URL, _ map[string]string, _ []byte, _ time.Duration, _ bool, _ currentTimeProvider, _ *Config) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakeResponseBody{body: []byte(`{"success": false, "code": "390104", "message": "invalid token"}`)},
+		}, nil
+	}
+	sc := getDefaultSnowflakeConn()
+	sc.cfg.SessionToken = "session_token"
+	sc.cfg.MasterToken = "master_token"
+	sc.cfg.SessionID = 123
+	sc.rest.FuncPost = postHeartbeat
+	sc.ctx = context.Background()
+	ta := sc.rest.TokenAccessor
+
+	err := authenticateWithConfig(sc)
+	driverErr, ok := err.(*SnowflakeError)
+	if !ok || driverErr.Number != 390104 {
+		t.Fatalf("should have failed with the server error, err: %v", err)
+	}
+	if token, _, sessionID := ta.GetTokens(); token != "" || sessionID != -1 {
+		t.Fatalf("the tokens should be cleared, got: %v, %v", token, sessionID)
+	}
+
+	cfg := &Config{Account: "a", User: "u", Password: "p", MasterToken: "master_token"}
+	if err = cfg.Validate(); err == nil || err.(*SnowflakeError).Number != ErrCodeMissingSessionToken {
+		t.Fatalf("the master token should require a session token, err: %v", err)
+	}
+	cfg = &Config{Account: "a", SessionToken: "session_token", SessionID: 123}
+	if err = cfg.Validate(); err == nil || err.(*SnowflakeError).Number != ErrCodeMissingMasterToken {
+		t.Fatalf("the session token should require a master token, err: %v", err)
+	}
+}
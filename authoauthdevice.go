@@ -0,0 +1,151 @@
+// Copyright (c) 2023 Snowflake Computing Inc. All rights reserved.
+
+package gosnowflake
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	oauthDeviceCodeGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+	// defaultOAuthDeviceCodeInterval is the polling interval used if the authorization server doesn't send one
+	defaultOAuthDeviceCodeInterval = 5
+	// oauthDeviceCodeSlowDown is added to the polling interval when the authorization server asks to slow down
+	oauthDeviceCodeSlowDown = 5
+)
+
+// oauthDeviceCodeIntervalUnit is the unit of the polling intervals sent by the authorization server
+var oauthDeviceCodeIntervalUnit = time.Second
+
+// OAuthDeviceCode is the code a user has to enter at the verification URI of the
+// authorization server to complete an OAuth device authorization. See Config.OnOAuthDeviceCode.
+type OAuthDeviceCode struct {
+	UserCode                string
+	VerificationURI         string
+	VerificationURIComplete string        // VerificationURI including the user code. Empty if not sent by the authorization server
+	ExpiresIn               time.Duration // How long the code can be entered
+}
+
+type oauthDeviceAuthorizationResponse struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int64  `json:"expires_in"`
+	Interval                int64  `json:"interval"`
+}
+
+type oauthTokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// authenticateByOAuthDeviceCode runs the OAuth 2.0 device authorization grant (RFC 8628) against the
+// authorization server in the config and returns the access token used to log in to Snowflake.
+func authenticateByOAuthDeviceCode(ctx context.Context, client *http.Client, cfg *Config) (string, error) {
+	form := url.Values{}
+	form.Set("client_id", cfg.OAuthClientID)
+	if cfg.OAuthScope != "" {
+		form.Set("scope", cfg.OAuthScope)
+	}
+	var authorization oauthDeviceAuthorizationResponse
+	status, err := postOAuthForm(ctx, client, cfg.OAuthDeviceAuthorizationURL, form, &authorization)
+	if err != nil {
+		return "", err
+	}
+	if status != http.StatusOK || authorization.DeviceCode == "" {
+		return "", errFailedToAuthOAuthDeviceCode(fmt.Sprintf("device authorization failed with HTTP status %v", status))
+	}
+
+	code := OAuthDeviceCode{
+		UserCode:                authorization.UserCode,
+		VerificationURI:         authorization.VerificationURI,
+		VerificationURIComplete: authorization.VerificationURIComplete,
+		ExpiresIn:               time.Duration(authorization.ExpiresIn) * time.Second,
+	}
+	if cfg.OnOAuthDeviceCode != nil {
+		cfg.OnOAuthDeviceCode(ctx, code)
+	} else {
+		fmt.Fprintf(os.Stderr, "To log in, open %v and enter the code %v\n", code.VerificationURI, code.UserCode)
+	}
+
+	// the user interaction is bounded by the expiration of the code instead of the login timeout
+	var expired <-chan time.Time
+	if code.ExpiresIn > 0 {
+		timer := time.NewTimer(code.ExpiresIn)
+		defer timer.Stop()
+		expired = timer.C
+	}
+	interval := authorization.Interval
+	if interval <= 0 {
+		interval = defaultOAuthDeviceCodeInterval
+	}
+	form = url.Values{}
+	form.Set("grant_type", oauthDeviceCodeGrantType)
+	form.Set("device_code", authorization.DeviceCode)
+	form.Set("client_id", cfg.OAuthClientID)
+	if cfg.OAuthClientSecret != "" {
+		form.Set("client_secret", cfg.OAuthClientSecret)
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-expired:
+			return "", errFailedToAuthOAuthDeviceCode("the device code expired before the authorization was completed")
+		case <-time.After(time.Duration(interval) * oauthDeviceCodeIntervalUnit):
+		}
+		var token oauthTokenResponse
+		if status, err = postOAuthForm(ctx, client, cfg.OAuthTokenURL, form, &token); err != nil {
+			return "", err
+		}
+		switch {
+		case status == http.StatusOK && token.AccessToken != "":
+			logger.WithContext(ctx).Info("OAuth device authorization completed")
+			return token.AccessToken, nil
+		case token.Error == "authorization_pending":
+			logger.WithContext(ctx).Debug("waiting for the user to complete the OAuth device authorization")
+		case token.Error == "slow_down":
+			interval += oauthDeviceCodeSlowDown
+		case token.Error != "":
+			return "", errFailedToAuthOAuthDeviceCode(strings.TrimSpace(token.Error + " " + token.ErrorDescription))
+		default:
+			return "", errFailedToAuthOAuthDeviceCode(fmt.Sprintf("token request failed with HTTP status %v", status))
+		}
+	}
+}
+
+// postOAuthForm posts the form to the authorization server and decodes the JSON response into v.
+func postOAuthForm(ctx context.Context, client *http.Client, endpoint string, form url.Values, v interface{}) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set(httpHeaderContentType, "application/x-www-form-urlencoded")
+	req.Header.Set(httpHeaderAccept, headerContentTypeApplicationJSON)
+	res, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return res.StatusCode, err
+	}
+	if len(body) > 0 {
+		if err = json.Unmarshal(body, v); err != nil {
+			return res.StatusCode, errFailedToAuthOAuthDeviceCode(
+				fmt.Sprintf("failed to parse the response with HTTP status %v: %v", res.StatusCode, err))
+		}
+	}
+	return res.StatusCode, nil
+}
@@ -0,0 +1,152 @@
+// Copyright (c) 2023 Snowflake Computing Inc. All rights reserved.
+
+package gosnowflake
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newOAuthDeviceCodeServer returns an authorization server completing the device authorization
+// after the given number of pending token requests, or denying it if deny is true.
+func newOAuthDeviceCodeServer(t *testing.T, pending int32, deny bool) (*httptest.Server, *int32) {
+	var tokenRequests int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/device", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Error(err)
+		}
+		if r.PostForm.Get("client_id") != "client" || r.PostForm.Get("scope") != "session:role:analyst" {
+			t.Errorf("unexpected device authorization request: %v", r.PostForm)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"device_code": "device", "user_code": "ABCD-1234", "verification_uri": "https://idp.example.com/device",
+			"verification_uri_complete": "https://idp.example.com/device?user_code=ABCD-1234", "expires_in": 600, "interval": 1}`))
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Error(err)
+		}
+		if r.PostForm.Get("grant_type") != oauthDeviceCodeGrantType || r.PostForm.Get("device_code") != "device" ||
+			r.PostForm.Get("client_id") != "client" || r.PostForm.Get("client_secret") != "secret" {
+			t.Errorf("unexpected token request: %v", r.PostForm)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		n := atomic.AddInt32(&tokenRequests, 1)
+		switch {
+		case n <= pending:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error": "authorization_pending"}`))
+		case deny:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error": "access_denied", "error_description": "the user denied the request"}`))
+		default:
+			w.Write([]byte(`{"access_token": "device-access-token", "token_type": "Bearer"}`))
+		}
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server, &tokenRequests
+}
+
+func getOAuthDeviceCodeSnowflakeConn(server *httptest.Server) *snowflakeConn {
+	sc := getDefaultSnowflakeConn()
+	sc.ctx = context.Background()
+	sc.rest.Client = server.Client()
+	sc.cfg.Authenticator = AuthTypeOAuthDeviceCode
+	sc.cfg.OAuthDeviceAuthorizationURL = server.URL + "/device"
+	sc.cfg.OAuthTokenURL = server.URL + "/token"
+	sc.cfg.OAuthClientID = "client"
+	sc.cfg.OAuthClientSecret = "secret"
+	sc.cfg.OAuthScope = "session:role:analyst"
+	return sc
+}
+
+func TestUnitAuthenticateOAuthDeviceCode(t *testing.T) {
+	defer func(unit time.Duration) { oauthDeviceCodeIntervalUnit = unit }(oauthDeviceCodeIntervalUnit)
+	oauthDeviceCodeIntervalUnit = time.Millisecond
+	server, tokenRequests := newOAuthDeviceCodeServer(t, 2, false)
+	sc := getOAuthDeviceCodeSnowflakeConn(server)
+	var codes []OAuthDeviceCode
+	sc.cfg.OnOAuthDeviceCode = func(_ context.Context, code OAuthDeviceCode) {
+		codes = append(codes, code)
+	}
+	sc.rest.FuncPostAuth = func(_ context.Context, _ *snowflakeRestful, _ *http.Client, _ *url.Values,
+		_ map[string]string, bodyCreator bodyCreatorType, _ time.Duration) (*authResponse, error) {
+		var ar authRequest
+		jsonBody, _ := bodyCreator()
+		if err := json.Unmarshal(jsonBody, &ar); err != nil {
+			return nil, err
+		}
+		if ar.Data.Authenticator != AuthTypeOAuth.String() || ar.Data.Token != "device-access-token" {
+			return nil, errors.New("unexpected authenticator or token: " + ar.Data.Authenticator + ", " + ar.Data.Token)
+		}
+		return &authResponse{
+			Success: true,
+			Data:    authResponseMain{Token: "t", MasterToken: "m", SessionID: 1},
+		}, nil
+	}
+
+	if err := authenticateWithConfig(sc); err != nil {
+		t.Fatal(err)
+	}
+	expected := OAuthDeviceCode{
+		UserCode:                "ABCD-1234",
+		VerificationURI:         "https://idp.example.com/device",
+		VerificationURIComplete: "https://idp.example.com/device?user_code=ABCD-1234",
+		ExpiresIn:               10 * time.Minute,
+	}
+	if len(codes) != 1 || codes[0] != expected {
+		t.Fatalf("unexpected device codes. expected: %+v, got: %+v", expected, codes)
+	}
+	if n := atomic.LoadInt32(tokenRequests); n != 3 {
+		t.Fatalf("expected 3 token requests, got: %v", n)
+	}
+	if token, _, _ := sc.rest.TokenAccessor.GetTokens(); token != "t" {
+		t.Fatalf("unexpected session token: %v", token)
+	}
+}
+
+func TestUnitAuthenticateOAuthDeviceCodeDenied(t *testing.T) {
+	defer func(unit time.Duration) { oauthDeviceCodeIntervalUnit = unit }(oauthDeviceCodeIntervalUnit)
+	oauthDeviceCodeIntervalUnit = time.Millisecond
+	server, _ := newOAuthDeviceCodeServer(t, 1, true)
+	sc := getOAuthDeviceCodeSnowflakeConn(server)
+	sc.cfg.OnOAuthDeviceCode = func(_ context.Context, _ OAuthDeviceCode) {}
+	sc.rest.FuncPostAuth = func(_ context.Context, _ *snowflakeRestful, _ *http.Client, _ *url.Values,
+		_ map[string]string, _ bodyCreatorType, _ time.Duration) (*authResponse, error) {
+		t.Fatal("should not log in to Snowflake")
+		return nil, nil
+	}
+
+	err := authenticateWithConfig(sc)
+	var se *SnowflakeError
+	if !errors.As(err, &se) || se.Number != ErrFailedToAuthOAuthDeviceCode {
+		t.Fatalf("expected error %v, got: %v", ErrFailedToAuthOAuthDeviceCode, err)
+	}
+}
+
+func TestOAuthDeviceCodeRequiredParameters(t *testing.T) {
+	cfg := &Config{
+		Account:                     "a",
+		Authenticator:               AuthTypeOAuthDeviceCode,
+		OAuthDeviceAuthorizationURL: "https://idp.example.com/device",
+		OAuthTokenURL:               "https://idp.example.com/token",
+	}
+	err := fillMissingConfigParameters(cfg)
+	var se *SnowflakeError
+	if !errors.As(err, &se) || se.Number != ErrCodeEmptyOAuthDeviceCodeParameter {
+		t.Fatalf("expected error %v, got: %v", ErrCodeEmptyOAuthDeviceCodeParameter, err)
+	}
+	cfg.OAuthClientID = "client"
+	if err = fillMissingConfigParameters(cfg); err != nil {
+		t.Fatalf("user and password should not be required. err: %v", err)
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -111,7 +112,7 @@ func authenticateBySAML(
 	if tokenURL, err = url.Parse(respd.Data.TokenURL); err != nil {
 		return nil, fmt.Errorf("failed to parse token URL. %v", respd.Data.TokenURL)
 	}
-	if ssoURL, err = url.Parse(respd.Data.TokenURL); err != nil {
+	if ssoURL, err = url.Parse(respd.Data.SSOURL); err != nil {
 		return nil, fmt.Errorf("failed to parse ssoURL URL. %v", respd.Data.SSOURL)
 	}
 	if !isPrefixEqual(oktaURL, ssoURL) || !isPrefixEqual(oktaURL, tokenURL) {
@@ -194,11 +195,11 @@ func isPrefixEqual(u1 *url.URL, u2 *url.URL) bool {
 	if p1 == "" && u1.Scheme == "https" {
 		p1 = "443"
 	}
-	p2 := u1.Port()
-	if p2 == "" && u1.Scheme == "https" {
+	p2 := u2.Port()
+	if p2 == "" && u2.Scheme == "https" {
 		p2 = "443"
 	}
-	return u1.Hostname() == u2.Hostname() && p1 == p2 && u1.Scheme == u2.Scheme
+	return strings.EqualFold(u1.Hostname(), u2.Hostname()) && p1 == p2 && u1.Scheme == u2.Scheme
 }
 
 // Makes a request to /session/authenticator-request to get SAML Information,
@@ -4,6 +4,7 @@ package gosnowflake
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"net/http"
 	"net/url"
@@ -238,3 +239,79 @@ func TestUnitAuthenticateBySAML(t *testing.T) {
 		t.Fatalf("failed. err: %v", err)
 	}
 }
+
+func TestUnitAuthenticateBySAMLWithOktaURLPath(t *testing.T) {
+	for _, authenticator := range []string{"https://company.okta.com", "https://company.okta.com/app/Snowflake/exk1AbC/sso/saml"} {
+		t.Run(authenticator, func(t *testing.T) {
+			cfg := &Config{}
+			if err := determineAuthenticatorType(cfg, authenticator); err != nil {
+				t.Fatal(err)
+			}
+			if cfg.Authenticator != AuthTypeOkta || cfg.OktaURL.String() != authenticator {
+				t.Fatalf("unexpected authenticator: %v, %v", cfg.Authenticator, cfg.OktaURL)
+			}
+			var requestedAuthenticator, tokenURL, ssoURL string
+			sr := &snowflakeRestful{
+				Protocol: "https",
+				Host:     "a.snowflakecomputing.com",
+				Port:     443,
+				FuncPostAuthSAML: func(_ context.Context, _ *snowflakeRestful, _ map[string]string, body []byte, _ time.Duration) (*authResponse, error) {
+					var req authRequest
+					if err := json.Unmarshal(body, &req); err != nil {
+						t.Fatal(err)
+					}
+					requestedAuthenticator = req.Data.Authenticator
+					return &authResponse{
+						Success: true,
+						Data: authResponseMain{
+							TokenURL: "https://company.okta.com/api/v1/authn",
+							SSOURL:   "https://company.okta.com/app/snowflake/exk1AbC/sso/saml",
+						},
+					}, nil
+				},
+				FuncPostAuthOKTA: func(_ context.Context, _ *snowflakeRestful, _ map[string]string, _ []byte, fullURL string, _ time.Duration) (*authOKTAResponse, error) {
+					tokenURL = fullURL
+					return &authOKTAResponse{SessionToken: "token"}, nil
+				},
+				FuncGetSSO: func(_ context.Context, _ *snowflakeRestful, _ *url.Values, _ map[string]string, fullURL string, _ time.Duration) ([]byte, error) {
+					ssoURL = fullURL
+					return []byte(`<html><form id="1" action="https&#x3a;&#x2f;&#x2f;a.snowflakecomputing.com&#x2f;fed&#x2f;login"></form></html>`), nil
+				},
+				TokenAccessor: getSimpleTokenAccessor(),
+			}
+			if _, err := authenticateBySAML(context.TODO(), sr, cfg.OktaURL, "testapp", "a", "u", "p"); err != nil {
+				t.Fatalf("failed. err: %v", err)
+			}
+			if requestedAuthenticator != authenticator {
+				t.Fatalf("the Okta URL should be sent with its path, got: %v", requestedAuthenticator)
+			}
+			if tokenURL != "https://company.okta.com/api/v1/authn" || ssoURL != "https://company.okta.com/app/snowflake/exk1AbC/sso/saml" {
+				t.Fatalf("unexpected IdP URLs: %v, %v", tokenURL, ssoURL)
+			}
+		})
+	}
+}
+
+func TestUnitAuthenticateBySAMLRejectsForeignSSOURL(t *testing.T) {
+	sr := &snowflakeRestful{
+		Protocol: "https",
+		Host:     "a.snowflakecomputing.com",
+		Port:     443,
+		FuncPostAuthSAML: func(_ context.Context, _ *snowflakeRestful, _ map[string]string, _ []byte, _ time.Duration) (*authResponse, error) {
+			return &authResponse{
+				Success: true,
+				Data: authResponseMain{
+					TokenURL: "https://company.okta.com/api/v1/authn",
+					SSOURL:   "https://impostor.example.com/sso/saml",
+				},
+			}, nil
+		},
+		TokenAccessor: getSimpleTokenAccessor(),
+	}
+	oktaURL := &url.URL{Scheme: "https", Host: "company.okta.com", Path: "/path"}
+	_, err := authenticateBySAML(context.TODO(), sr, oktaURL, "testapp", "a", "u", "p")
+	driverErr, ok := err.(*SnowflakeError)
+	if !ok || driverErr.Number != ErrCodeIdpConnectionError {
+		t.Fatalf("the SSO URL should be validated against the Okta URL, err: %v", err)
+	}
+}
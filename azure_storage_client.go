@@ -57,6 +57,15 @@ func (util *snowflakeAzureClient) createClient(info *execResponseStageInfo, _ bo
 	return client, nil
 }
 
+// azureContainerOptions sets the client of the Azure requests unless it is nil.
+func azureContainerOptions(client *http.Client) *container.ClientOptions {
+	options := &container.ClientOptions{}
+	if client != nil {
+		options.Transport = client
+	}
+	return options
+}
+
 // cloudUtil implementation
 func (util *snowflakeAzureClient) getFileHeader(meta *fileMetadata, filename string) (*fileHeader, error) {
 	client, ok := meta.client.(*azblob.Client)
@@ -69,7 +78,7 @@ func (util *snowflakeAzureClient) getFileHeader(meta *fileMetadata, filename str
 		return nil, err
 	}
 	path := azureLoc.path + strings.TrimLeft(filename, "/")
-	containerClient, err := container.NewClientWithNoCredential(client.URL(), &container.ClientOptions{})
+	containerClient, err := container.NewClientWithNoCredential(client.URL(), azureContainerOptions(meta.storageHTTPClient(false)))
 	if err != nil {
 		return nil, &SnowflakeError{
 			Message: "failed to create container client",
@@ -127,10 +136,15 @@ func (util *snowflakeAzureClient) getFileHeader(meta *fileMetadata, filename str
 		// sfcdigest is not in response, use empty string
 		digest = new(string)
 	}
+	var etag string
+	if resp.ETag != nil {
+		etag = string(*resp.ETag)
+	}
 	return &fileHeader{
 		*digest,
 		int64(len(metadata)),
 		&encryptionMetadata,
+		etag,
 	}, nil
 }
 
@@ -181,7 +195,7 @@ func (util *snowflakeAzureClient) uploadFile(
 			Message: "failed to cast to azure client",
 		}
 	}
-	containerClient, err := container.NewClientWithNoCredential(client.URL(), &container.ClientOptions{})
+	containerClient, err := container.NewClientWithNoCredential(client.URL(), azureContainerOptions(meta.storageHTTPClient(false)))
 	if err != nil {
 		return &SnowflakeError{
 			Message: "failed to create container client",
@@ -198,7 +212,7 @@ func (util *snowflakeAzureClient) uploadFile(
 		if meta.realSrcStream != nil {
 			uploadSrc = meta.realSrcStream
 		}
-		_, err = blobClient.UploadStream(context.Background(), uploadSrc, &azblob.UploadStreamOptions{
+		_, err = blobClient.UploadStream(meta.transferContext(), uploadSrc, &azblob.UploadStreamOptions{
 			BlockSize: int64(uploadSrc.Len()),
 			Metadata:  azureMeta,
 		})
@@ -223,7 +237,7 @@ func (util *snowflakeAzureClient) uploadFile(
 		if meta.options.putAzureCallback != nil {
 			blobOptions.Progress = meta.options.putAzureCallback.call
 		}
-		_, err = blobClient.UploadFile(context.Background(), f, blobOptions)
+		_, err = blobClient.UploadFile(meta.transferContext(), f, blobOptions)
 	}
 	if err != nil {
 		var se *azcore.ResponseError
@@ -261,7 +275,7 @@ func (util *snowflakeAzureClient) nativeDownloadFile(
 			Message: "failed to cast to azure client",
 		}
 	}
-	containerClient, err := container.NewClientWithNoCredential(client.URL(), &container.ClientOptions{})
+	containerClient, err := container.NewClientWithNoCredential(client.URL(), azureContainerOptions(meta.storageHTTPClient(true)))
 	if err != nil {
 		return &SnowflakeError{
 			Message: "failed to create container client",
@@ -279,7 +293,7 @@ func (util *snowflakeAzureClient) nativeDownloadFile(
 	}
 	defer f.Close()
 	_, err = blobClient.DownloadFile(
-		context.Background(), f, &azblob.DownloadFileOptions{
+		meta.transferContext(), f, &azblob.DownloadFileOptions{
 			Concurrency: uint16(maxConcurrency)})
 	if err != nil {
 		return err
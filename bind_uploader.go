@@ -9,22 +9,58 @@ import (
 	"database/sql/driver"
 	"fmt"
 	"reflect"
+	"regexp"
 	"strconv"
 	"strings"
 )
 
 const (
-	bindStageName            = "SYSTEM$BIND"
-	createTemporaryStageStmt = "CREATE OR REPLACE TEMPORARY STAGE " + bindStageName +
-		" file_format=" + "(type=csv field_optionally_enclosed_by='\"')"
+	// defaultTempStagePrefix is the prefix of the temporary stages if no other one is configured
+	defaultTempStagePrefix   = "SYSTEM$"
+	bindStageName            = defaultTempStagePrefix + "BIND"
+	bindStageFileFormat      = " file_format=" + "(type=csv field_optionally_enclosed_by='\"')"
+	createTemporaryStageStmt = "CREATE OR REPLACE TEMPORARY STAGE " + bindStageName + bindStageFileFormat
 
 	// size (in bytes) of max input stream (10MB default) as per JDBC specs
 	inputStreamBufferSize = 1024 * 1024 * 10
 )
 
+var tempStagePrefixRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_$]*$`)
+
+// isValidTempStagePrefix returns true if the prefix is a valid unquoted identifier.
+func isValidTempStagePrefix(prefix string) bool {
+	return tempStagePrefixRegexp.MatchString(prefix)
+}
+
+// tempObjectLocationRegexp matches a database and a schema, each unquoted or double-quoted.
+var tempObjectLocationRegexp = regexp.MustCompile(`^` + rawBindIdentifierPart + `\.` + rawBindIdentifierPart + `$`)
+
+// isValidTempObjectLocation returns true if the location is a schema qualified with its database.
+func isValidTempObjectLocation(location string) bool {
+	return tempObjectLocationRegexp.MatchString(location)
+}
+
+// tempStageName returns the name of a temporary stage created by the driver, prefixed as set with
+// WithTempStagePrefix or Config.TempStagePrefix and qualified with Config.TempObjectLocation if set.
+// cfg may be nil.
+func tempStageName(ctx context.Context, cfg *Config, name string) string {
+	if prefix, ok := ctx.Value(tempStagePrefix).(string); ok {
+		name = prefix + name
+	} else if cfg != nil && cfg.TempStagePrefix != "" {
+		name = cfg.TempStagePrefix + name
+	} else {
+		name = defaultTempStagePrefix + name
+	}
+	if cfg != nil && cfg.TempObjectLocation != "" {
+		return cfg.TempObjectLocation + "." + name
+	}
+	return name
+}
+
 type bindUploader struct {
 	ctx            context.Context
 	sc             *snowflakeConn
+	stageName      string
 	stagePath      string
 	fileCount      int
 	arrayBindStage string
@@ -92,7 +128,8 @@ func (bu *bindUploader) createStageIfNeeded() error {
 	if bu.arrayBindStage != "" {
 		return nil
 	}
-	data, err := bu.sc.exec(bu.ctx, createTemporaryStageStmt, false, false, false, []driver.NamedValue{})
+	createStageStmt := "CREATE OR REPLACE TEMPORARY STAGE " + bu.stageName + bindStageFileFormat
+	data, err := bu.sc.exec(bu.ctx, createStageStmt, false, false, false, []driver.NamedValue{})
 	if err != nil {
 		newThreshold := "0"
 		bu.sc.cfg.Params[sessionArrayBindStageThreshold] = &newThreshold
@@ -110,7 +147,7 @@ func (bu *bindUploader) createStageIfNeeded() error {
 			QueryID:  data.Data.QueryID,
 		}).exceptionTelemetry(bu.sc)
 	}
-	bu.arrayBindStage = bindStageName
+	bu.arrayBindStage = bu.stageName
 	return nil
 }
 
@@ -191,25 +228,38 @@ func (sc *snowflakeConn) processBindings(
 	arrayBindThreshold := sc.getArrayBindStageThreshold()
 	numBinds := arrayBindValueCount(bindings)
 	if 0 < arrayBindThreshold && arrayBindThreshold <= numBinds && !describeOnly && isArrayBind(bindings) {
+		stageName := tempStageName(ctx, sc.cfg, "BIND")
 		uploader := bindUploader{
 			sc:        sc,
 			ctx:       ctx,
-			stagePath: "@" + bindStageName + "/" + requestID.String(),
+			stageName: stageName,
+			stagePath: "@" + stageName + "/" + requestID.String(),
 		}
-		uploader.upload(bindings)
-		req.Bindings = nil
-		req.BindStage = uploader.stagePath
-	} else {
-		var err error
-		req.Bindings, err = getBindValues(bindings)
-		if err != nil {
-			return err
+		_, err := uploader.upload(bindings)
+		if err == nil {
+			req.Bindings = nil
+			req.BindStage = uploader.stagePath
+			return nil
 		}
-		req.BindStage = ""
+		// e.g. no privilege to create a stage. The binds are sent with the query instead.
+		logger.WithContext(ctx).Warnf("failed to upload binds to %v, sending them with the query: %v", uploader.stagePath, err)
 	}
+	var err error
+	req.Bindings, err = getBindValues(bindings)
+	if err != nil {
+		return err
+	}
+	req.BindStage = ""
 	return nil
 }
 
+// removeBindStageFiles removes the binds uploaded to the stage once the statement has been executed.
+func (sc *snowflakeConn) removeBindStageFiles(ctx context.Context, stagePath string) {
+	if _, err := sc.exec(ctx, "REMOVE "+stagePath, false, true, false, []driver.NamedValue{}); err != nil {
+		logger.WithContext(ctx).Warnf("failed to remove binds from %v: %v", stagePath, err)
+	}
+}
+
 func getBindValues(bindings []driver.NamedValue) (map[string]execBindParameter, error) {
 	tsmode := timestampNtzType
 	idx := 1
@@ -311,6 +361,19 @@ func supportedArrayBind(nv *driver.NamedValue) bool {
 	}
 }
 
+// supportedObjectBind returns true for maps with string keys and struct types registered
+// with RegisterObjectType which are bound as OBJECT
+func supportedObjectBind(nv *driver.NamedValue) bool {
+	if nv.Value == nil {
+		return false
+	}
+	if registeredObjectSchema(nv.Value) != nil {
+		return true
+	}
+	t := reflect.TypeOf(nv.Value)
+	return t.Kind() == reflect.Map && t.Key().Kind() == reflect.String
+}
+
 func supportedNullBind(nv *driver.NamedValue) bool {
 	switch reflect.TypeOf(nv.Value) {
 	case reflect.TypeOf(sql.NullString{}), reflect.TypeOf(sql.NullInt64{}),
@@ -4,13 +4,22 @@ package gosnowflake
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
 	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
 	"fmt"
+	"io"
 	"math/big"
 	"math/rand"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
 	"reflect"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 )
@@ -292,6 +301,335 @@ func TestBindingTimeInStruct(t *testing.T) {
 	})
 }
 
+func TestBindingObject(t *testing.T) {
+	runDBTest(t, func(dbt *DBTest) {
+		expected := map[string]interface{}{
+			"a": float64(1),
+			"b": map[string]interface{}{
+				"c": "d",
+				"e": []interface{}{float64(1), "f"},
+			},
+		}
+		dbt.mustExec("CREATE OR REPLACE TABLE test_object (id int, value OBJECT)")
+		defer dbt.mustExec("DROP TABLE IF EXISTS test_object")
+		dbt.mustExec("INSERT INTO test_object SELECT 1, ?", expected)
+		rows := dbt.mustQuery("SELECT value FROM test_object WHERE id = 1")
+		defer rows.Close()
+		if !rows.Next() {
+			dbt.Fatal("no data")
+		}
+		var out string
+		if err := rows.Scan(&out); err != nil {
+			dbt.Fatal(err)
+		}
+		var actual map[string]interface{}
+		if err := json.Unmarshal([]byte(out), &actual); err != nil {
+			dbt.Fatal(err)
+		}
+		if !reflect.DeepEqual(expected, actual) {
+			dbt.Fatalf("expected %v, got %v", expected, actual)
+		}
+	})
+}
+
+func TestGetBindValuesObject(t *testing.T) {
+	bindings := []driver.NamedValue{
+		{Ordinal: 1, Value: map[string]interface{}{"a": map[string]interface{}{"b": []int{1, 2}}}},
+	}
+	bindValues, err := getBindValues(bindings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bindValues["1"].Type != "OBJECT" {
+		t.Fatalf("expected OBJECT bind type, got: %v", bindValues["1"].Type)
+	}
+	if v, ok := bindValues["1"].Value.(*string); !ok || *v != `{"a":{"b":[1,2]}}` {
+		t.Fatalf("unexpected bind value: %v", bindValues["1"].Value)
+	}
+}
+
+// bindStagePostQueryMock responds to the queries executed while uploading array binds
+// to a local stage in stageDir and records them.
+func bindStagePostQueryMock(t *testing.T, stageDir string, requests *[]execRequest) func(context.Context, *snowflakeRestful,
+	*url.Values, map[string]string, []byte, time.Duration, UUID, *Config) (*execResponse, error) {
+	return func(_ context.Context, _ *snowflakeRestful, _ *url.Values,
+		_ map[string]string, body []byte, _ time.Duration, _ UUID, _ *Config) (*execResponse, error) {
+		var req execRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Fatal(err)
+		}
+		*requests = append(*requests, req)
+		switch {
+		case strings.HasPrefix(req.SQLText, "put "):
+			return &execResponse{
+				Data: execResponseData{
+					Command:           string(uploadCommand),
+					SrcLocations:      []string{"/tmp/placeholder/1"},
+					AutoCompress:      true,
+					SourceCompression: "auto_detect",
+					StageInfo: execResponseStageInfo{
+						LocationType: string(local),
+						Location:     stageDir,
+					},
+				},
+				Success: true,
+			}, nil
+		case strings.HasPrefix(req.SQLText, "INSERT"):
+			inserted := "100"
+			return &execResponse{
+				Data: execResponseData{
+					StatementTypeID: statementTypeIDDml,
+					RowType:         []execResponseRowType{{Name: "number of rows inserted", Type: "fixed"}},
+					RowSet:          [][]*string{{&inserted}},
+				},
+				Success: true,
+			}, nil
+		}
+		return &execResponse{Success: true}, nil
+	}
+}
+
+func TestArrayBindStageUpload(t *testing.T) {
+	stageDir := t.TempDir()
+	var requests []execRequest
+	sc := getDefaultSnowflakeConn()
+	sc.ctx = context.Background()
+	sc.cfg.ArrayBindStageThreshold = 10
+	sc.rest.FuncPostQuery = bindStagePostQueryMock(t, stageDir, &requests)
+
+	ids := make([]int, 100)
+	names := make([]string, 100)
+	for i := range ids {
+		ids[i] = i
+		names[i] = fmt.Sprintf("name%v", i)
+	}
+	result, err := sc.ExecContext(context.Background(), "INSERT INTO t VALUES (?, ?)", []driver.NamedValue{
+		{Ordinal: 1, Value: Array(&ids)},
+		{Ordinal: 2, Value: Array(&names)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if affected != 100 {
+		t.Fatalf("expected 100 affected rows, got: %v", affected)
+	}
+
+	expectedPrefixes := []string{createTemporaryStageStmt, "put ", "INSERT", "REMOVE @" + bindStageName + "/"}
+	if len(requests) != len(expectedPrefixes) {
+		t.Fatalf("unexpected queries: %v", requests)
+	}
+	for i, prefix := range expectedPrefixes {
+		if !strings.HasPrefix(requests[i].SQLText, prefix) {
+			t.Fatalf("query %v should start with %q, got: %v", i, prefix, requests[i].SQLText)
+		}
+	}
+	insert := requests[2]
+	if insert.Bindings != nil || !strings.HasPrefix(insert.BindStage, "@"+bindStageName+"/") {
+		t.Fatalf("binds should be read from the stage. bindings: %v, stage: %v", insert.Bindings, insert.BindStage)
+	}
+	if requests[3].SQLText != "REMOVE "+insert.BindStage {
+		t.Fatalf("unexpected cleanup query: %v", requests[3].SQLText)
+	}
+
+	// the staged binds are compressed CSV
+	f, err := os.Open(filepath.Join(stageDir, "1.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	r, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(content), "0,name0\n1,name1\n") {
+		t.Fatalf("unexpected staged binds: %q", content[:20])
+	}
+}
+
+func TestArrayBindStagePrefix(t *testing.T) {
+	testcases := []struct {
+		name      string
+		cfgPrefix string
+		ctxPrefix string
+		location  string
+		stage     string
+	}{
+		{"config", "GOV_TMP_", "", "", "GOV_TMP_BIND"},
+		{"context", "GOV_TMP_", "ETL_", "", "ETL_BIND"},
+		{"location", "", "", `SCRATCH_DB."tmp"`, `SCRATCH_DB."tmp".SYSTEM$BIND`},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			var requests []execRequest
+			sc := getDefaultSnowflakeConn()
+			sc.ctx = context.Background()
+			sc.cfg.ArrayBindStageThreshold = 1
+			sc.cfg.TempStagePrefix = tc.cfgPrefix
+			sc.cfg.TempObjectLocation = tc.location
+			sc.rest.FuncPostQuery = bindStagePostQueryMock(t, t.TempDir(), &requests)
+			ctx := context.Background()
+			if tc.ctxPrefix != "" {
+				var err error
+				if ctx, err = WithTempStagePrefix(ctx, tc.ctxPrefix); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			ids := []int{1, 2, 3}
+			if _, err := sc.ExecContext(ctx, "INSERT INTO t VALUES (?)", []driver.NamedValue{
+				{Ordinal: 1, Value: Array(&ids)},
+			}); err != nil {
+				t.Fatal(err)
+			}
+			if len(requests) != 4 {
+				t.Fatalf("unexpected queries: %v", requests)
+			}
+			if requests[0].SQLText != "CREATE OR REPLACE TEMPORARY STAGE "+tc.stage+bindStageFileFormat {
+				t.Fatalf("unexpected stage creation: %v", requests[0].SQLText)
+			}
+			if !strings.HasPrefix(requests[2].BindStage, "@"+tc.stage+"/") {
+				t.Fatalf("binds should be read from stage %v, got: %v", tc.stage, requests[2].BindStage)
+			}
+		})
+	}
+}
+
+func TestInvalidTempStagePrefix(t *testing.T) {
+	for _, prefix := range []string{"1TMP", "TMP-", "TMP; DROP TABLE t; --", "\"TMP\""} {
+		_, err := WithTempStagePrefix(context.Background(), prefix)
+		if se, ok := err.(*SnowflakeError); !ok || se.Number != ErrCodeInvalidTempStagePrefix {
+			t.Fatalf("expected error %v for prefix %q, got: %v", ErrCodeInvalidTempStagePrefix, prefix, err)
+		}
+		cfg := &Config{TempStagePrefix: prefix}
+		if se, ok := cfg.Validate().(*SnowflakeError); !ok || se.Number != ErrCodeInvalidTempStagePrefix {
+			t.Fatalf("expected error %v for prefix %q", ErrCodeInvalidTempStagePrefix, prefix)
+		}
+	}
+	if _, err := WithTempStagePrefix(context.Background(), "_gov$tmp_"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestInvalidTempObjectLocation(t *testing.T) {
+	for _, location := range []string{"SCRATCH_DB", "A.B.C", "SCRATCH_DB.", "DB.TMP; DROP TABLE t; --"} {
+		cfg := &Config{TempObjectLocation: location}
+		if se, ok := cfg.Validate().(*SnowflakeError); !ok || se.Number != ErrCodeInvalidTempObjectLocation {
+			t.Fatalf("expected error %v for location %q", ErrCodeInvalidTempObjectLocation, location)
+		}
+	}
+	if err := (&Config{TempObjectLocation: `scratch_db."Tmp Objects"`}).Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestArrayBindBelowStageThreshold(t *testing.T) {
+	var requests []execRequest
+	sc := getDefaultSnowflakeConn()
+	sc.ctx = context.Background()
+	sc.cfg.ArrayBindStageThreshold = 1000
+	sc.rest.FuncPostQuery = bindStagePostQueryMock(t, t.TempDir(), &requests)
+
+	ids := []int{1, 2, 3}
+	if _, err := sc.ExecContext(context.Background(), "INSERT INTO t VALUES (?)", []driver.NamedValue{
+		{Ordinal: 1, Value: Array(&ids)},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(requests) != 1 || requests[0].BindStage != "" || len(requests[0].Bindings) != 1 {
+		t.Fatalf("binds should be sent with the query: %+v", requests)
+	}
+}
+
+func TestBindFloat64RoundTrip(t *testing.T) {
+	var requests []execRequest
+	sc := getDefaultSnowflakeConn()
+	sc.ctx = context.Background()
+	sc.cfg.ArrayBindStageThreshold = 1000
+	sc.rest.FuncPostQuery = bindStagePostQueryMock(t, t.TempDir(), &requests)
+
+	values := []float64{0.1, 1e300, 1.7976931348623157e308, 5e-324, 123456789.12345678, -0.3}
+	for _, value := range values {
+		if _, err := sc.ExecContext(context.Background(), "INSERT INTO t VALUES (?)", []driver.NamedValue{
+			{Ordinal: 1, Value: value},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := sc.ExecContext(context.Background(), "INSERT INTO t VALUES (?)", []driver.NamedValue{
+		{Ordinal: 1, Value: Array(&values)},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(requests) != len(values)+1 {
+		t.Fatalf("unexpected requests: %+v", requests)
+	}
+	for i, value := range values {
+		bound, ok := requests[i].Bindings["1"].Value.(string)
+		if !ok {
+			t.Fatalf("unexpected bind value: %v", requests[i].Bindings["1"].Value)
+		}
+		if f, err := strconv.ParseFloat(bound, 64); err != nil || f != value {
+			t.Fatalf("%v did not round-trip, bound: %v", value, bound)
+		}
+	}
+	arrayBinds, ok := requests[len(values)].Bindings["1"].Value.([]interface{})
+	if !ok || len(arrayBinds) != len(values) {
+		t.Fatalf("unexpected array bind value: %v", requests[len(values)].Bindings["1"].Value)
+	}
+	for i, value := range values {
+		if f, err := strconv.ParseFloat(arrayBinds[i].(string), 64); err != nil || f != value {
+			t.Fatalf("%v did not round-trip in the array bind, bound: %v", value, arrayBinds[i])
+		}
+	}
+}
+
+func TestArrayBindRequestTooLarge(t *testing.T) {
+	var attempts int
+	sc := getDefaultSnowflakeConn()
+	sc.ctx = context.Background()
+	sc.cfg.ArrayBindStageThreshold = 1000
+	sc.rest.FuncPostQuery = postRestfulQueryHelper
+	sc.rest.FuncPost = func(_ context.Context, _ *snowflakeRestful, _ *url.URL, _ map[string]string, _ []byte,
+		_ time.Duration, _ bool, _ currentTimeProvider, _ *Config) (*http.Response, error) {
+		attempts++
+		return &http.Response{
+			StatusCode: http.StatusRequestEntityTooLarge,
+			Body:       &fakeResponseBody{body: []byte("Request Entity Too Large")},
+		}, nil
+	}
+
+	ids := []int{1, 2, 3}
+	_, err := sc.ExecContext(context.Background(), "INSERT INTO t VALUES (?)", []driver.NamedValue{
+		{Ordinal: 1, Value: Array(&ids)},
+	})
+	if attempts != 1 {
+		t.Fatalf("expected a single attempt, got: %v", attempts)
+	}
+	se, ok := err.(*SnowflakeError)
+	if !ok || se.Number != ErrArrayBindTooLarge {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(se.Error(), "ArrayBindStageThreshold") {
+		t.Fatalf("error should suggest uploading the binds to a stage: %v", se)
+	}
+
+	attempts = 0
+	_, err = sc.ExecContext(context.Background(), "INSERT INTO t VALUES (?)", []driver.NamedValue{
+		{Ordinal: 1, Value: int64(1)},
+	})
+	if se, ok = err.(*SnowflakeError); !ok || se.Number != ErrRequestTooLarge || attempts != 1 {
+		t.Fatalf("unexpected error: %v, attempts: %v", err, attempts)
+	}
+}
+
 func TestBindingInterface(t *testing.T) {
 	runDBTest(t, func(dbt *DBTest) {
 		rows := dbt.mustQueryContext(
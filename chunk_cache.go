@@ -0,0 +1,62 @@
+// Copyright (c) 2023 Snowflake Computing Inc. All rights reserved.
+
+package gosnowflake
+
+import (
+	"bytes"
+	"io"
+	"net/url"
+)
+
+const (
+	headerIfNoneMatch = "If-None-Match"
+	headerETag        = "ETag"
+)
+
+// ChunkCache caches the result chunks downloaded from the cloud storage, e.g. on disk for tools running
+// the same queries repeatedly. Set it with Config.ChunkCache. A cached chunk is requested with its ETag
+// in the If-None-Match header and is used only if the cloud storage responds 304 Not Modified.
+// The methods may be called concurrently by the chunk download goroutines.
+type ChunkCache interface {
+	// Get returns the cached body of the chunk and the ETag it was downloaded with, or false if it is not cached.
+	Get(key string) (body []byte, etag string, ok bool)
+	// Put caches the body of the chunk downloaded with the given ETag.
+	Put(key string, body []byte, etag string)
+}
+
+// chunkCacheKey returns the key of a chunk in the ChunkCache, i.e. its URL without the query string
+// holding the presigned credentials, which differ every time the result is fetched.
+func chunkCacheKey(chunkURL string) string {
+	u, err := url.Parse(chunkURL)
+	if err != nil {
+		return chunkURL
+	}
+	u.RawQuery = ""
+	return u.String()
+}
+
+// cachedChunkBody is the body of a chunk served from the ChunkCache. It is not counted as downloaded.
+type cachedChunkBody struct {
+	*bytes.Reader
+}
+
+func (cachedChunkBody) Close() error {
+	return nil
+}
+
+// cachingChunkBody passes the body of a downloaded chunk through and caches it once it is fully read.
+type cachingChunkBody struct {
+	io.ReadCloser
+	buf bytes.Buffer
+	put func(body []byte)
+}
+
+func (b *cachingChunkBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.buf.Write(p[:n])
+	if err == io.EOF && b.put != nil {
+		b.put(b.buf.Bytes())
+		b.put = nil
+	}
+	return n, err
+}
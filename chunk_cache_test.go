@@ -0,0 +1,107 @@
+// Copyright (c) 2023 Snowflake Computing Inc. All rights reserved.
+
+package gosnowflake
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testChunkCacheEntry struct {
+	body []byte
+	etag string
+}
+
+type testChunkCache struct {
+	mu      sync.Mutex
+	entries map[string]testChunkCacheEntry
+}
+
+func (c *testChunkCache) Get(key string) ([]byte, string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	return entry.body, entry.etag, ok
+}
+
+func (c *testChunkCache) Put(key string, body []byte, etag string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = testChunkCacheEntry{body: append([]byte(nil), body...), etag: etag}
+}
+
+func TestChunkCacheUsedWhenNotModified(t *testing.T) {
+	const etag = `"5d41402abc4b2a76b9719d911017c592"`
+	chunk := []byte(`["1","a"],["2","b"]`)
+	cache := &testChunkCache{entries: make(map[string]testChunkCacheEntry)}
+	var statuses []int
+	var mu sync.Mutex
+	get := func(_ context.Context, _ *snowflakeConn, _ string, headers map[string]string, _ time.Duration) (*http.Response, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if headers[headerIfNoneMatch] == etag {
+			statuses = append(statuses, http.StatusNotModified)
+			return &http.Response{StatusCode: http.StatusNotModified, Body: io.NopCloser(bytes.NewReader(nil))}, nil
+		}
+		statuses = append(statuses, http.StatusOK)
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{http.CanonicalHeaderKey(headerETag): []string{etag}},
+			Body:       io.NopCloser(bytes.NewReader(chunk)),
+		}, nil
+	}
+	rowSet := rowSetType{RowType: []execResponseRowType{{Name: "ID", Type: "fixed"}, {Name: "V", Type: "text"}}}
+
+	var results [][]string
+	for _, signature := range []string{"first", "second"} {
+		sc := getDefaultSnowflakeConn()
+		sc.cfg.ChunkCache = cache
+		metas := []execResponseChunk{{URL: "https://sfc.s3.amazonaws.com/results/qid/main/data_0_0_0?X-Amz-Signature=" + signature, RowCount: 2}}
+		rows := newChunkCacheTestRows(t, sc, metas, rowSet, get)
+		results = append(results, readAllTestRows(t, rows))
+	}
+
+	if !reflect.DeepEqual(statuses, []int{http.StatusOK, http.StatusNotModified}) {
+		t.Fatalf("the second download should be a conditional request, got statuses: %v", statuses)
+	}
+	if !reflect.DeepEqual(results[0], results[1]) || len(results[1]) != 2 {
+		t.Fatalf("the cached chunk should have the same rows. downloaded: %v, cached: %v", results[0], results[1])
+	}
+	if _, cachedETag, ok := cache.Get("https://sfc.s3.amazonaws.com/results/qid/main/data_0_0_0"); !ok || cachedETag != etag {
+		t.Fatalf("the chunk should be cached without the query string, got: %v", cache.entries)
+	}
+}
+
+func newChunkCacheTestRows(t *testing.T, sc *snowflakeConn, metas []execResponseChunk, rowSet rowSetType,
+	get func(context.Context, *snowflakeConn, string, map[string]string, time.Duration) (*http.Response, error)) *snowflakeRows {
+	var total int64
+	for _, meta := range metas {
+		total += int64(meta.RowCount)
+	}
+	scd := &snowflakeChunkDownloader{
+		sc:                 sc,
+		ctx:                context.Background(),
+		Total:              total,
+		TotalRowIndex:      int64(-1),
+		CellCount:          len(rowSet.RowType),
+		ChunkMetas:         metas,
+		ChunksMutex:        &sync.Mutex{},
+		QueryResultFormat:  string(jsonFormat),
+		RowSet:             rowSet,
+		FuncDownload:       downloadChunk,
+		FuncDownloadHelper: downloadChunkHelper,
+		FuncGet:            get,
+	}
+	rows := &snowflakeRows{sc: sc, queryID: "qid"}
+	rows.addDownloader(scd)
+	if err := scd.start(); err != nil {
+		t.Fatal(err)
+	}
+	return rows
+}
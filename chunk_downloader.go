@@ -4,6 +4,7 @@ package gosnowflake
 
 import (
 	"bufio"
+	"bytes"
 	"compress/gzip"
 	"context"
 	"encoding/json"
@@ -24,6 +25,7 @@ import (
 
 type chunkDownloader interface {
 	totalUncompressedSize() (acc int64)
+	totalRows() int64
 	hasNextResultSet() bool
 	nextResultSet() error
 	start() error
@@ -63,6 +65,13 @@ type snowflakeChunkDownloader struct {
 	QueryResultFormat  string
 	ArrowBatches       []*ArrowBatch
 	RowSet             rowSetType
+	projection         []bool     // columns decoded from the Arrow chunks. all of them if nil
+	dump               *chunkDump // writes the raw chunks as they are downloaded. nil unless WithChunkDump is set
+	RowLimit           int64
+	RowsDelivered      int64
+	MaxWorkers         int // number of goroutines downloading the chunks. MaxChunkDownloadWorkers is used if zero
+	downloadCtx        context.Context
+	cancelDownloads    context.CancelFunc
 	FuncDownload       func(context.Context, *snowflakeChunkDownloader, int)
 	FuncDownloadHelper func(context.Context, *snowflakeChunkDownloader, int) error
 	FuncGet            func(context.Context, *snowflakeConn, string, map[string]string, time.Duration) (*http.Response, error)
@@ -75,6 +84,10 @@ func (scd *snowflakeChunkDownloader) totalUncompressedSize() (acc int64) {
 	return
 }
 
+func (scd *snowflakeChunkDownloader) totalRows() int64 {
+	return scd.Total
+}
+
 func (scd *snowflakeChunkDownloader) hasNextResultSet() bool {
 	if len(scd.ChunkMetas) == 0 && scd.NextDownloader == nil {
 		return false // no extra chunk
@@ -92,9 +105,21 @@ func (scd *snowflakeChunkDownloader) nextResultSet() error {
 }
 
 func (scd *snowflakeChunkDownloader) start() error {
+	if scd.dump != nil {
+		if err := scd.dump.writeManifest(scd); err != nil {
+			return err
+		}
+	}
 	if usesArrowBatches(scd.ctx) {
 		return scd.startArrowBatches()
 	}
+	if scd.getQueryResultFormat() == arrowFormat {
+		projection, err := getColumnProjection(scd.ctx, scd.RowSet.RowType)
+		if err != nil {
+			return err
+		}
+		scd.projection = projection
+	}
 	scd.CurrentChunkSize = len(scd.RowSet.JSON) // cache the size
 	scd.CurrentIndex = -1                       // initial chunks idx
 	scd.CurrentChunkIndex = -1                  // initial chunk
@@ -111,41 +136,52 @@ func (scd *snowflakeChunkDownloader) start() error {
 		}
 		firstArrowChunk := buildFirstArrowChunk(scd.RowSet.RowSetBase64, loc, scd.pool)
 		higherPrecision := higherPrecisionEnabled(scd.ctx)
-		scd.CurrentChunk, err = firstArrowChunk.decodeArrowChunk(scd.RowSet.RowType, higherPrecision)
-		scd.CurrentChunkSize = firstArrowChunk.rowCount
+		scd.CurrentChunk, err = firstArrowChunk.decodeArrowChunk(scd.RowSet.RowType, higherPrecision, scd.projection)
 		if err != nil {
-			return err
+			return errFailedToDecodeArrowChunk(0, err)
 		}
+		scd.CurrentChunkSize = len(scd.CurrentChunk)
 	}
 
 	// start downloading chunks if exists
 	chunkMetaLen := len(scd.ChunkMetas)
 	if chunkMetaLen > 0 {
-		logger.Debugf("MaxChunkDownloadWorkers: %v", MaxChunkDownloadWorkers)
+		maxWorkers := scd.maxWorkers()
+		logger.Debugf("MaxChunkDownloadWorkers: %v", maxWorkers)
 		logger.Debugf("chunks: %v, total bytes: %d", chunkMetaLen, scd.totalUncompressedSize())
 		scd.ChunksMutex = &sync.Mutex{}
 		scd.DoneDownloadCond = sync.NewCond(scd.ChunksMutex)
 		scd.Chunks = make(map[int][]chunkRowType)
 		scd.ChunksChan = make(chan int, chunkMetaLen)
-		scd.ChunksError = make(chan *chunkError, MaxChunkDownloadWorkers)
+		scd.ChunksError = make(chan *chunkError, maxWorkers)
+		// outstanding downloads are cancelled once the chunks are no longer needed,
+		// e.g. when the preview rows are delivered
+		scd.downloadCtx, scd.cancelDownloads = context.WithCancel(scd.ctx)
 		for i := 0; i < chunkMetaLen; i++ {
 			chunk := scd.ChunkMetas[i]
 			logger.Debugf("add chunk to channel ChunksChan: %v, URL: %v, RowCount: %v, UncompressedSize: %v, ChunkResultFormat: %v",
 				i+1, chunk.URL, chunk.RowCount, chunk.UncompressedSize, scd.QueryResultFormat)
 			scd.ChunksChan <- i
 		}
-		for i := 0; i < intMin(MaxChunkDownloadWorkers, chunkMetaLen); i++ {
+		for i := 0; i < intMin(maxWorkers, chunkMetaLen); i++ {
 			scd.schedule()
 		}
 	}
 	return nil
 }
 
+func (scd *snowflakeChunkDownloader) maxWorkers() int {
+	if scd.MaxWorkers > 0 {
+		return scd.MaxWorkers
+	}
+	return MaxChunkDownloadWorkers
+}
+
 func (scd *snowflakeChunkDownloader) schedule() {
 	select {
 	case nextIdx := <-scd.ChunksChan:
 		logger.Infof("schedule chunk: %v", nextIdx+1)
-		go scd.FuncDownload(scd.ctx, scd, nextIdx)
+		go scd.FuncDownload(scd.downloadCtx, scd, nextIdx)
 	default:
 		// no more download
 		logger.Info("no more download")
@@ -157,9 +193,10 @@ func (scd *snowflakeChunkDownloader) checkErrorRetry() (err error) {
 	case errc := <-scd.ChunksError:
 		if scd.ChunksErrorCounter < maxChunkDownloaderErrorCounter &&
 			errc.Error != context.Canceled &&
-			errc.Error != context.DeadlineExceeded {
+			errc.Error != context.DeadlineExceeded &&
+			!isMaxDownloadBytesExceeded(errc.Error) {
 			// add the index to the chunks channel so that the download will be retried.
-			go scd.FuncDownload(scd.ctx, scd, errc.Index)
+			go scd.FuncDownload(scd.downloadCtx, scd, errc.Index)
 			scd.ChunksErrorCounter++
 			logger.Warningf("chunk idx: %v, err: %v. retrying (%v/%v)...",
 				errc.Index, errc.Error, scd.ChunksErrorCounter, maxChunkDownloaderErrorCounter)
@@ -175,9 +212,15 @@ func (scd *snowflakeChunkDownloader) checkErrorRetry() (err error) {
 }
 
 func (scd *snowflakeChunkDownloader) next() (chunkRowType, error) {
+	if scd.RowLimit > 0 && scd.RowsDelivered >= scd.RowLimit {
+		logger.Debugf("row limit preview reached: %v", scd.RowLimit)
+		scd.stopDownloads()
+		return chunkRowType{}, io.EOF
+	}
 	for {
 		scd.CurrentIndex++
 		if scd.CurrentIndex < scd.CurrentChunkSize {
+			scd.RowsDelivered++
 			return scd.CurrentChunk[scd.CurrentIndex], nil
 		}
 		scd.CurrentChunkIndex++ // next chunk
@@ -214,6 +257,9 @@ func (scd *snowflakeChunkDownloader) next() (chunkRowType, error) {
 	}
 
 	logger.Debugf("no more data")
+	if scd.cancelDownloads != nil {
+		scd.cancelDownloads()
+	}
 	if len(scd.ChunkMetas) > 0 {
 		close(scd.ChunksError)
 		close(scd.ChunksChan)
@@ -221,6 +267,25 @@ func (scd *snowflakeChunkDownloader) next() (chunkRowType, error) {
 	return chunkRowType{}, io.EOF
 }
 
+// stopDownloads cancels the outstanding chunk downloads and drops the chunks
+// that have not been scheduled yet.
+func (scd *snowflakeChunkDownloader) stopDownloads() {
+	if scd.cancelDownloads == nil {
+		return
+	}
+	scd.cancelDownloads()
+	for {
+		select {
+		case _, ok := <-scd.ChunksChan:
+			if !ok {
+				return
+			}
+		default:
+			return
+		}
+	}
+}
+
 func (scd *snowflakeChunkDownloader) reset() {
 	scd.Chunks = nil // detach all chunks. No way to go backward without reinitialize it.
 }
@@ -264,7 +329,11 @@ func getChunk(
 	if err != nil {
 		return nil, err
 	}
-	return newRetryHTTP(ctx, sc.rest.Client, http.NewRequest, u, headers, timeout, sc.currentTimeProvider, sc.cfg).execute()
+	return newRetryHTTP(ctx, sc.rest.getChunkClient(), http.NewRequest, u, headers, timeout, sc.currentTimeProvider, sc.cfg).
+		withExtraHeaders(sc.rest.extraHeaders).
+		countStatuses(sc.rest.statusCounts).
+		withWaitAlgo(sc.rest.waitAlgo).
+		execute()
 }
 
 func (scd *snowflakeChunkDownloader) startArrowBatches() error {
@@ -346,7 +415,8 @@ func downloadChunk(ctx context.Context, scd *snowflakeChunkDownloader, idx int)
 	}
 }
 
-func downloadChunkHelper(ctx context.Context, scd *snowflakeChunkDownloader, idx int) error {
+// chunkRequestHeaders returns the headers of the requests downloading the chunks from the cloud storage.
+func (scd *snowflakeChunkDownloader) chunkRequestHeaders() map[string]string {
 	headers := make(map[string]string)
 	if len(scd.ChunkHeader) > 0 {
 		logger.Debug("chunk header is provided.")
@@ -359,29 +429,94 @@ func downloadChunkHelper(ctx context.Context, scd *snowflakeChunkDownloader, idx
 		headers[headerSseCAlgorithm] = headerSseCAes
 		headers[headerSseCKey] = scd.Qrmk
 	}
+	return headers
+}
 
+// getChunkBody requests the chunk and returns the body of the successful response. With a ChunkCache,
+// the cached chunk is returned if the cloud storage confirms it is unchanged.
+func (scd *snowflakeChunkDownloader) getChunkBody(ctx context.Context, idx int) (io.ReadCloser, error) {
+	headers := scd.chunkRequestHeaders()
+	var cache ChunkCache
+	if scd.sc.cfg != nil {
+		cache = scd.sc.cfg.ChunkCache
+	}
+	var cacheKey string
+	var cached []byte
+	if cache != nil {
+		cacheKey = chunkCacheKey(scd.ChunkMetas[idx].URL)
+		if body, etag, ok := cache.Get(cacheKey); ok && etag != "" {
+			cached = body
+			headers[headerIfNoneMatch] = etag
+		}
+	}
 	resp, err := scd.FuncGet(ctx, scd.sc, scd.ChunkMetas[idx].URL, headers, scd.sc.rest.RequestTimeout)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	bufStream := bufio.NewReader(resp.Body)
-	defer resp.Body.Close()
 	logger.Debugf("response returned chunk: %v for URL: %v", idx+1, scd.ChunkMetas[idx].URL)
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		resp.Body.Close()
+		logger.Debugf("chunk %v is not modified, using the cached chunk", idx+1)
+		return cachedChunkBody{bytes.NewReader(cached)}, nil
+	}
 	if resp.StatusCode != http.StatusOK {
-		b, err := io.ReadAll(bufStream)
+		defer resp.Body.Close()
+		b, err := io.ReadAll(resp.Body)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		logger.Infof("HTTP: %v, URL: %v, Body: %v", resp.StatusCode, scd.ChunkMetas[idx].URL, b)
 		logger.Infof("Header: %v", resp.Header)
-		return &SnowflakeError{
+		return nil, &SnowflakeError{
 			Number:      ErrFailedToGetChunk,
 			SQLState:    SQLStateConnectionFailure,
 			Message:     errMsgFailedToGetChunk,
 			MessageArgs: []interface{}{idx},
 		}
 	}
-	return decodeChunk(scd, idx, bufStream)
+	if etag := resp.Header.Get(headerETag); cache != nil && etag != "" {
+		return &cachingChunkBody{ReadCloser: resp.Body, put: func(body []byte) {
+			cache.Put(cacheKey, body, etag)
+		}}, nil
+	}
+	return resp.Body, nil
+}
+
+func downloadChunkHelper(ctx context.Context, scd *snowflakeChunkDownloader, idx int) (err error) {
+	respBody, err := scd.getChunkBody(ctx, idx)
+	if err != nil {
+		return err
+	}
+	defer respBody.Close()
+	var source io.Reader = respBody
+	if _, ok := respBody.(cachedChunkBody); !ok {
+		body := &countingReader{reader: &limitedReader{reader: respBody, limiter: scd.sc.downloads}}
+		defer func() {
+			scd.sc.cfg.metricsCollector().BytesDownloaded(body.count)
+		}()
+		source = body
+	}
+	if scd.dump != nil {
+		f, err := scd.dump.createChunkFile(idx)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+		}()
+		source = io.TeeReader(source, f)
+	}
+	bufStream := bufio.NewReader(source)
+	if err = decodeChunk(scd, idx, bufStream); err != nil {
+		return err
+	}
+	if _, ok := respBody.(*cachingChunkBody); ok || scd.dump != nil {
+		// the decoders stop at the end of the rows, the chunk is cached or dumped once its body is read to EOF
+		_, err = io.Copy(io.Discard, bufStream)
+	}
+	return err
 }
 
 func decodeChunk(scd *snowflakeChunkDownloader, idx int, bufStream *bufio.Reader) (err error) {
@@ -429,7 +564,7 @@ func decodeChunk(scd *snowflakeChunkDownloader, idx int, bufStream *bufio.Reader
 	} else {
 		ipcReader, err := ipc.NewReader(source, ipc.WithAllocator(scd.pool))
 		if err != nil {
-			return err
+			return errFailedToDecodeArrowChunk(idx+1, err)
 		}
 		var loc *time.Location
 		if scd.sc != nil && scd.sc.cfg != nil {
@@ -443,16 +578,16 @@ func decodeChunk(scd *snowflakeChunkDownloader, idx int, bufStream *bufio.Reader
 		}
 		if usesArrowBatches(scd.ctx) {
 			if scd.ArrowBatches[idx].rec, err = arc.decodeArrowBatch(scd); err != nil {
-				return err
+				return errFailedToDecodeArrowChunk(idx+1, err)
 			}
 			// updating metadata
 			scd.ArrowBatches[idx].rowCount = countArrowBatchRows(scd.ArrowBatches[idx].rec)
 			return nil
 		}
 		highPrec := higherPrecisionEnabled(scd.ctx)
-		respd, err = arc.decodeArrowChunk(scd.RowSet.RowType, highPrec)
+		respd, err = arc.decodeArrowChunk(scd.RowSet.RowType, highPrec, scd.projection)
 		if err != nil {
-			return err
+			return errFailedToDecodeArrowChunk(idx+1, err)
 		}
 	}
 	logger.Debugf(
@@ -491,6 +626,10 @@ func (scd *streamChunkDownloader) totalUncompressedSize() (acc int64) {
 	return -1
 }
 
+func (scd *streamChunkDownloader) totalRows() int64 {
+	return scd.Total
+}
+
 func (scd *streamChunkDownloader) hasNextResultSet() bool {
 	return scd.readErr == nil
 }
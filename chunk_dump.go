@@ -0,0 +1,208 @@
+// Copyright (c) 2023 Snowflake Computing Inc. All rights reserved.
+
+package gosnowflake
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	chunkDumpVersion      = 1
+	chunkDumpManifestFile = "manifest.json"
+	chunkDumpFileFmt      = "chunk_%05d"
+)
+
+// chunkDumpManifest describes a result set dumped with WithChunkDump. The chunks are stored next to it
+// as received from the cloud storage, with the file names in place of the URLs.
+type chunkDumpManifest struct {
+	Version           int                   `json:"version"`
+	QueryID           string                `json:"queryId"`
+	QueryResultFormat string                `json:"queryResultFormat"`
+	Timezone          string                `json:"timezone,omitempty"`
+	Total             int64                 `json:"total"`
+	RowType           []execResponseRowType `json:"rowType"`
+	RowSet            [][]*string           `json:"rowSet,omitempty"`
+	RowSetBase64      string                `json:"rowSetBase64,omitempty"`
+	Chunks            []execResponseChunk   `json:"chunks"`
+}
+
+// chunkDump writes a result set to a directory, see WithChunkDump.
+type chunkDump struct {
+	dir     string
+	queryID string
+}
+
+// newChunkDump returns the dump of the result set of the query if the context is set with WithChunkDump.
+func newChunkDump(ctx context.Context, queryID string) *chunkDump {
+	if ctx == nil {
+		return nil
+	}
+	dir, ok := ctx.Value(chunkDumpDir).(string)
+	if !ok || dir == "" {
+		return nil
+	}
+	return &chunkDump{dir: dir, queryID: queryID}
+}
+
+// writeManifest creates the directory and writes the manifest of the result set of scd to it.
+// Only the current result set of a multi-statement query is written.
+func (d *chunkDump) writeManifest(scd *snowflakeChunkDownloader) error {
+	if err := os.MkdirAll(d.dir, 0700); err != nil {
+		return err
+	}
+	manifest := chunkDumpManifest{
+		Version:           chunkDumpVersion,
+		QueryID:           d.queryID,
+		QueryResultFormat: scd.QueryResultFormat,
+		Total:             scd.Total,
+		RowType:           scd.RowSet.RowType,
+		RowSet:            scd.RowSet.JSON,
+		RowSetBase64:      scd.RowSet.RowSetBase64,
+		Chunks:            make([]execResponseChunk, len(scd.ChunkMetas)),
+	}
+	if scd.sc != nil && scd.sc.cfg != nil {
+		if loc := getCurrentLocation(scd.sc.cfg.Params); loc != nil {
+			manifest.Timezone = loc.String()
+		}
+	}
+	for i, chunk := range scd.ChunkMetas {
+		chunk.URL = fmt.Sprintf(chunkDumpFileFmt, i)
+		manifest.Chunks[i] = chunk
+	}
+	b, err := json.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(d.dir, chunkDumpManifestFile), b, 0600)
+}
+
+// createChunkFile returns the file the raw chunk is written to. A chunk downloaded again replaces it.
+func (d *chunkDump) createChunkFile(idx int) (*os.File, error) {
+	return os.OpenFile(filepath.Join(d.dir, fmt.Sprintf(chunkDumpFileFmt, idx)), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+}
+
+// DumpChunks writes the result set to dir as WithChunkDump does, for rows queried without it. The chunks
+// are downloaded again, so the rows can still be read with Next; WithChunkDump writes them as they are
+// downloaded for the rows instead. Only the current result set of a multi-statement query is written.
+func (rows *snowflakeRows) DumpChunks(dir string) error {
+	if err := rows.waitForAsyncQueryStatus(); err != nil {
+		return err
+	}
+	scd, ok := rows.ChunkDownloader.(*snowflakeChunkDownloader)
+	if !ok {
+		return errInvalidChunkDump(dir, "the rows of a streaming download cannot be dumped")
+	}
+	d := &chunkDump{dir: dir, queryID: rows.queryID}
+	if err := d.writeManifest(scd); err != nil {
+		return err
+	}
+	ctx := scd.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	for i := range scd.ChunkMetas {
+		if err := d.downloadChunk(ctx, scd, i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// downloadChunk downloads the chunk of scd again and writes it to its file.
+func (d *chunkDump) downloadChunk(ctx context.Context, scd *snowflakeChunkDownloader, idx int) error {
+	body, err := scd.getChunkBody(ctx, idx)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	f, err := d.createChunkFile(idx)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(f, body); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// LoadChunkDump returns the rows of a result set written to dir with WithChunkDump or DumpChunks. The values are
+// converted as they would be for the original query, with the timezone of its session.
+//
+//	rows, err := sf.LoadChunkDump(ctx, "testdata/orders")
+//	...
+//	dest := make([]driver.Value, len(rows.Columns()))
+//	for rows.Next(dest) == nil {
+//		...
+//	}
+func LoadChunkDump(ctx context.Context, dir string) (driver.Rows, error) {
+	b, err := os.ReadFile(filepath.Join(dir, chunkDumpManifestFile))
+	if err != nil {
+		return nil, err
+	}
+	var manifest chunkDumpManifest
+	if err = json.Unmarshal(b, &manifest); err != nil {
+		return nil, errInvalidChunkDump(dir, err.Error())
+	}
+	if manifest.Version != chunkDumpVersion {
+		return nil, errInvalidChunkDump(dir, fmt.Sprintf("unsupported version %v", manifest.Version))
+	}
+	cfg := &Config{Params: map[string]*string{}}
+	if manifest.Timezone != "" {
+		cfg.Params["timezone"] = &manifest.Timezone
+	}
+	sc := &snowflakeConn{
+		cfg:                 cfg,
+		rest:                &snowflakeRestful{},
+		currentTimeProvider: defaultTimeProvider,
+	}
+	scd := &snowflakeChunkDownloader{
+		sc:                 sc,
+		ctx:                ctx,
+		pool:               getAllocator(ctx),
+		ChunkMetas:         manifest.Chunks,
+		Total:              manifest.Total,
+		TotalRowIndex:      int64(-1),
+		CellCount:          len(manifest.RowType),
+		QueryResultFormat:  manifest.QueryResultFormat,
+		ChunksMutex:        &sync.Mutex{},
+		FuncDownload:       downloadChunk,
+		FuncDownloadHelper: downloadChunkHelper,
+		FuncGet:            getDumpedChunk(dir),
+		RowSet: rowSetType{
+			RowType:      manifest.RowType,
+			JSON:         manifest.RowSet,
+			RowSetBase64: manifest.RowSetBase64,
+		},
+	}
+	rows := &snowflakeRows{
+		sc:       sc,
+		queryID:  manifest.QueryID,
+		location: getCurrentLocation(cfg.Params),
+	}
+	rows.addDownloader(scd)
+	if err = scd.start(); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// getDumpedChunk returns a FuncGet reading the chunks from the files of a dump.
+func getDumpedChunk(dir string) func(context.Context, *snowflakeConn, string, map[string]string, time.Duration) (*http.Response, error) {
+	return func(_ context.Context, _ *snowflakeConn, name string, _ map[string]string, _ time.Duration) (*http.Response, error) {
+		f, err := os.Open(filepath.Join(dir, filepath.Base(name)))
+		if err != nil {
+			return nil, err
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: f}, nil
+	}
+}
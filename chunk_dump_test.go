@@ -0,0 +1,208 @@
+// Copyright (c) 2023 Snowflake Computing Inc. All rights reserved.
+
+package gosnowflake
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"database/sql/driver"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newChunkDumpTestRows returns started rows downloading the chunks from the map and dumping them to dir.
+// The downloads of each chunk are counted in downloads.
+func newChunkDumpTestRows(t *testing.T, format resultFormat, rowSet rowSetType, metas []execResponseChunk,
+	chunks map[string][]byte, dir string, downloads map[string]int) *snowflakeRows {
+	sc := getDefaultSnowflakeConn()
+	tz := "America/Los_Angeles"
+	sc.cfg.Params = map[string]*string{"timezone": &tz}
+	var mu sync.Mutex
+	total := int64(len(rowSet.JSON))
+	for _, meta := range metas {
+		total += int64(meta.RowCount)
+	}
+	scd := &snowflakeChunkDownloader{
+		sc:                 sc,
+		ctx:                context.Background(),
+		pool:               getAllocator(context.Background()),
+		Total:              total,
+		TotalRowIndex:      int64(-1),
+		CellCount:          len(rowSet.RowType),
+		ChunkMetas:         metas,
+		ChunksMutex:        &sync.Mutex{},
+		QueryResultFormat:  string(format),
+		RowSet:             rowSet,
+		FuncDownload:       downloadChunk,
+		FuncDownloadHelper: downloadChunkHelper,
+		FuncGet: func(_ context.Context, _ *snowflakeConn, url string, _ map[string]string, _ time.Duration) (*http.Response, error) {
+			mu.Lock()
+			downloads[url]++
+			mu.Unlock()
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(chunks[url]))}, nil
+		},
+		dump: newChunkDump(WithChunkDump(context.Background(), dir), "qid"),
+	}
+	rows := &snowflakeRows{sc: sc, queryID: "qid"}
+	rows.addDownloader(scd)
+	if err := scd.start(); err != nil {
+		t.Fatal(err)
+	}
+	return rows
+}
+
+// readAllTestRows returns the rows formatted with fmt, so that the times are compared with their locations by name.
+func readAllTestRows(t *testing.T, rows driver.Rows) []string {
+	var all []string
+	for {
+		dest := make([]driver.Value, len(rows.Columns()))
+		err := rows.Next(dest)
+		if err == io.EOF {
+			return all
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		all = append(all, fmt.Sprint(dest))
+	}
+}
+
+func TestDumpAndLoadJSONChunks(t *testing.T) {
+	var gzipped bytes.Buffer
+	gw := gzip.NewWriter(&gzipped)
+	if _, err := gw.Write([]byte(`["4","d","1672531200.000000000"],["5",null,"1672534800.000000000"]`)); err != nil {
+		t.Fatal(err)
+	}
+	gw.Close()
+	one, a, ts := "1", "a", "1672527600.123000000"
+	rowSet := rowSetType{
+		RowType: []execResponseRowType{
+			{Name: "ID", Type: "fixed"}, {Name: "V", Type: "text", Nullable: true}, {Name: "TS", Type: "timestamp_ltz", Scale: 9},
+		},
+		JSON: [][]*string{{&one, &a, &ts}},
+	}
+	metas := []execResponseChunk{{URL: "https://s3/chunk0", RowCount: 2}, {URL: "https://s3/chunk1", RowCount: 2}}
+	chunks := map[string][]byte{
+		"https://s3/chunk0": []byte(`["2","b","1672531200.500000000"],["3","c","1672531200.000000001"]`),
+		"https://s3/chunk1": gzipped.Bytes(),
+	}
+	dir := filepath.Join(t.TempDir(), "dump")
+	downloads := make(map[string]int)
+	rows := newChunkDumpTestRows(t, jsonFormat, rowSet, metas, chunks, dir, downloads)
+	expected := readAllTestRows(t, rows)
+	if len(expected) != 5 {
+		t.Fatalf("the rows should be readable while they are dumped, got: %v", expected)
+	}
+	if downloads["https://s3/chunk0"] != 1 || downloads["https://s3/chunk1"] != 1 {
+		t.Fatalf("the chunks should be dumped as they are downloaded for the rows, got: %v", downloads)
+	}
+	stored, err := os.ReadFile(filepath.Join(dir, "chunk_00001"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(stored, gzipped.Bytes()) {
+		t.Fatal("the chunks should be stored as downloaded")
+	}
+
+	loaded, err := LoadChunkDump(context.Background(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer loaded.Close()
+	if columns := loaded.Columns(); !reflect.DeepEqual(columns, []string{"ID", "V", "TS"}) {
+		t.Fatalf("unexpected columns: %v", columns)
+	}
+	if queryID := loaded.(SnowflakeRows).GetQueryID(); queryID != "qid" {
+		t.Fatalf("unexpected query ID: %v", queryID)
+	}
+	got := readAllTestRows(t, loaded)
+	if !reflect.DeepEqual(expected, got) {
+		t.Fatalf("the loaded rows should match the original rows. expected: %v, got: %v", expected, got)
+	}
+	if !strings.Contains(got[0], "PST") {
+		t.Fatalf("the timestamps should be in the session timezone, got: %v", got[0])
+	}
+}
+
+func TestDumpAndLoadArrowChunks(t *testing.T) {
+	rowSet := rowSetType{
+		RowType:      []execResponseRowType{{Name: "ID", Type: "fixed"}},
+		RowSetBase64: base64.StdEncoding.EncodeToString(arrowIPCStream(t, []int64{1})),
+	}
+	metas := []execResponseChunk{{URL: "chunk0", RowCount: 2}, {URL: "chunk1", RowCount: 3}}
+	chunks := map[string][]byte{
+		"chunk0": arrowIPCStream(t, []int64{2, 3}),
+		"chunk1": arrowIPCStream(t, []int64{4, 5, 6}),
+	}
+	dir := t.TempDir()
+	downloads := make(map[string]int)
+	rows := newChunkDumpTestRows(t, arrowFormat, rowSet, metas, chunks, dir, downloads)
+	expected := readAllTestRows(t, rows)
+	if downloads["chunk0"] != 1 || downloads["chunk1"] != 1 {
+		t.Fatalf("the chunks should be dumped as they are downloaded for the rows, got: %v", downloads)
+	}
+
+	loaded, err := LoadChunkDump(context.Background(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := readAllTestRows(t, loaded)
+	if len(got) != 6 || !reflect.DeepEqual(expected, got) {
+		t.Fatalf("the loaded rows should match the original rows. expected: %v, got: %v", expected, got)
+	}
+}
+
+func TestDumpChunks(t *testing.T) {
+	rowSet := rowSetType{
+		RowType:      []execResponseRowType{{Name: "ID", Type: "fixed"}},
+		RowSetBase64: base64.StdEncoding.EncodeToString(arrowIPCStream(t, []int64{1})),
+	}
+	metas := []execResponseChunk{{URL: "chunk0", RowCount: 2}}
+	chunks := map[string][]byte{"chunk0": arrowIPCStream(t, []int64{2, 3})}
+	downloads := make(map[string]int)
+	rows := newChunkDumpTestRows(t, arrowFormat, rowSet, metas, chunks, "", downloads)
+	dir := filepath.Join(t.TempDir(), "dump")
+	if err := rows.DumpChunks(dir); err != nil {
+		t.Fatal(err)
+	}
+	expected := readAllTestRows(t, rows)
+	if len(expected) != 3 {
+		t.Fatalf("the rows should still be readable after the dump, got: %v", expected)
+	}
+	if downloads["chunk0"] != 2 {
+		t.Fatalf("DumpChunks should download the chunks again, got: %v", downloads)
+	}
+
+	loaded, err := LoadChunkDump(context.Background(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := readAllTestRows(t, loaded); !reflect.DeepEqual(expected, got) {
+		t.Fatalf("the loaded rows should match the original rows. expected: %v, got: %v", expected, got)
+	}
+}
+
+func TestLoadInvalidChunkDump(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "manifest.json"), []byte(`{"version":99}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	_, err := LoadChunkDump(context.Background(), dir)
+	if !errors.Is(err, &SnowflakeError{Number: ErrInvalidChunkDump}) {
+		t.Fatalf("an unknown dump version should be rejected, err: %v", err)
+	}
+	if _, err = LoadChunkDump(context.Background(), t.TempDir()); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("a missing manifest should fail, err: %v", err)
+	}
+}
@@ -3,17 +3,22 @@
 package gosnowflake
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"database/sql/driver"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"math/rand"
+	"net/http"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/apache/arrow/go/v12/arrow/ipc"
 	"github.com/apache/arrow/go/v12/arrow/memory"
@@ -611,3 +616,140 @@ func TestQueryArrowStream(t *testing.T) {
 		}
 	})
 }
+
+func TestDecodeCompressedArrowChunk(t *testing.T) {
+	for name, opts := range map[string][]ipc.Option{
+		ArrowCompressionNone:     nil,
+		ArrowCompressionLZ4Frame: {ipc.WithLZ4()},
+		ArrowCompressionZstd:     {ipc.WithZstd()},
+	} {
+		opts := opts
+		t.Run(name, func(t *testing.T) {
+			stream := arrowIPCStream(t, []int64{1, 2, 3}, opts...)
+			scd := &snowflakeChunkDownloader{
+				ctx:               context.Background(),
+				pool:              memory.NewGoAllocator(),
+				QueryResultFormat: string(arrowFormat),
+				ChunkMetas:        []execResponseChunk{{RowCount: 3}},
+				Chunks:            make(map[int][]chunkRowType),
+				ChunksMutex:       &sync.Mutex{},
+				RowSet: rowSetType{
+					RowType: []execResponseRowType{{Name: "ID", Type: "fixed"}},
+				},
+			}
+			if err := decodeChunk(scd, 0, bufio.NewReader(bytes.NewReader(stream))); err != nil {
+				t.Fatal(err)
+			}
+			rows := scd.Chunks[0]
+			if len(rows) != 3 {
+				t.Fatalf("expected 3 rows, got: %v", len(rows))
+			}
+			for i, row := range rows {
+				if fmt.Sprint(row.ArrowRow[0]) != fmt.Sprint(i+1) {
+					t.Fatalf("unexpected value in row %v: %v", i, row.ArrowRow[0])
+				}
+			}
+		})
+	}
+}
+
+type recordingTransport struct {
+	mu    sync.Mutex
+	hosts []string
+}
+
+func (rt *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	rt.hosts = append(rt.hosts, req.URL.Host)
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader("[]")),
+		Request:    req,
+	}, nil
+}
+
+func TestChunkDownloadUsesDedicatedTransport(t *testing.T) {
+	control := &recordingTransport{}
+	chunks := &recordingTransport{}
+	sc, err := buildSnowflakeConn(context.Background(), Config{
+		Account:                "a",
+		Host:                   "a.snowflakecomputing.com",
+		Transporter:            control,
+		ChunkDownloadTransport: chunks,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := getChunk(context.Background(), sc, "https://chunks.example.com/chunk_0", map[string]string{}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if len(chunks.hosts) != 1 || chunks.hosts[0] != "chunks.example.com" {
+		t.Fatalf("chunk should be downloaded with the dedicated transport, got: %v", chunks.hosts)
+	}
+	if len(control.hosts) != 0 {
+		t.Fatalf("control transport should not be used for chunks, got: %v", control.hosts)
+	}
+	if sc.rest.Client.Transport != control {
+		t.Fatal("control requests should keep using Transporter")
+	}
+
+	sc, err = buildSnowflakeConn(context.Background(), Config{
+		Account:     "a",
+		Host:        "a.snowflakecomputing.com",
+		Transporter: control,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sc.rest.getChunkClient() != sc.rest.Client {
+		t.Fatal("chunks should be downloaded with the control client without ChunkDownloadTransport")
+	}
+}
+
+func TestArrowDecodeErrorIsTyped(t *testing.T) {
+	var gets int32
+	get := func(_ context.Context, _ *snowflakeConn, _ string, _ map[string]string, _ time.Duration) (*http.Response, error) {
+		atomic.AddInt32(&gets, 1)
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader([]byte("not an Arrow stream")))}, nil
+	}
+	sc := getDefaultSnowflakeConn()
+	scd := &snowflakeChunkDownloader{
+		sc:                 sc,
+		ctx:                context.Background(),
+		pool:               memory.NewGoAllocator(),
+		Total:              3,
+		TotalRowIndex:      int64(-1),
+		CellCount:          1,
+		ChunkMetas:         []execResponseChunk{{URL: "https://sfc.s3.amazonaws.com/results/qid/main/data_0_0_0", RowCount: 2}},
+		ChunksMutex:        &sync.Mutex{},
+		QueryResultFormat:  string(arrowFormat),
+		MaxWorkers:         1,
+		FuncDownload:       downloadChunk,
+		FuncDownloadHelper: downloadChunkHelper,
+		FuncGet:            get,
+		RowSet: rowSetType{
+			RowType:      []execResponseRowType{{Name: "ID", Type: "fixed"}},
+			RowSetBase64: base64.StdEncoding.EncodeToString(arrowIPCStream(t, []int64{1})),
+		},
+	}
+	rows := &snowflakeRows{sc: sc, queryID: "qid"}
+	rows.addDownloader(scd)
+	if err := scd.start(); err != nil {
+		t.Fatal(err)
+	}
+	dest := make([]driver.Value, 1)
+	if err := rows.Next(dest); err != nil {
+		t.Fatal(err)
+	}
+	err := rows.Next(dest)
+	var se *SnowflakeError
+	if !errors.As(err, &se) || se.Number != ErrFailedToDecodeArrowChunk {
+		t.Fatalf("expected ErrFailedToDecodeArrowChunk, got: %v", err)
+	}
+	if n := atomic.LoadInt32(&gets); int(n) != maxChunkDownloaderErrorCounter+1 {
+		t.Fatalf("the chunk should be downloaded again before failing, got %v downloads", n)
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright (c) 2023 Snowflake Computing Inc. All rights reserved.
+
+package gosnowflake
+
+import (
+	"sync"
+	"time"
+)
+
+// defaultCircuitBreakerCooldown is used if Config.CircuitBreakerCooldown is not set
+const defaultCircuitBreakerCooldown = 30 * time.Second
+
+type circuitState int
+
+const (
+	// circuitClosed lets all requests through
+	circuitClosed circuitState = iota
+	// circuitOpen fails the requests fast until the cooldown elapses
+	circuitOpen
+	// circuitHalfOpen lets a single request through to probe the host
+	circuitHalfOpen
+)
+
+// hostCircuit is the state of the circuit breaker of a host, shared by all connections to it.
+type hostCircuit struct {
+	mutex    sync.Mutex
+	state    circuitState
+	failures int
+	openedAt time.Time
+}
+
+// hostCircuits maps the hosts to their *hostCircuit.
+var hostCircuits sync.Map
+
+// circuitBreaker applies the thresholds of a configuration to the circuit of a host.
+type circuitBreaker struct {
+	circuit   *hostCircuit
+	host      string
+	threshold int
+	cooldown  time.Duration
+}
+
+// newCircuitBreaker returns the circuit breaker of the host, or nil if it is not enabled in the configuration.
+func newCircuitBreaker(cfg *Config, host string) *circuitBreaker {
+	if cfg == nil || cfg.CircuitBreakerThreshold <= 0 {
+		return nil
+	}
+	circuit, _ := hostCircuits.LoadOrStore(host, &hostCircuit{})
+	cooldown := cfg.CircuitBreakerCooldown
+	if cooldown <= 0 {
+		cooldown = defaultCircuitBreakerCooldown
+	}
+	return &circuitBreaker{
+		circuit:   circuit.(*hostCircuit),
+		host:      host,
+		threshold: cfg.CircuitBreakerThreshold,
+		cooldown:  cooldown,
+	}
+}
+
+// allow returns an error if the request must fail fast. Once the cooldown elapsed, the first request
+// is let through to probe the host, while the others keep failing fast until its outcome is recorded.
+func (b *circuitBreaker) allow() error {
+	if b == nil {
+		return nil
+	}
+	b.circuit.mutex.Lock()
+	defer b.circuit.mutex.Unlock()
+	switch b.circuit.state {
+	case circuitOpen:
+		if time.Since(b.circuit.openedAt) >= b.cooldown {
+			logger.Infof("circuit breaker of %v is half-open. probing the host", b.host)
+			b.circuit.state = circuitHalfOpen
+			return nil
+		}
+	case circuitHalfOpen:
+	default:
+		return nil
+	}
+	return errCircuitBreakerOpen(b.host, b.circuit.failures)
+}
+
+// tripped returns an error if the circuit is open, without letting a probing request through.
+func (b *circuitBreaker) tripped() error {
+	if b == nil {
+		return nil
+	}
+	b.circuit.mutex.Lock()
+	defer b.circuit.mutex.Unlock()
+	if b.circuit.state == circuitOpen {
+		return errCircuitBreakerOpen(b.host, b.circuit.failures)
+	}
+	return nil
+}
+
+// record updates the circuit with the outcome of a request. A failure of the probing request,
+// or the threshold of consecutive failures, opens the circuit. A success closes it.
+func (b *circuitBreaker) record(failed bool) {
+	if b == nil {
+		return
+	}
+	b.circuit.mutex.Lock()
+	defer b.circuit.mutex.Unlock()
+	if !failed {
+		if b.circuit.state != circuitClosed {
+			logger.Infof("circuit breaker of %v is closed", b.host)
+		}
+		b.circuit.state = circuitClosed
+		b.circuit.failures = 0
+		return
+	}
+	b.circuit.failures++
+	if b.circuit.state == circuitHalfOpen || b.circuit.failures >= b.threshold {
+		if b.circuit.state != circuitOpen {
+			logger.Warningf("circuit breaker of %v is open after %v consecutive failures. failing requests fast for %v",
+				b.host, b.circuit.failures, b.cooldown)
+		}
+		b.circuit.state = circuitOpen
+		b.circuit.openedAt = time.Now()
+	}
+}
+
+// abandon is called if a request ends without an outcome, e.g. when its context is canceled,
+// so that another request can probe the host if it was the probing one.
+func (b *circuitBreaker) abandon() {
+	if b == nil {
+		return
+	}
+	b.circuit.mutex.Lock()
+	defer b.circuit.mutex.Unlock()
+	if b.circuit.state == circuitHalfOpen {
+		b.circuit.state = circuitOpen
+	}
+}
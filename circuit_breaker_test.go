@@ -0,0 +1,110 @@
+// Copyright (c) 2023 Snowflake Computing Inc. All rights reserved.
+
+package gosnowflake
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakerTripsAfterThreshold(t *testing.T) {
+	cfg := &Config{CircuitBreakerThreshold: 3, CircuitBreakerCooldown: time.Hour}
+	breaker := newCircuitBreaker(cfg, "threshold.circuit.example.com")
+	for i := 0; i < 2; i++ {
+		breaker.record(true)
+		if err := breaker.allow(); err != nil {
+			t.Fatalf("the circuit should be closed after %v failures, err: %v", i+1, err)
+		}
+	}
+	breaker.record(false)
+	for i := 0; i < 3; i++ {
+		if err := breaker.allow(); err != nil {
+			t.Fatalf("a success should reset the consecutive failures, err: %v", err)
+		}
+		breaker.record(true)
+	}
+	// the circuit is shared by the connections to the host
+	other := newCircuitBreaker(cfg, "threshold.circuit.example.com")
+	var se *SnowflakeError
+	if err := other.allow(); !errors.As(err, &se) || se.Number != ErrCircuitBreakerOpen {
+		t.Fatalf("expected error %v after 3 consecutive failures, got: %v", ErrCircuitBreakerOpen, err)
+	}
+	if err := newCircuitBreaker(cfg, "other.circuit.example.com").allow(); err != nil {
+		t.Fatalf("the circuits of other hosts should be closed, err: %v", err)
+	}
+	if newCircuitBreaker(&Config{}, "threshold.circuit.example.com") != nil {
+		t.Fatal("the circuit breaker should be disabled without a threshold")
+	}
+}
+
+func TestCircuitBreakerHalfOpen(t *testing.T) {
+	cfg := &Config{CircuitBreakerThreshold: 1, CircuitBreakerCooldown: 10 * time.Millisecond}
+	breaker := newCircuitBreaker(cfg, "half-open.circuit.example.com")
+	breaker.record(true)
+	time.Sleep(20 * time.Millisecond)
+	if err := breaker.allow(); err != nil {
+		t.Fatalf("a request should probe the host after the cooldown, err: %v", err)
+	}
+	if err := breaker.allow(); err == nil {
+		t.Fatal("only a single request should probe the host")
+	}
+	breaker.record(true)
+	if err := breaker.allow(); err == nil {
+		t.Fatal("a failed probe should open the circuit again")
+	}
+	time.Sleep(20 * time.Millisecond)
+	if err := breaker.allow(); err != nil {
+		t.Fatalf("a request should probe the host after the cooldown, err: %v", err)
+	}
+	breaker.abandon()
+	if err := breaker.allow(); err != nil {
+		t.Fatalf("another request should probe the host if the probe is abandoned, err: %v", err)
+	}
+	breaker.record(false)
+	if err := breaker.allow(); err != nil {
+		t.Fatalf("a successful probe should close the circuit, err: %v", err)
+	}
+}
+
+func TestRetryHTTPCircuitBreakerFastFail(t *testing.T) {
+	cfg := &Config{CircuitBreakerThreshold: 1, CircuitBreakerCooldown: 50 * time.Millisecond}
+	fullURL, err := url.Parse("https://retry.circuit.example.com/queries/v1/query-request?requestId=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &fakeHTTPClient{statusCode: http.StatusServiceUnavailable}
+	_, err = newRetryHTTP(context.Background(), client, emptyRequest, fullURL,
+		make(map[string]string), 60*time.Second, defaultTimeProvider, cfg).doPost().setBody([]byte{0}).execute()
+	var se *SnowflakeError
+	if !errors.As(err, &se) || se.Number != ErrCircuitBreakerOpen {
+		t.Fatalf("expected error %v, got: %v", ErrCircuitBreakerOpen, err)
+	}
+	if client.retryNumber != 1 {
+		t.Fatalf("the request should not be retried once the circuit is open, got %v attempts", client.retryNumber)
+	}
+
+	start := time.Now()
+	_, err = newRetryHTTP(context.Background(), client, emptyRequest, fullURL,
+		make(map[string]string), 60*time.Second, defaultTimeProvider, cfg).doPost().setBody([]byte{0}).execute()
+	if !errors.As(err, &se) || se.Number != ErrCircuitBreakerOpen {
+		t.Fatalf("expected error %v, got: %v", ErrCircuitBreakerOpen, err)
+	}
+	if client.retryNumber != 1 || time.Since(start) > time.Second {
+		t.Fatalf("the request should fail fast without being sent. attempts: %v, elapsed: %v", client.retryNumber, time.Since(start))
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	client = &fakeHTTPClient{success: true, cnt: 1}
+	res, err := newRetryHTTP(context.Background(), client, emptyRequest, fullURL,
+		make(map[string]string), 60*time.Second, defaultTimeProvider, cfg).doPost().setBody([]byte{0}).execute()
+	if err != nil {
+		t.Fatalf("the probing request should be sent after the cooldown, err: %v", err)
+	}
+	if res.StatusCode != http.StatusOK || client.retryNumber != 1 {
+		t.Fatalf("unexpected probe. status: %v, attempts: %v", res.StatusCode, client.retryNumber)
+	}
+}
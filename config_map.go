@@ -0,0 +1,107 @@
+// Copyright (c) 2023 Snowflake Computing Inc. All rights reserved.
+
+package gosnowflake
+
+import (
+	"encoding/base64"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// secretConfigKeys are the ToMap keys whose values are credentials.
+var secretConfigKeys = map[string]bool{
+	"password":          true,
+	"passcode":          true,
+	"token":             true,
+	"privateKey":        true,
+	"oauthClientSecret": true,
+	"socks5ProxyUrl":    true, // may embed the proxy credentials
+}
+
+// IsSecretConfigKey reports whether the value stored under key by
+// Config.ToMap is a credential that should not be logged.
+func IsSecretConfigKey(key string) bool {
+	return secretConfigKeys[key]
+}
+
+// ToMap returns the Config as a flat map using the DSN parameter names, plus
+// "user", "password", "host" and "port". Durations are expressed in seconds
+// and ConfigBool fields as "true" or "false"; unset fields are omitted. Use
+// IsSecretConfigKey to filter out credentials before logging the result.
+func (cfg *Config) ToMap() map[string]string {
+	params := configParams(cfg, true)
+	m := make(map[string]string, len(*params)+4)
+	for k, v := range *params {
+		m[k] = v[0]
+	}
+	if cfg.PrivateKey != nil {
+		privateKeyInBytes, err := marshalPKCS8PrivateKey(cfg.PrivateKey)
+		if err != nil {
+			logger.Warnf("failed to marshal the private key: %v", err)
+		} else {
+			m["privateKey"] = base64.URLEncoding.EncodeToString(privateKeyInBytes)
+		}
+	}
+	if cfg.User != "" {
+		m["user"] = cfg.User
+	}
+	if cfg.Password != "" {
+		m["password"] = cfg.Password
+	}
+	if cfg.Host != "" {
+		m["host"] = cfg.Host
+	}
+	if cfg.Port != 0 {
+		m["port"] = strconv.Itoa(cfg.Port)
+	}
+	return m
+}
+
+// ConfigFromMap builds a Config from a map in the format returned by
+// Config.ToMap. Unknown keys become session parameters, as they do in a DSN.
+func ConfigFromMap(m map[string]string) (*Config, error) {
+	cfg := &Config{
+		Params:        make(map[string]*string),
+		Authenticator: AuthTypeSnowflake, // Default to snowflake
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		v := m[k]
+		switch k {
+		case "user":
+			cfg.User = v
+		case "password":
+			cfg.Password = v
+		case "host":
+			cfg.Host = v
+		case "port":
+			port, err := strconv.Atoi(v)
+			if err != nil {
+				return nil, err
+			}
+			cfg.Port = port
+		default:
+			if err := parseDSNParam(cfg, k, v); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if cfg.Account == "" && strings.HasSuffix(cfg.Host, defaultDomain) {
+		posDot := strings.Index(cfg.Host, ".")
+		if posDot > 0 {
+			cfg.Account = cfg.Host[:posDot]
+		}
+	}
+	if posDot := strings.Index(cfg.Account, "."); posDot >= 0 {
+		cfg.Account = cfg.Account[:posDot]
+	}
+	if err := fillMissingConfigParameters(cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
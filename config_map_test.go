@@ -0,0 +1,108 @@
+// Copyright (c) 2023 Snowflake Computing Inc. All rights reserved.
+
+package gosnowflake
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestConfigMapRoundTrip(t *testing.T) {
+	cfg := &Config{
+		Account:                        "a",
+		User:                           "u",
+		Password:                       "p",
+		Database:                       "db",
+		Warehouse:                      "wh",
+		LoginTimeout:                   30 * time.Second,
+		ClientTimeout:                  5 * time.Minute,
+		ConnectTimeout:                 10 * time.Second,
+		JWTLeeway:                      2 * time.Second,
+		ValidateDefaultParameters:      ConfigBoolFalse,
+		ClientSessionKeepAlive:         ConfigBoolTrue,
+		ClientStoreTemporaryCredential: ConfigBoolFalse,
+		IncludeRetryReason:             ConfigBoolFalse,
+		RetryableHTTPStatuses:          []int{429, 503},
+		Params:                         map[string]*string{"QUERY_TAG": strPtr("tag")},
+	}
+	if err := fillMissingConfigParameters(cfg); err != nil {
+		t.Fatal(err)
+	}
+	m := cfg.ToMap()
+	for k, v := range map[string]string{
+		"user":                      "u",
+		"host":                      "a.snowflakecomputing.com",
+		"port":                      "443",
+		"loginTimeout":              "30",
+		"clientTimeout":             "300",
+		"connectTimeout":            "10",
+		"validateDefaultParameters": "false",
+		"clientSessionKeepAlive":    "true",
+		"QUERY_TAG":                 "tag",
+	} {
+		if m[k] != v {
+			t.Errorf("unexpected value for %v: %q, expected %q", k, m[k], v)
+		}
+	}
+	got, err := ConfigFromMap(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, cfg) {
+		t.Fatalf("the config did not round trip.\ngot:      %+v\nexpected: %+v", got, cfg)
+	}
+}
+
+func TestConfigMapOmitsUnsetFields(t *testing.T) {
+	m := (&Config{Account: "a", User: "u", Password: "p"}).ToMap()
+	for _, k := range []string{"loginTimeout", "clientTimeout", "jwtTimeout", "application", "clientSessionKeepAlive", "port", "host"} {
+		if v, ok := m[k]; ok {
+			t.Errorf("unset field %v should be omitted, got %q", k, v)
+		}
+	}
+	cfg, err := ConfigFromMap(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.LoginTimeout != defaultLoginTimeout || cfg.Port != 443 || cfg.ClientSessionKeepAlive != configBoolNotSet {
+		t.Fatalf("the defaults should be filled in: %+v", cfg)
+	}
+}
+
+func TestConfigFromMapErrors(t *testing.T) {
+	for _, m := range []map[string]string{
+		{"user": "u", "password": "p"},
+		{"account": "a", "user": "u", "password": "p", "port": "x"},
+		{"account": "a", "user": "u", "password": "p", "loginTimeout": "x"},
+		{"account": "a", "user": "u", "password": "p", "clientSessionKeepAlive": "maybe"},
+	} {
+		if _, err := ConfigFromMap(m); err == nil {
+			t.Errorf("expected an error for %v", m)
+		}
+	}
+}
+
+func TestIsSecretConfigKey(t *testing.T) {
+	m := (&Config{
+		Account:           "a",
+		User:              "u",
+		Password:          "p",
+		Passcode:          "123456",
+		Token:             "t",
+		OAuthClientSecret: "s",
+	}).ToMap()
+	for _, k := range []string{"password", "passcode", "token", "oauthClientSecret"} {
+		if _, ok := m[k]; !ok {
+			t.Fatalf("missing %v", k)
+		}
+		if !IsSecretConfigKey(k) {
+			t.Errorf("%v should be secret", k)
+		}
+	}
+	for _, k := range []string{"user", "account", "loginTimeout"} {
+		if IsSecretConfigKey(k) {
+			t.Errorf("%v should not be secret", k)
+		}
+	}
+}
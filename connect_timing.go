@@ -0,0 +1,125 @@
+// Copyright (c) 2023 Snowflake Computing Inc. All rights reserved.
+
+package gosnowflake
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// ConnectTiming is the breakdown of the time spent opening a connection, passed to Config.OnConnectTiming.
+// DNS, TLSHandshake and OCSP are summed over the login requests, and are zero for the requests
+// reusing an idle connection of the transport.
+type ConnectTiming struct {
+	DNS          time.Duration // resolution of the host names
+	TLSHandshake time.Duration // TLS handshakes, including the OCSP checks of the server certificates
+	OCSP         time.Duration // revocation checks of the server certificates
+	Login        time.Duration // login request to Snowflake, including its retries
+	Total        time.Duration // from the start of opening the connection until it is established
+}
+
+// connectTimer collects the ConnectTiming of a connection until it is reported.
+type connectTimer struct {
+	mu       sync.Mutex
+	start    time.Time
+	dnsStart time.Time
+	tlsStart time.Time
+	timing   ConnectTiming
+	reported bool
+}
+
+func newConnectTimer() *connectTimer {
+	return &connectTimer{start: time.Now()}
+}
+
+// add adds d to the duration returned by field, unless the timing was already reported.
+func (t *connectTimer) add(field func(*ConnectTiming) *time.Duration, d time.Duration) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.reported {
+		*field(&t.timing) += d
+	}
+}
+
+func (t *connectTimer) addOCSP(d time.Duration) {
+	t.add(func(c *ConnectTiming) *time.Duration { return &c.OCSP }, d)
+}
+
+func (t *connectTimer) addLogin(d time.Duration) {
+	t.add(func(c *ConnectTiming) *time.Duration { return &c.Login }, d)
+}
+
+// withClientTrace returns a context timing the DNS resolutions and TLS handshakes of its requests.
+func (t *connectTimer) withClientTrace(ctx context.Context) context.Context {
+	if t == nil {
+		return ctx
+	}
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.mu.Lock()
+			t.dnsStart = time.Now()
+			t.mu.Unlock()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			t.mu.Lock()
+			start := t.dnsStart
+			t.mu.Unlock()
+			t.add(func(c *ConnectTiming) *time.Duration { return &c.DNS }, time.Since(start))
+		},
+		TLSHandshakeStart: func() {
+			t.mu.Lock()
+			t.tlsStart = time.Now()
+			t.mu.Unlock()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			t.mu.Lock()
+			start := t.tlsStart
+			t.mu.Unlock()
+			t.add(func(c *ConnectTiming) *time.Duration { return &c.TLSHandshake }, time.Since(start))
+		},
+	})
+}
+
+// report passes the timing to hook once. The durations measured afterwards are ignored.
+func (t *connectTimer) report(hook func(ConnectTiming)) {
+	if t == nil || hook == nil {
+		return
+	}
+	t.mu.Lock()
+	if t.reported {
+		t.mu.Unlock()
+		return
+	}
+	t.reported = true
+	t.timing.Total = time.Since(t.start)
+	timing := t.timing
+	t.mu.Unlock()
+	hook(timing)
+}
+
+// transportWithOCSPTimer returns a copy of the transport adding the time spent verifying the server
+// certificates, i.e. in the OCSP checks, to the timer. Like transportWithOCSPSoftFailHook, it is not shared,
+// so the connection has a connection pool of its own, see Config.OnConnectTiming.
+func transportWithOCSPTimer(base *http.Transport, timer *connectTimer) *http.Transport {
+	if base.TLSClientConfig == nil || base.TLSClientConfig.VerifyPeerCertificate == nil {
+		return base
+	}
+	t := base.Clone()
+	verify := base.TLSClientConfig.VerifyPeerCertificate
+	t.TLSClientConfig.VerifyPeerCertificate = func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
+		start := time.Now()
+		defer func() {
+			timer.addOCSP(time.Since(start))
+		}()
+		return verify(rawCerts, verifiedChains)
+	}
+	return t
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 2023 Snowflake Computing Inc. All rights reserved.
+
+package gosnowflake
+
+import (
+	"context"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestUnitConnectTimingReportsLogin(t *testing.T) {
+	const loginDelay = 20 * time.Millisecond
+	var timings []ConnectTiming
+	sc := getDefaultSnowflakeConn()
+	sc.cfg.OnConnectTiming = func(timing ConnectTiming) {
+		timings = append(timings, timing)
+	}
+	sc.connectTimer = newConnectTimer()
+	sc.rest.FuncPostAuth = func(ctx context.Context, sr *snowflakeRestful, client *http.Client, params *url.Values, headers map[string]string, bodyCreator bodyCreatorType, timeout time.Duration) (*authResponse, error) {
+		time.Sleep(loginDelay)
+		return postAuthSuccess(ctx, sr, client, params, headers, bodyCreator, timeout)
+	}
+	sc.ctx = context.Background()
+	if err := authenticateWithConfig(sc); err != nil {
+		t.Fatal(err)
+	}
+	sc.connectTimer.report(sc.cfg.OnConnectTiming)
+	sc.connectTimer.addLogin(time.Hour)
+	sc.connectTimer.report(sc.cfg.OnConnectTiming)
+
+	if len(timings) != 1 {
+		t.Fatalf("the timing should be reported once, got: %v", len(timings))
+	}
+	if timings[0].Login < loginDelay || timings[0].Login >= time.Hour {
+		t.Fatalf("unexpected login duration: %v", timings[0].Login)
+	}
+	if timings[0].Total < timings[0].Login {
+		t.Fatalf("the total %v should include the login %v", timings[0].Total, timings[0].Login)
+	}
+}
+
+func TestConnectTimingTLSHandshakeAndOCSP(t *testing.T) {
+	const verifyDelay = 10 * time.Millisecond
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	base := server.Client().Transport.(*http.Transport).Clone()
+	base.TLSClientConfig.VerifyPeerCertificate = func(_ [][]byte, _ [][]*x509.Certificate) error {
+		time.Sleep(verifyDelay)
+		return nil
+	}
+	timer := newConnectTimer()
+	client := &http.Client{Transport: transportWithOCSPTimer(base, timer)}
+	req, err := http.NewRequestWithContext(timer.withClientTrace(context.Background()), http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	var timing ConnectTiming
+	timer.report(func(c ConnectTiming) {
+		timing = c
+	})
+	if timing.OCSP < verifyDelay {
+		t.Fatalf("unexpected OCSP duration: %v", timing.OCSP)
+	}
+	if timing.TLSHandshake < timing.OCSP {
+		t.Fatalf("the TLS handshake %v should include the OCSP checks %v", timing.TLSHandshake, timing.OCSP)
+	}
+	if timing.Login != 0 {
+		t.Fatalf("no login was made, got: %v", timing.Login)
+	}
+}
@@ -45,8 +45,12 @@ const (
 
 const (
 	sessionClientSessionKeepAlive          = "client_session_keep_alive"
+	sessionClientSessionKeepAliveFrequency = "client_session_keep_alive_heartbeat_frequency"
 	sessionClientValidateDefaultParameters = "CLIENT_VALIDATE_DEFAULT_PARAMETERS"
 	sessionArrayBindStageThreshold         = "client_stage_array_binding_threshold"
+	arrowCompressionParameter              = "CLIENT_ARROW_COMPRESSION_CODEC"
+	resultChunkSizeParameter               = "CLIENT_RESULT_CHUNK_SIZE"
+	sessionGoQueryResultFormat             = "go_query_result_format"
 	serviceName                            = "service_name"
 )
 
@@ -69,6 +73,13 @@ type snowflakeConn struct {
 	internal            InternalClient
 	queryContextCache   *queryContextCache
 	currentTimeProvider currentTimeProvider
+	getCache            *getCache
+	prepareCache        *prepareCache
+	lastQueryID         atomic.Value // string. set by the statements run concurrently, e.g. in file transfers
+	downloads           *downloadLimiter
+	connectTimer        *connectTimer // nil unless Config.OnConnectTiming is set
+	// masterTokenExpiresAt is when the session can no longer be renewed. Zero if unknown.
+	masterTokenExpiresAt time.Time
 }
 
 var (
@@ -83,10 +94,19 @@ func (sc *snowflakeConn) exec(
 	isInternal bool,
 	describeOnly bool,
 	bindings []driver.NamedValue) (
-	*execResponse, error) {
-	var err error
+	data *execResponse, err error) {
+	if query, bindings, err = interpolateRawBinds(query, bindings); err != nil {
+		return nil, err
+	}
 	counter := atomic.AddUint64(&sc.SequenceCounter, 1) // query sequence counter
 
+	metrics := sc.cfg.metricsCollector()
+	metrics.QueryStarted(ctx, query)
+	startTime := time.Now()
+	defer func() {
+		metrics.QueryFinished(ctx, query, time.Since(startTime), err)
+	}()
+
 	queryContext, err := buildQueryContext(sc.queryContextCache)
 	if err != nil {
 		logger.Errorf("error while building query context: %v", err)
@@ -100,17 +120,39 @@ func (sc *snowflakeConn) exec(
 		SequenceID:   counter,
 		QueryContext: queryContext,
 	}
+	for name, value := range getStatementParameters(ctx) {
+		req.Parameters[name] = value
+	}
 	if key := ctx.Value(multiStatementCount); key != nil {
 		req.Parameters[string(multiStatementCount)] = key
 	}
+	if format := sc.resultFormatOverride(ctx); format != "" {
+		req.Parameters[strings.ToUpper(sessionGoQueryResultFormat)] = format
+	}
+	if key := ctx.Value(beginAutocommit); key != nil {
+		req.Parameters[string(beginAutocommit)] = key
+	}
+	if priority := getQueryPriority(ctx); priority != "" {
+		req.Parameters[string(queryPriority)] = string(priority)
+	}
+	if sc.cfg.ArrowCompression != "" {
+		req.Parameters[arrowCompressionParameter] = strings.ToUpper(sc.cfg.ArrowCompression)
+	}
+	if sc.cfg.ResultChunkSize > 0 {
+		req.Parameters[resultChunkSizeParameter] = sc.cfg.ResultChunkSize
+	}
 	logger.WithContext(ctx).Infof("parameters: %v", req.Parameters)
 
 	// handle bindings, if required
-	requestID := getOrGenerateRequestIDFromContext(ctx)
+	requestID := getOrGenerateQueryRequestID(ctx, sc.cfg)
 	if len(bindings) > 0 {
 		if err = sc.processBindings(ctx, bindings, describeOnly, requestID, &req); err != nil {
 			return nil, err
 		}
+		if req.BindStage != "" && !noResult {
+			// asynchronous queries may still read the binds after returning
+			defer sc.removeBindStageFiles(ctx, req.BindStage)
+		}
 	}
 	logger.WithContext(ctx).Infof("bindings: %v", req.Bindings)
 
@@ -130,21 +172,65 @@ func (sc *snowflakeConn) exec(
 		return nil, err
 	}
 
-	data, err := sc.rest.FuncPostQuery(ctx, sc.rest, &url.Values{}, headers,
-		jsonBody, sc.rest.RequestTimeout, requestID, sc.cfg)
+	data, code, err := sc.submit(ctx, headers, jsonBody, requestID)
+	if isSessionLost(data, err) && sc.canReconnect(ctx, query, isInternal, &req) {
+		if err = sc.reconnect(ctx); err != nil {
+			return nil, err
+		}
+		// the request ID of the failed request cannot be reused for the new session
+		data, code, err = sc.resubmit(ctx, headers, jsonBody)
+	}
 	if err != nil {
+		if se, ok := err.(*SnowflakeError); ok && se.Number == ErrRequestTooLarge &&
+			req.BindStage == "" && isArrayBind(bindings) {
+			return data, &SnowflakeError{
+				Number:      ErrArrayBindTooLarge,
+				SQLState:    se.SQLState,
+				Message:     errMsgArrayBindTooLarge,
+				MessageArgs: []interface{}{arrayBindValueCount(bindings)},
+			}
+		}
 		return data, err
 	}
-	code := -1
-	if data.Code != "" {
-		code, err = strconv.Atoi(data.Code)
-		if err != nil {
+	resumeStart := time.Now()
+	for attempt := 0; !data.Success; attempt++ {
+		delay, ok := sc.warehouseResumeRetryDelay(ctx, query, code, attempt, time.Since(resumeStart))
+		if !ok {
+			break
+		}
+		logger.WithContext(ctx).Infof("the warehouse is being resumed, submitting the query again in %v", delay)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+		if data, code, err = sc.resubmit(ctx, headers, jsonBody); err != nil {
 			return data, err
 		}
 	}
-	logger.WithContext(ctx).Infof("Success: %v, Code: %v", data.Success, code)
+	if !data.Success && sc.isRetryableTransientQueryError(ctx, query, code) {
+		logger.WithContext(ctx).Infof("transient error %v for read only query, retrying once", code)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(transientQueryErrorRetryDelay):
+		}
+		if data, code, err = sc.resubmit(ctx, headers, jsonBody); err != nil {
+			return data, err
+		}
+	}
+	if !isInternal && data.Data.QueryID != "" {
+		sc.lastQueryID.Store(data.Data.QueryID)
+		if sc.cfg.OnStatement != nil {
+			sc.cfg.OnStatement(ctx, query, data.Data.QueryID)
+		}
+	}
 	if !data.Success {
-		err = (populateErrorFields(code, data)).exceptionTelemetry(sc)
+		if code == ErrSessionGone {
+			// the session cannot be used anymore, see IsValid
+			sc.rest.TokenAccessor.SetTokens("", "", -1)
+		}
+		err = (populateErrorFields(code, data)).queryExceptionTelemetry(sc, query)
 		return nil, err
 	}
 
@@ -165,6 +251,11 @@ func (sc *snowflakeConn) exec(
 		}
 	}
 
+	if updates := getQueryStatusUpdates(ctx); updates != nil && data.Data.QueryID != "" &&
+		(data.Data.AsyncRows != nil || data.Data.AsyncResult != nil) {
+		go sc.pollQueryStatus(ctx, data.Data.QueryID, updates)
+	}
+
 	logger.WithContext(ctx).Info("Exec/Query SUCCESS")
 	if data.Data.FinalDatabaseName != "" {
 		sc.cfg.Database = data.Data.FinalDatabaseName
@@ -182,6 +273,30 @@ func (sc *snowflakeConn) exec(
 	return data, err
 }
 
+// submit posts the query request and returns the response with its error code, -1 if it has none.
+func (sc *snowflakeConn) submit(ctx context.Context, headers map[string]string, jsonBody []byte, requestID UUID) (
+	*execResponse, int, error) {
+	data, err := sc.rest.FuncPostQuery(ctx, sc.rest, &url.Values{}, headers,
+		jsonBody, sc.rest.RequestTimeout, requestID, sc.cfg)
+	if err != nil {
+		return data, -1, err
+	}
+	code := -1
+	if data.Code != "" {
+		if code, err = strconv.Atoi(data.Code); err != nil {
+			return data, -1, err
+		}
+	}
+	logger.WithContext(ctx).Infof("Success: %v, Code: %v", data.Success, code)
+	return data, code, nil
+}
+
+// resubmit posts the query request again. The failed request is finished, so it is submitted as a new request.
+func (sc *snowflakeConn) resubmit(ctx context.Context, headers map[string]string, jsonBody []byte) (
+	*execResponse, int, error) {
+	return sc.submit(ctx, headers, jsonBody, NewUUID())
+}
+
 func extractQueryContext(data *execResponse) (queryContext, error) {
 	var queryContext queryContext
 	err := json.Unmarshal(data.Data.QueryContext, &queryContext)
@@ -236,7 +351,12 @@ func (sc *snowflakeConn) BeginTx(
 		return nil, driver.ErrBadConn
 	}
 	isDesc := isDescribeOnly(ctx)
-	if _, err := sc.exec(ctx, "BEGIN", false, /* noResult */
+	txOptions := getTransactionOptions(ctx)
+	beginCtx := ctx
+	if txOptions.Autocommit != configBoolNotSet {
+		beginCtx = context.WithValue(ctx, beginAutocommit, txOptions.Autocommit == ConfigBoolTrue)
+	}
+	if _, err := sc.exec(beginCtx, txOptions.beginStatement(), false, /* noResult */
 		false /* isInternal */, isDesc, nil); err != nil {
 		return nil, err
 	}
@@ -258,7 +378,7 @@ func (sc *snowflakeConn) Close() (err error) {
 	sc.stopHeartBeat()
 	defer sc.cleanup()
 
-	if sc.cfg != nil && !sc.cfg.KeepSessionAlive {
+	if sc.cfg != nil && !sc.cfg.KeepSessionAlive && (sc.cfg.SessionToken == "" || sc.cfg.CloseAttachedSession) {
 		if err = sc.rest.FuncCloseSession(sc.ctx, sc.rest, sc.rest.RequestTimeout); err != nil {
 			logger.Error(err)
 		}
@@ -278,6 +398,10 @@ func (sc *snowflakeConn) PrepareContext(
 		sc:    sc,
 		query: query,
 	}
+	if params, ok := sc.prepareCache.get(sc.prepareCacheKey(query)); ok {
+		logger.WithContext(ctx).Debugf("reusing the cached bind parameters of the statement")
+		stmt.params = params
+	}
 	return stmt, nil
 }
 
@@ -307,7 +431,7 @@ func (sc *snowflakeConn) ExecContext(
 				SQLState: data.Data.SQLState,
 				Message:  err.Error(),
 				QueryID:  data.Data.QueryID,
-			}).exceptionTelemetry(sc)
+			}).queryExceptionTelemetry(sc, query)
 		}
 		return nil, err
 	}
@@ -328,6 +452,9 @@ func (sc *snowflakeConn) ExecContext(
 			affectedRows: updatedRows,
 			insertID:     -1,
 			queryID:      data.Data.QueryID,
+			warnings:     data.Data.Warnings,
+			stats:        data.Data.Stats,
+			session:      sessionContextOf(&data.Data),
 		}, nil // last insert id is not supported by Snowflake
 	} else if isMultiStmt(&data.Data) {
 		return sc.handleMultiExec(ctx, data.Data)
@@ -386,7 +513,7 @@ func (sc *snowflakeConn) queryContextInternal(
 				SQLState: data.Data.SQLState,
 				Message:  err.Error(),
 				QueryID:  data.Data.QueryID,
-			}).exceptionTelemetry(sc)
+			}).queryExceptionTelemetry(sc, query)
 		}
 		return nil, err
 	}
@@ -398,9 +525,14 @@ func (sc *snowflakeConn) queryContextInternal(
 
 	rows := new(snowflakeRows)
 	rows.sc = sc
+	rows.nullToZeroValue = nullToZeroValueEnabled(ctx)
 	rows.queryID = data.Data.QueryID
+	rows.warnings = data.Data.Warnings
+	rows.stats = data.Data.Stats
+	rows.session = sessionContextOf(&data.Data)
 
 	if isMultiStmt(&data.Data) {
+		rows.childIDs = childQueryIDs(&data.Data)
 		// handleMultiQuery is responsible to fill rows with childResults
 		if err = sc.handleMultiQuery(ctx, data.Data, rows); err != nil {
 			return nil, err
@@ -431,6 +563,41 @@ func (sc *snowflakeConn) Query(
 	return sc.QueryContext(sc.ctx, query, toNamedValues(args))
 }
 
+// IsValid implements driver.Validator. It checks without a round-trip that the connection
+// still holds a session that can be renewed, so that database/sql discards dead connections.
+func (sc *snowflakeConn) IsValid() bool {
+	if sc.rest == nil || sc.rest.TokenAccessor == nil {
+		return false
+	}
+	token, masterToken, sessionID := sc.rest.TokenAccessor.GetTokens()
+	if token == "" || masterToken == "" || sessionID == -1 {
+		return false
+	}
+	return sc.masterTokenExpiresAt.IsZero() || time.Now().Before(sc.masterTokenExpiresAt)
+}
+
+// HTTPStatusCounts returns the number of HTTP responses received by the connection so far, by status code.
+// Every attempt of a retried request is counted, as well as the downloads of the result chunks.
+func (sc *snowflakeConn) HTTPStatusCounts() map[int]int64 {
+	if sc.rest == nil {
+		return map[int]int64{}
+	}
+	return sc.rest.statusCounts.snapshot()
+}
+
+// BytesDownloaded returns the number of bytes the connection downloaded from the cloud storage so far,
+// i.e. the result chunks and the files fetched by GET. Files served from the GET cache are not counted.
+func (sc *snowflakeConn) BytesDownloaded() int64 {
+	return sc.downloads.downloaded()
+}
+
+// LastQueryID returns the query ID of the last statement executed on the connection, including a failed one,
+// or an empty string if none was. The statements run internally by the driver are not taken into account.
+func (sc *snowflakeConn) LastQueryID() string {
+	queryID, _ := sc.lastQueryID.Load().(string)
+	return queryID
+}
+
 func (sc *snowflakeConn) Ping(ctx context.Context) error {
 	logger.WithContext(ctx).Infoln("Ping")
 	if sc.rest == nil {
@@ -447,12 +614,40 @@ func (sc *snowflakeConn) Ping(ctx context.Context) error {
 // CheckNamedValue determines which types are handled by this driver aside from
 // the instances captured by driver.Value
 func (sc *snowflakeConn) CheckNamedValue(nv *driver.NamedValue) error {
-	if supportedNullBind(nv) || supportedArrayBind(nv) {
+	if supportedNullBind(nv) || supportedArrayBind(nv) || supportedObjectBind(nv) || supportedFileBind(nv) ||
+		supportedRawBind(nv) || supportedTimeOfDayBind(nv) {
 		return nil
 	}
 	return driver.ErrSkip
 }
 
+// Keys of the map returned by SessionInfo
+const (
+	SessionInfoSessionID = "sessionId"
+	SessionInfoRole      = "role"
+	SessionInfoWarehouse = "warehouse"
+	SessionInfoDatabase  = "database"
+	SessionInfoSchema    = "schema"
+)
+
+// SessionInfo returns the session ID, role, warehouse, database and schema of the current session.
+// The values are captured at login and updated by the responses of subsequent queries, e.g. USE commands.
+func (sc *snowflakeConn) SessionInfo() map[string]string {
+	info := make(map[string]string)
+	if sc.rest != nil && sc.rest.TokenAccessor != nil {
+		if _, _, sessionID := sc.rest.TokenAccessor.GetTokens(); sessionID > 0 {
+			info[SessionInfoSessionID] = strconv.FormatInt(sessionID, 10)
+		}
+	}
+	if sc.cfg != nil {
+		info[SessionInfoRole] = sc.cfg.Role
+		info[SessionInfoWarehouse] = sc.cfg.Warehouse
+		info[SessionInfoDatabase] = sc.cfg.Database
+		info[SessionInfoSchema] = sc.cfg.Schema
+	}
+	return info
+}
+
 func (sc *snowflakeConn) GetQueryStatus(
 	ctx context.Context,
 	queryID string) (
@@ -492,7 +687,7 @@ func (sc *snowflakeConn) QueryArrowStream(ctx context.Context, query string, bin
 				SQLState: data.Data.SQLState,
 				Message:  err.Error(),
 				QueryID:  data.Data.QueryID,
-			}).exceptionTelemetry(sc)
+			}).queryExceptionTelemetry(sc, query)
 		}
 		return nil, err
 	}
@@ -726,6 +921,17 @@ func (scd *snowflakeArrowStreamChunkDownloader) GetBatches() (out []ArrowStreamB
 	return
 }
 
+// chunkClient returns the client for result chunk downloads if they use a dedicated transport.
+func chunkClient(cfg *Config) *http.Client {
+	if cfg.ChunkDownloadTransport == nil {
+		return nil
+	}
+	return &http.Client{
+		Timeout:   cfg.ClientTimeout,
+		Transport: cfg.ChunkDownloadTransport,
+	}
+}
+
 func buildSnowflakeConn(ctx context.Context, config Config) (*snowflakeConn, error) {
 	sc := &snowflakeConn{
 		SequenceCounter:     0,
@@ -734,21 +940,64 @@ func buildSnowflakeConn(ctx context.Context, config Config) (*snowflakeConn, err
 		queryContextCache:   (&queryContextCache{}).init(),
 		currentTimeProvider: defaultTimeProvider,
 	}
+	if sc.cfg.OnConnectTiming != nil {
+		sc.connectTimer = newConnectTimer()
+	}
+	if sc.cfg.GetCacheBytes > 0 {
+		sc.getCache = newGetCache(sc.cfg.GetCacheBytes)
+	}
+	if !sc.cfg.DisablePrepareCache {
+		sc.prepareCache = newPrepareCache(maxPrepareCacheEntries)
+	}
+	sc.downloads = newDownloadLimiter(sc.cfg.MaxDownloadBytes)
 	var st http.RoundTripper = SnowflakeTransport
 	if sc.cfg.Transporter == nil {
 		if sc.cfg.InsecureMode {
 			// no revocation check with OCSP. Think twice when you want to enable this option.
 			st = snowflakeInsecureTransport
+		} else if sc.cfg.DisableOCSPChecks {
+			// no revocation check with OCSP, but the certificate chain and host name are still verified
+			st = snowflakeNoOCSPTransport
 		} else {
 			// set OCSP fail open mode
 			ocspResponseCacheLock.Lock()
 			atomic.StoreUint32((*uint32)(&ocspFailOpen), uint32(sc.cfg.OCSPFailOpen))
 			ocspResponseCacheLock.Unlock()
 		}
+		if version, ok := tlsVersions[sc.cfg.MinTLSVersion]; ok {
+			st = transportWithMinTLSVersion(st.(*http.Transport), version)
+		}
+		if sc.cfg.RootCAs != nil {
+			st = transportWithRootCAs(st.(*http.Transport), sc.cfg.RootCAs)
+		}
+		if sc.cfg.ConnectTimeout > 0 || sc.cfg.ResponseHeaderTimeout > 0 {
+			st = transportWithTimeouts(st.(*http.Transport), sc.cfg.ConnectTimeout, sc.cfg.ResponseHeaderTimeout)
+		}
+		if sc.cfg.SOCKS5ProxyURL != "" {
+			proxyURL, err := parseSOCKS5ProxyURL(sc.cfg.SOCKS5ProxyURL)
+			if err != nil {
+				return nil, err
+			}
+			st = transportWithSOCKS5Proxy(st.(*http.Transport), proxyURL)
+		}
+		if sc.cfg.DNSCacheTTL > 0 {
+			st = transportWithDNSCache(st.(*http.Transport), sc.cfg.DNSCacheTTL)
+		}
+		if sc.cfg.OnOCSPSoftFail != nil && !sc.cfg.InsecureMode && !sc.cfg.DisableOCSPChecks {
+			// applied last so that the shared transport copies above are not derived from a copy of this connection
+			st = transportWithOCSPSoftFailHook(st.(*http.Transport), sc.cfg.OnOCSPSoftFail)
+		}
+		if sc.connectTimer != nil && !sc.cfg.InsecureMode && !sc.cfg.DisableOCSPChecks {
+			st = transportWithOCSPTimer(st.(*http.Transport), sc.connectTimer)
+		}
 	} else {
 		// use the custom transport
 		st = sc.cfg.Transporter
 	}
+	if sc.cfg.MaxRequestsPerSecond > 0 {
+		// shared by the login and the query requests, but not by the chunk downloads from the cloud storage
+		st = &rateLimitedTransport{base: st, limiter: newRequestRateLimiter(sc.cfg.MaxRequestsPerSecond)}
+	}
 	if strings.HasSuffix(sc.cfg.Host, privateLinkSuffix) {
 		if err := sc.setupOCSPPrivatelink(sc.cfg.Application, sc.cfg.Host); err != nil {
 			return nil, err
@@ -779,7 +1028,9 @@ func buildSnowflakeConn(ctx context.Context, config Config) (*snowflakeConn, err
 			Timeout:   sc.cfg.JWTClientTimeout,
 			Transport: st,
 		},
+		ChunkClient:         chunkClient(sc.cfg),
 		TokenAccessor:       tokenAccessor,
+		extraHeaders:        newExtraHeaders(sc.cfg),
 		LoginTimeout:        sc.cfg.LoginTimeout,
 		RequestTimeout:      sc.cfg.RequestTimeout,
 		FuncPost:            postRestful,
@@ -794,6 +1045,11 @@ func buildSnowflakeConn(ctx context.Context, config Config) (*snowflakeConn, err
 		FuncPostAuthSAML:    postAuthSAML,
 		FuncPostAuthOKTA:    postAuthOKTA,
 		FuncGetSSO:          getSSO,
+		OnSessionRenew:      sc.cfg.OnSessionRenew,
+		statusCounts:        &httpStatusCounter{},
+	}
+	if sc.cfg.RetryJitterSeed != 0 {
+		sc.rest.waitAlgo = newSeededWaitAlgo(sc.cfg.RetryJitterSeed)
 	}
 
 	if sc.cfg.DisableTelemetry {
@@ -3,6 +3,7 @@
 package gosnowflake
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
 	"database/sql/driver"
@@ -13,6 +14,8 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"reflect"
+	"strconv"
 	"strings"
 	"sync"
 	"testing"
@@ -173,6 +176,384 @@ func TestExecWithSpecificRequestID(t *testing.T) {
 	}
 }
 
+func TestExecRequestsArrowCompression(t *testing.T) {
+	var codec interface{}
+	postQueryMock := func(_ context.Context, _ *snowflakeRestful,
+		_ *url.Values, _ map[string]string, body []byte, _ time.Duration,
+		_ UUID, _ *Config) (*execResponse, error) {
+		var req execRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Fatal(err)
+		}
+		codec = req.Parameters[arrowCompressionParameter]
+		return &execResponse{Success: true}, nil
+	}
+	sc := &snowflakeConn{
+		cfg:               &Config{Params: map[string]*string{}, ArrowCompression: "zstd"},
+		rest:              &snowflakeRestful{FuncPostQuery: postQueryMock},
+		queryContextCache: (&queryContextCache{}).init(),
+	}
+	if _, err := sc.exec(context.Background(), "SELECT 1", false /* noResult */, false, /* isInternal */
+		false /* describeOnly */, nil); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if codec != ArrowCompressionZstd {
+		t.Fatalf("unexpected compression codec requested: %v", codec)
+	}
+}
+
+func TestExecRequestsResultChunkSize(t *testing.T) {
+	var size interface{}
+	postQueryMock := func(_ context.Context, _ *snowflakeRestful,
+		_ *url.Values, _ map[string]string, body []byte, _ time.Duration,
+		_ UUID, _ *Config) (*execResponse, error) {
+		var req execRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Fatal(err)
+		}
+		size = req.Parameters[resultChunkSizeParameter]
+		return &execResponse{Success: true}, nil
+	}
+	sc := &snowflakeConn{
+		cfg:               &Config{Params: map[string]*string{}, ResultChunkSize: 48},
+		rest:              &snowflakeRestful{FuncPostQuery: postQueryMock},
+		queryContextCache: (&queryContextCache{}).init(),
+	}
+	if _, err := sc.exec(context.Background(), "SELECT 1", false /* noResult */, false, /* isInternal */
+		false /* describeOnly */, nil); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	// JSON numbers are decoded as float64
+	if size != float64(48) {
+		t.Fatalf("unexpected result chunk size requested: %v", size)
+	}
+
+	size = nil
+	sc.cfg.ResultChunkSize = 0
+	if _, err := sc.exec(context.Background(), "SELECT 1", false /* noResult */, false, /* isInternal */
+		false /* describeOnly */, nil); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if size != nil {
+		t.Fatalf("the chunk size should not be sent by default, got: %v", size)
+	}
+}
+
+func TestExecRequestIDPrefix(t *testing.T) {
+	var requestID UUID
+	postQueryMock := func(_ context.Context, _ *snowflakeRestful,
+		_ *url.Values, _ map[string]string, _ []byte, _ time.Duration,
+		id UUID, _ *Config) (*execResponse, error) {
+		requestID = id
+		return &execResponse{Success: true}, nil
+	}
+	sc := &snowflakeConn{
+		cfg:               &Config{Params: map[string]*string{}, RequestIDPrefix: "4bf92f3577b3"},
+		rest:              &snowflakeRestful{FuncPostQuery: postQueryMock},
+		queryContextCache: (&queryContextCache{}).init(),
+	}
+	if _, err := sc.exec(context.Background(), "SELECT 1", false /* noResult */, false, /* isInternal */
+		false /* describeOnly */, nil); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !strings.HasPrefix(requestID.String(), "4bf92f35-77b3-") {
+		t.Fatalf("the request id should start with the prefix, got: %v", requestID)
+	}
+}
+
+func TestExecWithResultFormat(t *testing.T) {
+	var requested interface{}
+	postQueryMock := func(_ context.Context, _ *snowflakeRestful,
+		_ *url.Values, _ map[string]string, body []byte, _ time.Duration,
+		_ UUID, _ *Config) (*execResponse, error) {
+		var req execRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Fatal(err)
+		}
+		requested = req.Parameters["GO_QUERY_RESULT_FORMAT"]
+		return &execResponse{Success: true}, nil
+	}
+	sc := &snowflakeConn{
+		cfg:               &Config{Params: map[string]*string{}},
+		rest:              &snowflakeRestful{FuncPostQuery: postQueryMock},
+		queryContextCache: (&queryContextCache{}).init(),
+	}
+	exec := func(format string) {
+		requested = nil
+		if _, err := sc.exec(WithResultFormat(context.Background(), format), "SELECT 1", false, /* noResult */
+			false /* isInternal */, false /* describeOnly */, nil); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+	}
+
+	exec("json")
+	if requested != ResultFormatJSON {
+		t.Fatalf("unexpected result format requested: %v", requested)
+	}
+	exec(ResultFormatArrow)
+	if requested != ResultFormatArrow {
+		t.Fatalf("unexpected result format requested: %v", requested)
+	}
+
+	origLogger := GetLogger()
+	defer SetLogger(&origLogger)
+	buf := &bytes.Buffer{}
+	testLogger := CreateDefaultLogger()
+	testLogger.SetOutput(buf)
+	SetLogger(&testLogger)
+
+	connFormat := "JSON"
+	sc.cfg.Params[sessionGoQueryResultFormat] = &connFormat
+	exec(ResultFormatArrow)
+	if requested != nil {
+		t.Fatalf("Arrow should not be requested when disabled at the connection, got: %v", requested)
+	}
+	if !strings.Contains(buf.String(), "Arrow result format requested, but it is disabled") {
+		t.Fatalf("expected a warning, got log: %v", buf.String())
+	}
+}
+
+func TestExecWithStatementParameters(t *testing.T) {
+	var params map[string]interface{}
+	postQueryMock := func(_ context.Context, _ *snowflakeRestful,
+		_ *url.Values, _ map[string]string, body []byte, _ time.Duration,
+		_ UUID, _ *Config) (*execResponse, error) {
+		var req execRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Fatal(err)
+		}
+		params = req.Parameters
+		return &execResponse{Success: true}, nil
+	}
+	sc := &snowflakeConn{
+		cfg:               &Config{Params: map[string]*string{}},
+		rest:              &snowflakeRestful{FuncPostQuery: postQueryMock},
+		queryContextCache: (&queryContextCache{}).init(),
+	}
+	ctx, err := WithStatementParameters(context.Background(), map[string]string{
+		"query_tag":         "nightly_load",
+		"USE_CACHED_RESULT": "false",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, _ = WithMultiStatement(ctx, 2)
+	if _, err = sc.exec(ctx, "SELECT 1; SELECT 2", false /* noResult */, false, /* isInternal */
+		false /* describeOnly */, nil); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if params["QUERY_TAG"] != "nightly_load" || params["USE_CACHED_RESULT"] != "false" ||
+		params["MULTI_STATEMENT_COUNT"] != float64(2) {
+		t.Fatalf("unexpected parameters: %v", params)
+	}
+
+	if _, err = sc.exec(context.Background(), "SELECT 1", false /* noResult */, false, /* isInternal */
+		false /* describeOnly */, nil); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if _, ok := params["QUERY_TAG"]; ok {
+		t.Fatalf("statement parameters should not be sent with other statements: %v", params)
+	}
+
+	for _, name := range []string{"multi_statement_count", "GO_QUERY_RESULT_FORMAT", "AUTOCOMMIT", "CLIENT_ARROW_COMPRESSION_CODEC", "query_priority"} {
+		_, err = WithStatementParameters(context.Background(), map[string]string{name: "1"})
+		driverErr, ok := err.(*SnowflakeError)
+		if !ok || driverErr.Number != ErrReservedStatementParameter {
+			t.Fatalf("%v should be refused, err: %v", name, err)
+		}
+	}
+}
+
+func TestExecWithQueryPriority(t *testing.T) {
+	var params map[string]interface{}
+	postQueryMock := func(_ context.Context, _ *snowflakeRestful,
+		_ *url.Values, _ map[string]string, body []byte, _ time.Duration,
+		_ UUID, _ *Config) (*execResponse, error) {
+		var req execRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Fatal(err)
+		}
+		params = req.Parameters
+		return &execResponse{Success: true}, nil
+	}
+	sc := &snowflakeConn{
+		cfg:               &Config{Params: map[string]*string{}},
+		rest:              &snowflakeRestful{FuncPostQuery: postQueryMock},
+		queryContextCache: (&queryContextCache{}).init(),
+	}
+	ctx := WithQueryPriority(context.Background(), QueryPriorityLow)
+	if _, err := sc.exec(ctx, "SELECT 1", false /* noResult */, false, /* isInternal */
+		false /* describeOnly */, nil); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if params["QUERY_PRIORITY"] != "LOW" {
+		t.Fatalf("unexpected parameters: %v", params)
+	}
+
+	if _, err := sc.exec(context.Background(), "SELECT 1", false /* noResult */, false, /* isInternal */
+		false /* describeOnly */, nil); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if _, ok := params["QUERY_PRIORITY"]; ok {
+		t.Fatalf("the priority should not be sent without the option: %v", params)
+	}
+}
+
+func TestQueryWarnings(t *testing.T) {
+	responses := map[string]string{
+		"SELECT": `{"data":{"queryId":"1","rowtype":[{"name":"C","type":"fixed"}],"rowset":[["1"]],` +
+			`"total":1,"returned":1,"queryResultFormat":"json","statementTypeId":4096,` +
+			`"warnings":["implicit cast of VARCHAR to NUMBER in column C"]},"code":null,"success":true}`,
+		"INSERT": `{"data":{"queryId":"2","rowtype":[{"name":"number of rows inserted","type":"fixed"}],` +
+			`"rowset":[["1"]],"total":1,"returned":1,"queryResultFormat":"json","statementTypeId":12544,` +
+			`"warnings":[{"message":"value truncated in column D"}]},"code":null,"success":true}`,
+	}
+	postQueryMock := func(_ context.Context, _ *snowflakeRestful,
+		_ *url.Values, _ map[string]string, body []byte, _ time.Duration,
+		_ UUID, _ *Config) (*execResponse, error) {
+		var req execRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Fatal(err)
+		}
+		var resp execResponse
+		if err := json.Unmarshal([]byte(responses[strings.Fields(req.SQLText)[0]]), &resp); err != nil {
+			t.Fatal(err)
+		}
+		return &resp, nil
+	}
+	sc := &snowflakeConn{
+		cfg:               &Config{Params: map[string]*string{}},
+		rest:              &snowflakeRestful{FuncPostQuery: postQueryMock},
+		queryContextCache: (&queryContextCache{}).init(),
+	}
+	rows, err := sc.QueryContext(context.Background(), "SELECT '1'::NUMBER AS C", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	warnings := rows.(SnowflakeRows).Warnings()
+	if len(warnings) != 1 || warnings[0] != "implicit cast of VARCHAR to NUMBER in column C" {
+		t.Fatalf("unexpected warnings: %v", warnings)
+	}
+
+	result, err := sc.ExecContext(context.Background(), "INSERT INTO t VALUES ('abc')", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	warnings = result.(SnowflakeResult).Warnings()
+	if len(warnings) != 1 || warnings[0] != "value truncated in column D" {
+		t.Fatalf("unexpected warnings: %v", warnings)
+	}
+}
+
+func TestQueryStats(t *testing.T) {
+	responses := map[string]string{
+		"SELECT": `{"data":{"queryId":"1","rowtype":[{"name":"C","type":"fixed"}],"rowset":[["1"]],` +
+			`"total":1,"returned":1,"queryResultFormat":"json","statementTypeId":4096,` +
+			`"stats":{"bytesScanned":1048576,"partitionsScanned":3,"partitionsTotal":40,"creditsUsed":0.000125}},` +
+			`"code":null,"success":true}`,
+		"INSERT": `{"data":{"queryId":"2","rowtype":[{"name":"number of rows inserted","type":"fixed"}],` +
+			`"rowset":[["1"]],"total":1,"returned":1,"queryResultFormat":"json","statementTypeId":12544,` +
+			`"stats":{"bytesScanned":512,"partitionsScanned":1,"partitionsTotal":1}},"code":null,"success":true}`,
+		"UPDATE": `{"data":{"queryId":"3","rowtype":[{"name":"number of rows updated","type":"fixed"}],` +
+			`"rowset":[["0"]],"total":1,"returned":1,"queryResultFormat":"json","statementTypeId":12288},` +
+			`"code":null,"success":true}`,
+	}
+	postQueryMock := func(_ context.Context, _ *snowflakeRestful,
+		_ *url.Values, _ map[string]string, body []byte, _ time.Duration,
+		_ UUID, _ *Config) (*execResponse, error) {
+		var req execRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Fatal(err)
+		}
+		var resp execResponse
+		if err := json.Unmarshal([]byte(responses[strings.Fields(req.SQLText)[0]]), &resp); err != nil {
+			t.Fatal(err)
+		}
+		return &resp, nil
+	}
+	sc := &snowflakeConn{
+		cfg:               &Config{Params: map[string]*string{}},
+		rest:              &snowflakeRestful{FuncPostQuery: postQueryMock},
+		queryContextCache: (&queryContextCache{}).init(),
+	}
+	rows, err := sc.QueryContext(context.Background(), "SELECT C FROM t", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	expected := QueryStats{BytesScanned: 1048576, PartitionsScanned: 3, PartitionsTotal: 40, CreditsUsed: 0.000125}
+	if stats := rows.(SnowflakeRows).QueryStats(); stats == nil || *stats != expected {
+		t.Fatalf("unexpected stats. expected: %+v, got: %+v", expected, stats)
+	}
+
+	result, err := sc.ExecContext(context.Background(), "INSERT INTO t VALUES (1)", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = QueryStats{BytesScanned: 512, PartitionsScanned: 1, PartitionsTotal: 1}
+	if stats := result.(SnowflakeResult).QueryStats(); stats == nil || *stats != expected {
+		t.Fatalf("unexpected stats. expected: %+v, got: %+v", expected, stats)
+	}
+
+	result, err = sc.ExecContext(context.Background(), "UPDATE t SET C = 1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats := result.(SnowflakeResult).QueryStats(); stats != nil {
+		t.Fatalf("expected no stats, got: %+v", stats)
+	}
+}
+
+func TestSessionContext(t *testing.T) {
+	responses := map[string]string{
+		"SELECT": `{"data":{"queryId":"1","rowtype":[{"name":"C","type":"fixed"}],"rowset":[["1"]],` +
+			`"total":1,"returned":1,"queryResultFormat":"json","statementTypeId":4096,` +
+			`"finalDatabaseName":"DB","finalSchemaName":"PUBLIC","finalWarehouseName":"WH_XL","finalRoleName":"ANALYST"},` +
+			`"code":null,"success":true}`,
+		"INSERT": `{"data":{"queryId":"2","rowtype":[{"name":"number of rows inserted","type":"fixed"}],` +
+			`"rowset":[["1"]],"total":1,"returned":1,"queryResultFormat":"json","statementTypeId":12544,` +
+			`"finalDatabaseName":"DB","finalSchemaName":"STAGING","finalWarehouseName":"WH_S","finalRoleName":"LOADER"},` +
+			`"code":null,"success":true}`,
+	}
+	postQueryMock := func(_ context.Context, _ *snowflakeRestful,
+		_ *url.Values, _ map[string]string, body []byte, _ time.Duration,
+		_ UUID, _ *Config) (*execResponse, error) {
+		var req execRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Fatal(err)
+		}
+		var resp execResponse
+		if err := json.Unmarshal([]byte(responses[strings.Fields(req.SQLText)[0]]), &resp); err != nil {
+			t.Fatal(err)
+		}
+		return &resp, nil
+	}
+	sc := &snowflakeConn{
+		cfg:               &Config{Params: map[string]*string{}, Role: "PUBLIC", Warehouse: "WH_S"},
+		rest:              &snowflakeRestful{FuncPostQuery: postQueryMock},
+		queryContextCache: (&queryContextCache{}).init(),
+	}
+	rows, err := sc.QueryContext(context.Background(), "SELECT C FROM t", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	expected := SessionContext{Database: "DB", Schema: "PUBLIC", Warehouse: "WH_XL", Role: "ANALYST"}
+	if session := rows.(SnowflakeRows).SessionContext(); session != expected {
+		t.Fatalf("unexpected session context. expected: %+v, got: %+v", expected, session)
+	}
+
+	result, err := sc.ExecContext(context.Background(), "INSERT INTO t VALUES (1)", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = SessionContext{Database: "DB", Schema: "STAGING", Warehouse: "WH_S", Role: "LOADER"}
+	if session := result.(SnowflakeResult).SessionContext(); session != expected {
+		t.Fatalf("unexpected session context. expected: %+v, got: %+v", expected, session)
+	}
+}
+
 // TestServiceName tests two things:
 // 1. request header contains X-Snowflake-Service if the cfg parameters
 // contains SERVICE_NAME
@@ -475,6 +856,301 @@ func TestExecWithServerSideError(t *testing.T) {
 	}
 }
 
+func transientErrorPostQueryMock(calls *int) func(context.Context, *snowflakeRestful,
+	*url.Values, map[string]string, []byte, time.Duration, UUID, *Config) (*execResponse, error) {
+	return func(_ context.Context, _ *snowflakeRestful,
+		_ *url.Values, _ map[string]string, _ []byte, _ time.Duration,
+		_ UUID, _ *Config) (*execResponse, error) {
+		*calls++
+		if *calls == 1 {
+			return &execResponse{
+				Data:    execResponseData{SQLState: "57P03", QueryID: "1"},
+				Message: "transient error",
+				Code:    "000630",
+				Success: false,
+			}, nil
+		}
+		return &execResponse{
+			Data:    execResponseData{QueryID: "2"},
+			Success: true,
+		}, nil
+	}
+}
+
+func TestExecRetriesReadOnlyQueryOnTransientError(t *testing.T) {
+	origDelay := transientQueryErrorRetryDelay
+	transientQueryErrorRetryDelay = time.Millisecond
+	defer func() { transientQueryErrorRetryDelay = origDelay }()
+
+	calls := 0
+	sc := &snowflakeConn{
+		cfg:       &Config{Params: map[string]*string{}, RetryQueryOnTransientError: true},
+		rest:      &snowflakeRestful{FuncPostQuery: transientErrorPostQueryMock(&calls)},
+		telemetry: testTelemetry,
+	}
+	data, err := sc.exec(context.Background(), "SELECT 1", false, /* noResult */
+		false /* isInternal */, false /* describeOnly */, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 {
+		t.Fatalf("expected 2 submissions, got: %v", calls)
+	}
+	if data.Data.QueryID != "2" {
+		t.Fatalf("expected result of the retried query, got query id: %v", data.Data.QueryID)
+	}
+}
+
+func TestExecDoesNotRetryOnTransientError(t *testing.T) {
+	origDelay := transientQueryErrorRetryDelay
+	transientQueryErrorRetryDelay = time.Millisecond
+	defer func() { transientQueryErrorRetryDelay = origDelay }()
+
+	testcases := []struct {
+		name    string
+		query   string
+		enabled bool
+	}{
+		{"disabled", "SELECT 1", false},
+		{"insert", "INSERT INTO t VALUES (1)", true},
+		{"update", "UPDATE t SET c = 1", true},
+		{"delete", "/* comment */ DELETE FROM t", true},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			calls := 0
+			sc := &snowflakeConn{
+				cfg:       &Config{Params: map[string]*string{}, RetryQueryOnTransientError: tc.enabled},
+				rest:      &snowflakeRestful{FuncPostQuery: transientErrorPostQueryMock(&calls)},
+				telemetry: testTelemetry,
+			}
+			_, err := sc.exec(context.Background(), tc.query, false, /* noResult */
+				false /* isInternal */, false /* describeOnly */, nil)
+			sfe, ok := err.(*SnowflakeError)
+			if !ok || sfe.Number != 630 {
+				t.Fatalf("expected transient error, got: %v", err)
+			}
+			if calls != 1 {
+				t.Fatalf("expected a single submission, got: %v", calls)
+			}
+		})
+	}
+}
+
+func TestExecRetriesWhileWarehouseResumes(t *testing.T) {
+	origDelay := transientQueryErrorRetryDelay
+	transientQueryErrorRetryDelay = time.Millisecond
+	defer func() { transientQueryErrorRetryDelay = origDelay }()
+
+	calls := 0
+	sc := &snowflakeConn{
+		cfg:       &Config{Params: map[string]*string{}, WarehouseResumeTimeout: time.Minute},
+		rest:      &snowflakeRestful{FuncPostQuery: transientErrorPostQueryMock(&calls)},
+		telemetry: testTelemetry,
+	}
+	data, err := sc.exec(context.Background(), "SELECT 1", false, /* noResult */
+		false /* isInternal */, false /* describeOnly */, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 {
+		t.Fatalf("expected 2 submissions, got: %v", calls)
+	}
+	if data.Data.QueryID != "2" {
+		t.Fatalf("expected result of the retried query, got query id: %v", data.Data.QueryID)
+	}
+}
+
+func TestExecStopsRetryingAfterWarehouseResumeTimeout(t *testing.T) {
+	origDelay := transientQueryErrorRetryDelay
+	transientQueryErrorRetryDelay = time.Millisecond
+	defer func() { transientQueryErrorRetryDelay = origDelay }()
+
+	testcases := []struct {
+		name     string
+		code     string
+		query    string
+		minCalls int
+		maxCalls int
+	}{
+		{"resuming", "000630", "SELECT 1", 2, 3},
+		{"other error", "002003", "SELECT 1", 1, 1},
+		{"dml", "000630", "INSERT INTO t VALUES (1)", 1, 1},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			calls := 0
+			sc := &snowflakeConn{
+				// 1ms, 2ms and 4ms pauses exceed the timeout after the third submission
+				cfg: &Config{Params: map[string]*string{}, WarehouseResumeTimeout: 3 * time.Millisecond},
+				rest: &snowflakeRestful{FuncPostQuery: func(_ context.Context, _ *snowflakeRestful,
+					_ *url.Values, _ map[string]string, _ []byte, _ time.Duration,
+					_ UUID, _ *Config) (*execResponse, error) {
+					calls++
+					return &execResponse{Message: "failed", Code: tc.code, Success: false}, nil
+				}},
+				telemetry: testTelemetry,
+			}
+			_, err := sc.exec(context.Background(), tc.query, false, /* noResult */
+				false /* isInternal */, false /* describeOnly */, nil)
+			if err == nil {
+				t.Fatal("the query should fail")
+			}
+			if calls < tc.minCalls || calls > tc.maxCalls {
+				t.Fatalf("expected %v to %v submissions, got: %v", tc.minCalls, tc.maxCalls, calls)
+			}
+		})
+	}
+}
+
+func TestWarehouseResumeRetryDelay(t *testing.T) {
+	sc := &snowflakeConn{cfg: &Config{WarehouseResumeTimeout: time.Minute}}
+	ctx := context.Background()
+	for attempt, expected := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 16 * time.Second, 16 * time.Second} {
+		delay, ok := sc.warehouseResumeRetryDelay(ctx, "SELECT 1", warehouseResumingErrorCode, attempt, 0)
+		if !ok || delay != expected {
+			t.Fatalf("attempt %v: unexpected delay %v, expected %v", attempt, delay, expected)
+		}
+	}
+	if delay, ok := sc.warehouseResumeRetryDelay(ctx, "SELECT 1", warehouseResumingErrorCode, 3, 55*time.Second); !ok || delay != 5*time.Second {
+		t.Fatalf("the delay should be capped by the remaining time, got %v", delay)
+	}
+	if _, ok := sc.warehouseResumeRetryDelay(ctx, "SELECT 1", warehouseResumingErrorCode, 3, time.Minute); ok {
+		t.Fatal("the query should not be retried after the timeout")
+	}
+	if _, ok := (&snowflakeConn{cfg: &Config{}}).warehouseResumeRetryDelay(ctx, "SELECT 1", warehouseResumingErrorCode, 0, 0); ok {
+		t.Fatal("the query should not be retried by default")
+	}
+}
+
+// expiredSessionPostQueryMock fails the queries of the stale session with the session gone error.
+func expiredSessionPostQueryMock(calls *int) func(context.Context, *snowflakeRestful, *url.Values, map[string]string, []byte, time.Duration, UUID, *Config) (*execResponse, error) {
+	return func(_ context.Context, sr *snowflakeRestful, _ *url.Values, _ map[string]string, _ []byte, _ time.Duration, _ UUID, _ *Config) (*execResponse, error) {
+		*calls++
+		if token, _, _ := sr.TokenAccessor.GetTokens(); token == "stale" {
+			return &execResponse{
+				Message: "Session no longer exists.  New login required to access the service.",
+				Code:    "390111",
+				Success: false,
+			}, nil
+		}
+		return &execResponse{
+			Data:    execResponseData{QueryID: "2"},
+			Success: true,
+		}, nil
+	}
+}
+
+func TestExecReconnectsAfterSessionExpired(t *testing.T) {
+	testcases := []struct {
+		name          string
+		query         string
+		enabled       bool
+		authenticator AuthType
+		reconnect     bool
+	}{
+		{"select", "SELECT 1", true, AuthTypeSnowflake, true},
+		{"jwt", "SELECT 1", true, AuthTypeJwt, true},
+		{"disabled", "SELECT 1", false, AuthTypeSnowflake, false},
+		{"insert", "INSERT INTO t VALUES (1)", true, AuthTypeSnowflake, false},
+		{"external browser", "SELECT 1", true, AuthTypeExternalBrowser, false},
+		{"device code", "SELECT 1", true, AuthTypeOAuthDeviceCode, false},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			queries, logins := 0, 0
+			sc := getDefaultSnowflakeConn()
+			sc.cfg.AutoReconnect = tc.enabled
+			sc.cfg.Authenticator = tc.authenticator
+			sc.rest.FuncPostQuery = expiredSessionPostQueryMock(&queries)
+			sc.rest.FuncPostAuth = func(ctx context.Context, sr *snowflakeRestful, client *http.Client, params *url.Values,
+				headers map[string]string, bodyCreator bodyCreatorType, timeout time.Duration) (*authResponse, error) {
+				logins++
+				return postAuthSuccess(ctx, sr, client, params, headers, bodyCreator, timeout)
+			}
+			sc.rest.TokenAccessor.SetTokens("stale", "stale", 1)
+
+			data, err := sc.exec(context.Background(), tc.query, false, /* noResult */
+				false /* isInternal */, false /* describeOnly */, nil)
+			if !tc.reconnect {
+				sfe, ok := err.(*SnowflakeError)
+				if !ok || sfe.Number != ErrSessionGone {
+					t.Fatalf("expected session gone error, got: %v", err)
+				}
+				if queries != 1 || logins != 0 {
+					t.Fatalf("expected a single submission without login. submissions: %v, logins: %v", queries, logins)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if queries != 2 || logins != 1 {
+				t.Fatalf("expected a login and 2 submissions. submissions: %v, logins: %v", queries, logins)
+			}
+			if data.Data.QueryID != "2" {
+				t.Fatalf("expected result of the retried query, got query id: %v", data.Data.QueryID)
+			}
+			if token, masterToken, _ := sc.rest.TokenAccessor.GetTokens(); token != "t" || masterToken != "m" {
+				t.Fatalf("expected the tokens of the new session, got: %v, %v", token, masterToken)
+			}
+		})
+	}
+}
+
+func TestExecReconnectFailureKeepsConnection(t *testing.T) {
+	queries := 0
+	sc := getDefaultSnowflakeConn()
+	sc.cfg.AutoReconnect = true
+	sc.rest.FuncPostQuery = expiredSessionPostQueryMock(&queries)
+	sc.rest.FuncPostAuth = func(context.Context, *snowflakeRestful, *http.Client, *url.Values,
+		map[string]string, bodyCreatorType, time.Duration) (*authResponse, error) {
+		return nil, &SnowflakeError{Number: ErrCodeFailedToConnect, Message: "login failed"}
+	}
+	sc.rest.FuncCloseSession = closeSessionMock
+	sc.rest.TokenAccessor.SetTokens("stale", "stale", 1)
+
+	_, err := sc.exec(context.Background(), "SELECT 1", false, /* noResult */
+		false /* isInternal */, false /* describeOnly */, nil)
+	if se, ok := err.(*SnowflakeError); !ok || se.Number != ErrCodeFailedToConnect {
+		t.Fatalf("expected the login error, got: %v", err)
+	}
+	if sc.cfg == nil || sc.rest == nil {
+		t.Fatal("the connection should be kept after a failed login")
+	}
+	if sc.IsValid() {
+		t.Fatal("the connection without a session should not be valid")
+	}
+	if err = sc.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestIsReadOnlyQuery(t *testing.T) {
+	testcases := []struct {
+		query    string
+		readOnly bool
+	}{
+		{"SELECT 1", true},
+		{"  select * from t", true},
+		{"WITH a AS (SELECT 1) SELECT * FROM a", true},
+		{"/* comment */ SELECT 1", true},
+		{"-- comment\nSELECT 1", true},
+		{"(SELECT 1)", true},
+		{"SHOW TABLES", true},
+		{"DESC TABLE t", true},
+		{"INSERT INTO t SELECT 1", false},
+		{"MERGE INTO t USING s ON t.a = s.a WHEN MATCHED THEN DELETE", false},
+		{"CREATE TABLE t AS SELECT 1", false},
+		{"SELECTED", false},
+	}
+	for _, tc := range testcases {
+		if isReadOnlyQuery(tc.query) != tc.readOnly {
+			t.Errorf("unexpected result for %q. expected: %v", tc.query, tc.readOnly)
+		}
+	}
+}
+
 func TestConcurrentReadOnParams(t *testing.T) {
 	t.Skip("Fails randomly")
 	config, err := ParseDSN(dsn)
@@ -641,3 +1317,204 @@ func TestBeginCreatesTransaction(t *testing.T) {
 		}
 	})
 }
+
+func TestSessionInfo(t *testing.T) {
+	postAuthMock := func(_ context.Context, _ *snowflakeRestful, _ *http.Client, _ *url.Values, _ map[string]string, _ bodyCreatorType, _ time.Duration) (*authResponse, error) {
+		return &authResponse{
+			Success: true,
+			Data: authResponseMain{
+				Token:       "t",
+				MasterToken: "m",
+				SessionID:   1234,
+				SessionInfo: authResponseSessionInfo{
+					DatabaseName:  "DB",
+					SchemaName:    "SCHEMA",
+					WarehouseName: "WH",
+					RoleName:      "ROLE",
+				},
+			},
+		}, nil
+	}
+	postQueryMock := func(_ context.Context, _ *snowflakeRestful, _ *url.Values, _ map[string]string, _ []byte, _ time.Duration, _ UUID, _ *Config) (*execResponse, error) {
+		return &execResponse{
+			Data:    execResponseData{FinalRoleName: "OTHER_ROLE"},
+			Code:    "0",
+			Success: true,
+		}, nil
+	}
+	sc := &snowflakeConn{
+		ctx: context.Background(),
+		cfg: &Config{Account: "a", User: "u", Password: "p", Params: map[string]*string{}},
+		rest: &snowflakeRestful{
+			FuncPostAuth:  postAuthMock,
+			FuncPostQuery: postQueryMock,
+			TokenAccessor: getSimpleTokenAccessor(),
+		},
+		telemetry:         &snowflakeTelemetry{enabled: false},
+		queryContextCache: (&queryContextCache{}).init(),
+	}
+	if err := authenticateWithConfig(sc); err != nil {
+		t.Fatal(err)
+	}
+	var conn SnowflakeConnection = sc
+	info := conn.SessionInfo()
+	expected := map[string]string{
+		SessionInfoSessionID: "1234",
+		SessionInfoDatabase:  "DB",
+		SessionInfoSchema:    "SCHEMA",
+		SessionInfoWarehouse: "WH",
+		SessionInfoRole:      "ROLE",
+	}
+	for k, v := range expected {
+		if info[k] != v {
+			t.Errorf("expected %v to be %v, got: %v", k, v, info[k])
+		}
+	}
+	if _, err := sc.exec(sc.ctx, "USE ROLE OTHER_ROLE", false, false, false, nil); err != nil {
+		t.Fatal(err)
+	}
+	if role := sc.SessionInfo()[SessionInfoRole]; role != "OTHER_ROLE" {
+		t.Fatalf("role should be updated after USE, got: %v", role)
+	}
+}
+
+func TestIsValid(t *testing.T) {
+	sc := getDefaultSnowflakeConn()
+	sc.rest.TokenAccessor.SetTokens("token", "masterToken", 123)
+	if !sc.IsValid() {
+		t.Fatal("connection with a session should be valid")
+	}
+	sc.masterTokenExpiresAt = time.Now().Add(time.Hour)
+	if !sc.IsValid() {
+		t.Fatal("connection with a non expired master token should be valid")
+	}
+	sc.masterTokenExpiresAt = time.Now().Add(-time.Second)
+	if sc.IsValid() {
+		t.Fatal("connection with an expired master token should not be valid")
+	}
+	sc.masterTokenExpiresAt = time.Time{}
+	sc.rest.TokenAccessor.SetTokens("", "", -1)
+	if sc.IsValid() {
+		t.Fatal("connection with an invalidated session should not be valid")
+	}
+}
+
+func TestIsValidAfterSessionGone(t *testing.T) {
+	postQueryMock := func(_ context.Context, _ *snowflakeRestful, _ *url.Values,
+		_ map[string]string, _ []byte, _ time.Duration, _ UUID, _ *Config) (*execResponse, error) {
+		return &execResponse{
+			Code:    strconv.Itoa(ErrSessionGone),
+			Message: "Session no longer exists.",
+			Success: false,
+		}, nil
+	}
+	sc := getDefaultSnowflakeConn()
+	sc.rest.TokenAccessor.SetTokens("token", "masterToken", 123)
+	sc.rest.FuncPostQuery = postQueryMock
+	sc.queryContextCache = (&queryContextCache{}).init()
+	if !sc.IsValid() {
+		t.Fatal("connection should be valid before the session is gone")
+	}
+	if _, err := sc.exec(context.Background(), "SELECT 1", false, false, false, nil); err == nil {
+		t.Fatal("should have failed")
+	}
+	if sc.IsValid() {
+		t.Fatal("connection should not be valid after the session is gone")
+	}
+	var _ driver.Validator = sc
+}
+
+func TestOnStatement(t *testing.T) {
+	var queryCount int
+	postQueryMock := func(_ context.Context, _ *snowflakeRestful, _ *url.Values,
+		_ map[string]string, _ []byte, _ time.Duration, _ UUID, _ *Config) (*execResponse, error) {
+		queryCount++
+		return &execResponse{
+			Data: execResponseData{
+				QueryID:           "query-" + strconv.Itoa(queryCount),
+				RowType:           []execResponseRowType{{Name: "C", Type: "fixed"}},
+				QueryResultFormat: "json",
+			},
+			Success: true,
+		}, nil
+	}
+	type statement struct {
+		query   string
+		queryID string
+	}
+	var statements []statement
+	sc := getPooledSnowflakeConn()
+	sc.cfg.OnStatement = func(_ context.Context, query string, queryID string) {
+		statements = append(statements, statement{query, queryID})
+	}
+	sc.rest.FuncPostQuery = postQueryMock
+	db := sql.OpenDB(showConnectorMock{sc: sc})
+	defer db.Close()
+
+	if _, err := db.ExecContext(context.Background(), "INSERT INTO t VALUES (?)", "secret"); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := db.QueryContext(context.Background(), "SELECT c FROM t WHERE c = ?", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows.Close()
+	expected := []statement{
+		{"INSERT INTO t VALUES (?)", "query-1"},
+		{"SELECT c FROM t WHERE c = ?", "query-2"},
+	}
+	if !reflect.DeepEqual(statements, expected) {
+		t.Fatalf("unexpected statements. expected: %v, got: %v", expected, statements)
+	}
+}
+
+func TestLastQueryID(t *testing.T) {
+	var queryCount int
+	postQueryMock := func(_ context.Context, _ *snowflakeRestful, _ *url.Values,
+		_ map[string]string, _ []byte, _ time.Duration, _ UUID, _ *Config) (*execResponse, error) {
+		queryCount++
+		resp := &execResponse{
+			Data: execResponseData{
+				QueryID:           "query-" + strconv.Itoa(queryCount),
+				RowType:           []execResponseRowType{{Name: "C", Type: "fixed"}},
+				QueryResultFormat: "json",
+			},
+			Success: true,
+		}
+		if queryCount == 3 {
+			resp.Success = false
+			resp.Code = "2003"
+			resp.Message = "table does not exist"
+		}
+		return resp, nil
+	}
+	sc := getPooledSnowflakeConn()
+	sc.rest.FuncPostQuery = postQueryMock
+	db := sql.OpenDB(showConnectorMock{sc: sc})
+	defer db.Close()
+
+	if sc.LastQueryID() != "" {
+		t.Fatalf("no query was executed yet, got: %v", sc.LastQueryID())
+	}
+	if _, err := db.ExecContext(context.Background(), "INSERT INTO t VALUES (1)"); err != nil {
+		t.Fatal(err)
+	}
+	if sc.LastQueryID() != "query-1" {
+		t.Fatalf("unexpected last query ID after the exec: %v", sc.LastQueryID())
+	}
+	rows, err := db.QueryContext(context.Background(), "SELECT c FROM t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows.Close()
+	if sc.LastQueryID() != "query-2" {
+		t.Fatalf("unexpected last query ID after the query: %v", sc.LastQueryID())
+	}
+	if _, err = db.ExecContext(context.Background(), "INSERT INTO missing VALUES (1)"); err == nil {
+		t.Fatal("should have failed")
+	}
+	if sc.LastQueryID() != "query-3" {
+		t.Fatalf("the query ID of a failed statement should be kept, got: %v", sc.LastQueryID())
+	}
+	var _ SnowflakeConnection = sc
+}
@@ -8,12 +8,16 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
 )
 
 func (sc *snowflakeConn) isClientSessionKeepAliveEnabled() bool {
+	if sc.cfg.ClientSessionKeepAlive != configBoolNotSet {
+		return sc.cfg.ClientSessionKeepAlive == ConfigBoolTrue
+	}
 	paramsMutex.Lock()
 	v, ok := sc.cfg.Params[sessionClientSessionKeepAlive]
 	paramsMutex.Unlock()
@@ -29,12 +33,37 @@ func (sc *snowflakeConn) startHeartBeat() {
 	}
 	if sc.rest != nil {
 		sc.rest.HeartBeat = &heartbeat{
-			restful: sc.rest,
+			restful:  sc.rest,
+			interval: sc.getHeartbeatInterval(),
 		}
 		sc.rest.HeartBeat.start()
 	}
 }
 
+// getHeartbeatInterval returns the heartbeat interval set by CLIENT_SESSION_KEEP_ALIVE_HEARTBEAT_FREQUENCY
+// in seconds. The value is kept between 15 minutes and an hour.
+func (sc *snowflakeConn) getHeartbeatInterval() time.Duration {
+	paramsMutex.Lock()
+	v, ok := sc.cfg.Params[sessionClientSessionKeepAliveFrequency]
+	paramsMutex.Unlock()
+	if !ok {
+		return heartBeatInterval
+	}
+	seconds, err := strconv.ParseInt(*v, 10, 64)
+	if err != nil {
+		logger.Warnf("invalid %v: %v. using %v", sessionClientSessionKeepAliveFrequency, *v, heartBeatInterval)
+		return heartBeatInterval
+	}
+	interval := time.Duration(seconds) * time.Second
+	if interval < minHeartBeatInterval {
+		return minHeartBeatInterval
+	}
+	if interval > heartBeatInterval {
+		return heartBeatInterval
+	}
+	return interval
+}
+
 func (sc *snowflakeConn) stopHeartBeat() {
 	if sc.cfg != nil && !sc.isClientSessionKeepAliveEnabled() {
 		return
@@ -44,10 +73,47 @@ func (sc *snowflakeConn) stopHeartBeat() {
 	}
 }
 
+// resultFormatOverride returns the result format requested by WithResultFormat if the connection supports it.
+// Otherwise a warning is logged and an empty string is returned, so that the format of the connection is used.
+func (sc *snowflakeConn) resultFormatOverride(ctx context.Context) string {
+	format, _ := ctx.Value(queryResultFormat).(string)
+	if format == "" {
+		return ""
+	}
+	format = strings.ToUpper(format)
+	if format != ResultFormatArrow && format != ResultFormatJSON {
+		logger.WithContext(ctx).Warnf("unsupported result format %v requested, using the format of the connection", format)
+		return ""
+	}
+	if format == ResultFormatArrow {
+		paramsMutex.Lock()
+		var connFormat string
+		for name, value := range sc.cfg.Params {
+			if strings.EqualFold(name, sessionGoQueryResultFormat) && value != nil {
+				connFormat = *value
+			}
+		}
+		paramsMutex.Unlock()
+		if strings.EqualFold(connFormat, ResultFormatJSON) {
+			logger.WithContext(ctx).Warnf("Arrow result format requested, but it is disabled by %v of the connection. using JSON",
+				strings.ToUpper(sessionGoQueryResultFormat))
+			return ""
+		}
+	}
+	return format
+}
+
 func (sc *snowflakeConn) getArrayBindStageThreshold() int {
 	paramsMutex.Lock()
 	v, ok := sc.cfg.Params[sessionArrayBindStageThreshold]
 	paramsMutex.Unlock()
+	if ok && *v == "0" {
+		// disabled by the server or after failing to create the stage
+		return 0
+	}
+	if sc.cfg.ArrayBindStageThreshold > 0 {
+		return sc.cfg.ArrayBindStageThreshold
+	}
 	if !ok {
 		return 0
 	}
@@ -86,20 +152,22 @@ func (sc *snowflakeConn) processFileTransfer(
 	isInternal bool) (
 	*execResponse, error) {
 	sfa := snowflakeFileTransferAgent{
+		ctx:     ctx,
 		sc:      sc,
 		data:    &data.Data,
 		command: query,
 		options: new(SnowflakeFileTransferOptions),
 	}
+	if op := getFileTransferOptions(ctx); op != nil {
+		sfa.options = op
+	}
 	if fs := getFileStream(ctx); fs != nil {
 		sfa.sourceStream = fs
 		if isInternal {
-			sfa.data.AutoCompress = false
+			// internal streams, e.g. array binds, are compressed only when requested
+			sfa.data.AutoCompress = sfa.options.compressSourceFromStream
 		}
 	}
-	if op := getFileTransferOptions(ctx); op != nil {
-		sfa.options = op
-	}
 	if sfa.options.MultiPartThreshold == 0 {
 		sfa.options.MultiPartThreshold = dataSizeThreshold
 	}
@@ -166,6 +234,22 @@ func (sc *snowflakeConn) populateSessionParameters(parameters []nameValueParamet
 	}
 }
 
+// populateSessionInfo stores the current database, schema, warehouse and role returned at login
+func (sc *snowflakeConn) populateSessionInfo(sessionInfo authResponseSessionInfo) {
+	if sessionInfo.DatabaseName != "" {
+		sc.cfg.Database = sessionInfo.DatabaseName
+	}
+	if sessionInfo.SchemaName != "" {
+		sc.cfg.Schema = sessionInfo.SchemaName
+	}
+	if sessionInfo.WarehouseName != "" {
+		sc.cfg.Warehouse = sessionInfo.WarehouseName
+	}
+	if sessionInfo.RoleName != "" {
+		sc.cfg.Role = sessionInfo.RoleName
+	}
+}
+
 func isAsyncMode(ctx context.Context) bool {
 	val := ctx.Value(asyncMode)
 	if val == nil {
@@ -197,6 +281,126 @@ func isDml(v int64) bool {
 	return statementTypeIDDml <= v && v <= statementTypeIDMultiTableInsert
 }
 
+// warehouseResumingErrorCode is the transient server error of a query submitted while its warehouse is being resumed.
+const warehouseResumingErrorCode = 630
+
+// transientQueryErrorCodes are server error numbers after which a read only query
+// can be submitted again without side effects.
+var transientQueryErrorCodes = map[int]bool{
+	warehouseResumingErrorCode: true, // transient error while the warehouse is being resumed
+}
+
+// transientQueryErrorRetryDelay is the pause before a read only query is submitted again.
+var transientQueryErrorRetryDelay = 1 * time.Second
+
+// maxWarehouseResumeRetryDelay caps the backoff between the submissions of a query while the warehouse resumes.
+const maxWarehouseResumeRetryDelay = 16 * time.Second
+
+var readOnlyQueryRegexp = regexp.MustCompile(`(?is)^\s*(?:/\*.*?\*/\s*|--[^\n]*\n\s*)*\(*\s*(?:select|with|show|describe|desc|explain)\b`)
+
+// isReadOnlyQuery returns true if the query is a SELECT-like statement.
+func isReadOnlyQuery(query string) bool {
+	return readOnlyQueryRegexp.MatchString(query)
+}
+
+// sessionLostErrorCodes are server error numbers after which the session cannot be used anymore.
+var sessionLostErrorCodes = map[int]bool{
+	ErrSessionGone: true,
+	390114:         true, // the master token expired, so the session cannot be renewed
+}
+
+// isSessionLost returns true if the query failed because its session is gone.
+func isSessionLost(data *execResponse, err error) bool {
+	if err != nil {
+		se, ok := err.(*SnowflakeError)
+		return ok && sessionLostErrorCodes[se.Number]
+	}
+	if data == nil || data.Success {
+		return false
+	}
+	code, err := strconv.Atoi(data.Code)
+	return err == nil && sessionLostErrorCodes[code]
+}
+
+// canReconnect returns true if the query may be submitted once more on a new session.
+// Only read only queries are retried, so that DML is never applied twice.
+func (sc *snowflakeConn) canReconnect(ctx context.Context, query string, isInternal bool, req *execRequest) bool {
+	return sc.cfg.AutoReconnect &&
+		isNonInteractiveLogin(sc.cfg) &&
+		!isInternal &&
+		req.BindStage == "" &&
+		ctx.Value(multiStatementCount) == nil &&
+		isReadOnlyQuery(query)
+}
+
+// isNonInteractiveLogin returns true if the driver can log in again in the middle of a query, i.e. without
+// prompting the user, e.g. in a browser or for a device code, and with credentials of its own.
+func isNonInteractiveLogin(cfg *Config) bool {
+	if cfg.SessionToken != "" {
+		// a pre-established session cannot be attached to again once it is gone
+		return false
+	}
+	switch cfg.Authenticator {
+	case AuthTypeSnowflake, AuthTypeOAuth, AuthTypeJwt, AuthTypeOkta:
+		return true
+	}
+	return false
+}
+
+// reconnect logs in again with the configuration of the connection, replacing its session. The state of the
+// lost session, e.g. set with USE or ALTER SESSION, is not restored. If the login fails, the connection is kept
+// without a session, so that IsValid reports false and database/sql discards it.
+func (sc *snowflakeConn) reconnect(ctx context.Context) error {
+	logger.WithContext(ctx).Warnln("session expired. logging in again, the state of the session is lost")
+	if sc.ctx == nil {
+		sc.ctx = context.Background()
+	}
+	// a failed login cleans up the connection
+	rest, cfg := sc.rest, sc.cfg
+	if err := authenticateWithConfig(sc); err != nil {
+		sc.rest, sc.cfg = rest, cfg
+		sc.rest.TokenAccessor.SetTokens("", "", -1)
+		return err
+	}
+	return nil
+}
+
+// isRetryableTransientQueryError returns true if the failed query may be submitted once more.
+// Multi statement queries are never retried as they may contain DML.
+func (sc *snowflakeConn) isRetryableTransientQueryError(ctx context.Context, query string, code int) bool {
+	return sc.cfg.RetryQueryOnTransientError &&
+		transientQueryErrorCodes[code] &&
+		ctx.Value(multiStatementCount) == nil &&
+		isReadOnlyQuery(query)
+}
+
+// warehouseResumeRetryDelay returns the pause before a read only query that failed while its warehouse
+// was being resumed is submitted again, and false once Config.WarehouseResumeTimeout is exceeded.
+// The pause doubles with each attempt.
+func (sc *snowflakeConn) warehouseResumeRetryDelay(ctx context.Context, query string, code int, attempt int, elapsed time.Duration) (time.Duration, bool) {
+	if sc.cfg.WarehouseResumeTimeout <= 0 ||
+		code != warehouseResumingErrorCode ||
+		ctx.Value(multiStatementCount) != nil ||
+		!isReadOnlyQuery(query) {
+		return 0, false
+	}
+	delay := transientQueryErrorRetryDelay
+	for i := 0; i < attempt && delay < maxWarehouseResumeRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxWarehouseResumeRetryDelay {
+		delay = maxWarehouseResumeRetryDelay
+	}
+	remaining := sc.cfg.WarehouseResumeTimeout - elapsed
+	if remaining <= 0 {
+		return 0, false
+	}
+	if delay > remaining {
+		delay = remaining
+	}
+	return delay, true
+}
+
 func updateRows(data execResponseData) (int64, error) {
 	var count int64
 	for i, n := 0, len(data.RowType); i < n; i++ {
@@ -213,7 +417,8 @@ func updateRows(data execResponseData) (int64, error) {
 // Note that the statement type code is also equivalent to type INSERT, so an
 // additional check of the name is required
 func isMultiStmt(data *execResponseData) bool {
-	var isMultistatementByReturningSelect = data.StatementTypeID == statementTypeIDSelect && data.RowType[0].Name == "multiple statement execution"
+	var isMultistatementByReturningSelect = data.StatementTypeID == statementTypeIDSelect &&
+		len(data.RowType) > 0 && data.RowType[0].Name == "multiple statement execution"
 	return isMultistatementByReturningSelect || data.StatementTypeID == statementTypeIDMultistatement
 }
 
@@ -247,7 +452,7 @@ func populateChunkDownloader(
 		// stream chunk downloading only works for row based data formats, i.e. json
 		fetcher := &httpStreamChunkFetcher{
 			ctx:      ctx,
-			client:   sc.rest.Client,
+			client:   sc.rest.getChunkClient(),
 			clientIP: sc.cfg.ClientIP,
 			headers:  data.ChunkHeaders,
 			qrmk:     data.Qrmk,
@@ -264,6 +469,8 @@ func populateChunkDownloader(
 		ChunkMetas:         data.Chunks,
 		Total:              data.Total,
 		TotalRowIndex:      int64(-1),
+		RowLimit:           getRowLimitPreview(ctx),
+		MaxWorkers:         getPrefetchThreads(ctx),
 		CellCount:          len(data.RowType),
 		Qrmk:               data.Qrmk,
 		QueryResultFormat:  data.QueryResultFormat,
@@ -276,6 +483,7 @@ func populateChunkDownloader(
 			JSON:         data.RowSet,
 			RowSetBase64: data.RowSetBase64,
 		},
+		dump: newChunkDump(ctx, data.QueryID),
 	}
 }
 
@@ -7,6 +7,7 @@ import (
 	"database/sql"
 	"database/sql/driver"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"math"
 	"math/big"
@@ -24,6 +25,10 @@ import (
 
 const format = "2006-01-02 15:04:05.999999999"
 
+// timestampTzFormat is used for TIMESTAMP_TZ values uploaded to a stage. The numeric
+// offset is included so that the server doesn't interpret the value in the session time zone.
+const timestampTzFormat = format + " -07:00"
+
 type timezoneType int
 
 const (
@@ -83,10 +88,18 @@ func goTypeToSnowflake(v driver.Value, tsmode snowflakeType) snowflakeType {
 		return changeType
 	case time.Time, sql.NullTime:
 		return tsmode
+	case TimeOfDay:
+		return timeType
 	}
 	if supportedArrayBind(&driver.NamedValue{Value: v}) {
 		return sliceType
 	}
+	if supportedObjectBind(&driver.NamedValue{Value: v}) {
+		return objectType
+	}
+	if supportedFileBind(&driver.NamedValue{Value: v}) {
+		return fileType
+	}
 	return unSupportedType
 }
 
@@ -100,7 +113,7 @@ func snowflakeTypeToGo(dbtype snowflakeType, scale int64) reflect.Type {
 		return reflect.TypeOf(float64(0))
 	case realType:
 		return reflect.TypeOf(float64(0))
-	case textType, variantType, objectType, arrayType:
+	case textType, variantType, objectType, arrayType, fileType:
 		return reflect.TypeOf("")
 	case dateType, timeType, timestampLtzType, timestampNtzType, timestampTzType:
 		return reflect.TypeOf(time.Now())
@@ -120,6 +133,15 @@ func valueToString(v driver.Value, tsmode snowflakeType) (*string, error) {
 	if v == nil {
 		return nil, nil
 	}
+	if schema := registeredObjectSchema(v); schema != nil {
+		return schema.toJSON(v)
+	}
+	if f, ok := fileBindValue(v); ok {
+		return fileToString(f)
+	}
+	if d, ok := v.(TimeOfDay); ok {
+		return durationToTimeString(time.Duration(d))
+	}
 	v1 := reflect.ValueOf(v)
 	switch v1.Kind() {
 	case reflect.Bool:
@@ -129,12 +151,27 @@ func valueToString(v driver.Value, tsmode snowflakeType) (*string, error) {
 		s := strconv.FormatInt(v1.Int(), 10)
 		return &s, nil
 	case reflect.Float64:
-		s := strconv.FormatFloat(v1.Float(), 'g', -1, 32)
+		s := strconv.FormatFloat(v1.Float(), 'g', -1, 64)
 		return &s, nil
 	case reflect.String:
 		s := v1.String()
 		return &s, nil
-	case reflect.Slice, reflect.Map:
+	case reflect.Map:
+		if v1.IsNil() {
+			return nil, nil
+		}
+		if v1.Type().Key().Kind() == reflect.String {
+			// OBJECT bind. nested maps, slices and structs are serialized as JSON
+			b, err := json.Marshal(v)
+			if err != nil {
+				return nil, err
+			}
+			s := string(b)
+			return &s, nil
+		}
+		s := v1.String()
+		return &s, nil
+	case reflect.Slice:
 		if v1.IsNil() {
 			return nil, nil
 		}
@@ -172,7 +209,7 @@ func valueToString(v driver.Value, tsmode snowflakeType) (*string, error) {
 			if !typedVal.Valid {
 				return nil, nil
 			}
-			s := strconv.FormatFloat(typedVal.Float64, 'g', -1, 32)
+			s := strconv.FormatFloat(typedVal.Float64, 'g', -1, 64)
 			return &s, nil
 		case sql.NullString:
 			if !typedVal.Valid {
@@ -562,7 +599,7 @@ func arrowToValue(
 			}
 		}
 		return err
-	case textType, arrayType, variantType, objectType:
+	case textType, arrayType, variantType, objectType, fileType:
 		strings := srcValue.(*array.String)
 		for i := range destcol {
 			if !srcValue.IsNull(i) {
@@ -753,14 +790,14 @@ func snowflakeArrayToString(nv *driver.NamedValue, stream bool) (snowflakeType,
 		t = realType
 		a := nv.Value.(*float64Array)
 		for _, x := range *a {
-			v := fmt.Sprintf("%g", x)
+			v := strconv.FormatFloat(x, 'g', -1, 64)
 			arr = append(arr, &v)
 		}
 	case reflect.TypeOf(&float32Array{}):
 		t = realType
 		a := nv.Value.(*float32Array)
 		for _, x := range *a {
-			v := fmt.Sprintf("%g", x)
+			v := strconv.FormatFloat(float64(x), 'g', -1, 32)
 			arr = append(arr, &v)
 		}
 	case reflect.TypeOf(&boolArray{}):
@@ -804,7 +841,7 @@ func snowflakeArrayToString(nv *driver.NamedValue, stream bool) (snowflakeType,
 		for _, x := range *a {
 			var v string
 			if stream {
-				v = x.Format(format)
+				v = x.Format(timestampTzFormat)
 			} else {
 				_, offset := x.Zone()
 				v = fmt.Sprintf("%v %v", x.UnixNano(), offset/60+1440)
@@ -881,12 +918,12 @@ func interfaceSliceToString(interfaceSlice reflect.Value, stream bool, tzType ..
 			case float32:
 				t = realType
 				x := val.Interface().(float32)
-				v := fmt.Sprintf("%g", x)
+				v := strconv.FormatFloat(float64(x), 'g', -1, 32)
 				arr = append(arr, &v)
 			case float64:
 				t = realType
 				x := val.Interface().(float64)
-				v := fmt.Sprintf("%g", x)
+				v := strconv.FormatFloat(x, 'g', -1, 64)
 				arr = append(arr, &v)
 			case bool:
 				t = booleanType
@@ -921,7 +958,7 @@ func interfaceSliceToString(interfaceSlice reflect.Value, stream bool, tzType ..
 					t = timestampTzType
 					var v string
 					if stream {
-						v = x.Format(format)
+						v = x.Format(timestampTzFormat)
 					} else {
 						_, offset := x.Zone()
 						v = fmt.Sprintf("%v %v", x.UnixNano(), offset/60+1440)
@@ -1126,6 +1163,42 @@ func recordToSchema(sc *arrow.Schema, rowType []execResponseRowType, loc *time.L
 	return arrow.NewSchema(fields, &meta), nil
 }
 
+// rowTypeToArrowSchema returns the schema of the converted Arrow batches of a result with the given
+// row type, for a result without any batch to take the schema from. The integer types sent by the server
+// are not known then, so NUMBER columns are INT64, or FLOAT64 if they have a scale.
+func rowTypeToArrowSchema(rowType []execResponseRowType, loc *time.Location) *arrow.Schema {
+	fields := make([]arrow.Field, len(rowType))
+	for i, column := range rowType {
+		var t arrow.DataType
+		switch getSnowflakeType(column.Type) {
+		case fixedType:
+			if column.Scale == 0 {
+				t = arrow.PrimitiveTypes.Int64
+			} else {
+				t = arrow.PrimitiveTypes.Float64
+			}
+		case realType:
+			t = arrow.PrimitiveTypes.Float64
+		case booleanType:
+			t = arrow.FixedWidthTypes.Boolean
+		case dateType:
+			t = arrow.FixedWidthTypes.Date32
+		case timeType:
+			t = &arrow.Time64Type{Unit: arrow.Nanosecond}
+		case timestampNtzType, timestampTzType:
+			t = &arrow.TimestampType{Unit: arrow.Nanosecond}
+		case timestampLtzType:
+			t = &arrow.TimestampType{Unit: arrow.Nanosecond, TimeZone: loc.String()}
+		case binaryType:
+			t = arrow.BinaryTypes.Binary
+		default:
+			t = arrow.BinaryTypes.String
+		}
+		fields[i] = arrow.Field{Name: column.Name, Type: t, Nullable: column.Nullable}
+	}
+	return arrow.NewSchema(fields, nil)
+}
+
 // TypedNullTime is required to properly bind the null value with the snowflakeType as the Snowflake functions
 // require the type of the field to be provided explicitly for the null values
 type TypedNullTime struct {
@@ -117,7 +117,10 @@ func TestGoTypeToSnowflake(t *testing.T) {
 		{in: Array([]interface{}{time.Now()}, TimestampTZType), tmode: timeType, out: sliceType},
 		{in: Array([]interface{}{time.Now()}, DateType), tmode: timestampNtzType, out: sliceType},
 		{in: Array([]interface{}{time.Now()}, TimeType), tmode: timestampTzType, out: sliceType},
+		{in: map[string]interface{}{"a": 1}, tmode: nullType, out: objectType},
+		{in: map[string]string{"a": "b"}, tmode: nullType, out: objectType},
 		// negative
+		{in: map[int]string{1: "b"}, tmode: nullType, out: unSupportedType},
 		{in: 123, tmode: nullType, out: unSupportedType},
 		{in: int8(12), tmode: nullType, out: unSupportedType},
 		{in: int32(456), tmode: nullType, out: unSupportedType},
@@ -238,6 +241,41 @@ func TestValueToString(t *testing.T) {
 	}
 }
 
+func TestValueToStringFloatRoundTrip(t *testing.T) {
+	for _, value := range []float64{0.1, 1e300, 1.7976931348623157e308, 5e-324, 123456789.12345678, -0.3} {
+		for _, v := range []driver.Value{value, sql.NullFloat64{Float64: value, Valid: true}} {
+			s, err := valueToString(v, nullType)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if f, err := strconv.ParseFloat(*s, 64); err != nil || f != value {
+				t.Fatalf("%v did not round-trip, got: %v", value, *s)
+			}
+		}
+	}
+	_, arr := snowflakeArrayToString(&driver.NamedValue{Value: Array(&[]float32{0.1, 3.4028235e38})}, false)
+	if *arr[0] != "0.1" || *arr[1] != "3.4028235e+38" {
+		t.Fatalf("unexpected float32 array binds: %v, %v", *arr[0], *arr[1])
+	}
+}
+
+func TestValueToStringObject(t *testing.T) {
+	v := map[string]interface{}{
+		"a": int64(1),
+		"b": map[string]interface{}{"c": "d", "e": []interface{}{1, "f", nil}},
+	}
+	expected := `{"a":1,"b":{"c":"d","e":[1,"f",null]}}`
+	if s, err := valueToString(v, nullType); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if s == nil || *s != expected {
+		t.Fatalf("expected '%v', got %v", expected, s)
+	}
+	var nilMap map[string]interface{}
+	if s, err := valueToString(nilMap, nullType); err != nil || s != nil {
+		t.Fatalf("nil map should be bound as NULL. got: %v, err: %v", s, err)
+	}
+}
+
 func TestExtractTimestamp(t *testing.T) {
 	s := "1234abcdef" // pragma: allowlist secret
 	_, _, err := extractTimestamp(&s)
@@ -1772,3 +1810,62 @@ func TestTimeTypeValueToString(t *testing.T) {
 		})
 	}
 }
+
+func TestTimestampTzBindKeepsLocation(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testcases := []struct {
+		in        time.Time
+		out       string
+		streamOut string
+	}{
+		{
+			in:        time.Date(2020, 1, 2, 10, 11, 12, 0, newYork),
+			out:       "1577977872000000000 1140",
+			streamOut: "2020-01-02 10:11:12 -05:00",
+		},
+		{
+			// daylight saving time
+			in:        time.Date(2020, 7, 2, 10, 11, 12, 123000000, newYork),
+			out:       "1593699072123000000 1200",
+			streamOut: "2020-07-02 10:11:12.123 -04:00",
+		},
+		{
+			in:        time.Date(2020, 1, 2, 10, 11, 12, 0, tokyo),
+			out:       "1577927472000000000 1980",
+			streamOut: "2020-01-02 10:11:12 +09:00",
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.streamOut, func(t *testing.T) {
+			output, err := timeTypeValueToString(tc.in, timestampTzType)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *output != tc.out {
+				t.Errorf("unexpected bind value. expected: %v, got: %v", tc.out, *output)
+			}
+
+			for _, stream := range []bool{false, true} {
+				expected := tc.out
+				if stream {
+					expected = tc.streamOut
+				}
+				typ, values := snowflakeArrayToString(&driver.NamedValue{Value: Array([]time.Time{tc.in}, TimestampTZType)}, stream)
+				if typ != timestampTzType || len(values) != 1 || *values[0] != expected {
+					t.Errorf("unexpected array bind value. stream: %v, expected: %v, got: %v", stream, expected, values)
+				}
+				typ, values = snowflakeArrayToString(&driver.NamedValue{Value: Array([]interface{}{tc.in}, TimestampTZType)}, stream)
+				if typ != timestampTzType || len(values) != 1 || *values[0] != expected {
+					t.Errorf("unexpected interface array bind value. stream: %v, expected: %v, got: %v", stream, expected, values)
+				}
+			}
+		})
+	}
+}
@@ -26,6 +26,7 @@ const (
 	binaryType
 	timeType
 	booleanType
+	fileType
 	// the following are not snowflake types per se but internal types
 	nullType
 	sliceType
@@ -47,6 +48,7 @@ var snowflakeToDriverType = map[string]snowflakeType{
 	"BINARY":        binaryType,
 	"TIME":          timeType,
 	"BOOLEAN":       booleanType,
+	"FILE":          fileType,
 	"NULL":          nullType,
 	"SLICE":         sliceType,
 	"CHANGE_TYPE":   changeType,
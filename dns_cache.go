@@ -0,0 +1,104 @@
+// Copyright (c) 2023 Snowflake Computing Inc. All rights reserved.
+
+package gosnowflake
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type dnsCacheEntry struct {
+	addrs     []string
+	expiresAt time.Time
+}
+
+// dnsCache caches the resolved addresses of the hosts the driver connects to.
+// It is shared by all connections so that connection churn doesn't hit the resolver.
+type dnsCache struct {
+	mu         sync.Mutex
+	entries    map[string]dnsCacheEntry
+	funcLookup func(ctx context.Context, host string) ([]string, error)
+	funcNow    func() time.Time
+}
+
+var defaultDNSCache = newDNSCache()
+
+type dnsCacheTransportKey struct {
+	ttl time.Duration
+}
+
+func newDNSCache() *dnsCache {
+	return &dnsCache{
+		entries:    make(map[string]dnsCacheEntry),
+		funcLookup: net.DefaultResolver.LookupHost,
+		funcNow:    time.Now,
+	}
+}
+
+func (dc *dnsCache) lookup(ctx context.Context, host string, ttl time.Duration) ([]string, error) {
+	dc.mu.Lock()
+	entry, ok := dc.entries[host]
+	dc.mu.Unlock()
+	if ok && dc.funcNow().Before(entry.expiresAt) {
+		logger.Debugf("using cached addresses for host %v: %v", host, entry.addrs)
+		return entry.addrs, nil
+	}
+	addrs, err := dc.funcLookup(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	dc.mu.Lock()
+	dc.entries[host] = dnsCacheEntry{addrs: addrs, expiresAt: dc.funcNow().Add(ttl)}
+	dc.mu.Unlock()
+	return addrs, nil
+}
+
+func (dc *dnsCache) invalidate(host string) {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	delete(dc.entries, host)
+}
+
+// dialContext returns a dial function resolving host names with the cache before dialing with funcDial.
+// If none of the cached addresses can be dialed, the host is removed from the cache so that
+// the next dial resolves it again, e.g. after a failover.
+func (dc *dnsCache) dialContext(
+	funcDial func(ctx context.Context, network, addr string) (net.Conn, error),
+	ttl time.Duration) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil || net.ParseIP(host) != nil {
+			return funcDial(ctx, network, addr)
+		}
+		addrs, err := dc.lookup(ctx, host, ttl)
+		if err != nil {
+			return nil, err
+		}
+		for _, ip := range addrs {
+			var conn net.Conn
+			if conn, err = funcDial(ctx, network, net.JoinHostPort(ip, port)); err == nil {
+				return conn, nil
+			}
+			logger.Debugf("failed to dial %v for host %v: %v", ip, host, err)
+		}
+		dc.invalidate(host)
+		if err == nil {
+			err = &net.DNSError{Err: "no addresses found", Name: host, IsNotFound: true}
+		}
+		return nil, err
+	}
+}
+
+// transportWithDNSCache returns a copy of the transport resolving the host names with the default DNS cache.
+func transportWithDNSCache(base *http.Transport, ttl time.Duration) *http.Transport {
+	return cachedTransport(base, dnsCacheTransportKey{ttl: ttl}, func(t *http.Transport) {
+		funcDial := base.DialContext
+		if funcDial == nil {
+			funcDial = (&net.Dialer{}).DialContext
+		}
+		t.DialContext = defaultDNSCache.dialContext(funcDial, ttl)
+	})
+}
@@ -0,0 +1,135 @@
+// Copyright (c) 2023 Snowflake Computing Inc. All rights reserved.
+
+package gosnowflake
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+type dnsCacheTestDialer struct {
+	dialed []string
+	fail   bool
+}
+
+func (d *dnsCacheTestDialer) dial(_ context.Context, _, addr string) (net.Conn, error) {
+	d.dialed = append(d.dialed, addr)
+	if d.fail {
+		return nil, errors.New("connection refused")
+	}
+	client, server := net.Pipe()
+	server.Close()
+	return client, nil
+}
+
+func newTestDNSCache(lookups *int, addrs ...string) *dnsCache {
+	dc := newDNSCache()
+	dc.funcLookup = func(_ context.Context, host string) ([]string, error) {
+		*lookups++
+		return addrs, nil
+	}
+	return dc
+}
+
+func TestDNSCacheReusesAddressWithinTTL(t *testing.T) {
+	var lookups int
+	dc := newTestDNSCache(&lookups, "10.0.0.1")
+	now := time.Now()
+	dc.funcNow = func() time.Time { return now }
+	dialer := &dnsCacheTestDialer{}
+	dial := dc.dialContext(dialer.dial, time.Minute)
+
+	for i := 0; i < 2; i++ {
+		conn, err := dial(context.Background(), "tcp", "account.snowflakecomputing.com:443")
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn.Close()
+	}
+	if lookups != 1 {
+		t.Fatalf("expected a single lookup, got: %v", lookups)
+	}
+	if len(dialer.dialed) != 2 || dialer.dialed[0] != "10.0.0.1:443" || dialer.dialed[1] != "10.0.0.1:443" {
+		t.Fatalf("unexpected dialed addresses: %v", dialer.dialed)
+	}
+
+	now = now.Add(2 * time.Minute)
+	conn, err := dial(context.Background(), "tcp", "account.snowflakecomputing.com:443")
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+	if lookups != 2 {
+		t.Fatalf("expected the host to be resolved again after the TTL, lookups: %v", lookups)
+	}
+}
+
+func TestDNSCacheInvalidatedOnDialFailure(t *testing.T) {
+	var lookups int
+	dc := newTestDNSCache(&lookups, "10.0.0.1", "10.0.0.2")
+	dialer := &dnsCacheTestDialer{fail: true}
+	dial := dc.dialContext(dialer.dial, time.Hour)
+
+	if _, err := dial(context.Background(), "tcp", "account.snowflakecomputing.com:443"); err == nil {
+		t.Fatal("should have failed to dial")
+	}
+	if len(dialer.dialed) != 2 {
+		t.Fatalf("expected all the addresses to be dialed, got: %v", dialer.dialed)
+	}
+	dialer.fail = false
+	conn, err := dial(context.Background(), "tcp", "account.snowflakecomputing.com:443")
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+	if lookups != 2 {
+		t.Fatalf("expected the host to be resolved again after a dial failure, lookups: %v", lookups)
+	}
+}
+
+func TestDNSCacheSkipsIPAddresses(t *testing.T) {
+	var lookups int
+	dc := newTestDNSCache(&lookups, "10.0.0.1")
+	dialer := &dnsCacheTestDialer{}
+	conn, err := dc.dialContext(dialer.dial, time.Hour)(context.Background(), "tcp", "127.0.0.1:443")
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+	if lookups != 0 || dialer.dialed[0] != "127.0.0.1:443" {
+		t.Fatalf("unexpected lookups: %v, dialed: %v", lookups, dialer.dialed)
+	}
+}
+
+func TestBuildSnowflakeConnWithDNSCache(t *testing.T) {
+	sc, err := buildSnowflakeConn(context.Background(), Config{
+		Account:     "a",
+		Host:        "a.snowflakecomputing.com",
+		DNSCacheTTL: time.Minute,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	transport, ok := sc.rest.Client.Transport.(*http.Transport)
+	if !ok || transport == SnowflakeTransport {
+		t.Fatalf("expected a transport with the DNS cache, got: %v", sc.rest.Client.Transport)
+	}
+	if transport.TLSClientConfig.VerifyPeerCertificate == nil {
+		t.Fatal("OCSP check should be kept")
+	}
+	other, err := buildSnowflakeConn(context.Background(), Config{
+		Account:     "a",
+		Host:        "a.snowflakecomputing.com",
+		DNSCacheTTL: time.Minute,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other.rest.Client.Transport != transport {
+		t.Fatal("connections with the same settings should share the transport")
+	}
+}
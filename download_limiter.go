@@ -0,0 +1,59 @@
+// Copyright (c) 2023 Snowflake Computing Inc. All rights reserved.
+
+package gosnowflake
+
+import (
+	"errors"
+	"io"
+	"sync/atomic"
+)
+
+// downloadLimiter counts the bytes a connection downloaded from the cloud storage, i.e. the result
+// chunks and the files fetched by GET, and enforces Config.MaxDownloadBytes.
+type downloadLimiter struct {
+	max   int64 // zero disables the limit
+	count int64 // accessed atomically
+}
+
+func newDownloadLimiter(maxBytes int64) *downloadLimiter {
+	return &downloadLimiter{max: maxBytes}
+}
+
+// add counts n more bytes and fails once the total is over the limit.
+func (l *downloadLimiter) add(n int64) error {
+	if l == nil {
+		return nil
+	}
+	total := atomic.AddInt64(&l.count, n)
+	if l.max > 0 && total > l.max {
+		return errMaxDownloadBytesExceeded(total, l.max)
+	}
+	return nil
+}
+
+func (l *downloadLimiter) downloaded() int64 {
+	if l == nil {
+		return 0
+	}
+	return atomic.LoadInt64(&l.count)
+}
+
+// limitedReader counts the bytes read from a download and fails the read exceeding the limit,
+// so that a large result is aborted while it is streamed rather than once it is fully downloaded.
+type limitedReader struct {
+	reader  io.Reader
+	limiter *downloadLimiter
+}
+
+func (r *limitedReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if limitErr := r.limiter.add(int64(n)); limitErr != nil {
+		return n, limitErr
+	}
+	return n, err
+}
+
+func isMaxDownloadBytesExceeded(err error) bool {
+	var se *SnowflakeError
+	return errors.As(err, &se) && se.Number == ErrMaxDownloadBytesExceeded
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2023 Snowflake Computing Inc. All rights reserved.
+
+package gosnowflake
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMaxDownloadBytesAbortsChunkDownload(t *testing.T) {
+	chunk := []byte(strings.Repeat(`["1","abcdefghij"],`, 10) + `["1","abcdefghij"]`)
+	metas := make([]execResponseChunk, 4)
+	for i := range metas {
+		metas[i] = execResponseChunk{URL: fmt.Sprintf("https://s3/chunk%v", i), RowCount: 11}
+	}
+	sc := getDefaultSnowflakeConn()
+	sc.cfg.MaxDownloadBytes = int64(len(chunk)) + 10
+	sc.downloads = newDownloadLimiter(sc.cfg.MaxDownloadBytes)
+	var requests int
+	var mutex sync.Mutex
+	scd := &snowflakeChunkDownloader{
+		sc:                 sc,
+		ctx:                context.Background(),
+		Total:              int64(11 * len(metas)),
+		TotalRowIndex:      int64(-1),
+		CellCount:          2,
+		ChunkMetas:         metas,
+		ChunksMutex:        &sync.Mutex{},
+		QueryResultFormat:  string(jsonFormat),
+		RowSet:             rowSetType{RowType: []execResponseRowType{{Name: "ID", Type: "fixed"}, {Name: "V", Type: "text"}}},
+		MaxWorkers:         1,
+		FuncDownload:       downloadChunk,
+		FuncDownloadHelper: downloadChunkHelper,
+		FuncGet: func(_ context.Context, _ *snowflakeConn, _ string, _ map[string]string, _ time.Duration) (*http.Response, error) {
+			mutex.Lock()
+			requests++
+			mutex.Unlock()
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(chunk))}, nil
+		},
+	}
+	rows := &snowflakeRows{sc: sc, queryID: "qid"}
+	rows.addDownloader(scd)
+	if err := scd.start(); err != nil {
+		t.Fatal(err)
+	}
+
+	var err error
+	dest := make([]driver.Value, 2)
+	read := 0
+	for err == nil {
+		if err = rows.Next(dest); err == nil {
+			read++
+		}
+	}
+	if !isMaxDownloadBytesExceeded(err) {
+		t.Fatalf("the download should be aborted, err: %v", err)
+	}
+	if read != 11 {
+		t.Fatalf("only the rows of the first chunk should be read, got: %v", read)
+	}
+	if downloaded := sc.BytesDownloaded(); downloaded <= sc.cfg.MaxDownloadBytes || downloaded > 2*int64(len(chunk)) {
+		t.Fatalf("unexpected number of bytes downloaded: %v", downloaded)
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+	if requests != 2 {
+		t.Fatalf("the chunk over the limit should not be retried nor the next chunks downloaded, got %v requests", requests)
+	}
+}
+
+func TestDownloadLimiterWithoutLimit(t *testing.T) {
+	limiter := newDownloadLimiter(0)
+	for i := 0; i < 3; i++ {
+		if err := limiter.add(1 << 40); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if limiter.downloaded() != 3<<40 {
+		t.Fatalf("unexpected number of bytes downloaded: %v", limiter.downloaded())
+	}
+	var nilLimiter *downloadLimiter
+	if err := nilLimiter.add(1); err != nil || nilLimiter.downloaded() != 0 {
+		t.Fatalf("a nil limiter should not count, err: %v", err)
+	}
+}
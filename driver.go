@@ -43,6 +43,7 @@ func (d SnowflakeDriver) OpenWithConfig(ctx context.Context, config Config) (dri
 	if err = authenticateWithConfig(sc); err != nil {
 		return nil, err
 	}
+	sc.connectTimer.report(config.OnConnectTiming)
 	sc.connectionTelemetry(&config)
 
 	sc.startHeartBeat()
@@ -497,6 +497,47 @@ func TestParseDSN(t *testing.T) {
 			ocspMode: ocspModeFailOpen,
 			err:      nil,
 		},
+		{
+			dsn: fmt.Sprintf("u:p@a.snowflakecomputing.com:443?account=a&authenticator=%v", url.QueryEscape("https://company.okta.com")),
+			config: &Config{
+				Account: "a", User: "u", Password: "p",
+				Authenticator: AuthTypeOkta,
+				OktaURL: &url.URL{
+					Scheme: "https",
+					Host:   "company.okta.com",
+				},
+				Protocol: "https", Host: "a.snowflakecomputing.com", Port: 443,
+				OCSPFailOpen:              OCSPFailOpenTrue,
+				ValidateDefaultParameters: ConfigBoolTrue,
+				ClientTimeout:             defaultClientTimeout,
+				JWTClientTimeout:          defaultJWTClientTimeout,
+				ExternalBrowserTimeout:    defaultExternalBrowserTimeout,
+				IncludeRetryReason:        ConfigBoolTrue,
+			},
+			ocspMode: ocspModeFailOpen,
+			err:      nil,
+		},
+		{
+			dsn: fmt.Sprintf("u:p@a.snowflakecomputing.com:443?account=a&authenticator=%v", url.QueryEscape("https://Company.Okta.com/app/Snowflake/exk1AbC/sso/saml")),
+			config: &Config{
+				Account: "a", User: "u", Password: "p",
+				Authenticator: AuthTypeOkta,
+				OktaURL: &url.URL{
+					Scheme: "https",
+					Host:   "company.okta.com",
+					Path:   "/app/Snowflake/exk1AbC/sso/saml",
+				},
+				Protocol: "https", Host: "a.snowflakecomputing.com", Port: 443,
+				OCSPFailOpen:              OCSPFailOpenTrue,
+				ValidateDefaultParameters: ConfigBoolTrue,
+				ClientTimeout:             defaultClientTimeout,
+				JWTClientTimeout:          defaultJWTClientTimeout,
+				ExternalBrowserTimeout:    defaultExternalBrowserTimeout,
+				IncludeRetryReason:        ConfigBoolTrue,
+			},
+			ocspMode: ocspModeFailOpen,
+			err:      nil,
+		},
 		{
 			dsn: fmt.Sprintf("u:p@a.snowflake.local:9876?account=a&protocol=http&authenticator=SNOWFLAKE_JWT&privateKey=%v", privKeyPKCS1),
 			config: &Config{
@@ -543,6 +584,21 @@ func TestParseDSN(t *testing.T) {
 			ocspMode: ocspModeFailClosed,
 			err:      nil,
 		},
+		{
+			dsn: "user:pass@account/db/s?disableOCSPChecks=true",
+			config: &Config{
+				Account: "account", User: "user", Password: "pass",
+				Protocol: "https", Host: "account.snowflakecomputing.com", Port: 443,
+				Database: "db", Schema: "s", OCSPFailOpen: OCSPFailOpenTrue, DisableOCSPChecks: true,
+				ValidateDefaultParameters: ConfigBoolTrue,
+				ClientTimeout:             defaultClientTimeout,
+				JWTClientTimeout:          defaultJWTClientTimeout,
+				ExternalBrowserTimeout:    defaultExternalBrowserTimeout,
+				IncludeRetryReason:        ConfigBoolTrue,
+			},
+			ocspMode: ocspModeInsecure,
+			err:      nil,
+		},
 		{
 			dsn: "user:pass@account/db/s?insecureMode=true&ocspFailOpen=false",
 			config: &Config{
@@ -645,6 +701,187 @@ func TestParseDSN(t *testing.T) {
 			ocspMode: ocspModeFailOpen,
 			err:      nil,
 		},
+		{
+			dsn: "u:p@a.r.c.snowflakecomputing.com/db/s?account=a.r.c&retryQueryOnTransientError=true",
+			config: &Config{
+				Account: "a", User: "u", Password: "p",
+				Protocol: "https", Host: "a.r.c.snowflakecomputing.com", Port: 443,
+				Database: "db", Schema: "s", ValidateDefaultParameters: ConfigBoolTrue, OCSPFailOpen: OCSPFailOpenTrue,
+				ClientTimeout:              defaultClientTimeout,
+				JWTClientTimeout:           defaultJWTClientTimeout,
+				ExternalBrowserTimeout:     defaultExternalBrowserTimeout,
+				IncludeRetryReason:         ConfigBoolTrue,
+				RetryQueryOnTransientError: true,
+			},
+			ocspMode: ocspModeFailOpen,
+			err:      nil,
+		},
+		{
+			dsn: "u:p@a.r.c.snowflakecomputing.com/db/s?account=a.r.c&autoReconnect=true",
+			config: &Config{
+				Account: "a", User: "u", Password: "p",
+				Protocol: "https", Host: "a.r.c.snowflakecomputing.com", Port: 443,
+				Database: "db", Schema: "s", ValidateDefaultParameters: ConfigBoolTrue, OCSPFailOpen: OCSPFailOpenTrue,
+				ClientTimeout:          defaultClientTimeout,
+				JWTClientTimeout:       defaultJWTClientTimeout,
+				ExternalBrowserTimeout: defaultExternalBrowserTimeout,
+				IncludeRetryReason:     ConfigBoolTrue,
+				AutoReconnect:          true,
+			},
+			ocspMode: ocspModeFailOpen,
+			err:      nil,
+		},
+		{
+			dsn: "u:p@a.r.c.snowflakecomputing.com/db/s?account=a.r.c&retryJitterSeed=-42",
+			config: &Config{
+				Account: "a", User: "u", Password: "p",
+				Protocol: "https", Host: "a.r.c.snowflakecomputing.com", Port: 443,
+				Database: "db", Schema: "s", ValidateDefaultParameters: ConfigBoolTrue, OCSPFailOpen: OCSPFailOpenTrue,
+				ClientTimeout:          defaultClientTimeout,
+				JWTClientTimeout:       defaultJWTClientTimeout,
+				ExternalBrowserTimeout: defaultExternalBrowserTimeout,
+				IncludeRetryReason:     ConfigBoolTrue,
+				RetryJitterSeed:        -42,
+			},
+			ocspMode: ocspModeFailOpen,
+			err:      nil,
+		},
+		{
+			dsn: "u:p@a.r.c.snowflakecomputing.com/db/s?account=a.r.c&retryableHttpStatuses=429,503",
+			config: &Config{
+				Account: "a", User: "u", Password: "p",
+				Protocol: "https", Host: "a.r.c.snowflakecomputing.com", Port: 443,
+				Database: "db", Schema: "s", ValidateDefaultParameters: ConfigBoolTrue, OCSPFailOpen: OCSPFailOpenTrue,
+				ClientTimeout:          defaultClientTimeout,
+				JWTClientTimeout:       defaultJWTClientTimeout,
+				ExternalBrowserTimeout: defaultExternalBrowserTimeout,
+				IncludeRetryReason:     ConfigBoolTrue,
+				RetryableHTTPStatuses:  []int{429, 503},
+			},
+			ocspMode: ocspModeFailOpen,
+			err:      nil,
+		},
+		{
+			dsn: "u:p@a.r.c.snowflakecomputing.com/db/s?account=a.r.c&maxDownloadBytes=1073741824",
+			config: &Config{
+				Account: "a", User: "u", Password: "p",
+				Protocol: "https", Host: "a.r.c.snowflakecomputing.com", Port: 443,
+				Database: "db", Schema: "s", ValidateDefaultParameters: ConfigBoolTrue, OCSPFailOpen: OCSPFailOpenTrue,
+				ClientTimeout:          defaultClientTimeout,
+				JWTClientTimeout:       defaultJWTClientTimeout,
+				ExternalBrowserTimeout: defaultExternalBrowserTimeout,
+				IncludeRetryReason:     ConfigBoolTrue,
+				MaxDownloadBytes:       1 << 30,
+			},
+			ocspMode: ocspModeFailOpen,
+			err:      nil,
+		},
+		{
+			dsn: "u:p@a.r.c.snowflakecomputing.com/db/s?account=a.r.c&resultChunkSize=48",
+			config: &Config{
+				Account: "a", User: "u", Password: "p",
+				Protocol: "https", Host: "a.r.c.snowflakecomputing.com", Port: 443,
+				Database: "db", Schema: "s", ValidateDefaultParameters: ConfigBoolTrue, OCSPFailOpen: OCSPFailOpenTrue,
+				ClientTimeout:          defaultClientTimeout,
+				JWTClientTimeout:       defaultJWTClientTimeout,
+				ExternalBrowserTimeout: defaultExternalBrowserTimeout,
+				IncludeRetryReason:     ConfigBoolTrue,
+				ResultChunkSize:        48,
+			},
+			ocspMode: ocspModeFailOpen,
+			err:      nil,
+		},
+		{
+			dsn: "u:p@a.r.c.snowflakecomputing.com/db/s?account=a.r.c&requestIdPrefix=4bf92f3577b3",
+			config: &Config{
+				Account: "a", User: "u", Password: "p",
+				Protocol: "https", Host: "a.r.c.snowflakecomputing.com", Port: 443,
+				Database: "db", Schema: "s", ValidateDefaultParameters: ConfigBoolTrue, OCSPFailOpen: OCSPFailOpenTrue,
+				ClientTimeout:          defaultClientTimeout,
+				JWTClientTimeout:       defaultJWTClientTimeout,
+				ExternalBrowserTimeout: defaultExternalBrowserTimeout,
+				IncludeRetryReason:     ConfigBoolTrue,
+				RequestIDPrefix:        "4bf92f3577b3",
+			},
+			ocspMode: ocspModeFailOpen,
+			err:      nil,
+		},
+		{
+			dsn: "u:p@a.r.c.snowflakecomputing.com/db/s?account=a.r.c&disablePrepareCache=true",
+			config: &Config{
+				Account: "a", User: "u", Password: "p",
+				Protocol: "https", Host: "a.r.c.snowflakecomputing.com", Port: 443,
+				Database: "db", Schema: "s", ValidateDefaultParameters: ConfigBoolTrue, OCSPFailOpen: OCSPFailOpenTrue,
+				ClientTimeout:          defaultClientTimeout,
+				JWTClientTimeout:       defaultJWTClientTimeout,
+				ExternalBrowserTimeout: defaultExternalBrowserTimeout,
+				IncludeRetryReason:     ConfigBoolTrue,
+				DisablePrepareCache:    true,
+			},
+			ocspMode: ocspModeFailOpen,
+			err:      nil,
+		},
+		{
+			dsn: "u:p@a.r.c.snowflakecomputing.com/db/s?account=a.r.c&tempObjectLocation=SCRATCH_DB.TMP",
+			config: &Config{
+				Account: "a", User: "u", Password: "p",
+				Protocol: "https", Host: "a.r.c.snowflakecomputing.com", Port: 443,
+				Database: "db", Schema: "s", ValidateDefaultParameters: ConfigBoolTrue, OCSPFailOpen: OCSPFailOpenTrue,
+				ClientTimeout:          defaultClientTimeout,
+				JWTClientTimeout:       defaultJWTClientTimeout,
+				ExternalBrowserTimeout: defaultExternalBrowserTimeout,
+				IncludeRetryReason:     ConfigBoolTrue,
+				TempObjectLocation:     "SCRATCH_DB.TMP",
+			},
+			ocspMode: ocspModeFailOpen,
+			err:      nil,
+		},
+		{
+			dsn: "u:p@a.r.c.snowflakecomputing.com/db/s?account=a.r.c&warehouseResumeTimeout=120",
+			config: &Config{
+				Account: "a", User: "u", Password: "p",
+				Protocol: "https", Host: "a.r.c.snowflakecomputing.com", Port: 443,
+				Database: "db", Schema: "s", ValidateDefaultParameters: ConfigBoolTrue, OCSPFailOpen: OCSPFailOpenTrue,
+				ClientTimeout:          defaultClientTimeout,
+				JWTClientTimeout:       defaultJWTClientTimeout,
+				ExternalBrowserTimeout: defaultExternalBrowserTimeout,
+				IncludeRetryReason:     ConfigBoolTrue,
+				WarehouseResumeTimeout: 2 * time.Minute,
+			},
+			ocspMode: ocspModeFailOpen,
+			err:      nil,
+		},
+		{
+			dsn: "u:p@a.r.c.snowflakecomputing.com/db/s?account=a.r.c&connectTimeout=5&responseHeaderTimeout=30",
+			config: &Config{
+				Account: "a", User: "u", Password: "p",
+				Protocol: "https", Host: "a.r.c.snowflakecomputing.com", Port: 443,
+				Database: "db", Schema: "s", ValidateDefaultParameters: ConfigBoolTrue, OCSPFailOpen: OCSPFailOpenTrue,
+				ClientTimeout:          defaultClientTimeout,
+				JWTClientTimeout:       defaultJWTClientTimeout,
+				ExternalBrowserTimeout: defaultExternalBrowserTimeout,
+				IncludeRetryReason:     ConfigBoolTrue,
+				ConnectTimeout:         5 * time.Second,
+				ResponseHeaderTimeout:  30 * time.Second,
+			},
+			ocspMode: ocspModeFailOpen,
+			err:      nil,
+		},
+		{
+			dsn: "u:p@a.r.c.snowflakecomputing.com/db/s?account=a.r.c&maxRequestsPerSecond=2.5",
+			config: &Config{
+				Account: "a", User: "u", Password: "p",
+				Protocol: "https", Host: "a.r.c.snowflakecomputing.com", Port: 443,
+				Database: "db", Schema: "s", ValidateDefaultParameters: ConfigBoolTrue, OCSPFailOpen: OCSPFailOpenTrue,
+				ClientTimeout:          defaultClientTimeout,
+				JWTClientTimeout:       defaultJWTClientTimeout,
+				ExternalBrowserTimeout: defaultExternalBrowserTimeout,
+				IncludeRetryReason:     ConfigBoolTrue,
+				MaxRequestsPerSecond:   2.5,
+			},
+			ocspMode: ocspModeFailOpen,
+			err:      nil,
+		},
 		{
 			dsn: "u:p@a.r.c.snowflakecomputing.com/db/s?account=a.r.c&includeRetryReason=true",
 			config: &Config{
@@ -815,6 +1052,45 @@ func TestParseDSN(t *testing.T) {
 				if test.config.DisableQueryContextCache != cfg.DisableQueryContextCache {
 					t.Fatalf("%v: Failed to match DisableQueryContextCache. expected: %v, got: %v", i, test.config.DisableQueryContextCache, cfg.DisableQueryContextCache)
 				}
+				if test.config.RetryQueryOnTransientError != cfg.RetryQueryOnTransientError {
+					t.Fatalf("%v: Failed to match RetryQueryOnTransientError. expected: %v, got: %v", i, test.config.RetryQueryOnTransientError, cfg.RetryQueryOnTransientError)
+				}
+				if test.config.AutoReconnect != cfg.AutoReconnect {
+					t.Fatalf("%v: Failed to match AutoReconnect. expected: %v, got: %v", i, test.config.AutoReconnect, cfg.AutoReconnect)
+				}
+				if test.config.MaxRequestsPerSecond != cfg.MaxRequestsPerSecond {
+					t.Fatalf("%v: Failed to match MaxRequestsPerSecond. expected: %v, got: %v", i, test.config.MaxRequestsPerSecond, cfg.MaxRequestsPerSecond)
+				}
+				if test.config.MaxDownloadBytes != cfg.MaxDownloadBytes {
+					t.Fatalf("%v: Failed to match MaxDownloadBytes. expected: %v, got: %v", i, test.config.MaxDownloadBytes, cfg.MaxDownloadBytes)
+				}
+				if test.config.ResultChunkSize != cfg.ResultChunkSize {
+					t.Fatalf("%v: Failed to match ResultChunkSize. expected: %v, got: %v", i, test.config.ResultChunkSize, cfg.ResultChunkSize)
+				}
+				if test.config.RequestIDPrefix != cfg.RequestIDPrefix {
+					t.Fatalf("%v: Failed to match RequestIDPrefix. expected: %v, got: %v", i, test.config.RequestIDPrefix, cfg.RequestIDPrefix)
+				}
+				if test.config.DisablePrepareCache != cfg.DisablePrepareCache {
+					t.Fatalf("%v: Failed to match DisablePrepareCache. expected: %v, got: %v", i, test.config.DisablePrepareCache, cfg.DisablePrepareCache)
+				}
+				if test.config.TempObjectLocation != cfg.TempObjectLocation {
+					t.Fatalf("%v: Failed to match TempObjectLocation. expected: %v, got: %v", i, test.config.TempObjectLocation, cfg.TempObjectLocation)
+				}
+				if test.config.WarehouseResumeTimeout != cfg.WarehouseResumeTimeout {
+					t.Fatalf("%v: Failed to match WarehouseResumeTimeout. expected: %v, got: %v", i, test.config.WarehouseResumeTimeout, cfg.WarehouseResumeTimeout)
+				}
+				if test.config.ConnectTimeout != cfg.ConnectTimeout {
+					t.Fatalf("%v: Failed to match ConnectTimeout. expected: %v, got: %v", i, test.config.ConnectTimeout, cfg.ConnectTimeout)
+				}
+				if test.config.ResponseHeaderTimeout != cfg.ResponseHeaderTimeout {
+					t.Fatalf("%v: Failed to match ResponseHeaderTimeout. expected: %v, got: %v", i, test.config.ResponseHeaderTimeout, cfg.ResponseHeaderTimeout)
+				}
+				if !reflect.DeepEqual(test.config.RetryableHTTPStatuses, cfg.RetryableHTTPStatuses) {
+					t.Fatalf("%v: Failed to match RetryableHTTPStatuses. expected: %v, got: %v", i, test.config.RetryableHTTPStatuses, cfg.RetryableHTTPStatuses)
+				}
+				if test.config.RetryJitterSeed != cfg.RetryJitterSeed {
+					t.Fatalf("%v: Failed to match RetryJitterSeed. expected: %v, got: %v", i, test.config.RetryJitterSeed, cfg.RetryJitterSeed)
+				}
 				if test.config.IncludeRetryReason != cfg.IncludeRetryReason {
 					t.Fatalf("%v: Failed to match IncludeRetryReason. expected: %v, got: %v", i, test.config.IncludeRetryReason, cfg.IncludeRetryReason)
 				}
@@ -988,6 +1264,16 @@ func TestDSN(t *testing.T) {
 			},
 			dsn: "u:p@a.b.snowflakecomputing.com:443?application=special+go&database=db&loginTimeout=10&ocspFailOpen=true&passcode=db&passcodeInPassword=true&region=b&requestTimeout=300&role=ro&schema=sc&validateDefaultParameters=true",
 		},
+		{
+			cfg: &Config{
+				User:                  "u",
+				Password:              "p",
+				Account:               "a",
+				ConnectTimeout:        5 * time.Second,
+				ResponseHeaderTimeout: 30 * time.Second,
+			},
+			dsn: "u:p@a.snowflakecomputing.com:443?connectTimeout=5&ocspFailOpen=true&responseHeaderTimeout=30&validateDefaultParameters=true",
+		},
 		{
 			cfg: &Config{
 				User:                           "u",
@@ -1008,6 +1294,24 @@ func TestDSN(t *testing.T) {
 			},
 			dsn: "u:p@a.snowflakecomputing.com:443?authenticator=externalbrowser&clientStoreTemporaryCredential=false&ocspFailOpen=true&validateDefaultParameters=true",
 		},
+		{
+			cfg: &Config{
+				User:                   "u",
+				Password:               "p",
+				Account:                "a",
+				ClientSessionKeepAlive: ConfigBoolTrue,
+			},
+			dsn: "u:p@a.snowflakecomputing.com:443?clientSessionKeepAlive=true&ocspFailOpen=true&validateDefaultParameters=true",
+		},
+		{
+			cfg: &Config{
+				User:                   "u",
+				Password:               "p",
+				Account:                "a",
+				ClientSessionKeepAlive: ConfigBoolFalse,
+			},
+			dsn: "u:p@a.snowflakecomputing.com:443?clientSessionKeepAlive=false&ocspFailOpen=true&validateDefaultParameters=true",
+		},
 		{
 			cfg: &Config{
 				User:          "u",
@@ -1021,6 +1325,20 @@ func TestDSN(t *testing.T) {
 			},
 			dsn: "u:p@a.snowflakecomputing.com:443?authenticator=https%3A%2F%2Fsc.okta.com&ocspFailOpen=true&validateDefaultParameters=true",
 		},
+		{
+			cfg: &Config{
+				User:          "u",
+				Password:      "p",
+				Account:       "a",
+				Authenticator: AuthTypeOkta,
+				OktaURL: &url.URL{
+					Scheme: "https",
+					Host:   "sc.okta.com",
+					Path:   "/app/Snowflake/exk1AbC/sso/saml",
+				},
+			},
+			dsn: "u:p@a.snowflakecomputing.com:443?authenticator=https%3A%2F%2Fsc.okta.com%2Fapp%2FSnowflake%2Fexk1AbC%2Fsso%2Fsaml&ocspFailOpen=true&validateDefaultParameters=true",
+		},
 		{
 			cfg: &Config{
 				User:     "u",
@@ -1088,6 +1406,15 @@ func TestDSN(t *testing.T) {
 			},
 			dsn: "u:p@a.snowflakecomputing.com:443?insecureMode=true&ocspFailOpen=true&validateDefaultParameters=true",
 		},
+		{
+			cfg: &Config{
+				User:              "u",
+				Password:          "p",
+				Account:           "a",
+				DisableOCSPChecks: true,
+			},
+			dsn: "u:p@a.snowflakecomputing.com:443?disableOCSPChecks=true&ocspFailOpen=true&validateDefaultParameters=true",
+		},
 		{
 			cfg: &Config{
 				User:     "u",
@@ -1134,6 +1461,16 @@ func TestDSN(t *testing.T) {
 			},
 			dsn: "u:p@a.b.c.snowflakecomputing.com:443?clientTimeout=300&jwtTimeout=30&ocspFailOpen=true&region=b.c&validateDefaultParameters=true",
 		},
+		{
+			cfg: &Config{
+				User:             "u",
+				Password:         "p",
+				Account:          "a.b.c",
+				JWTExpireTimeout: 30 * time.Minute,
+				JWTLeeway:        5 * time.Minute,
+			},
+			dsn: "u:p@a.b.c.snowflakecomputing.com:443?jwtLeeway=300&jwtTimeout=1800&ocspFailOpen=true&region=b.c&validateDefaultParameters=true",
+		},
 		{
 			cfg: &Config{
 				User:     "u",
@@ -1152,6 +1489,64 @@ func TestDSN(t *testing.T) {
 			},
 			dsn: "u:p@a.b.c.snowflakecomputing.com:443?ocspFailOpen=true&region=b.c&tracing=debug&validateDefaultParameters=true",
 		},
+		{
+			cfg: &Config{
+				User:             "u",
+				Password:         "p",
+				Account:          "a.b.c",
+				ArrowCompression: ArrowCompressionZstd,
+			},
+			dsn: "u:p@a.b.c.snowflakecomputing.com:443?arrowCompression=ZSTD&ocspFailOpen=true&region=b.c&validateDefaultParameters=true",
+		},
+		{
+			cfg: &Config{
+				User:            "u",
+				Password:        "p",
+				Account:         "a.b.c",
+				ResultChunkSize: 48,
+			},
+			dsn: "u:p@a.b.c.snowflakecomputing.com:443?ocspFailOpen=true&region=b.c&resultChunkSize=48&validateDefaultParameters=true",
+		},
+		{
+			cfg: &Config{
+				User:            "u",
+				Password:        "p",
+				Account:         "a.b.c",
+				RequestIDPrefix: "4bf92f3577b3",
+			},
+			dsn: "u:p@a.b.c.snowflakecomputing.com:443?ocspFailOpen=true&region=b.c&requestIdPrefix=4bf92f3577b3&validateDefaultParameters=true",
+		},
+		{
+			cfg: &Config{
+				User:                "u",
+				Password:            "p",
+				Account:             "a.b.c",
+				DisablePrepareCache: true,
+			},
+			dsn: "u:p@a.b.c.snowflakecomputing.com:443?disablePrepareCache=true&ocspFailOpen=true&region=b.c&validateDefaultParameters=true",
+		},
+		{
+			cfg: &Config{
+				User:          "u",
+				Password:      "p",
+				Account:       "a.b.c",
+				MinTLSVersion: "1.3",
+			},
+			dsn: "u:p@a.b.c.snowflakecomputing.com:443?minTlsVersion=1.3&ocspFailOpen=true&region=b.c&validateDefaultParameters=true",
+		},
+		{
+			cfg: &Config{
+				User:                        "u",
+				Password:                    "p",
+				Account:                     "a.b.c",
+				Authenticator:               AuthTypeOAuthDeviceCode,
+				OAuthDeviceAuthorizationURL: "https://idp.example.com/device",
+				OAuthTokenURL:               "https://idp.example.com/token",
+				OAuthClientID:               "client",
+				OAuthScope:                  "session:role:analyst",
+			},
+			dsn: "u:p@a.b.c.snowflakecomputing.com:443?authenticator=oauth_device_code&oauthClientId=client&oauthDeviceAuthorizationUrl=https%3A%2F%2Fidp.example.com%2Fdevice&oauthScope=session%3Arole%3Aanalyst&oauthTokenUrl=https%3A%2F%2Fidp.example.com%2Ftoken&ocspFailOpen=true&region=b.c&validateDefaultParameters=true",
+		},
 		{
 			cfg: &Config{
 				User:                  "u",
@@ -1218,6 +1613,15 @@ func TestDSN(t *testing.T) {
 			},
 			dsn: "u:p@a.b.c.snowflakecomputing.com:443?disableQueryContextCache=true&ocspFailOpen=true&region=b.c&validateDefaultParameters=true",
 		},
+		{
+			cfg: &Config{
+				User:                       "u",
+				Password:                   "p",
+				Account:                    "a.b.c",
+				RetryQueryOnTransientError: true,
+			},
+			dsn: "u:p@a.b.c.snowflakecomputing.com:443?ocspFailOpen=true&region=b.c&retryQueryOnTransientError=true&validateDefaultParameters=true",
+		},
 		{
 			cfg: &Config{
 				User:               "u",
@@ -1389,3 +1793,105 @@ func TestConfigValidateTmpDirPath(t *testing.T) {
 		t.Fatalf("Should fail on not existing TmpDirPath")
 	}
 }
+
+func TestConfigValidateNegativeTimeout(t *testing.T) {
+	for _, cfg := range []*Config{
+		{LoginTimeout: -time.Second},
+		{RequestTimeout: -time.Second},
+		{JWTLeeway: -time.Second},
+		{WarehouseResumeTimeout: -time.Second},
+	} {
+		err := cfg.Validate()
+		if err == nil {
+			t.Fatalf("should fail on negative timeout: %+v", cfg)
+		}
+		driverErr, ok := err.(*SnowflakeError)
+		if !ok || driverErr.Number != ErrCodeInvalidTimeout {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := (&Config{LoginTimeout: time.Second, RequestTimeout: 0}).Validate(); err != nil {
+		t.Fatalf("should not fail, err: %v", err)
+	}
+}
+
+func TestConfigValidateJWTLifetime(t *testing.T) {
+	if err := (&Config{JWTExpireTimeout: 50 * time.Minute, JWTLeeway: 10 * time.Minute}).Validate(); err != nil {
+		t.Fatalf("should not fail, err: %v", err)
+	}
+	err := (&Config{JWTExpireTimeout: 50 * time.Minute, JWTLeeway: 11 * time.Minute}).Validate()
+	driverErr, ok := err.(*SnowflakeError)
+	if !ok || driverErr.Number != ErrCodeInvalidJWTLifetime {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestConfigValidateArrowCompression(t *testing.T) {
+	for _, codec := range []string{"", ArrowCompressionNone, "lz4_frame", ArrowCompressionZstd} {
+		if err := (&Config{ArrowCompression: codec}).Validate(); err != nil {
+			t.Fatalf("should not fail on %q, err: %v", codec, err)
+		}
+	}
+	err := (&Config{ArrowCompression: "SNAPPY"}).Validate()
+	driverErr, ok := err.(*SnowflakeError)
+	if !ok || driverErr.Number != ErrCodeInvalidArrowCompression {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestConfigValidateResultChunkSize(t *testing.T) {
+	for _, size := range []int{0, 48, 160} {
+		if err := (&Config{ResultChunkSize: size}).Validate(); err != nil {
+			t.Fatalf("should not fail on %v, err: %v", size, err)
+		}
+	}
+	for _, size := range []int{-1, 47, 161} {
+		err := (&Config{ResultChunkSize: size}).Validate()
+		driverErr, ok := err.(*SnowflakeError)
+		if !ok || driverErr.Number != ErrCodeInvalidResultChunkSize {
+			t.Fatalf("%v: unexpected error: %v", size, err)
+		}
+	}
+}
+
+func TestConfigValidateMinTLSVersion(t *testing.T) {
+	for _, version := range []string{"", "1.0", "1.1", "1.2", "1.3"} {
+		if err := (&Config{MinTLSVersion: version}).Validate(); err != nil {
+			t.Fatalf("should not fail on %q, err: %v", version, err)
+		}
+	}
+	err := (&Config{MinTLSVersion: "1.4"}).Validate()
+	driverErr, ok := err.(*SnowflakeError)
+	if !ok || driverErr.Number != ErrCodeInvalidMinTLSVersion {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestValidateDSN(t *testing.T) {
+	validDSNs := []string{
+		"u:p@a.snowflakecomputing.com:443/db/s?account=a",
+		"u:p@a/db/s?warehouse=wh&role=r",
+		"u:p@a-org/db?loginTimeout=30&requestTimeout=10",
+	}
+	for _, dsn := range validDSNs {
+		if err := ValidateDSN(dsn); err != nil {
+			t.Errorf("dsn %v should be valid, err: %v", dsn, err)
+		}
+	}
+	invalidDSNs := []string{
+		"u:p@/db",
+		"u@a/db",
+		"u:p@a.snowflakecomputing.com:abc/db?account=a",
+		"u:p@a/db?authenticator=unknown",
+		"u:p@a/db?authenticator=https%3A%2F%2Fcompanyokta.com",
+		"u:p@a/db?authenticator=http%3A%2F%2Fcompany.okta.com",
+		"u:p@a/db?loginTimeout=abc",
+		"u:p@a/db?loginTimeout=-1",
+		"u:p@a/db?tmpDirPath=%2Fnot%2Fexisting",
+	}
+	for _, dsn := range invalidDSNs {
+		if err := ValidateDSN(dsn); err == nil {
+			t.Errorf("dsn %v should be invalid", dsn)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2023 Snowflake Computing Inc. All rights reserved.
+
+package gosnowflake
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const timeOfDayLength = 24 * time.Hour
+
+// TimeOfDay binds a time.Duration as a TIME value, the time of day that long after midnight.
+// It must be at least 0 and less than 24 hours. A plain time.Duration is bound as a number
+// of nanoseconds.
+//
+//	_, err := db.Exec("INSERT INTO shops(opens_at) VALUES (?)", sf.TimeOfDay(9*time.Hour+30*time.Minute))
+type TimeOfDay time.Duration
+
+// supportedTimeOfDayBind returns true if the value is a TimeOfDay, bound as a TIME value.
+func supportedTimeOfDayBind(nv *driver.NamedValue) bool {
+	_, ok := nv.Value.(TimeOfDay)
+	return ok
+}
+
+// durationToTimeString returns the TIME bind value of d, the nanoseconds since midnight.
+// The server rounds it to the scale of the column.
+func durationToTimeString(d time.Duration) (*string, error) {
+	if d < 0 || d >= timeOfDayLength {
+		return nil, errInvalidTimeOfDay(d)
+	}
+	s := strconv.FormatInt(int64(d), 10)
+	return &s, nil
+}
+
+// ScanDuration returns a sql.Scanner scanning a TIME column into dest as the time elapsed since midnight.
+// The fractional seconds are those of the column scale. A NULL value fails the scan.
+//
+//	var opensAt time.Duration
+//	err := rows.Scan(sf.ScanDuration(&opensAt))
+func ScanDuration(dest *time.Duration) sql.Scanner {
+	return &durationScanner{dest: dest}
+}
+
+type durationScanner struct {
+	dest *time.Duration
+}
+
+func (s *durationScanner) Scan(src interface{}) error {
+	if src == nil {
+		return errNullValue("time.Duration")
+	}
+	t, ok := src.(time.Time)
+	if !ok {
+		return fmt.Errorf("cannot scan %T into a time.Duration. the column must be TIME", src)
+	}
+	h, m, sec := t.Clock()
+	*s.dest = time.Duration(h)*time.Hour + time.Duration(m)*time.Minute +
+		time.Duration(sec)*time.Second + time.Duration(t.Nanosecond())
+	return nil
+}
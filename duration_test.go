@@ -0,0 +1,89 @@
+// Copyright (c) 2023 Snowflake Computing Inc. All rights reserved.
+
+package gosnowflake
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDurationTimeRoundTrip(t *testing.T) {
+	d := 12*time.Hour + 34*time.Minute + 56*time.Second + 123456789*time.Nanosecond
+	nv := &driver.NamedValue{Ordinal: 1, Value: TimeOfDay(d)}
+	if err := (&snowflakeConn{}).CheckNamedValue(nv); err != nil {
+		t.Fatalf("TimeOfDay should be accepted as is: %v", err)
+	}
+	bindValues, err := getBindValues([]driver.NamedValue{*nv})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bind := bindValues["1"]
+	if bind.Type != "TIME" {
+		t.Fatalf("TimeOfDay should be bound as TIME, got: %v", bind.Type)
+	}
+	if s, ok := bind.Value.(*string); !ok || *s != "45296123456789" {
+		t.Fatalf("unexpected bind value: %v", bind.Value)
+	}
+
+	// the server returns the bound value rounded to the scale of the column
+	testcases := []struct {
+		scale    int64
+		value    string
+		expected time.Duration
+	}{
+		{0, "45296", 12*time.Hour + 34*time.Minute + 56*time.Second},
+		{9, "45296.123456789", d},
+	}
+	for _, tc := range testcases {
+		var v driver.Value
+		if err = stringToValue(&v, execResponseRowType{Type: "time", Scale: tc.scale}, &tc.value, nil); err != nil {
+			t.Fatal(err)
+		}
+		var scanned time.Duration
+		if err = ScanDuration(&scanned).Scan(v); err != nil {
+			t.Fatal(err)
+		}
+		if scanned != tc.expected {
+			t.Fatalf("scale %v: unexpected duration. expected: %v, got: %v", tc.scale, tc.expected, scanned)
+		}
+	}
+}
+
+func TestDurationTimeBindOutOfRange(t *testing.T) {
+	for _, d := range []time.Duration{-time.Second, 24 * time.Hour} {
+		_, err := getBindValues([]driver.NamedValue{{Ordinal: 1, Value: TimeOfDay(d)}})
+		se, ok := err.(*SnowflakeError)
+		if !ok || se.Number != ErrInvalidTimeOfDay {
+			t.Fatalf("%v: unexpected error: %v", d, err)
+		}
+	}
+}
+
+func TestDurationBoundAsNumber(t *testing.T) {
+	d := 48 * time.Hour
+	if err := (&snowflakeConn{}).CheckNamedValue(&driver.NamedValue{Ordinal: 1, Value: d}); err != driver.ErrSkip {
+		t.Fatalf("a time.Duration should be left to the default converter, got: %v", err)
+	}
+	v, err := driver.DefaultParameterConverter.ConvertValue(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bindValues, err := getBindValues([]driver.NamedValue{{Ordinal: 1, Value: v}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bind := bindValues["1"]; bind.Type != "FIXED" || *bind.Value.(*string) != "172800000000000" {
+		t.Fatalf("a time.Duration should be bound as a number, got: %v %v", bind.Type, bind.Value)
+	}
+}
+
+func TestScanDurationNotTime(t *testing.T) {
+	var d time.Duration
+	for _, src := range []interface{}{nil, int64(1), "12:00:00"} {
+		if err := ScanDuration(&d).Scan(src); err == nil || !strings.Contains(err.Error(), "time.Duration") {
+			t.Fatalf("%v: unexpected error: %v", src, err)
+		}
+	}
+}
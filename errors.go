@@ -3,6 +3,8 @@
 package gosnowflake
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"runtime/debug"
 	"strconv"
@@ -36,6 +38,20 @@ func (se *SnowflakeError) Error() string {
 	return fmt.Sprintf("%06d: %s", se.Number, message)
 }
 
+// Is reports whether target is a *SnowflakeError with the same Number, so that errors.Is
+// matches sentinel errors keyed by number, e.g. &SnowflakeError{Number: ErrSessionGone}.
+// A target without Number matches by SQLState instead.
+func (se *SnowflakeError) Is(target error) bool {
+	t, ok := target.(*SnowflakeError)
+	if !ok {
+		return false
+	}
+	if t.Number != 0 {
+		return t.Number == se.Number && (t.SQLState == "" || t.SQLState == se.SQLState)
+	}
+	return t.SQLState != "" && t.SQLState == se.SQLState
+}
+
 func (se *SnowflakeError) generateTelemetryExceptionData() *telemetryData {
 	data := &telemetryData{
 		Message: map[string]string{
@@ -80,6 +96,20 @@ func (se *SnowflakeError) exceptionTelemetry(sc *snowflakeConn) *SnowflakeError
 	return se
 }
 
+// queryExceptionTelemetry is exceptionTelemetry for an error of the query. The SHA256 of the
+// query text is included if Config.TelemetryIncludeQueryText is set. Bind values are never included.
+func (se *SnowflakeError) queryExceptionTelemetry(sc *snowflakeConn, query string) *SnowflakeError {
+	data := se.generateTelemetryExceptionData()
+	if sc != nil && sc.cfg != nil && sc.cfg.TelemetryIncludeQueryText {
+		hash := sha256.Sum256([]byte(query))
+		data.Message[queryTextHashKey] = hex.EncodeToString(hash[:])
+	}
+	if err := se.sendExceptionTelemetry(sc, data); err != nil {
+		logger.Debugf("failed to log to telemetry: %v", data)
+	}
+	return se
+}
+
 // return populated error fields replacing the default response
 func populateErrorFields(code int, data *execResponse) *SnowflakeError {
 	err := errUnknownError()
@@ -125,6 +155,34 @@ const (
 	ErrCodePrivateKeyParseError = 260010
 	// ErrCodeFailedToParseAuthenticator is an error code for the case where a DNS includes an invalid authenticator
 	ErrCodeFailedToParseAuthenticator = 260011
+	// ErrCodeInvalidTimeout is an error code for the case where a timeout parameter is negative
+	ErrCodeInvalidTimeout = 260012
+	// ErrCodeInvalidAccountURL is an error code for the case where the account host cannot be resolved or doesn't match the certificate
+	ErrCodeInvalidAccountURL = 260013
+	// ErrCodeInvalidArrowCompression is an error code for the case where an unsupported Arrow compression codec is specified
+	ErrCodeInvalidArrowCompression = 260014
+	// ErrCodeInvalidMinTLSVersion is an error code for the case where an unknown minimum TLS version is specified
+	ErrCodeInvalidMinTLSVersion = 260015
+	// ErrCodeEmptyOAuthDeviceCodeParameter is an error code for the case where a parameter required by the OAuth device authorization is missing
+	ErrCodeEmptyOAuthDeviceCodeParameter = 260016
+	// ErrCodeInvalidJWTLifetime is an error code for the case where the JWT lifetime exceeds the bound allowed by Snowflake
+	ErrCodeInvalidJWTLifetime = 260017
+	// ErrCodeInvalidTempStagePrefix is an error code for the case where the temporary stage prefix is not a valid identifier
+	ErrCodeInvalidTempStagePrefix = 260018
+	// ErrCodeInvalidSOCKS5ProxyURL is an error code for the case where the SOCKS5 proxy URL is invalid
+	ErrCodeInvalidSOCKS5ProxyURL = 260019
+	// ErrCodeInvalidClientTimezone is an error code for the case where the client timezone is not a known timezone
+	ErrCodeInvalidClientTimezone = 260020
+	// ErrCodeMissingSessionToken is an error code for the case where the master token or the session ID of a pre-established session is set without its session token
+	ErrCodeMissingSessionToken = 260021
+	// ErrCodeInvalidRequestIDPrefix is an error code for the case where the request ID prefix is not up to 12 hex digits
+	ErrCodeInvalidRequestIDPrefix = 260022
+	// ErrCodeInvalidTempObjectLocation is an error code for the case where the location of the temporary objects is not a database qualified schema
+	ErrCodeInvalidTempObjectLocation = 260023
+	// ErrCodeInvalidResultChunkSize is an error code for the case where the result chunk size is not between 48 and 160 MB
+	ErrCodeInvalidResultChunkSize = 260024
+	// ErrCodeMissingMasterToken is an error code for the case where the session token of a pre-established session is set without its master token
+	ErrCodeMissingMasterToken = 260025
 
 	/* network */
 
@@ -150,11 +208,31 @@ const (
 	ErrFailedToGetExternalBrowserResponse = 261009
 	// ErrFailedToHeartbeat is an error code when a heartbeat fails.
 	ErrFailedToHeartbeat = 261010
+	// ErrRequestTooLarge is an error code for the case where the server rejected the request body as too large.
+	ErrRequestTooLarge = 261011
+	// ErrFailedToAuthOAuthDeviceCode is an error code for the case where the OAuth device authorization failed.
+	ErrFailedToAuthOAuthDeviceCode = 261012
+	// ErrCircuitBreakerOpen is an error code for the case where a request failed fast because the circuit breaker of the host is open.
+	ErrCircuitBreakerOpen = 261013
+	// ErrMaxDownloadBytesExceeded is an error code for the case where a connection downloaded more bytes than Config.MaxDownloadBytes
+	ErrMaxDownloadBytesExceeded = 261014
 
 	/* rows */
 
 	// ErrFailedToGetChunk is an error code for the case where it failed to get chunk of result set
 	ErrFailedToGetChunk = 262000
+	// ErrNotArrowResult is an error code for the case where the Arrow data is requested for a result set in another format
+	ErrNotArrowResult = 262001
+	// ErrInvalidResultCursorCheckpoint is an error code for the case where a result cursor checkpoint points past the last chunk
+	ErrInvalidResultCursorCheckpoint = 262002
+	// ErrInvalidChunkDump is an error code for a result set that cannot be dumped or a dump that cannot be loaded
+	ErrInvalidChunkDump = 262003
+	// ErrUnknownProjectedColumn is an error code for a column set by WithColumnProjection that is not in the result set
+	ErrUnknownProjectedColumn = 262004
+	// ErrFailedToDecodeArrowChunk is an error code for an Arrow chunk of the result set that cannot be decoded
+	ErrFailedToDecodeArrowChunk = 262005
+	// ErrNullValue is an error code for a NULL scanned into a Go type that cannot hold it
+	ErrNullValue = 262006
 
 	/* transaction*/
 
@@ -189,6 +267,10 @@ const (
 	ErrNotImplemented = 264011
 	// ErrInvalidPadding is an error code denoting the invalid padding of decryption key
 	ErrInvalidPadding = 264012
+	// ErrUnsupportedUnloadFormat is an error code denoting the unload file format is not supported
+	ErrUnsupportedUnloadFormat = 264013
+	// ErrCorruptStageFile is an error code denoting a gzip file downloaded by GET whose CRC or length doesn't match its trailer
+	ErrCorruptStageFile = 264014
 
 	/* binding */
 
@@ -196,6 +278,22 @@ const (
 	ErrBindSerialization = 265001
 	// ErrBindUpload is an error code for the uploading process of bind elements to the stage
 	ErrBindUpload = 265002
+	// ErrArrayBindTooLarge is an error code for the case where the array binds sent with the query are too large
+	ErrArrayBindTooLarge = 265003
+	// ErrStreamInsertRowLength is an error code for the case where a row passed to StreamInsert has a different number of values than the first row
+	ErrStreamInsertRowLength = 265004
+	// ErrObjectField is an error code for an OBJECT field that is not supported or doesn't match the registered struct field
+	ErrObjectField = 265005
+	// ErrObjectTypeNotRegistered is an error code for scanning an OBJECT into a struct type not registered with RegisterObjectType
+	ErrObjectTypeNotRegistered = 265006
+	// ErrArrayElement is an error code for an ARRAY scanned into a slice whose element type doesn't match the ARRAY elements
+	ErrArrayElement = 265007
+	// ErrInvalidRawBind is an error code for a RawBind that is not a valid identifier or has no ? placeholder
+	ErrInvalidRawBind = 265008
+	// ErrStructArrayBind is an error code for a slice passed to StructArrayBinds that is not a slice of supported structs
+	ErrStructArrayBind = 265009
+	// ErrInvalidTimeOfDay is an error code for a TimeOfDay bound as a TIME value that is not between 0 and 24 hours
+	ErrInvalidTimeOfDay = 265010
 
 	/* async */
 
@@ -219,6 +317,13 @@ const (
 	// ErrTooHighTimestampPrecision is an error code for the case where cannot convert Snowflake timestamp to arrow.Timestamp
 	ErrTooHighTimestampPrecision = 268003
 
+	/* statement */
+
+	// ErrReservedStatementParameter is an error code for the case where a statement parameter managed by the driver is set
+	ErrReservedStatementParameter = 270001
+	// ErrInvalidPrefetchThreads is an error code for the case where the number of prefetch threads of a statement is out of range
+	ErrInvalidPrefetchThreads = 270002
+
 	/* OCSP */
 
 	// ErrOCSPStatusRevoked is an error code for the case where the certificate is revoked.
@@ -290,6 +395,44 @@ const (
 	errMsgNoResultIDs                        = "no result IDs returned with the multi-statement query"
 	errMsgQueryStatus                        = "server ErrorCode=%s, ErrorMessage=%s"
 	errMsgInvalidPadding                     = "invalid padding on input"
+	errMsgInvalidTimeout                     = "%v must not be negative: %v"
+	errMsgInvalidJWTLifetime                 = "the JWT lifetime must not exceed %v. jwtTimeout: %v, jwtLeeway: %v"
+	errMsgUnsupportedUnloadFormat            = "unsupported unload file format: %v"
+	errMsgCorruptStageFile                   = "the gzip file %v downloaded from the stage is corrupt: %v"
+	errMsgInvalidArrowCompression            = "unsupported Arrow compression: %v. supported values are NONE, LZ4_FRAME and ZSTD"
+	errMsgInvalidMinTLSVersion               = "unknown minimum TLS version: %v. supported values are 1.0, 1.1, 1.2 and 1.3"
+	errMsgInvalidPrefetchThreads             = "invalid number of prefetch threads: %v. expected 1 to %v"
+	errMsgInvalidClientTimezone              = "unknown client timezone: %v. expected an IANA timezone name or %q"
+	errMsgMissingSessionToken                = "Config.MasterToken and Config.SessionID require Config.SessionToken to attach to a pre-established session"
+	errMsgMissingMasterToken                 = "Config.SessionToken requires Config.MasterToken to renew the pre-established session"
+	errMsgInvalidSOCKS5ProxyURL              = "invalid SOCKS5 proxy URL. expected socks5://[user:password@]host:port"
+	errMsgInvalidRequestIDPrefix             = "invalid request ID prefix: %q. it must be 1 to 12 hex digits"
+	errMsgInvalidTempObjectLocation          = "invalid temporary object location: %q. it must be a schema qualified with its database, e.g. SCRATCH_DB.TMP"
+	errMsgInvalidResultChunkSize             = "invalid result chunk size: %v. it must be between 48 and 160 MB"
+	errMsgInvalidTempStagePrefix             = "invalid temporary stage prefix: %q. it must start with a letter or an underscore, followed by letters, digits, underscores or dollar signs"
+	errMsgNotArrowResult                     = "the result set is not in the Arrow format: %v"
+	errMsgInvalidChunkDump                   = "invalid result chunk dump %v: %v"
+	errMsgInvalidResultCursorCheckpoint      = "invalid result cursor checkpoint. chunk index: %v, number of chunks: %v"
+	errMsgRequestTooLarge                    = "the request is too large. HTTP: %v, URL: %v"
+	errMsgMaxDownloadBytesExceeded           = "the connection downloaded %v bytes, more than the limit of %v bytes set by Config.MaxDownloadBytes"
+	errMsgCircuitBreakerOpen                 = "the circuit breaker of %v is open after %v consecutive failures. the request is not sent"
+	errMsgArrayBindTooLarge                  = "the array binds are too large to be sent with the query (%v values). upload them to a stage by setting Config.ArrayBindStageThreshold or CLIENT_STAGE_ARRAY_BINDING_THRESHOLD to a lower value"
+	errMsgInvalidAccountURL                  = "failed to connect to %v. verify the account identifier and region are correct. err: %v"
+	errMsgReservedStatementParameter         = "statement parameter %v is managed by the driver and cannot be set"
+	errMsgStreamInsertRowLength              = "row %v of the batch has %v values, expected %v"
+	errMsgEmptyOAuthDeviceCodeParameter      = "%v is required by the OAUTH_DEVICE_CODE authenticator"
+	errMsgFailedToAuthOAuthDeviceCode        = "failed to get an OAuth token with the device authorization: %v"
+	errMsgObjectField                        = "invalid field %q of OBJECT type %v: %v"
+	errMsgObjectTypeNotRegistered            = "type %v is not registered as an OBJECT type. call RegisterObjectType first"
+	errMsgArrayElement                       = "cannot scan ARRAY into %v: %v"
+	errMsgArrayElementIndex                  = "cannot scan ARRAY into %v: element %v: %v"
+	errMsgInvalidRawBind                     = "invalid RawBind for parameter %v. only positional identifiers, e.g. my_table or \"My Table\", can be interpolated"
+	errMsgRawBindWithoutPlaceholder          = "no ? placeholder for the RawBind of parameter %v"
+	errMsgStructArrayBind                    = "cannot bind the rows of type %v: %v"
+	errMsgInvalidTimeOfDay                   = "cannot bind %v as a TIME value. it must be at least 0 and less than 24h"
+	errMsgUnknownProjectedColumn             = "the projected column %v is not in the result set"
+	errMsgFailedToDecodeArrowChunk           = "failed to decode the Arrow chunk %v of the result set: %v"
+	errMsgNullValue                          = "cannot scan NULL into %v. scan into a pointer or a sql.Null type, or run the query with WithNullToZeroValue"
 )
 
 // Returned if a DNS doesn't include account parameter.
@@ -324,6 +467,303 @@ func errInvalidRegion() *SnowflakeError {
 	}
 }
 
+// Returned if a timeout parameter is negative.
+func errInvalidTimeout(name string, value time.Duration) *SnowflakeError {
+	return &SnowflakeError{
+		Number:      ErrCodeInvalidTimeout,
+		Message:     errMsgInvalidTimeout,
+		MessageArgs: []interface{}{name, value},
+	}
+}
+
+// Returned if a connection downloaded more bytes than Config.MaxDownloadBytes.
+func errMaxDownloadBytesExceeded(downloaded int64, maxBytes int64) *SnowflakeError {
+	return &SnowflakeError{
+		Number:      ErrMaxDownloadBytesExceeded,
+		SQLState:    SQLStateConnectionFailure,
+		Message:     errMsgMaxDownloadBytesExceeded,
+		MessageArgs: []interface{}{downloaded, maxBytes},
+	}
+}
+
+// Returned if the circuit breaker of the host fails the request fast.
+func errCircuitBreakerOpen(host string, failures int) *SnowflakeError {
+	return &SnowflakeError{
+		Number:      ErrCircuitBreakerOpen,
+		SQLState:    SQLStateConnectionFailure,
+		Message:     errMsgCircuitBreakerOpen,
+		MessageArgs: []interface{}{host, failures},
+	}
+}
+
+// Returned if the sum of the JWT expire timeout and leeway exceeds the lifetime allowed by Snowflake.
+func errInvalidJWTLifetime(timeout time.Duration, leeway time.Duration) *SnowflakeError {
+	return &SnowflakeError{
+		Number:      ErrCodeInvalidJWTLifetime,
+		Message:     errMsgInvalidJWTLifetime,
+		MessageArgs: []interface{}{maxJWTLifetime, timeout, leeway},
+	}
+}
+
+// Returned if the Arrow compression codec is not supported.
+func errInvalidArrowCompression(codec string) *SnowflakeError {
+	return &SnowflakeError{
+		Number:      ErrCodeInvalidArrowCompression,
+		Message:     errMsgInvalidArrowCompression,
+		MessageArgs: []interface{}{codec},
+	}
+}
+
+// Returned if Config.MinTLSVersion is not a known TLS version.
+func errInvalidMinTLSVersion(version string) *SnowflakeError {
+	return &SnowflakeError{
+		Number:      ErrCodeInvalidMinTLSVersion,
+		Message:     errMsgInvalidMinTLSVersion,
+		MessageArgs: []interface{}{version},
+	}
+}
+
+// Returned if the prefix of the temporary stages is not a valid unquoted identifier.
+func errInvalidTempStagePrefix(prefix string) *SnowflakeError {
+	return &SnowflakeError{
+		Number:      ErrCodeInvalidTempStagePrefix,
+		Message:     errMsgInvalidTempStagePrefix,
+		MessageArgs: []interface{}{prefix},
+	}
+}
+
+// Returned if the prefix of the request IDs is not 1 to 12 hex digits.
+func errInvalidRequestIDPrefix(prefix string) *SnowflakeError {
+	return &SnowflakeError{
+		Number:      ErrCodeInvalidRequestIDPrefix,
+		Message:     errMsgInvalidRequestIDPrefix,
+		MessageArgs: []interface{}{prefix},
+	}
+}
+
+// Returned if a gzip file downloaded by GET is still corrupt after downloading it once more.
+func errCorruptStageFile(fileName string, err error) *SnowflakeError {
+	return &SnowflakeError{
+		Number:      ErrCorruptStageFile,
+		Message:     errMsgCorruptStageFile,
+		MessageArgs: []interface{}{fileName, err},
+	}
+}
+
+// Returned if the location of the temporary objects is not a schema qualified with its database.
+func errInvalidTempObjectLocation(location string) *SnowflakeError {
+	return &SnowflakeError{
+		Number:      ErrCodeInvalidTempObjectLocation,
+		Message:     errMsgInvalidTempObjectLocation,
+		MessageArgs: []interface{}{location},
+	}
+}
+
+// Returned if Config.ResultChunkSize is not between 48 and 160 MB.
+func errInvalidResultChunkSize(size int) *SnowflakeError {
+	return &SnowflakeError{
+		Number:      ErrCodeInvalidResultChunkSize,
+		Message:     errMsgInvalidResultChunkSize,
+		MessageArgs: []interface{}{size},
+	}
+}
+
+// Returned if Config.ClientTimezone is not a known timezone.
+func errInvalidClientTimezone(timezone string) *SnowflakeError {
+	return &SnowflakeError{
+		Number:      ErrCodeInvalidClientTimezone,
+		Message:     errMsgInvalidClientTimezone,
+		MessageArgs: []interface{}{timezone, ClientTimezoneServerDefault},
+	}
+}
+
+// Returned if the master token or the session ID of a pre-established session is set without its session token.
+func errMissingSessionToken() *SnowflakeError {
+	return &SnowflakeError{
+		Number:  ErrCodeMissingSessionToken,
+		Message: errMsgMissingSessionToken,
+	}
+}
+
+// Returned if the session token of a pre-established session is set without its master token.
+func errMissingMasterToken() *SnowflakeError {
+	return &SnowflakeError{
+		Number:  ErrCodeMissingMasterToken,
+		Message: errMsgMissingMasterToken,
+	}
+}
+
+// Returned if Config.SOCKS5ProxyURL is not a SOCKS5 URL with a host and a port.
+// The URL is not included as it may contain the credentials of the proxy.
+func errInvalidSOCKS5ProxyURL() *SnowflakeError {
+	return &SnowflakeError{
+		Number:  ErrCodeInvalidSOCKS5ProxyURL,
+		Message: errMsgInvalidSOCKS5ProxyURL,
+	}
+}
+
+// Returned if a parameter required by AuthTypeOAuthDeviceCode is missing.
+func errEmptyOAuthDeviceCodeParameter(param string) *SnowflakeError {
+	return &SnowflakeError{
+		Number:      ErrCodeEmptyOAuthDeviceCodeParameter,
+		Message:     errMsgEmptyOAuthDeviceCodeParameter,
+		MessageArgs: []interface{}{param},
+	}
+}
+
+// Returned if the authorization server refused or failed the OAuth device authorization.
+func errFailedToAuthOAuthDeviceCode(reason string) *SnowflakeError {
+	return &SnowflakeError{
+		Number:      ErrFailedToAuthOAuthDeviceCode,
+		SQLState:    SQLStateConnectionRejected,
+		Message:     errMsgFailedToAuthOAuthDeviceCode,
+		MessageArgs: []interface{}{reason},
+	}
+}
+
+// Returned if a statement parameter managed by the driver is passed to WithStatementParameters.
+func errReservedStatementParameter(name string) *SnowflakeError {
+	return &SnowflakeError{
+		Number:      ErrReservedStatementParameter,
+		Message:     errMsgReservedStatementParameter,
+		MessageArgs: []interface{}{name},
+	}
+}
+
+// Returned if the number of prefetch threads passed to WithPrefetchThreads is out of range.
+func errInvalidPrefetchThreads(n int) *SnowflakeError {
+	return &SnowflakeError{
+		Number:      ErrInvalidPrefetchThreads,
+		Message:     errMsgInvalidPrefetchThreads,
+		MessageArgs: []interface{}{n, maxPrefetchThreads},
+	}
+}
+
+// Returned if a row passed to StreamInsert doesn't have the same number of values as the first row of the batch.
+func errStreamInsertRowLength(row int, length int, expected int) *SnowflakeError {
+	return &SnowflakeError{
+		Number:      ErrStreamInsertRowLength,
+		Message:     errMsgStreamInsertRowLength,
+		MessageArgs: []interface{}{row, length, expected},
+	}
+}
+
+// Returned if a field of an OBJECT type is not supported or doesn't match the OBJECT value.
+func errObjectField(typ string, field string, reason string) *SnowflakeError {
+	return &SnowflakeError{
+		Number:      ErrObjectField,
+		Message:     errMsgObjectField,
+		MessageArgs: []interface{}{field, typ, reason},
+	}
+}
+
+// Returned if an OBJECT is scanned into a struct type not registered with RegisterObjectType.
+func errObjectTypeNotRegistered(typ string) *SnowflakeError {
+	return &SnowflakeError{
+		Number:      ErrObjectTypeNotRegistered,
+		Message:     errMsgObjectTypeNotRegistered,
+		MessageArgs: []interface{}{typ},
+	}
+}
+
+// Returned if an ARRAY cannot be scanned into the destination slice. index is -1 if no element is at fault.
+func errArrayElement(typ string, index int, reason string) *SnowflakeError {
+	if index < 0 {
+		return &SnowflakeError{
+			Number:      ErrArrayElement,
+			Message:     errMsgArrayElement,
+			MessageArgs: []interface{}{typ, reason},
+		}
+	}
+	return &SnowflakeError{
+		Number:      ErrArrayElement,
+		Message:     errMsgArrayElementIndex,
+		MessageArgs: []interface{}{typ, index, reason},
+	}
+}
+
+// Returned if a RawBind is bound by name or is not an identifier.
+func errInvalidRawBind(param interface{}) *SnowflakeError {
+	return &SnowflakeError{
+		Number:      ErrInvalidRawBind,
+		Message:     errMsgInvalidRawBind,
+		MessageArgs: []interface{}{param},
+	}
+}
+
+// Returned if the query has no ? placeholder for a RawBind.
+func errRawBindWithoutPlaceholder(ordinal int) *SnowflakeError {
+	return &SnowflakeError{
+		Number:      ErrInvalidRawBind,
+		Message:     errMsgRawBindWithoutPlaceholder,
+		MessageArgs: []interface{}{ordinal},
+	}
+}
+
+// Returned if the rows passed to StructArrayBinds are not a slice of structs with supported fields.
+func errStructArrayBind(typ string, reason string) *SnowflakeError {
+	return &SnowflakeError{
+		Number:      ErrStructArrayBind,
+		Message:     errMsgStructArrayBind,
+		MessageArgs: []interface{}{typ, reason},
+	}
+}
+
+// Returned if a time.Duration bound as a TIME value is negative or not less than 24 hours.
+func errInvalidTimeOfDay(d time.Duration) *SnowflakeError {
+	return &SnowflakeError{
+		Number:      ErrInvalidTimeOfDay,
+		Message:     errMsgInvalidTimeOfDay,
+		MessageArgs: []interface{}{d},
+	}
+}
+
+// Returned if the result set cannot be dumped to dir or the dump in dir cannot be loaded.
+func errInvalidChunkDump(dir string, reason string) *SnowflakeError {
+	return &SnowflakeError{
+		Number:      ErrInvalidChunkDump,
+		Message:     errMsgInvalidChunkDump,
+		MessageArgs: []interface{}{dir, reason},
+	}
+}
+
+// Returned if a column set by WithColumnProjection is not in the result set.
+func errUnknownProjectedColumn(column string) *SnowflakeError {
+	return &SnowflakeError{
+		Number:      ErrUnknownProjectedColumn,
+		Message:     errMsgUnknownProjectedColumn,
+		MessageArgs: []interface{}{column},
+	}
+}
+
+// Returned if an Arrow chunk of the result set cannot be decoded. Chunk 0 holds the rows sent with the query response.
+func errFailedToDecodeArrowChunk(chunk int, err error) *SnowflakeError {
+	return &SnowflakeError{
+		Number:      ErrFailedToDecodeArrowChunk,
+		Message:     errMsgFailedToDecodeArrowChunk,
+		MessageArgs: []interface{}{chunk, err},
+	}
+}
+
+// Returned if a NULL is scanned into a Go type that cannot hold it.
+func errNullValue(typ string) *SnowflakeError {
+	return &SnowflakeError{
+		Number:      ErrNullValue,
+		Message:     errMsgNullValue,
+		MessageArgs: []interface{}{typ},
+	}
+}
+
+// Returned if the account host cannot be resolved or doesn't match the server certificate.
+func errInvalidAccountURL(host string, err error) *SnowflakeError {
+	return &SnowflakeError{
+		Number:      ErrCodeInvalidAccountURL,
+		SQLState:    SQLStateConnectionWasNotEstablished,
+		Message:     errMsgInvalidAccountURL,
+		MessageArgs: []interface{}{host, err},
+	}
+}
+
 // Returned if the server side returns an error without meaningful message.
 func errUnknownError() *SnowflakeError {
 	return &SnowflakeError{
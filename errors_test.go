@@ -3,6 +3,9 @@
 package gosnowflake
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"strings"
 	"testing"
 )
@@ -79,3 +82,62 @@ func TestErrorMessage(t *testing.T) {
 		t.Errorf("failed to format error. %v", e)
 	}
 }
+
+func TestErrorIs(t *testing.T) {
+	err := fmt.Errorf("query failed: %w", &SnowflakeError{
+		Number:   ErrSessionGone,
+		SQLState: "08001",
+		Message:  "session gone",
+	})
+	if !errors.Is(err, &SnowflakeError{Number: ErrSessionGone}) {
+		t.Error("should match the error number")
+	}
+	if !errors.Is(err, &SnowflakeError{Number: ErrSessionGone, SQLState: "08001"}) {
+		t.Error("should match the error number and SQLState")
+	}
+	if errors.Is(err, &SnowflakeError{Number: ErrSessionGone, SQLState: "08004"}) {
+		t.Error("should not match another SQLState")
+	}
+	if errors.Is(err, &SnowflakeError{Number: ErrRoleNotExist}) {
+		t.Error("should not match another error number")
+	}
+	if !errors.Is(err, &SnowflakeError{SQLState: "08001"}) {
+		t.Error("should match the SQLState")
+	}
+	if errors.Is(err, &SnowflakeError{}) {
+		t.Error("should not match an empty error")
+	}
+}
+
+func TestIsSQLState(t *testing.T) {
+	err := fmt.Errorf("query failed: %w", &SnowflakeError{Number: 1, SQLState: SQLStateConnectionFailure})
+	for state, expected := range map[string]bool{
+		SQLStateConnectionFailure:  true,
+		"08":                       true,
+		SQLStateConnectionRejected: false,
+		"22":                       false,
+		"":                         false,
+	} {
+		if IsSQLState(err, state) != expected {
+			t.Errorf("unexpected result for %q, expected: %v", state, expected)
+		}
+	}
+	if IsSQLState(errors.New("not a Snowflake error"), "08") {
+		t.Error("should not match an error without SQLState")
+	}
+}
+
+func TestPopulateErrorFieldsSQLState(t *testing.T) {
+	var resp execResponse
+	if err := json.Unmarshal([]byte(`{"data":{"sqlState":"42S02","queryId":"01aa"},`+
+		`"code":"002003","message":"Object 'T' does not exist","success":false}`), &resp); err != nil {
+		t.Fatal(err)
+	}
+	err := populateErrorFields(2003, &resp)
+	if err.SQLState != "42S02" || err.Number != 2003 || err.QueryID != "01aa" {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if !IsSQLState(err, "42S02") || !errors.Is(err, &SnowflakeError{Number: 2003}) {
+		t.Fatalf("predicates should match the parsed error: %v", err)
+	}
+}
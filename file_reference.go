@@ -0,0 +1,78 @@
+// Copyright (c) 2023 Snowflake Computing Inc. All rights reserved.
+
+package gosnowflake
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// SnowflakeFile is the value of a FILE column, a reference to a file on a stage with its metadata.
+// A FILE column can be scanned into a *SnowflakeFile, and a SnowflakeFile or *SnowflakeFile can be
+// bound to send a FILE reference, e.g. to insert it into a FILE column. A nil *SnowflakeFile is bound as NULL.
+//
+//	var f sf.SnowflakeFile
+//	err := db.QueryRow("SELECT TO_FILE('@my_stage', 'images/cat.png')").Scan(&f)
+type SnowflakeFile struct {
+	Stage         string `json:"STAGE"`                     // name of the stage
+	RelativePath  string `json:"RELATIVE_PATH"`             // path of the file relative to the stage
+	StageFileURL  string `json:"STAGE_FILE_URL"`            // permanent URL of the file
+	ScopedFileURL string `json:"SCOPED_FILE_URL,omitempty"` // URL of the file valid for the duration of the query
+	ContentType   string `json:"CONTENT_TYPE,omitempty"`    // MIME type of the file
+	Size          int64  `json:"SIZE,omitempty"`            // size of the file in bytes
+	ETag          string `json:"ETAG,omitempty"`            // ETag of the file
+	LastModified  string `json:"LAST_MODIFIED,omitempty"`   // last modification time of the file, as returned by Snowflake
+}
+
+// Scan decodes a FILE value. A NULL value resets f.
+func (f *SnowflakeFile) Scan(src interface{}) error {
+	var b []byte
+	switch v := src.(type) {
+	case nil:
+		*f = SnowflakeFile{}
+		return nil
+	case string:
+		b = []byte(v)
+	case []byte:
+		b = v
+	default:
+		return fmt.Errorf("cannot scan %T into a FILE value", src)
+	}
+	var decoded SnowflakeFile
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		return fmt.Errorf("invalid FILE value: %w", err)
+	}
+	*f = decoded
+	return nil
+}
+
+// fileBindValue returns the FILE reference bound with v, and whether v is a FILE reference.
+func fileBindValue(v driver.Value) (*SnowflakeFile, bool) {
+	switch f := v.(type) {
+	case SnowflakeFile:
+		return &f, true
+	case *SnowflakeFile:
+		return f, true
+	}
+	return nil, false
+}
+
+// supportedFileBind returns true for the FILE references, SnowflakeFile and *SnowflakeFile
+func supportedFileBind(nv *driver.NamedValue) bool {
+	_, ok := fileBindValue(nv.Value)
+	return ok
+}
+
+// fileToString serializes a FILE reference for a bind. A nil reference is bound as NULL.
+func fileToString(f *SnowflakeFile) (*string, error) {
+	if f == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(f)
+	if err != nil {
+		return nil, err
+	}
+	s := string(b)
+	return &s, nil
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2023 Snowflake Computing Inc. All rights reserved.
+
+package gosnowflake
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+const testFileValue = `{"STAGE":"MY_STAGE","RELATIVE_PATH":"images/cat.png",` +
+	`"STAGE_FILE_URL":"https://a.snowflakecomputing.com/api/files/DB/S/MY_STAGE/images%2fcat.png",` +
+	`"CONTENT_TYPE":"image/png","SIZE":1024,"ETAG":"e3b0c442","LAST_MODIFIED":"Mon, 02 Oct 2023 10:00:00 GMT"}`
+
+func TestScanFileColumn(t *testing.T) {
+	rowType := []execResponseRowType{{Name: "F", Type: "file", Nullable: true}}
+	rows := newJSONTestRows(rowType, [][]*string{{strPtr(testFileValue)}, {nil}})
+	if scanType := rows.ColumnTypeScanType(0); scanType != reflect.TypeOf("") {
+		t.Fatalf("unexpected scan type: %v", scanType)
+	}
+	if typeName := rows.ColumnTypeDatabaseTypeName(0); typeName != "FILE" {
+		t.Fatalf("unexpected type name: %v", typeName)
+	}
+
+	dest := make([]driver.Value, 1)
+	if err := rows.Next(dest); err != nil {
+		t.Fatal(err)
+	}
+	var f SnowflakeFile
+	if err := f.Scan(dest[0]); err != nil {
+		t.Fatal(err)
+	}
+	expected := SnowflakeFile{
+		Stage:        "MY_STAGE",
+		RelativePath: "images/cat.png",
+		StageFileURL: "https://a.snowflakecomputing.com/api/files/DB/S/MY_STAGE/images%2fcat.png",
+		ContentType:  "image/png",
+		Size:         1024,
+		ETag:         "e3b0c442",
+		LastModified: "Mon, 02 Oct 2023 10:00:00 GMT",
+	}
+	if f != expected {
+		t.Fatalf("unexpected FILE value. expected: %+v, got: %+v", expected, f)
+	}
+
+	if err := rows.Next(dest); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Scan(dest[0]); err != nil {
+		t.Fatal(err)
+	}
+	if f != (SnowflakeFile{}) {
+		t.Fatalf("a NULL value should reset the FILE value, got: %+v", f)
+	}
+	if err := f.Scan("not json"); err == nil {
+		t.Fatal("an invalid FILE value should fail")
+	}
+}
+
+func TestBindFileReference(t *testing.T) {
+	var f SnowflakeFile
+	if err := f.Scan(testFileValue); err != nil {
+		t.Fatal(err)
+	}
+	sc := &snowflakeConn{}
+	var nilFile *SnowflakeFile
+	bindings := []driver.NamedValue{
+		{Ordinal: 1, Value: f},
+		{Ordinal: 2, Value: &f},
+		{Ordinal: 3, Value: nilFile},
+	}
+	for i := range bindings {
+		if err := sc.CheckNamedValue(&bindings[i]); err != nil {
+			t.Fatalf("FILE references should be accepted as binds, err: %v", err)
+		}
+	}
+	bindValues, err := getBindValues(bindings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"1", "2"} {
+		bind := bindValues[name]
+		if bind.Type != "FILE" {
+			t.Fatalf("expected FILE bind type, got: %v", bind.Type)
+		}
+		v, ok := bind.Value.(*string)
+		if !ok || v == nil {
+			t.Fatalf("unexpected bind value: %v", bind.Value)
+		}
+		var bound SnowflakeFile
+		if err = json.Unmarshal([]byte(*v), &bound); err != nil {
+			t.Fatal(err)
+		}
+		if bound != f {
+			t.Fatalf("the bound FILE value should round trip. expected: %+v, got: %+v", f, bound)
+		}
+	}
+	if bind := bindValues["3"]; bind.Type != "FILE" || bind.Value.(*string) != nil {
+		t.Fatalf("a nil FILE reference should be bound as NULL, got: %+v", bind)
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"fmt"
 	"io"
 	"math"
+	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
@@ -104,6 +105,7 @@ type SnowflakeFileTransferOptions struct {
 }
 
 type snowflakeFileTransferAgent struct {
+	ctx                         context.Context
 	sc                          *snowflakeConn
 	data                        *execResponseData
 	command                     string
@@ -172,6 +174,8 @@ func (sfa *snowflakeFileTransferAgent) execute() error {
 	for _, meta := range sfa.fileMetadata {
 		meta.overwrite = sfa.overwrite
 		meta.sfa = sfa
+		meta.ctx = sfa.ctx
+		meta.storageTransport = storageTransport(sfa.sc.cfg)
 		meta.options = sfa.options
 		if sfa.stageLocationType != local {
 			sizeThreshold := sfa.options.MultiPartThreshold
@@ -202,6 +206,24 @@ func (sfa *snowflakeFileTransferAgent) execute() error {
 	return nil
 }
 
+// storageHTTPClient returns the client of the requests to the cloud storage made before the files are
+// transferred, or nil if the default client of the storage SDK is used.
+func (sfa *snowflakeFileTransferAgent) storageHTTPClient() *http.Client {
+	if transport := storageTransport(sfa.sc.cfg); transport != nil {
+		return &http.Client{Transport: transport}
+	}
+	return nil
+}
+
+// transferCanceled returns the error of the command context once it is done,
+// so that no more files are transferred.
+func (sfa *snowflakeFileTransferAgent) transferCanceled() error {
+	if sfa.ctx == nil {
+		return nil
+	}
+	return sfa.ctx.Err()
+}
+
 func (sfa *snowflakeFileTransferAgent) parseCommand() error {
 	var err error
 	if sfa.data.Command != "" {
@@ -269,6 +291,9 @@ func (sfa *snowflakeFileTransferAgent) parseCommand() error {
 		sfa.parallel = sfa.data.Parallel
 	}
 	sfa.overwrite = !sfa.options.DisablePutOverwrite
+	if overwrite, ok := getPutOverwrite(sfa.ctx); ok {
+		sfa.overwrite = overwrite
+	}
 	sfa.stageLocationType = cloudType(strings.ToUpper(sfa.data.StageInfo.LocationType))
 	sfa.stageInfo = &sfa.data.StageInfo
 	sfa.presignedURLs = make([]string, 0)
@@ -415,6 +440,10 @@ func (sfa *snowflakeFileTransferAgent) initFileMetadata() error {
 					stageLocationType: sfa.stageLocationType,
 					stageInfo:         sfa.stageInfo,
 					localLocation:     sfa.localLocation,
+					getCache:          sfa.sc.getCache,
+					downloads:         sfa.sc.downloads,
+					verifyGzip:        sfa.sc.cfg.VerifyGetGzip,
+					downloadTransport: sfa.sc.cfg.ChunkDownloadTransport,
 				})
 			}
 		}
@@ -608,7 +637,7 @@ func (sfa *snowflakeFileTransferAgent) transferAccelerateConfig() error {
 		}
 		ret, err := client.GetBucketAccelerateConfiguration(context.Background(), &s3.GetBucketAccelerateConfigurationInput{
 			Bucket: &s3Loc.bucketName,
-		})
+		}, s3HTTPClientOption(sfa.storageHTTPClient()))
 		sfa.useAccelerateEndpoint = ret != nil && ret.Status == "Enabled"
 		if err != nil {
 			var ae smithy.APIError
@@ -733,6 +762,9 @@ func (sfa *snowflakeFileTransferAgent) uploadFilesParallel(fileMetas []*fileMeta
 				}(i, meta)
 			}
 			wg.Wait()
+			if err = sfa.transferCanceled(); err != nil {
+				return err
+			}
 
 			// append errors with no result associated to separate array
 			var errorMessages []string
@@ -806,6 +838,9 @@ func (sfa *snowflakeFileTransferAgent) uploadFilesSequential(fileMetas []*fileMe
 	idx := 0
 	fileMetaLen := len(fileMetas)
 	for idx < fileMetaLen {
+		if err := sfa.transferCanceled(); err != nil {
+			return err
+		}
 		res, err := sfa.uploadOneFile(fileMetas[idx])
 		if err != nil {
 			return err
@@ -875,79 +910,87 @@ func (sfa *snowflakeFileTransferAgent) uploadOneFile(meta *fileMetadata) (*fileM
 	return meta, nil
 }
 
+// downloadFilesParallel downloads all the files, e.g. the files of the stage matching the pattern of
+// a GET, with at most sfa.parallel downloads at a time. A download starts as soon as another one
+// completes. The files whose credentials or presigned URL expired are downloaded once more.
 func (sfa *snowflakeFileTransferAgent) downloadFilesParallel(fileMetas []*fileMetadata) error {
-	idx := 0
-	fileMetaLen := len(fileMetas)
-	var err error
-	for idx < fileMetaLen {
-		endOfIdx := intMin(fileMetaLen, idx+int(sfa.parallel))
-		targetMeta := fileMetas[idx:endOfIdx]
-		for {
-			var wg sync.WaitGroup
-			results := make([]*fileMetadata, len(targetMeta))
-			errors := make([]error, len(targetMeta))
-			for i, meta := range targetMeta {
-				wg.Add(1)
-				go func(k int, m *fileMetadata) {
-					defer wg.Done()
-					results[k], errors[k] = sfa.downloadOneFile(m)
-				}(i, meta)
-			}
-			wg.Wait()
+	targetMeta := fileMetas
+	for len(targetMeta) > 0 {
+		results := sfa.downloadFilesConcurrently(targetMeta)
+		if err := sfa.transferCanceled(); err != nil {
+			return err
+		}
 
-			retryMeta := make([]*fileMetadata, 0)
-			for i, result := range results {
-				result.errorDetails = errors[i]
-				if result.resStatus == renewToken || result.resStatus == renewPresignedURL {
-					retryMeta = append(retryMeta, result)
-				} else {
-					sfa.results = append(sfa.results, result)
-				}
-			}
-			if len(retryMeta) == 0 {
-				break
+		retryMeta := make([]*fileMetadata, 0)
+		for _, result := range results {
+			if result.resStatus == renewToken || result.resStatus == renewPresignedURL {
+				retryMeta = append(retryMeta, result)
+			} else {
+				sfa.results = append(sfa.results, result)
 			}
-			logger.WithContext(sfa.sc.ctx).Infof("%v retries found", len(retryMeta))
+		}
+		if len(retryMeta) == 0 {
+			break
+		}
+		logger.WithContext(sfa.sc.ctx).Infof("%v retries found", len(retryMeta))
 
-			needRenewToken := false
-			for _, result := range retryMeta {
-				if result.resStatus == renewToken {
-					needRenewToken = true
-				}
-				logger.WithContext(sfa.sc.ctx).Infof(
-					"retying download file %v with status %v",
-					result.name, result.resStatus)
+		needRenewToken := false
+		for _, result := range retryMeta {
+			if result.resStatus == renewToken {
+				needRenewToken = true
 			}
+			logger.WithContext(sfa.sc.ctx).Infof(
+				"retying download file %v with status %v",
+				result.name, result.resStatus)
+		}
 
-			if needRenewToken {
-				client, err := sfa.renewExpiredClient()
-				if err != nil {
-					return err
-				}
-				for _, result := range retryMeta {
-					result.client = client
-				}
-				if endOfIdx < fileMetaLen {
-					for i := idx + int(sfa.parallel); i < fileMetaLen; i++ {
-						fileMetas[i].client = client
-					}
-				}
+		if needRenewToken {
+			client, err := sfa.renewExpiredClient()
+			if err != nil {
+				return err
 			}
-
 			for _, result := range retryMeta {
-				if result.resStatus == renewPresignedURL {
-					sfa.updateFileMetadataWithPresignedURL()
-					break
-				}
+				result.client = client
 			}
-			targetMeta = retryMeta
 		}
-		if endOfIdx == fileMetaLen {
-			break
+
+		for _, result := range retryMeta {
+			if result.resStatus == renewPresignedURL {
+				sfa.updateFileMetadataWithPresignedURL()
+				break
+			}
 		}
-		idx += int(sfa.parallel)
+		targetMeta = retryMeta
 	}
-	return err
+	return nil
+}
+
+// downloadFilesConcurrently downloads the files with at most sfa.parallel downloads at a time and
+// returns their metadata with the status of the download, in the order of fileMetas.
+func (sfa *snowflakeFileTransferAgent) downloadFilesConcurrently(fileMetas []*fileMetadata) []*fileMetadata {
+	slots := make(chan struct{}, int64Max(sfa.parallel, 1))
+	results := make([]*fileMetadata, len(fileMetas))
+	var wg sync.WaitGroup
+	for i, meta := range fileMetas {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(k int, m *fileMetadata) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			result, err := sfa.downloadOneFile(m)
+			if result == nil {
+				// the download did not start
+				result = m
+				result.resStatus = errStatus
+			}
+			result.errorDetails = err
+			results[k] = result
+		}(i, meta)
+	}
+	wg.Wait()
+	return results
 }
 
 func (sfa *snowflakeFileTransferAgent) downloadOneFile(meta *fileMetadata) (*fileMetadata, error) {
@@ -1042,7 +1085,9 @@ func (sfa *snowflakeFileTransferAgent) result() (*execResponse, error) {
 				return rowset[i].srcFileName < rowset[j].srcFileName
 			})
 			ccrs := make([][]*string, 0, len(rowset))
-			for _, rs := range rowset {
+			for i := range rowset {
+				// the rows point into rowset, not into a loop variable reused by every iteration
+				rs := &rowset[i]
 				srcFileSize := fmt.Sprintf("%v", rs.srcFileSize)
 				dstFileSize := fmt.Sprintf("%v", rs.dstFileSize)
 				resStatus := rs.resStatus.String()
@@ -1097,11 +1142,13 @@ func (sfa *snowflakeFileTransferAgent) result() (*execResponse, error) {
 					nil, nil, meta.resStatus, meta.errorDetails,
 				})
 			}
+			// one row per downloaded file, whatever the order in which the downloads completed
 			sort.Slice(rowset, func(i, j int) bool {
-				return rowset[i].srcFileName < rowset[j].srcFileName
+				return rowset[i].dstFileName < rowset[j].dstFileName
 			})
 			ccrs := make([][]*string, 0, len(rowset))
-			for _, rs := range rowset {
+			for i := range rowset {
+				rs := &rowset[i]
 				dstFileSize := fmt.Sprintf("%v", rs.dstFileSize)
 				resStatus := rs.resStatus.String()
 				errorStr := ""
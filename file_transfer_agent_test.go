@@ -5,6 +5,7 @@ package gosnowflake
 import (
 	"bytes"
 	"context"
+	"database/sql/driver"
 	"errors"
 	"fmt"
 	"io"
@@ -661,3 +662,121 @@ func TestReadonlyTmpDirPathShouldFail(t *testing.T) {
 		t.Fatalf("should not upload file as temporary directory is not readable")
 	}
 }
+
+func TestGetWithPatternDownloadsAllMatchingFiles(t *testing.T) {
+	stageDir := t.TempDir()
+	localDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(stageDir, "data"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	contents := map[string]string{"a.csv": "1,a\n", "b.csv": "2,b\n", "c.csv": "3,c\n", "notes.txt": "not matching"}
+	for name, content := range contents {
+		if err := os.WriteFile(filepath.Join(stageDir, "data", name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sc := getDefaultSnowflakeConn()
+	sc.rest.FuncPostQuery = func(_ context.Context, _ *snowflakeRestful, _ *url.Values,
+		_ map[string]string, _ []byte, _ time.Duration, _ UUID, _ *Config) (*execResponse, error) {
+		// the server expands the pattern into the listing of the matching files
+		return &execResponse{
+			Data: execResponseData{
+				QueryID:       "qid",
+				Command:       string(downloadCommand),
+				SrcLocations:  []string{"data/c.csv", "data/a.csv", "data/b.csv"},
+				LocalLocation: localDir,
+				Parallel:      2,
+				StageInfo: execResponseStageInfo{
+					LocationType: string(local),
+					Location:     stageDir,
+				},
+			},
+			Success: true,
+		}, nil
+	}
+
+	rows, err := sc.QueryContext(context.Background(), `GET @my_stage/data file:///tmp/dst PATTERN='.*[.]csv'`, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var files []string
+	dest := make([]driver.Value, len(rows.Columns()))
+	for rows.Next(dest) == nil {
+		files = append(files, fmt.Sprint(dest[0]))
+		if status := fmt.Sprint(dest[2]); status != downloaded.String() {
+			t.Fatalf("unexpected status of %v: %v", dest[0], status)
+		}
+	}
+	if strings.Join(files, ",") != "a.csv,b.csv,c.csv" {
+		t.Fatalf("expected one row per matching file, got: %v", files)
+	}
+	for _, name := range files {
+		b, err := os.ReadFile(filepath.Join(localDir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != contents[name] {
+			t.Fatalf("unexpected content of %v: %q", name, b)
+		}
+	}
+	if _, err = os.Stat(filepath.Join(localDir, "notes.txt")); !os.IsNotExist(err) {
+		t.Fatalf("only the listed files should be downloaded, err: %v", err)
+	}
+}
+
+func TestPutWithOverwriteReplacesExistingFile(t *testing.T) {
+	stageDir := t.TempDir()
+	srcDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(stageDir, "data.csv"), []byte("1,old\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	srcFile := filepath.Join(srcDir, "data.csv")
+	if err := os.WriteFile(srcFile, []byte("1,new\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	sc := getDefaultSnowflakeConn()
+	sc.rest.FuncPostQuery = func(_ context.Context, _ *snowflakeRestful, _ *url.Values,
+		_ map[string]string, _ []byte, _ time.Duration, _ UUID, _ *Config) (*execResponse, error) {
+		return &execResponse{
+			Data: execResponseData{
+				QueryID:           "qid",
+				Command:           string(uploadCommand),
+				SrcLocations:      []string{srcFile},
+				SourceCompression: "none",
+				StageInfo: execResponseStageInfo{
+					LocationType: string(local),
+					Location:     stageDir,
+				},
+			},
+			Success: true,
+		}, nil
+	}
+	put := func(ctx context.Context) (status string, content string) {
+		rows, err := sc.QueryContext(ctx, "PUT file://"+srcFile+" @my_stage", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rows.Close()
+		dest := make([]driver.Value, len(rows.Columns()))
+		if err = rows.Next(dest); err != nil {
+			t.Fatal(err)
+		}
+		b, err := os.ReadFile(filepath.Join(stageDir, "data.csv"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return fmt.Sprint(dest[6]), string(b)
+	}
+
+	ctx := WithFileTransferOptions(context.Background(), &SnowflakeFileTransferOptions{DisablePutOverwrite: true})
+	if status, content := put(ctx); status != skipped.String() || content != "1,old\n" {
+		t.Fatalf("the existing file should be skipped, status: %v, content: %q", status, content)
+	}
+	if status, content := put(WithOverwrite(ctx, true)); status != uploaded.String() || content != "1,new\n" {
+		t.Fatalf("the existing file should be replaced, status: %v, content: %q", status, content)
+	}
+	if status, _ := put(WithOverwrite(context.Background(), false)); status != skipped.String() {
+		t.Fatalf("the existing file should be skipped, status: %v", status)
+	}
+}
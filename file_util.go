@@ -5,9 +5,11 @@ package gosnowflake
 import (
 	"bytes"
 	"compress/gzip"
+	"context"
 	"crypto/sha256"
 	"encoding/base64"
 	"io"
+	"net/http"
 	"net/url"
 	"os"
 	usr "os/user"
@@ -106,6 +108,7 @@ func (util *snowflakeFileUtil) getDigestAndSizeForFile(fileName string) (string,
 type fileMetadata struct {
 	name               string
 	sfa                *snowflakeFileTransferAgent
+	ctx                context.Context
 	stageLocationType  cloudType
 	resStatus          resultStatus
 	stageInfo          *execResponseStageInfo
@@ -132,6 +135,11 @@ type fileMetadata struct {
 	lastMaxConcurrency int
 	localLocation      string
 	options            *SnowflakeFileTransferOptions
+	getCache           *getCache
+	downloads          *downloadLimiter
+	verifyGzip         bool
+	downloadTransport  http.RoundTripper
+	storageTransport   http.RoundTripper
 
 	/* streaming PUT */
 	srcStream     *bytes.Buffer
@@ -147,10 +155,32 @@ type fileMetadata struct {
 	mockUploader    s3UploadAPI
 	mockDownloader  s3DownloadAPI
 	mockHeader      s3HeaderAPI
+	mockAborter     s3AbortAPI
 	mockGcsClient   gcsAPI
 	mockAzureClient azureAPI
 }
 
+// transferContext returns the context of the PUT/GET command. The transfer is aborted once it is done.
+func (meta *fileMetadata) transferContext() context.Context {
+	if meta.ctx == nil {
+		return context.Background()
+	}
+	return meta.ctx
+}
+
+// storageHTTPClient returns the client of the requests to the cloud storage,
+// or nil if the default client of the storage SDK is used.
+func (meta *fileMetadata) storageHTTPClient(download bool) *http.Client {
+	transport := meta.storageTransport
+	if download && meta.downloadTransport != nil {
+		transport = meta.downloadTransport
+	}
+	if transport == nil {
+		return nil
+	}
+	return &http.Client{Transport: transport}
+}
+
 type fileTransferResultType struct {
 	name               string
 	srcFileName        string
@@ -167,6 +197,7 @@ type fileHeader struct {
 	digest             string
 	contentLength      int64
 	encryptionMetadata *encryptMetadata
+	etag               string
 }
 
 func getReaderFromBuffer(src **bytes.Buffer) io.Reader {
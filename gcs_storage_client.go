@@ -3,6 +3,7 @@
 package gosnowflake
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -19,8 +20,12 @@ const (
 	gcsMetadataMatdescKey         = gcsMetadataPrefix + "matdesc"
 	gcsMetadataEncryptionDataProp = gcsMetadataPrefix + "encryptiondata"
 	gcsFileHeaderDigest           = "gcs-file-header-digest"
+	gcsHeaderResumable            = "x-goog-resumable"
 )
 
+// gcsResumableChunkSize is the size of the chunks of a resumable upload. It must be a multiple of 256 KiB.
+var gcsResumableChunkSize int64 = 8 * 1024 * 1024
+
 type snowflakeGcsClient struct {
 }
 
@@ -59,7 +64,7 @@ func (util *snowflakeGcsClient) getFileHeader(meta *fileMetadata, filename strin
 			"Authorization": "Bearer " + accessToken,
 		}
 
-		req, err := http.NewRequest("HEAD", URL.String(), nil)
+		req, err := http.NewRequestWithContext(meta.transferContext(), "HEAD", URL.String(), nil)
 		if err != nil {
 			return nil, err
 		}
@@ -67,7 +72,7 @@ func (util *snowflakeGcsClient) getFileHeader(meta *fileMetadata, filename strin
 			req.Header.Add(k, v)
 		}
 		var client gcsAPI
-		client = &http.Client{}
+		client = gcsHTTPClient(meta.storageHTTPClient(false))
 		// for testing only
 		if meta.mockGcsClient != nil {
 			client = meta.mockGcsClient
@@ -120,6 +125,7 @@ func (util *snowflakeGcsClient) getFileHeader(meta *fileMetadata, filename strin
 			digest:             digest,
 			contentLength:      int64(contentLength),
 			encryptionMetadata: encryptionMeta,
+			etag:               resp.Header.Get("ETag"),
 		}, nil
 	}
 	return nil, nil
@@ -189,71 +195,281 @@ func (util *snowflakeGcsClient) uploadFile(
 		gcsHeaders[gcsMetadataMatdescKey] = encryptMeta.matdesc
 	}
 
-	var uploadSrc io.Reader
+	var client gcsAPI
+	client = gcsHTTPClient(meta.storageHTTPClient(false))
+	// for testing only
+	if meta.mockGcsClient != nil {
+		client = meta.mockGcsClient
+	}
+
+	// presigned URLs are only valid for a single PUT, so a resumable session requires the access token
+	if accessToken != "" && meta.uploadSize > 0 && meta.uploadSize >= multiPartThreshold {
+		if err = util.uploadFileResumable(dataFile, meta, client, uploadURL, gcsHeaders); err != nil {
+			return err
+		}
+	} else {
+		var uploadSrc io.Reader
+		if meta.srcStream != nil {
+			uploadSrc = meta.srcStream
+			if meta.realSrcStream != nil {
+				uploadSrc = meta.realSrcStream
+			}
+		} else {
+			uploadSrc, err = os.Open(dataFile)
+			if err != nil {
+				return err
+			}
+		}
+
+		req, err := http.NewRequestWithContext(meta.transferContext(), "PUT", uploadURL.String(), uploadSrc)
+		if err != nil {
+			return err
+		}
+		for k, v := range gcsHeaders {
+			req.Header.Add(k, v)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode != http.StatusOK {
+			return util.uploadFailed(meta, resp, accessToken)
+		}
+	}
+
+	if meta.options.putCallback != nil {
+		meta.options.putCallback = &snowflakeProgressPercentage{
+			filename:        dataFile,
+			fileSize:        float64(meta.srcFileSize),
+			outputStream:    meta.options.putCallbackOutputStream,
+			showProgressBar: meta.options.showProgressBar,
+		}
+	}
+
+	meta.dstFileSize = meta.uploadSize
+	meta.resStatus = uploaded
+
+	meta.gcsFileHeaderDigest = gcsHeaders[gcsFileHeaderDigest]
+	meta.gcsFileHeaderContentLength = meta.uploadSize
+	if encryptMeta != nil {
+		if err = json.Unmarshal([]byte(gcsHeaders[gcsMetadataEncryptionDataProp]), &encryptMeta); err != nil {
+			return err
+		}
+	}
+	meta.gcsFileHeaderEncryptionMeta = encryptMeta
+	return nil
+}
+
+// uploadFailed sets the result status of the upload that failed with the given response
+// and returns the error.
+func (util *snowflakeGcsClient) uploadFailed(meta *fileMetadata, resp *http.Response, accessToken string) error {
+	if resp.StatusCode == 403 || resp.StatusCode == 408 || resp.StatusCode == 429 || resp.StatusCode == 500 || resp.StatusCode == 503 {
+		meta.lastError = fmt.Errorf(resp.Status)
+		meta.resStatus = needRetry
+	} else if accessToken == "" && resp.StatusCode == 400 && meta.lastError == nil {
+		meta.lastError = fmt.Errorf(resp.Status)
+		meta.resStatus = renewPresignedURL
+	} else if accessToken != "" && util.isTokenExpired(resp) {
+		meta.lastError = fmt.Errorf(resp.Status)
+		meta.resStatus = renewToken
+	} else {
+		meta.lastError = fmt.Errorf(resp.Status)
+	}
+	return meta.lastError
+}
+
+// uploadFileResumable uploads the file in chunks of gcsResumableChunkSize bytes with the GCS resumable
+// upload protocol. When a chunk fails, the offset persisted by GCS is queried and the upload resumes
+// from there instead of starting over. The upload gives up after defaultMaxRetry failures in a row.
+func (util *snowflakeGcsClient) uploadFileResumable(
+	dataFile string,
+	meta *fileMetadata,
+	client gcsAPI,
+	uploadURL *url.URL,
+	gcsHeaders map[string]string) error {
+	var src io.ReaderAt
 	if meta.srcStream != nil {
-		uploadSrc = meta.srcStream
+		stream := meta.srcStream
 		if meta.realSrcStream != nil {
-			uploadSrc = meta.realSrcStream
+			stream = meta.realSrcStream
 		}
+		src = bytes.NewReader(stream.Bytes())
 	} else {
-		uploadSrc, err = os.Open(dataFile)
+		f, err := os.Open(dataFile)
 		if err != nil {
 			return err
 		}
+		defer f.Close()
+		src = f
 	}
+	accessToken := strings.TrimPrefix(gcsHeaders["Authorization"], "Bearer ")
 
-	req, err := http.NewRequest("PUT", uploadURL.String(), uploadSrc)
+	req, err := http.NewRequestWithContext(meta.transferContext(), "POST", uploadURL.String(), nil)
 	if err != nil {
 		return err
 	}
 	for k, v := range gcsHeaders {
 		req.Header.Add(k, v)
 	}
-	var client gcsAPI
-	client = &http.Client{}
-	// for testing only
-	if meta.mockGcsClient != nil {
-		client = meta.mockGcsClient
-	}
+	req.Header.Set(gcsHeaderResumable, "start")
 	resp, err := client.Do(req)
 	if err != nil {
 		return err
 	}
-	if resp.StatusCode != http.StatusOK {
-		if resp.StatusCode == 403 || resp.StatusCode == 408 || resp.StatusCode == 429 || resp.StatusCode == 500 || resp.StatusCode == 503 {
-			meta.lastError = fmt.Errorf(resp.Status)
-			meta.resStatus = needRetry
-		} else if accessToken == "" && resp.StatusCode == 400 && meta.lastError == nil {
-			meta.lastError = fmt.Errorf(resp.Status)
-			meta.resStatus = renewPresignedURL
-		} else if accessToken != "" && util.isTokenExpired(resp) {
+	closeResponseBody(resp)
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return util.uploadFailed(meta, resp, accessToken)
+	}
+	sessionURL := resp.Header.Get("Location")
+	if sessionURL == "" {
+		meta.lastError = fmt.Errorf("no resumable upload session returned for %v", meta.dstFileName)
+		return meta.lastError
+	}
+	logger.Debugf("started resumable upload of %v", meta.dstFileName)
+
+	size := meta.uploadSize
+	var offset int64
+	failures := 0
+	for {
+		if err = meta.transferContext().Err(); err != nil {
+			util.abortResumableUpload(client, sessionURL, gcsHeaders)
+			meta.lastError = err
+			meta.resStatus = errStatus
+			return err
+		}
+		end := offset + gcsResumableChunkSize
+		if end > size {
+			end = size
+		}
+		req, err = http.NewRequestWithContext(meta.transferContext(), "PUT", sessionURL, io.NewSectionReader(src, offset, end-offset))
+		if err != nil {
+			return err
+		}
+		req.ContentLength = end - offset
+		req.Header.Set("Authorization", gcsHeaders["Authorization"])
+		req.Header.Set("Content-Range", fmt.Sprintf("bytes %v-%v/%v", offset, end-1, size))
+		resp, err = client.Do(req)
+		if err == nil {
+			closeResponseBody(resp)
+			switch resp.StatusCode {
+			case http.StatusOK, http.StatusCreated:
+				return nil
+			case http.StatusPermanentRedirect:
+				// the chunk is persisted, GCS expects the next one
+				offset = gcsResumeOffset(resp)
+				failures = 0
+				continue
+			}
+			if resp.StatusCode < 500 && resp.StatusCode != 408 && resp.StatusCode != 429 {
+				return util.uploadFailed(meta, resp, accessToken)
+			}
 			meta.lastError = fmt.Errorf(resp.Status)
-			meta.resStatus = renewToken
+		} else if meta.transferContext().Err() != nil {
+			// aborted at the beginning of the next iteration
+			continue
 		} else {
-			meta.lastError = fmt.Errorf(resp.Status)
+			meta.lastError = err
+		}
+		failures++
+		if failures >= defaultMaxRetry {
+			meta.resStatus = needRetry
+			return meta.lastError
+		}
+		logger.Debugf("chunk %v-%v of %v failed, querying the upload status. err: %v",
+			offset, end-1, meta.dstFileName, meta.lastError)
+		var done bool
+		if offset, done, err = util.queryResumableUpload(client, sessionURL, gcsHeaders, size); err != nil {
+			meta.lastError = err
+			meta.resStatus = needRetry
+			return err
+		}
+		if done {
+			return nil
 		}
-		return meta.lastError
 	}
+}
 
-	if meta.options.putCallback != nil {
-		meta.options.putCallback = &snowflakeProgressPercentage{
-			filename:        dataFile,
-			fileSize:        float64(meta.srcFileSize),
-			outputStream:    meta.options.putCallbackOutputStream,
-			showProgressBar: meta.options.showProgressBar,
-		}
+// queryResumableUpload returns the offset from which the resumable upload continues,
+// or true if GCS has already received the whole file.
+func (util *snowflakeGcsClient) queryResumableUpload(
+	client gcsAPI,
+	sessionURL string,
+	gcsHeaders map[string]string,
+	size int64) (int64, bool, error) {
+	req, err := http.NewRequest("PUT", sessionURL, nil)
+	if err != nil {
+		return 0, false, err
+	}
+	req.Header.Set("Authorization", gcsHeaders["Authorization"])
+	req.Header.Set("Content-Range", fmt.Sprintf("bytes */%v", size))
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, false, err
+	}
+	closeResponseBody(resp)
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated:
+		return size, true, nil
+	case http.StatusPermanentRedirect:
+		return gcsResumeOffset(resp), false, nil
 	}
+	return 0, false, fmt.Errorf("failed to query the resumable upload status: %v", resp.Status)
+}
 
-	meta.dstFileSize = meta.uploadSize
-	meta.resStatus = uploaded
+// gcsHTTPClient returns the client of the GCS requests, or a default one if it is nil.
+func gcsHTTPClient(client *http.Client) gcsAPI {
+	if client == nil {
+		return &http.Client{}
+	}
+	return client
+}
 
-	meta.gcsFileHeaderDigest = gcsHeaders[gcsFileHeaderDigest]
-	meta.gcsFileHeaderContentLength = meta.uploadSize
-	if err = json.Unmarshal([]byte(gcsHeaders[gcsMetadataEncryptionDataProp]), &encryptMeta); err != nil {
-		return err
+// abortResumableUpload cancels the resumable upload session, so that GCS discards the chunks
+// persisted so far. The context of the command is done, so the request is sent without it.
+func (util *snowflakeGcsClient) abortResumableUpload(
+	client gcsAPI,
+	sessionURL string,
+	gcsHeaders map[string]string) {
+	req, err := http.NewRequest("DELETE", sessionURL, nil)
+	if err != nil {
+		return
 	}
-	meta.gcsFileHeaderEncryptionMeta = encryptMeta
-	return nil
+	req.Header.Set("Authorization", gcsHeaders["Authorization"])
+	resp, err := client.Do(req)
+	if err != nil {
+		logger.Warnf("failed to cancel the resumable upload session: %v", err)
+		return
+	}
+	closeResponseBody(resp)
+	logger.Debugf("canceled the resumable upload session. HTTP: %v", resp.StatusCode)
+}
+
+// gcsResumeOffset returns the offset following the last byte persisted by GCS,
+// as reported by the Range header, e.g. "bytes=0-262143".
+func gcsResumeOffset(resp *http.Response) int64 {
+	rng := resp.Header.Get("Range")
+	idx := strings.LastIndex(rng, "-")
+	if idx < 0 {
+		return 0
+	}
+	last, err := strconv.ParseInt(rng[idx+1:], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return last + 1
+}
+
+func closeResponseBody(resp *http.Response) {
+	if resp.Body != nil {
+		resp.Body.Close()
+	}
+}
+
+// isGcsServerSideEncrypted returns true only if the stage explicitly states
+// that files are not encrypted on the client side, i.e. GCS server side encryption is used.
+func isGcsServerSideEncrypted(info *execResponseStageInfo) bool {
+	return info != nil && cloudType(info.LocationType) == gcsClient &&
+		info.IsClientSideEncrypted != nil && !*info.IsClientSideEncrypted
 }
 
 // cloudUtil implementation
@@ -277,7 +493,7 @@ func (util *snowflakeGcsClient) nativeDownloadFile(
 		}
 	}
 
-	req, err := http.NewRequest("GET", downloadURL.String(), nil)
+	req, err := http.NewRequestWithContext(meta.transferContext(), "GET", downloadURL.String(), nil)
 	if err != nil {
 		return err
 	}
@@ -285,7 +501,7 @@ func (util *snowflakeGcsClient) nativeDownloadFile(
 		req.Header.Add(k, v)
 	}
 	var client gcsAPI
-	client = &http.Client{}
+	client = gcsHTTPClient(meta.storageHTTPClient(true))
 	// for testing only
 	if meta.mockGcsClient != nil {
 		client = meta.mockGcsClient
@@ -4,12 +4,16 @@ package gosnowflake
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"os"
 	"path"
+	"strings"
 	"testing"
 )
 
@@ -961,3 +965,249 @@ func TestDownloadFileWithBadRequest(t *testing.T) {
 			renewPresignedURL, downloadMeta.resStatus)
 	}
 }
+
+func TestUploadFileToGcsWithServerSideEncryption(t *testing.T) {
+	isClientSideEncrypted := false
+	info := execResponseStageInfo{
+		Location:              "gcs-blob/storage/users/456/",
+		LocationType:          "GCS",
+		IsClientSideEncrypted: &isClientSideEncrypted,
+	}
+	encMat := snowflakeFileEncryption{
+		QueryStageMasterKey: "abCdEFO0upIT36dAxGsa0w==",
+		QueryID:             "01abc874-0406-1bf0-0000-53b10668e056",
+		SMKID:               92019681909886,
+	}
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Error(err)
+	}
+
+	gcsCli, err := new(snowflakeGcsClient).createClient(&info, false)
+	if err != nil {
+		t.Error(err)
+	}
+	uploadMeta := fileMetadata{
+		name:               "data1.txt.gz",
+		stageLocationType:  "GCS",
+		noSleepingTime:     true,
+		parallel:           int64(100),
+		client:             gcsCli,
+		sha256Digest:       "123456789abcdef",
+		stageInfo:          &info,
+		dstFileName:        "data1.txt.gz",
+		srcFileName:        path.Join(dir, "/test_data/put_get_1.txt"),
+		overwrite:          true,
+		dstCompressionType: compressionTypes["GZIP"],
+		encryptionMaterial: &encMat,
+		options: &SnowflakeFileTransferOptions{
+			MultiPartThreshold: dataSizeThreshold,
+		},
+		mockGcsClient: &clientMock{
+			DoFunc: func(req *http.Request) (*http.Response, error) {
+				if v := req.Header.Get(gcsMetadataEncryptionDataProp); v != "" {
+					t.Errorf("client side encryption header should not be set, got: %v", v)
+				}
+				if v := req.Header.Get(gcsMetadataMatdescKey); v != "" {
+					t.Errorf("matdesc header should not be set, got: %v", v)
+				}
+				return &http.Response{
+					Status:     "200 OK",
+					StatusCode: 200,
+				}, nil
+			},
+		},
+	}
+
+	uploadMeta.realSrcFileName = uploadMeta.srcFileName
+	fi, err := os.Stat(uploadMeta.srcFileName)
+	if err != nil {
+		t.Error(err)
+	}
+	uploadMeta.uploadSize = fi.Size()
+
+	if err = new(remoteStorageUtil).uploadOneFile(&uploadMeta); err != nil {
+		t.Fatal(err)
+	}
+	if uploadMeta.resStatus != uploaded {
+		t.Fatalf("expected %v result status, got: %v", uploaded, uploadMeta.resStatus)
+	}
+}
+
+func TestIsGcsServerSideEncrypted(t *testing.T) {
+	enabled := true
+	disabled := false
+	testcases := []struct {
+		info     *execResponseStageInfo
+		expected bool
+	}{
+		{nil, false},
+		{&execResponseStageInfo{LocationType: "GCS"}, false},
+		{&execResponseStageInfo{LocationType: "GCS", IsClientSideEncrypted: &enabled}, false},
+		{&execResponseStageInfo{LocationType: "GCS", IsClientSideEncrypted: &disabled}, true},
+		{&execResponseStageInfo{LocationType: "S3", IsClientSideEncrypted: &disabled}, false},
+	}
+	for _, test := range testcases {
+		if actual := isGcsServerSideEncrypted(test.info); actual != test.expected {
+			t.Errorf("expected %v for %+v, got: %v", test.expected, test.info, actual)
+		}
+	}
+}
+
+func TestUploadFileWithGcsResumableUploadResumesFailedChunk(t *testing.T) {
+	origChunkSize := gcsResumableChunkSize
+	gcsResumableChunkSize = 4
+	defer func() { gcsResumableChunkSize = origChunkSize }()
+
+	content := []byte("0123456789")
+	dataFile := path.Join(t.TempDir(), "data.csv")
+	if err := os.WriteFile(dataFile, content, readWriteFileMode); err != nil {
+		t.Fatal(err)
+	}
+	info := execResponseStageInfo{
+		Location:     "gcs-blob/storage/users/456/",
+		LocationType: "GCS",
+		Creds: execResponseCredentials{
+			GcsAccessToken: "test-token-124456577",
+		},
+	}
+	gcsCli, err := new(snowflakeGcsClient).createClient(&info, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ranges []string
+	received := make([]byte, len(content))
+	failed := false
+	uploadMeta := fileMetadata{
+		name:              "data.csv",
+		stageLocationType: "GCS",
+		noSleepingTime:    true,
+		client:            gcsCli,
+		sha256Digest:      "123456789abcdef",
+		stageInfo:         &info,
+		dstFileName:       "data.csv",
+		srcFileName:       dataFile,
+		uploadSize:        int64(len(content)),
+		options:           &SnowflakeFileTransferOptions{},
+		mockGcsClient: &clientMock{
+			DoFunc: func(req *http.Request) (*http.Response, error) {
+				if req.Header.Get("Authorization") != "Bearer test-token-124456577" {
+					t.Errorf("missing access token")
+				}
+				if req.Method == "POST" {
+					if req.Header.Get(gcsHeaderResumable) != "start" || req.Header.Get(gcsMetadataSfcDigest) != "123456789abcdef" {
+						t.Errorf("unexpected initiation headers: %v", req.Header)
+					}
+					return &http.Response{StatusCode: http.StatusOK, Header: http.Header{"Location": {"https://session"}}}, nil
+				}
+				contentRange := req.Header.Get("Content-Range")
+				if contentRange == "bytes */10" {
+					return &http.Response{StatusCode: http.StatusPermanentRedirect, Header: http.Header{"Range": {"bytes=0-3"}}}, nil
+				}
+				ranges = append(ranges, contentRange)
+				if contentRange == "bytes 4-7/10" && !failed {
+					failed = true
+					return &http.Response{Status: "503 Service Unavailable", StatusCode: http.StatusServiceUnavailable}, nil
+				}
+				var first, last, total int
+				if _, err := fmt.Sscanf(contentRange, "bytes %d-%d/%d", &first, &last, &total); err != nil {
+					t.Fatal(err)
+				}
+				body, err := io.ReadAll(req.Body)
+				if err != nil {
+					t.Fatal(err)
+				}
+				copy(received[first:], body)
+				if last == total-1 {
+					return &http.Response{StatusCode: http.StatusOK}, nil
+				}
+				return &http.Response{StatusCode: http.StatusPermanentRedirect,
+					Header: http.Header{"Range": {fmt.Sprintf("bytes=0-%v", last)}}}, nil
+			},
+		},
+	}
+
+	if err = new(snowflakeGcsClient).uploadFile(dataFile, &uploadMeta, nil, 1, 1); err != nil {
+		t.Fatal(err)
+	}
+	if uploadMeta.resStatus != uploaded {
+		t.Fatalf("expected %v result status, got: %v", uploaded, uploadMeta.resStatus)
+	}
+	expected := []string{"bytes 0-3/10", "bytes 4-7/10", "bytes 4-7/10", "bytes 8-9/10"}
+	if strings.Join(ranges, ",") != strings.Join(expected, ",") {
+		t.Fatalf("unexpected chunks. expected: %v, got: %v", expected, ranges)
+	}
+	if !bytes.Equal(received, content) {
+		t.Fatalf("unexpected uploaded content: %s", received)
+	}
+}
+
+func TestUploadFileWithGcsResumableUploadAbortedOnCancel(t *testing.T) {
+	origChunkSize := gcsResumableChunkSize
+	gcsResumableChunkSize = 4
+	defer func() { gcsResumableChunkSize = origChunkSize }()
+
+	content := []byte("0123456789")
+	dataFile := path.Join(t.TempDir(), "data.csv")
+	if err := os.WriteFile(dataFile, content, readWriteFileMode); err != nil {
+		t.Fatal(err)
+	}
+	info := execResponseStageInfo{
+		Location:     "gcs-blob/storage/users/456/",
+		LocationType: "GCS",
+		Creds: execResponseCredentials{
+			GcsAccessToken: "test-token-124456577",
+		},
+	}
+	gcsCli, err := new(snowflakeGcsClient).createClient(&info, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var requests []string
+	uploadMeta := fileMetadata{
+		name:              "data.csv",
+		ctx:               ctx,
+		stageLocationType: "GCS",
+		noSleepingTime:    true,
+		client:            gcsCli,
+		sha256Digest:      "123456789abcdef",
+		stageInfo:         &info,
+		dstFileName:       "data.csv",
+		srcFileName:       dataFile,
+		uploadSize:        int64(len(content)),
+		options:           &SnowflakeFileTransferOptions{},
+		mockGcsClient: &clientMock{
+			DoFunc: func(req *http.Request) (*http.Response, error) {
+				requests = append(requests, req.Method+" "+req.Header.Get("Content-Range"))
+				switch req.Method {
+				case "POST":
+					return &http.Response{StatusCode: http.StatusOK, Header: http.Header{"Location": {"https://session"}}}, nil
+				case "DELETE":
+					if req.URL.String() != "https://session" {
+						t.Errorf("unexpected session URL: %v", req.URL)
+					}
+					return &http.Response{StatusCode: 499}, nil
+				}
+				// the command is canceled while the first chunk is uploaded
+				cancel()
+				return &http.Response{StatusCode: http.StatusPermanentRedirect, Header: http.Header{"Range": {"bytes=0-3"}}}, nil
+			},
+		},
+	}
+
+	err = new(snowflakeGcsClient).uploadFile(dataFile, &uploadMeta, nil, 1, 1)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context canceled, got: %v", err)
+	}
+	expected := []string{"POST ", "PUT bytes 0-3/10", "DELETE "}
+	if strings.Join(requests, ",") != strings.Join(expected, ",") {
+		t.Fatalf("unexpected requests. expected: %v, got: %v", expected, requests)
+	}
+	if uploadMeta.resStatus != errStatus {
+		t.Fatalf("expected %v result status, got: %v", errStatus, uploadMeta.resStatus)
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2023 Snowflake Computing Inc. All rights reserved.
+
+package gosnowflake
+
+import (
+	"container/list"
+	"sync"
+)
+
+type getCacheEntry struct {
+	key  string
+	etag string
+	data []byte
+}
+
+// getCache is an in-memory LRU cache of the files downloaded by GET keyed by the stage path.
+// An entry is only served while the ETag of the file on the stage is unchanged.
+// The total size of the cached files never exceeds capacity bytes.
+type getCache struct {
+	mu       sync.Mutex
+	capacity int64
+	size     int64
+	lru      *list.List
+	entries  map[string]*list.Element
+}
+
+func newGetCache(capacity int64) *getCache {
+	return &getCache{
+		capacity: capacity,
+		lru:      list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+// get returns the cached file if its ETag matches. A stale entry is removed.
+func (gc *getCache) get(key string, etag string) ([]byte, bool) {
+	gc.mu.Lock()
+	defer gc.mu.Unlock()
+	elem, ok := gc.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*getCacheEntry)
+	if entry.etag != etag {
+		logger.Debugf("ETag of %v changed, invalidating the cached file", key)
+		gc.remove(elem)
+		return nil, false
+	}
+	gc.lru.MoveToFront(elem)
+	return entry.data, true
+}
+
+// put caches the file, evicting the least recently used files if needed.
+// Files larger than the capacity are not cached.
+func (gc *getCache) put(key string, etag string, data []byte) {
+	if int64(len(data)) > gc.capacity {
+		return
+	}
+	gc.mu.Lock()
+	defer gc.mu.Unlock()
+	if elem, ok := gc.entries[key]; ok {
+		gc.remove(elem)
+	}
+	for gc.size+int64(len(data)) > gc.capacity {
+		gc.remove(gc.lru.Back())
+	}
+	gc.entries[key] = gc.lru.PushFront(&getCacheEntry{key: key, etag: etag, data: data})
+	gc.size += int64(len(data))
+}
+
+func (gc *getCache) remove(elem *list.Element) {
+	entry := gc.lru.Remove(elem).(*getCacheEntry)
+	delete(gc.entries, entry.key)
+	gc.size -= int64(len(entry.data))
+}
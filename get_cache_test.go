@@ -0,0 +1,44 @@
+// Copyright (c) 2023 Snowflake Computing Inc. All rights reserved.
+
+package gosnowflake
+
+import (
+	"testing"
+)
+
+func TestGetCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	gc := newGetCache(10)
+	gc.put("a", "1", []byte("aaaa"))
+	gc.put("b", "1", []byte("bbbb"))
+	if _, ok := gc.get("a", "1"); !ok {
+		t.Fatal("a should be cached")
+	}
+	gc.put("c", "1", []byte("cccc"))
+	if _, ok := gc.get("b", "1"); ok {
+		t.Fatal("b should have been evicted")
+	}
+	if _, ok := gc.get("a", "1"); !ok {
+		t.Fatal("a should still be cached")
+	}
+	if gc.size != 8 {
+		t.Fatalf("unexpected size: %v", gc.size)
+	}
+	gc.put("d", "1", []byte("larger than the capacity"))
+	if _, ok := gc.get("d", "1"); ok {
+		t.Fatal("a file larger than the capacity should not be cached")
+	}
+}
+
+func TestGetCacheInvalidatesOnETagChange(t *testing.T) {
+	gc := newGetCache(10)
+	gc.put("a", "1", []byte("aaaa"))
+	if _, ok := gc.get("a", "2"); ok {
+		t.Fatal("should not serve a file with another ETag")
+	}
+	if _, ok := gc.get("a", "1"); ok {
+		t.Fatal("the stale file should have been removed")
+	}
+	if gc.size != 0 {
+		t.Fatalf("unexpected size: %v", gc.size)
+	}
+}
@@ -15,15 +15,22 @@ import (
 const (
 	// One hour interval should be good enough to renew tokens for four hours master token validity
 	heartBeatInterval = 3600 * time.Second
+	// Lower bound of CLIENT_SESSION_KEEP_ALIVE_HEARTBEAT_FREQUENCY
+	minHeartBeatInterval = 900 * time.Second
 )
 
 type heartbeat struct {
 	restful      *snowflakeRestful
 	shutdownChan chan bool
+	interval     time.Duration
 }
 
 func (hc *heartbeat) run() {
-	hbTicker := time.NewTicker(heartBeatInterval)
+	interval := hc.interval
+	if interval <= 0 {
+		interval = heartBeatInterval
+	}
+	hbTicker := time.NewTicker(interval)
 	defer hbTicker.Stop()
 	for {
 		select {
@@ -76,7 +83,7 @@ func (hc *heartbeat) heartbeatMain() error {
 			return err
 		}
 		if respd.Code == sessionExpiredCode {
-			err = hc.restful.renewExpiredSessionToken(context.Background(), timeout, token)
+			err = hc.restful.renewExpiredSessionToken(withSessionRenewReason(context.Background(), SessionRenewReasonHeartbeat), timeout, token)
 			if err != nil {
 				return err
 			}
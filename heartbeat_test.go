@@ -3,7 +3,9 @@
 package gosnowflake
 
 import (
+	"context"
 	"testing"
+	"time"
 )
 
 func TestUnitPostHeartbeat(t *testing.T) {
@@ -43,3 +45,44 @@ func TestUnitPostHeartbeat(t *testing.T) {
 		}
 	})
 }
+
+func TestUnitHeartbeatOnSessionRenew(t *testing.T) {
+	var reasons []string
+	sr := &snowflakeRestful{
+		FuncPost:         postTestRenewOnTokenRequest,
+		FuncRenewSession: renewRestfulSession,
+		TokenAccessor:    getSimpleTokenAccessor(),
+		OnSessionRenew: func(_ context.Context, reason string) {
+			reasons = append(reasons, reason)
+		},
+	}
+	sr.TokenAccessor.SetTokens("oldtoken", "oldmaster", 100)
+	heartbeat := &heartbeat{
+		restful: sr,
+	}
+	if err := heartbeat.heartbeatMain(); err != nil {
+		t.Fatalf("failed to heartbeat and renew session. err: %v", err)
+	}
+	if len(reasons) != 1 || reasons[0] != SessionRenewReasonHeartbeat {
+		t.Fatalf("unexpected renewal reasons: %v", reasons)
+	}
+}
+
+func TestUnitHeartbeatInterval(t *testing.T) {
+	sc := getDefaultSnowflakeConn()
+	if interval := sc.getHeartbeatInterval(); interval != heartBeatInterval {
+		t.Fatalf("unexpected default interval: %v", interval)
+	}
+	for value, expected := range map[string]time.Duration{
+		"1800":    30 * time.Minute,
+		"60":      minHeartBeatInterval,
+		"7200":    heartBeatInterval,
+		"invalid": heartBeatInterval,
+	} {
+		v := value
+		sc.cfg.Params[sessionClientSessionKeepAliveFrequency] = &v
+		if interval := sc.getHeartbeatInterval(); interval != expected {
+			t.Fatalf("unexpected interval for %v. expected: %v, got: %v", value, expected, interval)
+		}
+	}
+}
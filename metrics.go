@@ -0,0 +1,57 @@
+// Copyright (c) 2023 Snowflake Computing Inc. All rights reserved.
+
+package gosnowflake
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// MetricsCollector receives notifications about the driver activity.
+// It can be set in Config to expose counters like the number of queries,
+// downloaded bytes or HTTP retries. Implementations must be safe for concurrent use.
+type MetricsCollector interface {
+	// QueryStarted is called before a query is sent to Snowflake.
+	QueryStarted(ctx context.Context, query string)
+	// QueryFinished is called when Snowflake responded to a query. err is nil on success.
+	QueryFinished(ctx context.Context, query string, duration time.Duration, err error)
+	// BytesDownloaded is called when the chunk downloader fetched a result chunk.
+	BytesDownloaded(bytes int64)
+	// RequestRetried is called each time an HTTP request is retried. The retry reason is the HTTP status
+	// of the failed attempt, 0 for a timeout, or a code below 100 for another transport error, e.g. 1
+	// for a connection reset, 2 for a refused connection, 3 for a TLS error, 4 for a DNS error and
+	// 5 for an unexpected EOF.
+	RequestRetried(ctx context.Context, retryCount int, retryReason int)
+}
+
+type noopMetricsCollector struct {
+}
+
+func (noopMetricsCollector) QueryStarted(context.Context, string) {}
+
+func (noopMetricsCollector) QueryFinished(context.Context, string, time.Duration, error) {}
+
+func (noopMetricsCollector) BytesDownloaded(int64) {}
+
+func (noopMetricsCollector) RequestRetried(context.Context, int, int) {}
+
+// metricsCollector returns the collector set in the config or a no-op collector.
+func (c *Config) metricsCollector() MetricsCollector {
+	if c == nil || c.MetricsCollector == nil {
+		return noopMetricsCollector{}
+	}
+	return c.MetricsCollector
+}
+
+// countingReader counts the bytes read from the wrapped reader.
+type countingReader struct {
+	reader io.Reader
+	count  int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.count += int64(n)
+	return n, err
+}
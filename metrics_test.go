@@ -0,0 +1,116 @@
+// Copyright (c) 2023 Snowflake Computing Inc. All rights reserved.
+
+package gosnowflake
+
+import (
+	"context"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testMetricsCollector struct {
+	mu              sync.Mutex
+	queriesStarted  int
+	queriesFinished int
+	queryErrors     int
+	bytesDownloaded int64
+	retries         int
+	retryReasons    []int
+}
+
+func (c *testMetricsCollector) QueryStarted(_ context.Context, _ string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.queriesStarted++
+}
+
+func (c *testMetricsCollector) QueryFinished(_ context.Context, _ string, _ time.Duration, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.queriesFinished++
+	if err != nil {
+		c.queryErrors++
+	}
+}
+
+func (c *testMetricsCollector) BytesDownloaded(bytes int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.bytesDownloaded += bytes
+}
+
+func (c *testMetricsCollector) RequestRetried(_ context.Context, _ int, retryReason int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.retries++
+	c.retryReasons = append(c.retryReasons, retryReason)
+}
+
+func TestMetricsCollectorQuery(t *testing.T) {
+	collector := &testMetricsCollector{}
+	postQueryMock := func(_ context.Context, _ *snowflakeRestful,
+		_ *url.Values, _ map[string]string, _ []byte, _ time.Duration,
+		_ UUID, _ *Config) (*execResponse, error) {
+		return &execResponse{
+			Data:    execResponseData{},
+			Message: "",
+			Code:    "0",
+			Success: true,
+		}, nil
+	}
+	sc := &snowflakeConn{
+		cfg:               &Config{Params: map[string]*string{}, MetricsCollector: collector},
+		rest:              &snowflakeRestful{FuncPostQuery: postQueryMock},
+		queryContextCache: (&queryContextCache{}).init(),
+	}
+	if _, err := sc.exec(context.Background(), "SELECT 1", false /* noResult */, false, /* isInternal */
+		false /* describeOnly */, nil); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if collector.queriesStarted != 1 || collector.queriesFinished != 1 {
+		t.Fatalf("query should be reported once. started: %v, finished: %v",
+			collector.queriesStarted, collector.queriesFinished)
+	}
+	if collector.queryErrors != 0 {
+		t.Fatalf("no query error expected, got: %v", collector.queryErrors)
+	}
+}
+
+func TestMetricsCollectorRetry(t *testing.T) {
+	collector := &testMetricsCollector{}
+	client := &fakeHTTPClient{
+		cnt:        3,
+		success:    true,
+		statusCode: 429,
+	}
+	urlPtr, err := url.Parse("https://fakeaccountretrysuccess.snowflakecomputing.com:443/queries/v1/query-request?" + requestIDKey + "=testid")
+	if err != nil {
+		t.Fatal("failed to parse the test URL")
+	}
+	_, err = newRetryHTTP(context.TODO(),
+		client,
+		emptyRequest, urlPtr, make(map[string]string), 60*time.Second, defaultTimeProvider, &Config{MetricsCollector: collector}).doPost().setBody([]byte{0}).execute()
+	if err != nil {
+		t.Fatal("failed to run retry")
+	}
+	if collector.retries != 2 {
+		t.Fatalf("expected 2 retries, got: %v", collector.retries)
+	}
+	for _, reason := range collector.retryReasons {
+		if reason != 429 {
+			t.Fatalf("unexpected retry reason: %v", reason)
+		}
+	}
+}
+
+func TestMetricsCollectorDefault(t *testing.T) {
+	var cfg *Config
+	if _, ok := cfg.metricsCollector().(noopMetricsCollector); !ok {
+		t.Fatal("no-op collector should be used when config is missing")
+	}
+	if _, ok := (&Config{}).metricsCollector().(noopMetricsCollector); !ok {
+		t.Fatal("no-op collector should be used by default")
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"net/url"
 	"strconv"
+	"time"
 )
 
 const urlQueriesResultFmt = "/queries/%s/result"
@@ -115,6 +116,12 @@ type SnowflakeQueryStatus struct {
 // SnowflakeConnection is a wrapper to snowflakeConn that exposes API functions
 type SnowflakeConnection interface {
 	GetQueryStatus(ctx context.Context, queryID string) (*SnowflakeQueryStatus, error)
+	SessionInfo() map[string]string
+	OpenResultCursor(ctx context.Context, queryID string) (*ResultCursor, error)
+	ResumeResultCursor(ctx context.Context, checkpoint ResultCursorCheckpoint) (*ResultCursor, error)
+	HTTPStatusCounts() map[int]int64
+	BytesDownloaded() int64
+	LastQueryID() string
 }
 
 // checkQueryStatus returns the status given the query ID. If successful,
@@ -130,38 +137,15 @@ func (sc *snowflakeConn) checkQueryStatus(
 	ctx context.Context,
 	qid string) (
 	*retStatus, error) {
-	headers := make(map[string]string)
-	param := make(url.Values)
-	param.Add(requestGUIDKey, NewUUID().String())
-	if tok, _, _ := sc.rest.TokenAccessor.GetTokens(); tok != "" {
-		headers[headerAuthorizationKey] = fmt.Sprintf(headerSnowflakeToken, tok)
-	}
-	resultPath := fmt.Sprintf("/monitoring/queries/%s", qid)
-	url := sc.rest.getFullURL(resultPath, &param)
-
-	res, err := sc.rest.FuncGet(ctx, sc.rest, url, headers, sc.rest.RequestTimeout)
+	queryRet, err := sc.fetchQueryStatus(ctx, qid)
 	if err != nil {
-		logger.WithContext(ctx).Errorf("failed to get response. err: %v", err)
-		return nil, err
-	}
-	defer res.Body.Close()
-	var statusResp = statusResponse{}
-	if err = json.NewDecoder(res.Body).Decode(&statusResp); err != nil {
-		logger.WithContext(ctx).Errorf("failed to decode JSON. err: %v", err)
+		if se, ok := err.(*SnowflakeError); ok {
+			return nil, se.exceptionTelemetry(sc)
+		}
 		return nil, err
 	}
-
-	if !statusResp.Success || len(statusResp.Data.Queries) == 0 {
-		logger.WithContext(ctx).Errorf("status query returned not-success or no status returned.")
-		return nil, (&SnowflakeError{
-			Number:  ErrQueryStatus,
-			Message: "status query returned not-success or no status returned. Please retry",
-		}).exceptionTelemetry(sc)
-	}
-
-	queryRet := statusResp.Data.Queries[0]
 	if queryRet.ErrorCode != "" {
-		return &queryRet, (&SnowflakeError{
+		return queryRet, (&SnowflakeError{
 			Number:         ErrQueryStatus,
 			Message:        errMsgQueryStatus,
 			MessageArgs:    []interface{}{queryRet.ErrorCode, queryRet.ErrorMessage},
@@ -173,7 +157,7 @@ func (sc *snowflakeConn) checkQueryStatus(
 	// returned errorCode is 0. Now check what is the returned status of the query.
 	qStatus := strToQueryStatus(queryRet.Status)
 	if qStatus.isError() {
-		return &queryRet, (&SnowflakeError{
+		return queryRet, (&SnowflakeError{
 			Number: ErrQueryReportedError,
 			Message: fmt.Sprintf("%s: status from server: [%s]",
 				queryRet.ErrorMessage, queryRet.Status),
@@ -183,7 +167,7 @@ func (sc *snowflakeConn) checkQueryStatus(
 	}
 
 	if qStatus.isRunning() {
-		return &queryRet, (&SnowflakeError{
+		return queryRet, (&SnowflakeError{
 			Number: ErrQueryIsRunning,
 			Message: fmt.Sprintf("%s: status from server: [%s]",
 				queryRet.ErrorMessage, queryRet.Status),
@@ -192,7 +176,107 @@ func (sc *snowflakeConn) checkQueryStatus(
 		}).exceptionTelemetry(sc)
 	}
 	//success
-	return &queryRet, nil
+	return queryRet, nil
+}
+
+// fetchQueryStatus gets the status of the query from the monitoring endpoint.
+func (sc *snowflakeConn) fetchQueryStatus(ctx context.Context, qid string) (*retStatus, error) {
+	headers := make(map[string]string)
+	param := make(url.Values)
+	param.Add(requestGUIDKey, NewUUID().String())
+	if tok, _, _ := sc.rest.TokenAccessor.GetTokens(); tok != "" {
+		headers[headerAuthorizationKey] = fmt.Sprintf(headerSnowflakeToken, tok)
+	}
+	resultPath := fmt.Sprintf("/monitoring/queries/%s", qid)
+	url := sc.rest.getFullURL(resultPath, &param)
+
+	res, err := sc.rest.FuncGet(ctx, sc.rest, url, headers, sc.rest.RequestTimeout)
+	if err != nil {
+		logger.WithContext(ctx).Errorf("failed to get response. err: %v", err)
+		return nil, err
+	}
+	defer res.Body.Close()
+	var statusResp = statusResponse{}
+	if err = json.NewDecoder(res.Body).Decode(&statusResp); err != nil {
+		logger.WithContext(ctx).Errorf("failed to decode JSON. err: %v", err)
+		return nil, err
+	}
+
+	if !statusResp.Success || len(statusResp.Data.Queries) == 0 {
+		logger.WithContext(ctx).Errorf("status query returned not-success or no status returned.")
+		return nil, &SnowflakeError{
+			Number:  ErrQueryStatus,
+			Message: "status query returned not-success or no status returned. Please retry",
+		}
+	}
+	return &statusResp.Data.Queries[0], nil
+}
+
+// QueryStatusUpdate is a progress update of an asynchronous query sent to the channel
+// passed to WithQueryStatusUpdates.
+type QueryStatusUpdate struct {
+	QueryID      string
+	State        string // status reported by the server, e.g. QUEUED, RUNNING, SUCCESS or FAILED_WITH_ERROR
+	ProducedRows int64
+	ScanBytes    int64
+	ErrorCode    string
+	ErrorMessage string
+}
+
+// Done returns true if the query is finished. No more updates follow.
+func (u QueryStatusUpdate) Done() bool {
+	return !strToQueryStatus(u.State).isRunning()
+}
+
+var (
+	// queryStatusPollInterval is the interval of polling the status of an asynchronous query
+	queryStatusPollInterval = time.Second
+	// maxQueryStatusPollErrors is the number of consecutive failures to get the status before polling stops
+	maxQueryStatusPollErrors = 10
+)
+
+// pollQueryStatus sends an update to the channel whenever the status of the query changes
+// until the query is finished or the context is done.
+func (sc *snowflakeConn) pollQueryStatus(ctx context.Context, qid string, updates chan<- QueryStatusUpdate) {
+	var last *QueryStatusUpdate
+	failures := 0
+	for {
+		status, err := sc.fetchQueryStatus(ctx, qid)
+		if err != nil {
+			failures++
+			logger.WithContext(ctx).Debugf("failed to get status of query %v: %v", qid, err)
+			if failures >= maxQueryStatusPollErrors {
+				logger.WithContext(ctx).Warnf("stopped polling status of query %v: %v", qid, err)
+				return
+			}
+		} else {
+			failures = 0
+			update := QueryStatusUpdate{
+				QueryID:      qid,
+				State:        status.Status,
+				ProducedRows: status.Stats.ProducedRows,
+				ScanBytes:    status.Stats.ScanBytes,
+				ErrorCode:    status.ErrorCode,
+				ErrorMessage: status.ErrorMessage,
+			}
+			if last == nil || update != *last {
+				select {
+				case updates <- update:
+				case <-ctx.Done():
+					return
+				}
+				last = &update
+			}
+			if update.Done() {
+				return
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(queryStatusPollInterval):
+		}
+	}
 }
 
 func (sc *snowflakeConn) getQueryResultResp(
@@ -261,6 +345,7 @@ func (sc *snowflakeConn) buildRowsForRunningQuery(
 	driver.Rows, error) {
 	rows := new(snowflakeRows)
 	rows.sc = sc
+	rows.nullToZeroValue = nullToZeroValueEnabled(ctx)
 	rows.queryID = qid
 	if err := sc.rowsForRunningQuery(ctx, qid, rows); err != nil {
 		return nil, err
@@ -28,6 +28,19 @@ func getChildResults(IDs string, types string) []childResult {
 	return res
 }
 
+// childQueryIDs returns the query IDs of the statements of a multi-statement query in their order.
+func childQueryIDs(data *execResponseData) []string {
+	childResults := getChildResults(data.ResultIDs, data.ResultTypes)
+	if childResults == nil {
+		return nil
+	}
+	ids := make([]string, len(childResults))
+	for i, child := range childResults {
+		ids[i] = child.id
+	}
+	return ids
+}
+
 func (sc *snowflakeConn) handleMultiExec(
 	ctx context.Context,
 	data execResponseData) (
@@ -79,6 +92,8 @@ func (sc *snowflakeConn) handleMultiExec(
 		affectedRows: updatedRows,
 		insertID:     -1,
 		queryID:      data.QueryID,
+		childIDs:     childQueryIDs(&data),
+		session:      sessionContextOf(&data),
 	}, nil
 }
 
@@ -526,6 +526,45 @@ func TestUnitHandleMultiExec(t *testing.T) {
 	})
 }
 
+func TestMultiStatementChildQueryIDs(t *testing.T) {
+	postQueryMock := func(_ context.Context, _ *snowflakeRestful,
+		_ *url.Values, _ map[string]string, _ []byte, _ time.Duration,
+		_ UUID, _ *Config) (*execResponse, error) {
+		return &execResponse{
+			Data: execResponseData{
+				QueryID:         "parent",
+				StatementTypeID: statementTypeIDMultistatement,
+				ResultIDs:       "child1,child2,child3",
+				ResultTypes:     "4096,4096,4096", // SELECT, so that the row counts are not fetched
+			},
+			Success: true,
+		}, nil
+	}
+	sc := &snowflakeConn{
+		cfg:               &Config{Params: map[string]*string{}},
+		rest:              &snowflakeRestful{FuncPostQuery: postQueryMock},
+		queryContextCache: (&queryContextCache{}).init(),
+	}
+	ctx, err := WithMultiStatement(context.Background(), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := sc.ExecContext(ctx, "SELECT 1; SELECT 2; SELECT 3", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res := result.(SnowflakeResult)
+	if res.GetQueryID() != "parent" {
+		t.Fatalf("unexpected parent query id: %v", res.GetQueryID())
+	}
+	if ids := res.ChildQueryIDs(); !reflect.DeepEqual(ids, []string{"child1", "child2", "child3"}) {
+		t.Fatalf("unexpected child query ids: %v", ids)
+	}
+	if ids := (&snowflakeResult{queryID: "single"}).ChildQueryIDs(); ids != nil {
+		t.Fatalf("a single statement should have no child query ids, got: %v", ids)
+	}
+}
+
 func TestUnitHandleMultiQuery(t *testing.T) {
 	runSnowflakeConnTest(t, func(sct *SCTest) {
 		data := execResponseData{
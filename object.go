@@ -0,0 +1,320 @@
+// Copyright (c) 2023 Snowflake Computing Inc. All rights reserved.
+
+package gosnowflake
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// objectTimeFormat is used for time.Time fields of OBJECT values. The offset is included so
+// that the value is scanned back as the same instant.
+const objectTimeFormat = time.RFC3339Nano
+
+var objectTimeGoType = reflect.TypeOf(time.Time{})
+
+var (
+	objectScannerGoType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	objectValuerGoType  = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+)
+
+// objectSchemas maps the registered struct types to their *objectSchema.
+var objectSchemas sync.Map
+
+// objectField is a struct field mapped to a key of an OBJECT value.
+type objectField struct {
+	name  string
+	index int
+	typ   reflect.Type
+}
+
+// objectSchema describes how a registered struct type is converted to and from an OBJECT value.
+type objectSchema struct {
+	typ    reflect.Type
+	fields []objectField
+}
+
+// RegisterObjectType registers the struct type of v, a struct or a pointer to a struct, so that its
+// values can be bound as OBJECT values and OBJECT columns can be scanned into it with ScanObject.
+//
+// Each exported field is mapped to the OBJECT key given by its `sf` tag, or to the field name if the
+// tag is missing. Fields tagged with `sf:"-"` are skipped. The supported field types are bool, signed
+// and unsigned integers, floats, string, time.Time and pointers to them. time.Time fields are stored
+// as RFC 3339 strings with nanoseconds. Custom types implementing both sql.Scanner (on the pointer)
+// and driver.Valuer, e.g. wrappers of time.Time, are supported too: they are bound with the value
+// returned by Value and scanned by passing the decoded value, a time.Time for a timestamp, to Scan.
+func RegisterObjectType(v interface{}) error {
+	t := reflect.TypeOf(v)
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct || t == objectTimeGoType {
+		return errObjectField(fmt.Sprint(t), "", "only struct types can be registered")
+	}
+	schema := &objectSchema{typ: t}
+	names := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name := f.Tag.Get("sf")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		if !supportedObjectFieldType(f.Type) {
+			return errObjectField(t.String(), name, fmt.Sprintf("unsupported type %v", f.Type))
+		}
+		if names[name] {
+			return errObjectField(t.String(), name, "duplicate key")
+		}
+		names[name] = true
+		schema.fields = append(schema.fields, objectField{name: name, index: i, typ: f.Type})
+	}
+	objectSchemas.Store(t, schema)
+	return nil
+}
+
+func supportedObjectFieldType(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == objectTimeGoType || isObjectScannerType(t) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Bool, reflect.String, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+// isObjectScannerType checks if t is a custom type converted with its own sql.Scanner and driver.Valuer.
+func isObjectScannerType(t reflect.Type) bool {
+	return reflect.PtrTo(t).Implements(objectScannerGoType) && t.Implements(objectValuerGoType)
+}
+
+// registeredObjectSchema returns the schema of v if it is a registered struct or a non-nil pointer to one.
+func registeredObjectSchema(v interface{}) *objectSchema {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return nil
+	}
+	if t.Kind() == reflect.Ptr {
+		if reflect.ValueOf(v).IsNil() {
+			return nil
+		}
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	if schema, ok := objectSchemas.Load(t); ok {
+		return schema.(*objectSchema)
+	}
+	return nil
+}
+
+// toJSON validates the fields of v and serializes them as an OBJECT value.
+func (s *objectSchema) toJSON(v interface{}) (*string, error) {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	object := make(map[string]interface{}, len(s.fields))
+	for _, f := range s.fields {
+		fv := rv.Field(f.index)
+		if fv.Kind() == reflect.Ptr {
+			if fv.IsNil() {
+				object[f.name] = nil
+				continue
+			}
+			fv = fv.Elem()
+		}
+		switch {
+		case fv.Type() == objectTimeGoType:
+			object[f.name] = fv.Interface().(time.Time).Format(objectTimeFormat)
+		case isObjectScannerType(fv.Type()):
+			value, err := fv.Interface().(driver.Valuer).Value()
+			if err != nil {
+				return nil, errObjectField(s.typ.String(), f.name, err.Error())
+			}
+			if t, ok := value.(time.Time); ok {
+				value = t.Format(objectTimeFormat)
+			}
+			object[f.name] = value
+		case fv.Kind() == reflect.Float32 || fv.Kind() == reflect.Float64:
+			if math.IsNaN(fv.Float()) || math.IsInf(fv.Float(), 0) {
+				return nil, errObjectField(s.typ.String(), f.name, fmt.Sprintf("%v cannot be bound in an OBJECT", fv.Float()))
+			}
+			object[f.name] = fv.Interface()
+		default:
+			object[f.name] = fv.Interface()
+		}
+	}
+	b, err := json.Marshal(object)
+	if err != nil {
+		return nil, err
+	}
+	str := string(b)
+	return &str, nil
+}
+
+// fromJSON sets the fields of rv from the OBJECT value, checking that each value matches the field type.
+// Keys without a field are ignored and fields without a key are left unchanged.
+func (s *objectSchema) fromJSON(data []byte, rv reflect.Value) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var object map[string]interface{}
+	if err := decoder.Decode(&object); err != nil {
+		return errObjectField(s.typ.String(), "", err.Error())
+	}
+	for _, f := range s.fields {
+		value, ok := object[f.name]
+		if !ok {
+			continue
+		}
+		fv := rv.Field(f.index)
+		if value == nil {
+			fv.Set(reflect.Zero(f.typ))
+			continue
+		}
+		if fv.Kind() == reflect.Ptr {
+			fv.Set(reflect.New(f.typ.Elem()))
+			fv = fv.Elem()
+		}
+		if err := setObjectField(fv, value); err != nil {
+			return errObjectField(s.typ.String(), f.name, err.Error())
+		}
+	}
+	return nil
+}
+
+func setObjectField(fv reflect.Value, value interface{}) error {
+	mismatch := fmt.Errorf("cannot convert %v (%T) to %v", value, value, fv.Type())
+	if isObjectScannerType(fv.Type()) {
+		return fv.Addr().Interface().(sql.Scanner).Scan(objectScannerValue(value))
+	}
+	if fv.Type() == objectTimeGoType {
+		str, ok := value.(string)
+		if !ok {
+			return mismatch
+		}
+		t, err := time.Parse(objectTimeFormat, str)
+		if err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(t))
+		return nil
+	}
+	switch fv.Kind() {
+	case reflect.Bool:
+		b, ok := value.(bool)
+		if !ok {
+			return mismatch
+		}
+		fv.SetBool(b)
+	case reflect.String:
+		str, ok := value.(string)
+		if !ok {
+			return mismatch
+		}
+		fv.SetString(str)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, ok := value.(json.Number)
+		if !ok {
+			return mismatch
+		}
+		i, err := n.Int64()
+		if err != nil || fv.OverflowInt(i) {
+			return mismatch
+		}
+		fv.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, ok := value.(json.Number)
+		if !ok {
+			return mismatch
+		}
+		u, err := strconv.ParseUint(n.String(), 10, 64)
+		if err != nil || fv.OverflowUint(u) {
+			return mismatch
+		}
+		fv.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		n, ok := value.(json.Number)
+		if !ok {
+			return mismatch
+		}
+		f, err := n.Float64()
+		if err != nil || fv.OverflowFloat(f) {
+			return mismatch
+		}
+		fv.SetFloat(f)
+	default:
+		return mismatch
+	}
+	return nil
+}
+
+// objectScannerValue converts a decoded OBJECT value to the value passed to a custom sql.Scanner,
+// e.g. a time.Time for a string in the format of the time.Time fields.
+func objectScannerValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		if t, err := time.Parse(objectTimeFormat, v); err == nil {
+			return t
+		}
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+	}
+	return value
+}
+
+// ScanObject returns a sql.Scanner scanning an OBJECT column into dest, a pointer to a struct
+// registered with RegisterObjectType. A NULL value leaves dest unchanged.
+//
+//	var p Person
+//	err := rows.Scan(sf.ScanObject(&p))
+func ScanObject(dest interface{}) sql.Scanner {
+	return &objectScanner{dest: dest}
+}
+
+type objectScanner struct {
+	dest interface{}
+}
+
+func (s *objectScanner) Scan(src interface{}) error {
+	rv := reflect.ValueOf(s.dest)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errObjectField(fmt.Sprint(reflect.TypeOf(s.dest)), "", "the destination must be a non-nil pointer to a struct")
+	}
+	schema := registeredObjectSchema(s.dest)
+	if schema == nil {
+		return errObjectTypeNotRegistered(rv.Elem().Type().String())
+	}
+	switch v := src.(type) {
+	case nil:
+		return nil
+	case string:
+		return schema.fromJSON([]byte(v), rv.Elem())
+	case []byte:
+		return schema.fromJSON(v, rv.Elem())
+	default:
+		return errObjectField(schema.typ.String(), "", fmt.Sprintf("cannot scan %T into an OBJECT type", src))
+	}
+}
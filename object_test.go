@@ -0,0 +1,208 @@
+// Copyright (c) 2023 Snowflake Computing Inc. All rights reserved.
+
+package gosnowflake
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+)
+
+type objectTestPerson struct {
+	ID        int64     `sf:"id"`
+	Name      string    `sf:"name"`
+	CreatedAt time.Time `sf:"created_at"`
+	Age       *int32    `sf:"age"`
+	Ignored   string    `sf:"-"`
+	internal  string
+}
+
+func TestObjectRoundTrip(t *testing.T) {
+	if err := RegisterObjectType(objectTestPerson{}); err != nil {
+		t.Fatal(err)
+	}
+	var bound execBindParameter
+	postQueryMock := func(_ context.Context, _ *snowflakeRestful, _ *url.Values,
+		_ map[string]string, body []byte, _ time.Duration, _ UUID, _ *Config) (*execResponse, error) {
+		var req execRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Fatal(err)
+		}
+		if b, ok := req.Bindings["1"]; ok {
+			bound = b
+		}
+		value, _ := bound.Value.(string)
+		return &execResponse{
+			Data: execResponseData{
+				QueryID:           "1",
+				RowType:           []execResponseRowType{{Name: "V", Type: "object"}},
+				RowSet:            [][]*string{{&value}},
+				Total:             1,
+				Returned:          1,
+				QueryResultFormat: "json",
+			},
+			Success: true,
+		}, nil
+	}
+	sc := getPooledSnowflakeConn()
+	sc.rest.FuncPostQuery = postQueryMock
+	db := sql.OpenDB(showConnectorMock{sc: sc})
+	defer db.Close()
+
+	age := int32(42)
+	expected := objectTestPerson{
+		ID:        7,
+		Name:      "Alice",
+		CreatedAt: time.Date(2023, 5, 1, 12, 30, 45, 123456789, time.FixedZone("", 2*3600)),
+		Age:       &age,
+		Ignored:   "not bound",
+		internal:  "not bound",
+	}
+	if _, err := db.Exec("INSERT INTO t SELECT ?", &expected); err != nil {
+		t.Fatal(err)
+	}
+	if bound.Type != "OBJECT" {
+		t.Fatalf("expected OBJECT bind type, got: %v", bound.Type)
+	}
+	var object map[string]interface{}
+	if err := json.Unmarshal([]byte(bound.Value.(string)), &object); err != nil {
+		t.Fatal(err)
+	}
+	if len(object) != 4 || object["id"] != float64(7) || object["name"] != "Alice" ||
+		object["created_at"] != "2023-05-01T12:30:45.123456789+02:00" || object["age"] != float64(42) {
+		t.Fatalf("unexpected OBJECT value: %v", object)
+	}
+
+	var actual objectTestPerson
+	if err := db.QueryRow("SELECT v FROM t").Scan(ScanObject(&actual)); err != nil {
+		t.Fatal(err)
+	}
+	if actual.ID != expected.ID || actual.Name != expected.Name || !actual.CreatedAt.Equal(expected.CreatedAt) ||
+		actual.Age == nil || *actual.Age != age || actual.Ignored != "" || actual.internal != "" {
+		t.Fatalf("unexpected scanned value. expected: %+v, got: %+v", expected, actual)
+	}
+}
+
+func TestScanObjectFieldTypeMismatch(t *testing.T) {
+	if err := RegisterObjectType(&objectTestPerson{}); err != nil {
+		t.Fatal(err)
+	}
+	var p objectTestPerson
+	err := ScanObject(&p).Scan(`{"id": "seven", "name": "Alice"}`)
+	var se *SnowflakeError
+	if !errors.As(err, &se) || se.Number != ErrObjectField {
+		t.Fatalf("expected error %v, got: %v", ErrObjectField, err)
+	}
+	err = ScanObject(&p).Scan(`{"age": 3000000000}`)
+	if !errors.As(err, &se) || se.Number != ErrObjectField {
+		t.Fatalf("expected error %v for an overflowing value, got: %v", ErrObjectField, err)
+	}
+}
+
+func TestScanObjectNotRegistered(t *testing.T) {
+	var dest struct{ A int }
+	err := ScanObject(&dest).Scan(`{"A": 1}`)
+	var se *SnowflakeError
+	if !errors.As(err, &se) || se.Number != ErrObjectTypeNotRegistered {
+		t.Fatalf("expected error %v, got: %v", ErrObjectTypeNotRegistered, err)
+	}
+}
+
+func TestRegisterObjectTypeUnsupportedField(t *testing.T) {
+	err := RegisterObjectType(struct {
+		Tags []string
+	}{})
+	var se *SnowflakeError
+	if !errors.As(err, &se) || se.Number != ErrObjectField {
+		t.Fatalf("expected error %v, got: %v", ErrObjectField, err)
+	}
+	if err = RegisterObjectType(42); err == nil {
+		t.Fatal("expected an error registering a non-struct type")
+	}
+}
+
+// objectTestTime is a custom time type, as used by applications wrapping time.Time.
+type objectTestTime struct {
+	t time.Time
+}
+
+func (ot *objectTestTime) Scan(src interface{}) error {
+	t, ok := src.(time.Time)
+	if !ok {
+		return fmt.Errorf("cannot scan %T into objectTestTime", src)
+	}
+	ot.t = t
+	return nil
+}
+
+func (ot objectTestTime) Value() (driver.Value, error) {
+	return ot.t, nil
+}
+
+func TestScanTimestampIntoScanner(t *testing.T) {
+	postQueryMock := func(_ context.Context, _ *snowflakeRestful, _ *url.Values,
+		_ map[string]string, _ []byte, _ time.Duration, _ UUID, _ *Config) (*execResponse, error) {
+		value := "1682944245.123456789"
+		return &execResponse{
+			Data: execResponseData{
+				QueryID:           "1",
+				RowType:           []execResponseRowType{{Name: "TS", Type: "timestamp_ntz", Scale: 9}},
+				RowSet:            [][]*string{{&value}},
+				Total:             1,
+				Returned:          1,
+				QueryResultFormat: "json",
+			},
+			Success: true,
+		}, nil
+	}
+	sc := getDefaultSnowflakeConn()
+	sc.cfg.KeepSessionAlive = true
+	sc.rest.FuncPostQuery = postQueryMock
+	db := sql.OpenDB(showConnectorMock{sc: sc})
+	defer db.Close()
+
+	var actual objectTestTime
+	if err := db.QueryRow("SELECT ts FROM t").Scan(&actual); err != nil {
+		t.Fatal(err)
+	}
+	if expected := time.Date(2023, 5, 1, 12, 30, 45, 123456789, time.UTC); !actual.t.Equal(expected) {
+		t.Fatalf("unexpected timestamp. expected: %v, got: %v", expected, actual.t)
+	}
+}
+
+func TestObjectScannerField(t *testing.T) {
+	type event struct {
+		At    objectTestTime  `sf:"at"`
+		Until *objectTestTime `sf:"until"`
+	}
+	if err := RegisterObjectType(event{}); err != nil {
+		t.Fatal(err)
+	}
+	at := time.Date(2023, 5, 1, 12, 30, 45, 123456789, time.FixedZone("", 2*3600))
+	value, err := registeredObjectSchema(event{}).toJSON(event{At: objectTestTime{at}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *value != `{"at":"2023-05-01T12:30:45.123456789+02:00","until":null}` {
+		t.Fatalf("unexpected OBJECT value: %v", *value)
+	}
+
+	var actual event
+	if err = ScanObject(&actual).Scan(`{"at": "2023-05-01T12:30:45.123456789+02:00", "until": "2023-05-02T00:00:00Z"}`); err != nil {
+		t.Fatal(err)
+	}
+	if !actual.At.t.Equal(at) || actual.Until == nil || !actual.Until.t.Equal(time.Date(2023, 5, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected scanned value: %+v", actual)
+	}
+	err = ScanObject(&actual).Scan(`{"at": "not a time"}`)
+	var se *SnowflakeError
+	if !errors.As(err, &se) || se.Number != ErrObjectField {
+		t.Fatalf("expected error %v, got: %v", ErrObjectField, err)
+	}
+}
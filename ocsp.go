@@ -643,6 +643,7 @@ func verifyPeerCertificate(ctx context.Context, verifiedChains [][]*x509.Certifi
 		if r := canEarlyExitForOCSP(results, numberOfNoneRootCerts); r != nil {
 			return r.err
 		}
+		reportOCSPSoftFail(ctx, results, verifiedChains[i])
 	}
 
 	ocspResponseCacheLock.Lock()
@@ -691,6 +692,36 @@ func canEarlyExitForOCSP(results []*ocspStatus, chainSize int) *ocspStatus {
 	return nil
 }
 
+// reportOCSPSoftFail calls the hook of Config.OnOCSPSoftFail, if any, for each certificate
+// of the chain whose revocation status could not be obtained while the connection proceeds.
+func reportOCSPSoftFail(ctx context.Context, results []*ocspStatus, chain []*x509.Certificate) {
+	hook, ok := ctx.Value(ocspSoftFailHook).(func(*x509.Certificate, error))
+	if !ok || hook == nil {
+		return
+	}
+	for i, r := range results {
+		if r != nil && r.code != ocspStatusGood && r.err != nil {
+			hook(chain[i], r.err)
+		}
+	}
+}
+
+// transportWithOCSPSoftFailHook returns a copy of the transport calling hook when the OCSP check of a
+// certificate soft-fails. Unlike the other transport copies, it is not shared as the hooks cannot be compared,
+// and VerifyPeerCertificate has no request context to look the hook up per request. The connection therefore
+// has a connection pool of its own, see Config.OnOCSPSoftFail.
+func transportWithOCSPSoftFailHook(base *http.Transport, hook func(*x509.Certificate, error)) *http.Transport {
+	t := base.Clone()
+	if t.TLSClientConfig == nil {
+		t.TLSClientConfig = &tls.Config{}
+	}
+	t.TLSClientConfig.VerifyPeerCertificate = func(_ [][]byte, verifiedChains [][]*x509.Certificate) error {
+		overrideCacheDir()
+		return verifyPeerCertificate(context.WithValue(context.TODO(), ocspSoftFailHook, hook), verifiedChains)
+	}
+	return t
+}
+
 func validateWithCacheForAllCertificates(verifiedChains []*x509.Certificate) bool {
 	n := len(verifiedChains) - 1
 	for j := 0; j < n; j++ {
@@ -1031,6 +1062,21 @@ var snowflakeInsecureTransport = &http.Transport{
 	}).DialContext,
 }
 
+// snowflakeNoOCSPTransport is the transport object that validates the certificate chain and
+// host name but doesn't do certificate revocation check.
+var snowflakeNoOCSPTransport = &http.Transport{
+	TLSClientConfig: &tls.Config{
+		RootCAs: certPool,
+	},
+	MaxIdleConns:    10,
+	IdleConnTimeout: 30 * time.Minute,
+	Proxy:           http.ProxyFromEnvironment,
+	DialContext: (&net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}).DialContext,
+}
+
 // SnowflakeTransport includes the certificate revocation check with OCSP in sequential. By default, the driver uses
 // this transport object.
 var SnowflakeTransport = &http.Transport{
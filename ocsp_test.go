@@ -6,14 +6,20 @@ import (
 	"bytes"
 	"context"
 	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"crypto/tls"
 	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/base64"
 	"errors"
 	"fmt"
 	"io"
+	"math/big"
 	"net"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"os"
 	"testing"
@@ -578,3 +584,143 @@ func TestInitOCSPCacheFileCreation(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestDisableOCSPChecksTransport(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	if snowflakeNoOCSPTransport.TLSClientConfig.VerifyPeerCertificate != nil {
+		t.Fatal("revocation check should not be registered")
+	}
+	if snowflakeNoOCSPTransport.TLSClientConfig.InsecureSkipVerify {
+		t.Fatal("certificate chain verification should not be skipped")
+	}
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(server.Certificate())
+
+	t.Run("chain valid without revocation status", func(t *testing.T) {
+		// the test certificate has no OCSP responder, so a revocation check could never pass
+		tr := snowflakeNoOCSPTransport.Clone()
+		tr.TLSClientConfig.RootCAs = rootCAs
+		res, err := (&http.Client{Transport: tr}).Get(server.URL)
+		if err != nil {
+			t.Fatalf("failed to connect. err: %v", err)
+		}
+		res.Body.Close()
+	})
+
+	t.Run("unknown certificate authority", func(t *testing.T) {
+		_, err := (&http.Client{Transport: snowflakeNoOCSPTransport.Clone()}).Get(server.URL)
+		if err == nil {
+			t.Fatal("should fail for an untrusted certificate")
+		}
+	})
+
+	t.Run("host name mismatch", func(t *testing.T) {
+		tr := snowflakeNoOCSPTransport.Clone()
+		tr.TLSClientConfig.RootCAs = rootCAs
+		tr.TLSClientConfig.ServerName = "mismatch.snowflakecomputing.com"
+		_, err := (&http.Client{Transport: tr}).Get(server.URL)
+		var hostnameErr x509.HostnameError
+		if !errors.As(err, &hostnameErr) {
+			t.Fatalf("should fail with host name mismatch, got: %v", err)
+		}
+	})
+}
+
+func TestBuildSnowflakeConnWithDisabledOCSPChecks(t *testing.T) {
+	sc, err := buildSnowflakeConn(context.Background(), Config{
+		Account:           "a",
+		Host:              "a.snowflakecomputing.com",
+		DisableOCSPChecks: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sc.rest.Client.Transport != snowflakeNoOCSPTransport {
+		t.Fatal("transport without revocation check should be used")
+	}
+	if sc.cfg.ocspMode() != ocspModeInsecure {
+		t.Fatalf("unexpected OCSP mode: %v", sc.cfg.ocspMode())
+	}
+}
+
+func TestOCSPSoftFailHook(t *testing.T) {
+	t.Setenv(cacheServerEnabledEnv, "false")
+	t.Setenv(ocspTestResponderTimeoutEnv, "1000")
+	// the responder is not listening anymore
+	responder := httptest.NewServer(http.NotFoundHandler())
+	responderURL := responder.URL
+	responder.Close()
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "soft fail test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leafKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leafTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "soft fail test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		OCSPServer:   []string{responderURL},
+	}
+	leafDER, err := x509.CreateCertificate(rand.Reader, leafTemplate, ca, &leafKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(leafDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var softFailed []*x509.Certificate
+	transport := transportWithOCSPSoftFailHook(SnowflakeTransport, func(cert *x509.Certificate, err error) {
+		if err == nil {
+			t.Error("the soft fail should have a reason")
+		}
+		softFailed = append(softFailed, cert)
+	})
+	defer func() {
+		ocspFailOpen = OCSPFailOpenTrue
+	}()
+
+	ocspFailOpen = OCSPFailOpenTrue
+	if err = transport.TLSClientConfig.VerifyPeerCertificate(nil, [][]*x509.Certificate{{leaf, ca}}); err != nil {
+		t.Fatalf("fail open mode should proceed, err: %v", err)
+	}
+	if len(softFailed) != 1 || softFailed[0] != leaf {
+		t.Fatalf("the hook should be invoked for the leaf certificate, got: %v", softFailed)
+	}
+
+	softFailed = nil
+	ocspFailOpen = OCSPFailOpenFalse
+	if err = transport.TLSClientConfig.VerifyPeerCertificate(nil, [][]*x509.Certificate{{leaf, ca}}); err == nil {
+		t.Fatal("fail closed mode should fail")
+	}
+	if len(softFailed) != 0 {
+		t.Fatalf("the hook should not be invoked in fail closed mode, got: %v", softFailed)
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2023 Snowflake Computing Inc. All rights reserved.
+
+// Package parquet decodes Parquet files, e.g. downloaded by GET after unloading with COPY INTO, into driver.Rows.
+// It is a separate package so that the Parquet dependencies are built only by the applications using it.
+package parquet
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+	arrowparquet "github.com/apache/arrow/go/v12/parquet"
+	"github.com/apache/arrow/go/v12/parquet/pqarrow"
+)
+
+// parquetRows implements driver.Rows for a Parquet file downloaded by GET
+type parquetRows struct {
+	table   arrow.Table
+	reader  *array.TableReader
+	record  arrow.Record
+	idx     int
+	columns []string
+}
+
+// ReadFile decodes a Parquet file, e.g. downloaded by GET after unloading with COPY INTO,
+// into driver.Rows. Integers are returned as int64, floating point numbers as float64,
+// dates and timestamps as time.Time, binary data as []byte and all other values as string.
+// The returned rows must be closed.
+func ReadFile(ctx context.Context, fileName string) (driver.Rows, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	table, err := pqarrow.ReadTable(ctx, f, arrowparquet.NewReaderProperties(memory.DefaultAllocator),
+		pqarrow.ArrowReadProperties{}, memory.DefaultAllocator)
+	if err != nil {
+		return nil, err
+	}
+	columns := make([]string, table.NumCols())
+	for i, field := range table.Schema().Fields() {
+		columns[i] = field.Name
+	}
+	return &parquetRows{
+		table:   table,
+		reader:  array.NewTableReader(table, 0),
+		columns: columns,
+	}, nil
+}
+
+func (pr *parquetRows) Columns() []string {
+	return pr.columns
+}
+
+func (pr *parquetRows) Close() error {
+	pr.reader.Release()
+	pr.table.Release()
+	return nil
+}
+
+func (pr *parquetRows) Next(dest []driver.Value) error {
+	for pr.record == nil || pr.idx >= int(pr.record.NumRows()) {
+		if !pr.reader.Next() {
+			return io.EOF
+		}
+		pr.record = pr.reader.Record()
+		pr.idx = 0
+	}
+	for i, col := range pr.record.Columns() {
+		if i >= len(dest) {
+			break
+		}
+		value, err := parquetValueToDriverValue(col, pr.idx)
+		if err != nil {
+			return err
+		}
+		dest[i] = value
+	}
+	pr.idx++
+	return nil
+}
+
+func parquetValueToDriverValue(col arrow.Array, idx int) (driver.Value, error) {
+	if col.IsNull(idx) {
+		return nil, nil
+	}
+	switch values := col.(type) {
+	case *array.Boolean:
+		return values.Value(idx), nil
+	case *array.Int8:
+		return int64(values.Value(idx)), nil
+	case *array.Int16:
+		return int64(values.Value(idx)), nil
+	case *array.Int32:
+		return int64(values.Value(idx)), nil
+	case *array.Int64:
+		return values.Value(idx), nil
+	case *array.Uint8:
+		return int64(values.Value(idx)), nil
+	case *array.Uint16:
+		return int64(values.Value(idx)), nil
+	case *array.Uint32:
+		return int64(values.Value(idx)), nil
+	case *array.Float32:
+		return float64(values.Value(idx)), nil
+	case *array.Float64:
+		return values.Value(idx), nil
+	case *array.String:
+		return values.Value(idx), nil
+	case *array.Binary:
+		return values.Value(idx), nil
+	case *array.Decimal128:
+		scale := col.DataType().(*arrow.Decimal128Type).Scale
+		return values.Value(idx).ToString(scale), nil
+	case *array.Date32:
+		return values.Value(idx).ToTime(), nil
+	case *array.Timestamp:
+		unit := col.DataType().(*arrow.TimestampType).Unit
+		return values.Value(idx).ToTime(unit), nil
+	}
+	return fmt.Sprint(col.GetOneForMarshal(idx)), nil
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2023 Snowflake Computing Inc. All rights reserved.
+
+package parquet
+
+import (
+	"context"
+	"database/sql/driver"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+	"github.com/apache/arrow/go/v12/parquet/pqarrow"
+)
+
+func readAllParquetRows(t *testing.T, fileName string) ([]string, [][]driver.Value) {
+	rows, err := ReadFile(context.Background(), fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var result [][]driver.Value
+	for {
+		dest := make([]driver.Value, len(rows.Columns()))
+		if err = rows.Next(dest); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		result = append(result, dest)
+	}
+	return rows.Columns(), result
+}
+
+func TestReadFile(t *testing.T) {
+	pool := memory.NewGoAllocator()
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "ID", Type: arrow.PrimitiveTypes.Int32},
+		{Name: "NAME", Type: arrow.BinaryTypes.String, Nullable: true},
+	}, nil)
+	builder := array.NewRecordBuilder(pool, schema)
+	defer builder.Release()
+	builder.Field(0).(*array.Int32Builder).AppendValues([]int32{1, 2}, nil)
+	builder.Field(1).(*array.StringBuilder).AppendValues([]string{"a", ""}, []bool{true, false})
+	record := builder.NewRecord()
+	defer record.Release()
+	table := array.NewTableFromRecords(schema, []arrow.Record{record})
+	defer table.Release()
+
+	fileName := filepath.Join(t.TempDir(), "data.parquet")
+	f, err := os.Create(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = pqarrow.WriteTable(table, f, 1024, nil, pqarrow.DefaultWriterProps())
+	f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	columns, rows := readAllParquetRows(t, fileName)
+	if strings.Join(columns, ",") != "ID,NAME" {
+		t.Fatalf("unexpected columns: %v", columns)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 rows, got: %v", len(rows))
+	}
+	if rows[0][0] != int64(1) || rows[0][1] != "a" {
+		t.Fatalf("unexpected first row: %v", rows[0])
+	}
+	if rows[1][0] != int64(2) || rows[1][1] != nil {
+		t.Fatalf("unexpected second row: %v", rows[1])
+	}
+}
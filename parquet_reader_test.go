@@ -0,0 +1,39 @@
+// Copyright (c) 2023 Snowflake Computing Inc. All rights reserved.
+
+package gosnowflake
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/snowflakedb/gosnowflake/parquet"
+)
+
+func TestReadParquetFileFromUnload(t *testing.T) {
+	runDBTest(t, func(dbt *DBTest) {
+		dbt.mustExec("CREATE OR REPLACE TEMPORARY STAGE test_parquet_unload")
+		defer dbt.mustExec("DROP STAGE IF EXISTS test_parquet_unload")
+		dbt.mustExec("COPY INTO @test_parquet_unload/data.parquet FROM " +
+			"(SELECT 1::INT AS ID, 'a' AS NAME UNION ALL SELECT 2, 'b') " +
+			"FILE_FORMAT = (TYPE = PARQUET) HEADER = TRUE SINGLE = TRUE")
+		tmpDir := t.TempDir()
+		dbt.mustExec(fmt.Sprintf("GET @test_parquet_unload/data.parquet 'file://%v/'",
+			strings.ReplaceAll(filepath.ToSlash(tmpDir), "\\", "\\\\")))
+
+		rows, err := parquet.ReadFile(context.Background(), filepath.Join(tmpDir, "data.parquet"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rows.Close()
+		if columns := rows.Columns(); strings.Join(columns, ",") != "ID,NAME" {
+			t.Fatalf("unexpected columns: %v", columns)
+		}
+		if got := readAllTestRows(t, rows); !reflect.DeepEqual(got, []string{"[1 a]", "[2 b]"}) {
+			t.Fatalf("unexpected rows: %v", got)
+		}
+	})
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2023 Snowflake Computing Inc. All rights reserved.
+
+package gosnowflake
+
+import (
+	"container/list"
+	"sync"
+)
+
+// maxPrepareCacheEntries is the number of described statements kept by a connection.
+const maxPrepareCacheEntries = 256
+
+// prepareCacheKey identifies a described statement. The same SQL text refers to other objects, and may have
+// other bind parameters, in another database or schema, or with another role.
+type prepareCacheKey struct {
+	database string
+	schema   string
+	role     string
+	query    string
+}
+
+// prepareCacheKey returns the key of the statement in the current database, schema and role of the session.
+func (sc *snowflakeConn) prepareCacheKey(query string) prepareCacheKey {
+	return prepareCacheKey{
+		database: sc.cfg.Database,
+		schema:   sc.cfg.Schema,
+		role:     sc.cfg.Role,
+		query:    query,
+	}
+}
+
+type prepareCacheEntry struct {
+	key    prepareCacheKey
+	params []ParamInfo
+}
+
+// prepareCache is an in-memory LRU cache of the bind parameters of the statements described by a connection,
+// keyed by the SQL text and the current database, schema and role of the session, so that preparing the same
+// statement again doesn't describe it on the server. A nil cache caches nothing.
+type prepareCache struct {
+	mu       sync.Mutex
+	capacity int
+	lru      *list.List
+	entries  map[prepareCacheKey]*list.Element
+}
+
+func newPrepareCache(capacity int) *prepareCache {
+	return &prepareCache{
+		capacity: capacity,
+		lru:      list.New(),
+		entries:  make(map[prepareCacheKey]*list.Element),
+	}
+}
+
+// get returns the bind parameters of the statement if it was described before.
+func (pc *prepareCache) get(key prepareCacheKey) ([]ParamInfo, bool) {
+	if pc == nil {
+		return nil, false
+	}
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	elem, ok := pc.entries[key]
+	if !ok {
+		return nil, false
+	}
+	pc.lru.MoveToFront(elem)
+	return elem.Value.(*prepareCacheEntry).params, true
+}
+
+// put caches the bind parameters of the statement, evicting the least recently used statement if needed.
+func (pc *prepareCache) put(key prepareCacheKey, params []ParamInfo) {
+	if pc == nil {
+		return
+	}
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	if elem, ok := pc.entries[key]; ok {
+		elem.Value.(*prepareCacheEntry).params = params
+		pc.lru.MoveToFront(elem)
+		return
+	}
+	if pc.lru.Len() >= pc.capacity {
+		entry := pc.lru.Remove(pc.lru.Back()).(*prepareCacheEntry)
+		delete(pc.entries, entry.key)
+	}
+	pc.entries[key] = pc.lru.PushFront(&prepareCacheEntry{key: key, params: params})
+}
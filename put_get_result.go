@@ -0,0 +1,93 @@
+// Copyright (c) 2023 Snowflake Computing Inc. All rights reserved.
+
+package gosnowflake
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"strings"
+)
+
+// PutGetResult is a row of the result of a PUT or GET command.
+// For GET, Source and Destination are both the downloaded file and TargetSize is its size.
+type PutGetResult struct {
+	Source            string
+	Destination       string
+	SourceSize        int64
+	TargetSize        int64
+	SourceCompression string
+	TargetCompression string
+	Status            string
+	Encryption        string
+	Message           string
+}
+
+// PutGetResults reads the rows returned by a PUT or GET command into PutGetResult values.
+// The columns are matched by name, so the rows returned by the server and by the driver are both supported.
+// The rows are closed afterwards.
+func PutGetResults(rows driver.Rows) ([]PutGetResult, error) {
+	defer rows.Close()
+	columns := rows.Columns()
+	var results []PutGetResult
+	for {
+		values := make([]driver.Value, len(columns))
+		if err := rows.Next(values); err == io.EOF {
+			return results, nil
+		} else if err != nil {
+			return nil, err
+		}
+		row := make(showRow, len(columns))
+		for i, column := range columns {
+			row[strings.ToLower(column)] = values[i]
+		}
+		results = append(results, row.putGetResult())
+	}
+}
+
+// ScanPutGetResults is the same as PutGetResults for the rows returned by database/sql.
+func ScanPutGetResults(rows *sql.Rows) ([]PutGetResult, error) {
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	var results []PutGetResult
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		dest := make([]interface{}, len(columns))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err = rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		row := make(showRow, len(columns))
+		for i, column := range columns {
+			row[strings.ToLower(column)] = values[i]
+		}
+		results = append(results, row.putGetResult())
+	}
+	return results, rows.Err()
+}
+
+func (r showRow) putGetResult() PutGetResult {
+	result := PutGetResult{
+		Source:            r.string("source"),
+		Destination:       r.string("target"),
+		SourceSize:        r.int("source_size"),
+		TargetSize:        r.int("target_size"),
+		SourceCompression: r.string("source_compression"),
+		TargetCompression: r.string("target_compression"),
+		Status:            r.string("status"),
+		Encryption:        r.string("encryption"),
+		Message:           r.string("message"),
+	}
+	if _, ok := r["file"]; ok {
+		// GET
+		result.Source = r.string("file")
+		result.Destination = result.Source
+		result.TargetSize = r.int("size")
+	}
+	return result
+}
@@ -0,0 +1,104 @@
+// Copyright (c) 2023 Snowflake Computing Inc. All rights reserved.
+
+package gosnowflake
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestPutGetResults(t *testing.T) {
+	columns := []execResponseRowType{
+		{Name: "source", Type: "text"},
+		{Name: "target", Type: "text"},
+		{Name: "source_size", Type: "fixed"},
+		{Name: "target_size", Type: "fixed"},
+		{Name: "source_compression", Type: "text"},
+		{Name: "target_compression", Type: "text"},
+		{Name: "status", Type: "text"},
+		{Name: "encryption", Type: "text"},
+		{Name: "message", Type: "text"},
+	}
+	rowSet := [][]*string{
+		{strPtr("data.csv"), strPtr("data.csv.gz"), strPtr("1024"), strPtr("256"), strPtr("NONE"), strPtr("GZIP"),
+			strPtr("UPLOADED"), strPtr("ENCRYPTED"), strPtr("")},
+		{strPtr("other.csv"), strPtr("other.csv.gz"), strPtr("10"), strPtr("0"), strPtr("NONE"), strPtr("GZIP"),
+			strPtr("SKIPPED"), strPtr(""), strPtr("file already exists")},
+	}
+	postQueryMock := func(_ context.Context, _ *snowflakeRestful, _ *url.Values,
+		_ map[string]string, _ []byte, _ time.Duration, _ UUID, _ *Config) (*execResponse, error) {
+		return &execResponse{
+			Data: execResponseData{
+				QueryID:           "1",
+				RowType:           columns,
+				RowSet:            rowSet,
+				Total:             int64(len(rowSet)),
+				Returned:          int64(len(rowSet)),
+				QueryResultFormat: "json",
+			},
+			Success: true,
+		}, nil
+	}
+	sc := getDefaultSnowflakeConn()
+	sc.rest.FuncPostQuery = postQueryMock
+	rows, err := sc.QueryContext(context.Background(), "SELECT 1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := PutGetResults(rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []PutGetResult{
+		{
+			Source:            "data.csv",
+			Destination:       "data.csv.gz",
+			SourceSize:        1024,
+			TargetSize:        256,
+			SourceCompression: "NONE",
+			TargetCompression: "GZIP",
+			Status:            "UPLOADED",
+			Encryption:        "ENCRYPTED",
+		},
+		{
+			Source:            "other.csv",
+			Destination:       "other.csv.gz",
+			SourceSize:        10,
+			SourceCompression: "NONE",
+			TargetCompression: "GZIP",
+			Status:            "SKIPPED",
+			Message:           "file already exists",
+		},
+	}
+	if len(results) != len(expected) {
+		t.Fatalf("expected %v results, got: %+v", len(expected), results)
+	}
+	for i := range expected {
+		if results[i] != expected[i] {
+			t.Fatalf("unexpected result %v. expected: %+v, got: %+v", i, expected[i], results[i])
+		}
+	}
+}
+
+func TestScanPutGetResultsFromGet(t *testing.T) {
+	db, _ := openShowTestDB(t, []execResponseRowType{
+		{Name: "file", Type: "text"},
+		{Name: "size", Type: "fixed"},
+		{Name: "status", Type: "text"},
+		{Name: "message", Type: "text"},
+	}, [][]*string{{strPtr("data.csv.gz"), strPtr("256"), strPtr("DOWNLOADED"), strPtr("")}})
+	rows, err := db.QueryContext(context.Background(), "SELECT 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := ScanPutGetResults(rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := PutGetResult{Source: "data.csv.gz", Destination: "data.csv.gz", TargetSize: 256, Status: "DOWNLOADED"}
+	if len(results) != 1 || results[0] != expected {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+}
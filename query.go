@@ -14,6 +14,40 @@ const (
 	arrowFormat resultFormat = "arrow"
 )
 
+const (
+	// ResultFormatArrow requests the query result in the Arrow format
+	ResultFormatArrow = "ARROW"
+	// ResultFormatJSON requests the query result in the JSON format
+	ResultFormatJSON = "JSON"
+)
+
+// queryWarnings are the warnings returned with a successful query, e.g. about implicit casts.
+// The server sends either plain messages or objects with a message field.
+type queryWarnings []string
+
+func (qw *queryWarnings) UnmarshalJSON(data []byte) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	warnings := make(queryWarnings, 0, len(raw))
+	for _, r := range raw {
+		var message string
+		if err := json.Unmarshal(r, &message); err != nil {
+			var warning struct {
+				Message string `json:"message"`
+			}
+			if err = json.Unmarshal(r, &warning); err != nil {
+				return err
+			}
+			message = warning.Message
+		}
+		warnings = append(warnings, message)
+	}
+	*qw = warnings
+	return nil
+}
+
 type execBindParameter struct {
 	Type  string      `json:"type"`
 	Value interface{} `json:"value"`
@@ -79,7 +113,7 @@ type execResponseStageInfo struct {
 	Path                  string                  `json:"path,omitempty"`
 	Region                string                  `json:"region,omitempty"`
 	StorageAccount        string                  `json:"storageAccount,omitempty"`
-	IsClientSideEncrypted bool                    `json:"isClientSideEncrypted,omitempty"`
+	IsClientSideEncrypted *bool                   `json:"isClientSideEncrypted,omitempty"`
 	Creds                 execResponseCredentials `json:"creds,omitempty"`
 	PresignedURL          string                  `json:"presignedUrl,omitempty"`
 	EndPoint              string                  `json:"endPoint,omitempty"`
@@ -101,7 +135,10 @@ type execResponseData struct {
 	FinalSchemaName    string                `json:"finalSchemaName,omitempty"`
 	FinalWarehouseName string                `json:"finalWarehouseName,omitempty"`
 	FinalRoleName      string                `json:"finalRoleName,omitempty"`
-	NumberOfBinds      int                   `json:"numberOfBinds,omitempty"`   // java:int
+	NumberOfBinds      int                   `json:"numberOfBinds,omitempty"` // java:int
+	MetaDataOfBinds    []execResponseRowType `json:"metaDataOfBinds,omitempty"`
+	Warnings           queryWarnings         `json:"warnings,omitempty"`
+	Stats              *QueryStats           `json:"stats,omitempty"`
 	StatementTypeID    int64                 `json:"statementTypeId,omitempty"` // java:long
 	Version            int64                 `json:"version,omitempty"`         // java:long
 	Chunks             []execResponseChunk   `json:"chunks,omitempty"`
@@ -0,0 +1,78 @@
+// Copyright (c) 2023 Snowflake Computing Inc. All rights reserved.
+
+package gosnowflake
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// requestRateLimiter spaces the requests of a connection so that at most a given number of requests
+// is sent per second. It is a token bucket holding a single token.
+type requestRateLimiter struct {
+	mutex    sync.Mutex
+	interval time.Duration // minimum time between two requests
+	next     time.Time     // earliest time of the next request
+	now      func() time.Time
+	sleep    func(ctx context.Context, d time.Duration) error
+}
+
+func newRequestRateLimiter(requestsPerSecond float64) *requestRateLimiter {
+	return &requestRateLimiter{
+		interval: time.Duration(float64(time.Second) / requestsPerSecond),
+		now:      time.Now,
+		sleep:    sleepWithContext,
+	}
+}
+
+func sleepWithContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// wait blocks until the next request may be sent or ctx is done.
+func (l *requestRateLimiter) wait(ctx context.Context) error {
+	l.mutex.Lock()
+	now := l.now()
+	at := l.next
+	if at.Before(now) {
+		at = now
+	}
+	l.next = at.Add(l.interval)
+	l.mutex.Unlock()
+	if d := at.Sub(now); d > 0 {
+		logger.WithContext(ctx).Debugf("request rate limit reached. waiting %v", d)
+		return l.sleep(ctx, d)
+	}
+	return nil
+}
+
+// rateLimitedTransport waits for the rate limiter before every request, so that the retries count
+// against the limit too.
+type rateLimitedTransport struct {
+	base    http.RoundTripper
+	limiter *requestRateLimiter
+}
+
+func (t *rateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.limiter.wait(req.Context()); err != nil {
+		return nil, err
+	}
+	return t.base.RoundTrip(req)
+}
+
+// CloseIdleConnections closes the idle connections of the base transport, so that
+// http.Client.CloseIdleConnections reaches it when the connection is closed.
+func (t *rateLimitedTransport) CloseIdleConnections() {
+	if closer, ok := t.base.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+}
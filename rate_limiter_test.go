@@ -0,0 +1,169 @@
+// Copyright (c) 2023 Snowflake Computing Inc. All rights reserved.
+
+package gosnowflake
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRateLimiterClock is a clock advanced by the sleeps of the rate limiter only.
+type fakeRateLimiterClock struct {
+	now time.Time
+}
+
+func (c *fakeRateLimiterClock) install(l *requestRateLimiter) {
+	l.now = func() time.Time { return c.now }
+	l.sleep = func(ctx context.Context, d time.Duration) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		c.now = c.now.Add(d)
+		return nil
+	}
+}
+
+// recordingClockTransport records the time of the fake clock at which each request is sent.
+type recordingClockTransport struct {
+	clock    *fakeRateLimiterClock
+	sentAt   []time.Time
+	statuses []int
+}
+
+func (t *recordingClockTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.sentAt = append(t.sentAt, t.clock.now)
+	status := http.StatusOK
+	if len(t.statuses) > 0 {
+		status, t.statuses = t.statuses[0], t.statuses[1:]
+	}
+	return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader("{}")), Request: req}, nil
+}
+
+func TestRateLimitedTransportSpacesRequests(t *testing.T) {
+	clock := &fakeRateLimiterClock{now: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)}
+	start := clock.now
+	base := &recordingClockTransport{clock: clock}
+	limiter := newRequestRateLimiter(4)
+	clock.install(limiter)
+	client := &http.Client{Transport: &rateLimitedTransport{base: base, limiter: limiter}}
+
+	for i := 0; i < 3; i++ {
+		res, err := client.Get("https://a.snowflakecomputing.com/queries/v1/query-request")
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+	}
+	// the limit is not lowered by the time elapsed between the requests
+	clock.now = clock.now.Add(100 * time.Millisecond)
+	res, err := client.Get("https://a.snowflakecomputing.com/monitoring/queries/qid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	// after an idle period the next request is sent right away
+	clock.now = clock.now.Add(time.Minute)
+	idleEnd := clock.now
+	if res, err = client.Get("https://a.snowflakecomputing.com/session/heartbeat"); err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	expected := []time.Duration{0, 250 * time.Millisecond, 500 * time.Millisecond, 750 * time.Millisecond, idleEnd.Sub(start)}
+	if len(base.sentAt) != len(expected) {
+		t.Fatalf("unexpected number of requests: %v", len(base.sentAt))
+	}
+	for i, at := range base.sentAt {
+		if at.Sub(start) != expected[i] {
+			t.Fatalf("request %v sent at %v, expected at %v", i, at.Sub(start), expected[i])
+		}
+	}
+}
+
+func TestRateLimitedTransportCountsRetries(t *testing.T) {
+	clock := &fakeRateLimiterClock{now: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)}
+	start := clock.now
+	base := &recordingClockTransport{clock: clock, statuses: []int{http.StatusServiceUnavailable}}
+	limiter := newRequestRateLimiter(2)
+	clock.install(limiter)
+	client := &http.Client{Transport: &rateLimitedTransport{base: base, limiter: limiter}}
+	u := (&snowflakeRestful{Protocol: "https", Host: "a.snowflakecomputing.com", Port: 443}).getFullURL(queryRequestPath, &url.Values{})
+	// no backoff, as it sleeps on the real clock: the retry only waits for the rate limiter
+	w := &waitAlgo{mutex: &sync.Mutex{}, base: time.Second, cap: 0}
+	res, err := newRetryHTTP(context.Background(), client, http.NewRequest, u, map[string]string{}, 0, defaultTimeProvider, nil).
+		withWaitAlgo(w).
+		execute()
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if len(base.sentAt) != 2 || base.sentAt[1].Sub(start) != 500*time.Millisecond {
+		t.Fatalf("the retry should be spaced by the rate limiter. sent at: %v", base.sentAt)
+	}
+}
+
+func TestRateLimiterWaitCanceled(t *testing.T) {
+	limiter := newRequestRateLimiter(0.01)
+	if err := limiter.wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := limiter.wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("the wait should end with the context, err: %v", err)
+	}
+}
+
+func TestConnectionRateLimiter(t *testing.T) {
+	sc, err := buildSnowflakeConn(context.Background(), Config{
+		Account:              "a",
+		Host:                 "a.snowflakecomputing.com",
+		Transporter:          http.DefaultTransport,
+		MaxRequestsPerSecond: 10,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	transport, ok := sc.rest.Client.Transport.(*rateLimitedTransport)
+	if !ok || transport.base != http.DefaultTransport || transport.limiter.interval != 100*time.Millisecond {
+		t.Fatalf("the requests of the connection should be rate limited, transport: %#v", sc.rest.Client.Transport)
+	}
+	if jwtTransport, ok := sc.rest.JWTClient.Transport.(*rateLimitedTransport); !ok || jwtTransport.limiter != transport.limiter {
+		t.Fatal("the login requests should share the rate limiter of the connection")
+	}
+}
+
+type idleClosingTransport struct {
+	recordingClockTransport
+	closed int
+}
+
+func (t *idleClosingTransport) CloseIdleConnections() {
+	t.closed++
+}
+
+func TestRateLimitedTransportClosesIdleConnections(t *testing.T) {
+	base := &idleClosingTransport{}
+	sc, err := buildSnowflakeConn(context.Background(), Config{
+		Account:              "a",
+		Host:                 "a.snowflakecomputing.com",
+		Transporter:          base,
+		MaxRequestsPerSecond: 10,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc.rest.Client.CloseIdleConnections()
+	if base.closed != 1 {
+		t.Fatalf("the idle connections of the base transport should be closed, got %v calls", base.closed)
+	}
+	// a base transport without idle connections is skipped
+	(&rateLimitedTransport{base: &recordingClockTransport{}}).CloseIdleConnections()
+}
@@ -0,0 +1,135 @@
+// Copyright (c) 2023 Snowflake Computing Inc. All rights reserved.
+
+package gosnowflake
+
+import (
+	"database/sql/driver"
+	"regexp"
+	"strings"
+)
+
+// RawBind is a bind value interpolated into the SQL text in place of its ? placeholder instead of
+// being sent as a bound value, e.g. to parameterize a table name, which cannot be bound:
+//
+//	rows, err := db.Query("SELECT * FROM ? WHERE id = ?", sf.RawBind("my_db.public.my_table"), 42)
+//
+// Only identifiers are accepted: an unquoted identifier such as my_table, a double-quoted identifier
+// such as "My Table" with embedded double quotes doubled, or up to three of them qualified with dots.
+// Any other value fails the query before it is sent, so a RawBind cannot inject SQL. A RawBind
+// must be bound positionally.
+type RawBind string
+
+// rawBindIdentifierPart matches an unquoted identifier or a double-quoted identifier with escaped quotes.
+const rawBindIdentifierPart = `(?:[A-Za-z_][A-Za-z0-9_$]*|"(?:[^"\x00]|"")+")`
+
+var rawBindRegexp = regexp.MustCompile(`^` + rawBindIdentifierPart + `(?:\.` + rawBindIdentifierPart + `){0,2}$`)
+
+func isValidRawBind(v RawBind) bool {
+	return rawBindRegexp.MatchString(string(v))
+}
+
+// supportedRawBind returns true for the RawBind values, which are interpolated into the query.
+func supportedRawBind(nv *driver.NamedValue) bool {
+	_, ok := nv.Value.(RawBind)
+	return ok
+}
+
+// interpolateRawBinds replaces the ? placeholders of the RawBind bindings with their validated
+// values and returns the query with the remaining bindings, renumbered.
+func interpolateRawBinds(query string, bindings []driver.NamedValue) (string, []driver.NamedValue, error) {
+	hasRawBinds := false
+	for i := range bindings {
+		if supportedRawBind(&bindings[i]) {
+			hasRawBinds = true
+			break
+		}
+	}
+	if !hasRawBinds {
+		return query, bindings, nil
+	}
+	for i := range bindings {
+		if raw, ok := bindings[i].Value.(RawBind); ok {
+			if bindings[i].Name != "" {
+				return "", nil, errInvalidRawBind(bindings[i].Name)
+			}
+			if !isValidRawBind(raw) {
+				return "", nil, errInvalidRawBind(bindings[i].Ordinal)
+			}
+		}
+	}
+	placeholders := findBindPlaceholders(query)
+	var sb strings.Builder
+	remaining := make([]driver.NamedValue, 0, len(bindings))
+	last := 0
+	for i, binding := range bindings {
+		raw, ok := binding.Value.(RawBind)
+		if !ok {
+			binding.Ordinal = len(remaining) + 1
+			remaining = append(remaining, binding)
+			continue
+		}
+		if i >= len(placeholders) {
+			return "", nil, errRawBindWithoutPlaceholder(binding.Ordinal)
+		}
+		sb.WriteString(query[last:placeholders[i]])
+		sb.WriteString(string(raw))
+		last = placeholders[i] + 1
+	}
+	sb.WriteString(query[last:])
+	return sb.String(), remaining, nil
+}
+
+// findBindPlaceholders returns the offsets of the ? placeholders of the query, skipping the ones
+// in string literals, quoted identifiers, dollar-quoted strings and comments.
+func findBindPlaceholders(query string) []int {
+	var offsets []int
+	for i := 0; i < len(query); i++ {
+		switch {
+		case query[i] == '?':
+			offsets = append(offsets, i)
+		case query[i] == '\'':
+			i = skipQuoted(query, i, '\'')
+		case query[i] == '"':
+			i = skipQuoted(query, i, '"')
+		case strings.HasPrefix(query[i:], "$$"):
+			if end := strings.Index(query[i+2:], "$$"); end >= 0 {
+				i += end + 3
+			} else {
+				i = len(query)
+			}
+		case strings.HasPrefix(query[i:], "--"), strings.HasPrefix(query[i:], "//"):
+			if end := strings.IndexByte(query[i:], '\n'); end >= 0 {
+				i += end
+			} else {
+				i = len(query)
+			}
+		case strings.HasPrefix(query[i:], "/*"):
+			if end := strings.Index(query[i+2:], "*/"); end >= 0 {
+				i += end + 3
+			} else {
+				i = len(query)
+			}
+		}
+	}
+	return offsets
+}
+
+// skipQuoted returns the offset of the quote closing the string starting at start. Quotes are
+// escaped by doubling them, and with a backslash in string literals.
+func skipQuoted(query string, start int, quote byte) int {
+	for i := start + 1; i < len(query); i++ {
+		switch query[i] {
+		case '\\':
+			if quote == '\'' {
+				i++
+			}
+		case quote:
+			if i+1 < len(query) && query[i+1] == quote {
+				i++
+				continue
+			}
+			return i
+		}
+	}
+	return len(query)
+}
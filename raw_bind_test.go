@@ -0,0 +1,133 @@
+// Copyright (c) 2023 Snowflake Computing Inc. All rights reserved.
+
+package gosnowflake
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestInterpolateRawBinds(t *testing.T) {
+	testcases := []struct {
+		query    string
+		values   []driver.Value
+		expected string
+	}{
+		{"SELECT * FROM ?", []driver.Value{RawBind("my_table")}, "SELECT * FROM my_table"},
+		{"SELECT * FROM ? WHERE id = ?", []driver.Value{RawBind(`db."My Schema"."a""b"`), int64(1)},
+			`SELECT * FROM db."My Schema"."a""b" WHERE id = ?`},
+		{"SELECT ?, '?', \"?\", $$?$$ /* ? */ FROM ? -- ?\nWHERE c = ?",
+			[]driver.Value{"x", RawBind("T$1"), int64(2)},
+			"SELECT ?, '?', \"?\", $$?$$ /* ? */ FROM T$1 -- ?\nWHERE c = ?"},
+		{`SELECT 'it''s \'?\'', ? FROM ?`, []driver.Value{int64(1), RawBind("_t")},
+			`SELECT 'it''s \'?\'', ? FROM _t`},
+	}
+	for _, tc := range testcases {
+		var bindings []driver.NamedValue
+		for i, v := range tc.values {
+			bindings = append(bindings, driver.NamedValue{Ordinal: i + 1, Value: v})
+		}
+		query, remaining, err := interpolateRawBinds(tc.query, bindings)
+		if err != nil {
+			t.Fatalf("%v: %v", tc.query, err)
+		}
+		if query != tc.expected {
+			t.Fatalf("unexpected query. expected: %v, got: %v", tc.expected, query)
+		}
+		var expectedRemaining []driver.Value
+		for _, v := range tc.values {
+			if _, ok := v.(RawBind); !ok {
+				expectedRemaining = append(expectedRemaining, v)
+			}
+		}
+		if len(remaining) != len(expectedRemaining) {
+			t.Fatalf("%v: unexpected bindings: %v", tc.query, remaining)
+		}
+		for i, binding := range remaining {
+			if binding.Ordinal != i+1 || binding.Value != expectedRemaining[i] {
+				t.Fatalf("%v: the bindings should be renumbered, got: %v", tc.query, remaining)
+			}
+		}
+	}
+}
+
+func TestInvalidRawBind(t *testing.T) {
+	for _, raw := range []RawBind{
+		"",
+		"my_table; DROP TABLE users",
+		"t --",
+		"t /* */",
+		"1table",
+		"a.b.c.d",
+		"a..b",
+		`"unterminated`,
+		`"a"b"`,
+		`"a"; DROP TABLE t; --"`,
+		"my table",
+		"t'",
+		"(SELECT 1)",
+		"tab\x00le",
+	} {
+		_, _, err := interpolateRawBinds("SELECT * FROM ?", []driver.NamedValue{{Ordinal: 1, Value: raw}})
+		var se *SnowflakeError
+		if !errors.As(err, &se) || se.Number != ErrInvalidRawBind {
+			t.Fatalf("%q should be rejected, err: %v", raw, err)
+		}
+	}
+	_, _, err := interpolateRawBinds("SELECT * FROM :t", []driver.NamedValue{{Name: "t", Value: RawBind("t")}})
+	if !errors.Is(err, &SnowflakeError{Number: ErrInvalidRawBind}) {
+		t.Fatalf("a named RawBind should be rejected, err: %v", err)
+	}
+	_, _, err = interpolateRawBinds("SELECT * FROM t WHERE c = '?'", []driver.NamedValue{{Ordinal: 1, Value: RawBind("t")}})
+	if !errors.Is(err, &SnowflakeError{Number: ErrInvalidRawBind}) {
+		t.Fatalf("a RawBind without placeholder should be rejected, err: %v", err)
+	}
+}
+
+func TestRawBindQuery(t *testing.T) {
+	var req execRequest
+	sc := getPooledSnowflakeConn()
+	sc.rest.FuncPostQuery = func(_ context.Context, _ *snowflakeRestful, _ *url.Values,
+		_ map[string]string, body []byte, _ time.Duration, _ UUID, _ *Config) (*execResponse, error) {
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Fatal(err)
+		}
+		return &execResponse{
+			Data: execResponseData{
+				QueryID:           "1",
+				RowType:           []execResponseRowType{{Name: "C", Type: "fixed"}},
+				RowSet:            [][]*string{},
+				QueryResultFormat: "json",
+			},
+			Success: true,
+		}, nil
+	}
+	db := sql.OpenDB(showConnectorMock{sc: sc})
+	defer db.Close()
+
+	rows, err := db.Query("SELECT c FROM ? WHERE id = ?", RawBind("my_table"), 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows.Close()
+	if req.SQLText != "SELECT c FROM my_table WHERE id = ?" {
+		t.Fatalf("unexpected SQL text: %v", req.SQLText)
+	}
+	if len(req.Bindings) != 1 || req.Bindings["1"].Type != "FIXED" || req.Bindings["1"].Value != "42" {
+		t.Fatalf("unexpected bindings: %v", req.Bindings)
+	}
+
+	req = execRequest{}
+	if _, err = db.Query("SELECT c FROM ?", RawBind("t; DROP TABLE t")); !errors.Is(err, &SnowflakeError{Number: ErrInvalidRawBind}) {
+		t.Fatalf("an invalid RawBind should fail the query, err: %v", err)
+	}
+	if req.SQLText != "" {
+		t.Fatalf("an invalid RawBind should not be sent, got: %v", req.SQLText)
+	}
+}
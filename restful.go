@@ -30,6 +30,16 @@ const (
 	queryNotExecuting        = "000605"
 )
 
+// Reasons passed to Config.OnSessionRenew
+const (
+	// SessionRenewReasonHeartbeat is used when the session expired during the heartbeat
+	SessionRenewReasonHeartbeat = "heartbeat"
+	// SessionRenewReasonExpiredOnRequest is used when the session expired during a request
+	SessionRenewReasonExpiredOnRequest = "expired-on-request"
+	// SessionRenewReasonManual is used when the renewal is not triggered by an expired session
+	SessionRenewReasonManual = "manual"
+)
+
 // Snowflake Server Endpoints
 const (
 	loginRequestPath         = "/session/v1/login-request"
@@ -41,6 +51,45 @@ const (
 	heartBeatPath            = "/session/heartbeat"
 )
 
+// headers that cannot be overridden by Config.ExtraHeaders
+var reservedHeaders = map[string]bool{
+	http.CanonicalHeaderKey(headerAuthorizationKey): true,
+	http.CanonicalHeaderKey(httpHeaderContentType):  true,
+}
+
+// newExtraHeaders returns the headers sent with every request of a connection: Config.ExtraHeaders
+// without the reserved ones, and the User-Agent if Config.UserAgent is set.
+func newExtraHeaders(cfg *Config) map[string]string {
+	if cfg == nil {
+		return nil
+	}
+	headers := make(map[string]string)
+	for k, v := range cfg.ExtraHeaders {
+		if reservedHeaders[http.CanonicalHeaderKey(k)] {
+			logger.Warnf("header %v is reserved and cannot be overridden", k)
+			continue
+		}
+		headers[http.CanonicalHeaderKey(k)] = v
+	}
+	if cfg.UserAgent != "" {
+		headers[httpHeaderUserAgent] = cfg.UserAgent
+	}
+	return headers
+}
+
+// addExtraHeaders merges the headers returned by newExtraHeaders into headers, replacing
+// the headers of the same name.
+func addExtraHeaders(headers map[string]string, extraHeaders map[string]string) {
+	for k, v := range extraHeaders {
+		for name := range headers {
+			if http.CanonicalHeaderKey(name) == k {
+				delete(headers, name)
+			}
+		}
+		headers[k] = v
+	}
+}
+
 type (
 	funcGetType      func(context.Context, *snowflakeRestful, *url.URL, map[string]string, time.Duration) (*http.Response, error)
 	funcPostType     func(context.Context, *snowflakeRestful, *url.URL, map[string]string, []byte, time.Duration, bool, currentTimeProvider, *Config) (*http.Response, error)
@@ -61,6 +110,7 @@ type snowflakeRestful struct {
 
 	Client        *http.Client
 	JWTClient     *http.Client
+	ChunkClient   *http.Client
 	TokenAccessor TokenAccessor
 	HeartBeat     *heartbeat
 
@@ -79,6 +129,13 @@ type snowflakeRestful struct {
 	FuncPostAuthSAML func(context.Context, *snowflakeRestful, map[string]string, []byte, time.Duration) (*authResponse, error)
 	FuncPostAuthOKTA func(context.Context, *snowflakeRestful, map[string]string, []byte, string, time.Duration) (*authOKTAResponse, error)
 	FuncGetSSO       func(context.Context, *snowflakeRestful, *url.Values, map[string]string, string, time.Duration) ([]byte, error)
+
+	OnSessionRenew func(ctx context.Context, reason string)
+
+	extraHeaders map[string]string // sent with every request, see newExtraHeaders
+
+	statusCounts *httpStatusCounter // HTTP responses received by the connection, see HTTPStatusCounts
+	waitAlgo     *waitAlgo          // backoff between the retries. The default backoff is used if nil
 }
 
 func (sr *snowflakeRestful) getURL() *url.URL {
@@ -110,6 +167,14 @@ func (sr *snowflakeRestful) getClientFor(authType AuthType) *http.Client {
 	}
 }
 
+// Result chunks are downloaded from the cloud storage, which may require a different transport than Snowflake.
+func (sr *snowflakeRestful) getChunkClient() *http.Client {
+	if sr.ChunkClient != nil {
+		return sr.ChunkClient
+	}
+	return sr.Client
+}
+
 // Renew the snowflake session if the current token is still the stale token specified
 func (sr *snowflakeRestful) renewExpiredSessionToken(ctx context.Context, timeout time.Duration, expiredToken string) error {
 	err := sr.TokenAccessor.Lock()
@@ -125,6 +190,17 @@ func (sr *snowflakeRestful) renewExpiredSessionToken(ctx context.Context, timeou
 	return nil
 }
 
+func withSessionRenewReason(ctx context.Context, reason string) context.Context {
+	return context.WithValue(ctx, sessionRenewReason, reason)
+}
+
+func getSessionRenewReason(ctx context.Context) string {
+	if reason, ok := ctx.Value(sessionRenewReason).(string); ok {
+		return reason
+	}
+	return SessionRenewReasonManual
+}
+
 type renewSessionResponse struct {
 	Data    renewSessionResponseMain `json:"data"`
 	Message string                   `json:"message"`
@@ -167,6 +243,9 @@ func postRestful(
 	cfg *Config) (
 	*http.Response, error) {
 	return newRetryHTTP(ctx, sr.Client, http.NewRequest, fullURL, headers, timeout, currentTimeProvider, cfg).
+		withExtraHeaders(sr.extraHeaders).
+		countStatuses(sr.statusCounts).
+		withWaitAlgo(sr.waitAlgo).
 		doPost().
 		setBody(body).
 		doRaise4XX(raise4XX).
@@ -180,7 +259,11 @@ func getRestful(
 	headers map[string]string,
 	timeout time.Duration) (
 	*http.Response, error) {
-	return newRetryHTTP(ctx, sr.Client, http.NewRequest, fullURL, headers, timeout, defaultTimeProvider, nil).execute()
+	return newRetryHTTP(ctx, sr.Client, http.NewRequest, fullURL, headers, timeout, defaultTimeProvider, nil).
+		withExtraHeaders(sr.extraHeaders).
+		countStatuses(sr.statusCounts).
+		withWaitAlgo(sr.waitAlgo).
+		execute()
 }
 
 func postAuthRestful(
@@ -257,7 +340,7 @@ func postRestfulQueryHelper(
 			return nil, err
 		}
 		if respd.Code == sessionExpiredCode {
-			if err = sr.renewExpiredSessionToken(ctx, timeout, token); err != nil {
+			if err = sr.renewExpiredSessionToken(withSessionRenewReason(ctx, SessionRenewReasonExpiredOnRequest), timeout, token); err != nil {
 				return nil, err
 			}
 			return sr.FuncPostQuery(ctx, sr, params, headers, body, timeout, requestID, cfg)
@@ -298,7 +381,7 @@ func postRestfulQueryHelper(
 				return nil, err
 			}
 			if respd.Code == sessionExpiredCode {
-				if err = sr.renewExpiredSessionToken(ctx, timeout, token); err != nil {
+				if err = sr.renewExpiredSessionToken(withSessionRenewReason(ctx, SessionRenewReasonExpiredOnRequest), timeout, token); err != nil {
 					return nil, err
 				}
 				isSessionRenewed = true
@@ -315,6 +398,14 @@ func postRestfulQueryHelper(
 	}
 	logger.WithContext(ctx).Infof("HTTP: %v, URL: %v, Body: %v", resp.StatusCode, fullURL, b)
 	logger.WithContext(ctx).Infof("Header: %v", resp.Header)
+	if resp.StatusCode == http.StatusRequestEntityTooLarge {
+		return nil, &SnowflakeError{
+			Number:      ErrRequestTooLarge,
+			SQLState:    SQLStateConnectionFailure,
+			Message:     errMsgRequestTooLarge,
+			MessageArgs: []interface{}{resp.StatusCode, fullURL},
+		}
+	}
 	return nil, &SnowflakeError{
 		Number:      ErrFailedToPostQuery,
 		SQLState:    SQLStateConnectionFailure,
@@ -407,6 +498,8 @@ func renewRestfulSession(ctx context.Context, sr *snowflakeRestful, timeout time
 			return err
 		}
 		if !respd.Success {
+			// the master token is expired or invalid, the session cannot be used anymore
+			sr.TokenAccessor.SetTokens("", "", -1)
 			c, err := strconv.Atoi(respd.Code)
 			if err != nil {
 				return err
@@ -417,6 +510,9 @@ func renewRestfulSession(ctx context.Context, sr *snowflakeRestful, timeout time
 			}
 		}
 		sr.TokenAccessor.SetTokens(respd.Data.SessionToken, respd.Data.MasterToken, respd.Data.SessionID)
+		if sr.OnSessionRenew != nil {
+			sr.OnSessionRenew(ctx, getSessionRenewReason(ctx))
+		}
 		return nil
 	}
 	b, err := io.ReadAll(resp.Body)
@@ -479,7 +575,7 @@ func cancelQuery(ctx context.Context, sr *snowflakeRestful, requestID UUID, time
 		}
 		ctxRetry := getCancelRetry(ctx)
 		if !respd.Success && respd.Code == sessionExpiredCode {
-			if err = sr.FuncRenewSession(ctx, sr, timeout); err != nil {
+			if err = sr.FuncRenewSession(withSessionRenewReason(ctx, SessionRenewReasonExpiredOnRequest), sr, timeout); err != nil {
 				return err
 			}
 			return sr.FuncCancelQuery(ctx, sr, requestID, timeout)
@@ -3,10 +3,12 @@
 package gosnowflake
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"sync"
@@ -591,3 +593,125 @@ func TestCancelRetry(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+// postTestRenewOnTokenRequest reports an expired session for all requests
+// except the token request, which renews the session successfully.
+func postTestRenewOnTokenRequest(_ context.Context, _ *snowflakeRestful, fullURL *url.URL, _ map[string]string, _ []byte, _ time.Duration, _ bool, _ currentTimeProvider, _ *Config) (*http.Response, error) {
+	var resp interface{} = &execResponse{
+		Code:    sessionExpiredCode,
+		Success: true,
+	}
+	if fullURL.Path == tokenRequestPath {
+		resp = &renewSessionResponse{
+			Data: renewSessionResponseMain{
+				SessionToken: "newtoken",
+				MasterToken:  "newmaster",
+				SessionID:    200,
+			},
+			Success: true,
+		}
+	}
+	ba, err := json.Marshal(resp)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(bytes.NewReader(ba)),
+	}, nil
+}
+
+func TestUnitOnSessionRenewExpiredOnRequest(t *testing.T) {
+	postQueryTest := func(_ context.Context, _ *snowflakeRestful, _ *url.Values, _ map[string]string, _ []byte, _ time.Duration, _ UUID, _ *Config) (*execResponse, error) {
+		return &execResponse{Success: true}, nil
+	}
+	var reasons []string
+	sr := &snowflakeRestful{
+		FuncPost:         postTestRenewOnTokenRequest,
+		FuncPostQuery:    postQueryTest,
+		FuncRenewSession: renewRestfulSession,
+		TokenAccessor:    getSimpleTokenAccessor(),
+		OnSessionRenew: func(_ context.Context, reason string) {
+			reasons = append(reasons, reason)
+		},
+	}
+	sr.TokenAccessor.SetTokens("oldtoken", "oldmaster", 100)
+	if _, err := postRestfulQueryHelper(context.Background(), sr, &url.Values{}, make(map[string]string), []byte{0x12, 0x34}, 0, NewUUID(), &Config{}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(reasons) != 1 || reasons[0] != SessionRenewReasonExpiredOnRequest {
+		t.Fatalf("unexpected renewal reasons: %v", reasons)
+	}
+	if token, _, _ := sr.TokenAccessor.GetTokens(); token != "newtoken" {
+		t.Fatalf("unexpected token after renewal: %v", token)
+	}
+}
+
+func TestUnitOnSessionRenewNotCalledOnFailure(t *testing.T) {
+	called := false
+	sr := &snowflakeRestful{
+		FuncPost:      postTestAppBadGatewayError,
+		TokenAccessor: getSimpleTokenAccessor(),
+		OnSessionRenew: func(_ context.Context, _ string) {
+			called = true
+		},
+	}
+	if err := renewRestfulSession(context.Background(), sr, time.Second); err == nil {
+		t.Fatal("should have failed to renew the session")
+	}
+	if called {
+		t.Fatal("callback should not be invoked when the renewal fails")
+	}
+}
+
+type headerRecordingTransport struct {
+	mu      sync.Mutex
+	headers map[string]http.Header // by URL path
+}
+
+func (rt *headerRecordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	rt.headers[req.URL.Path] = req.Header.Clone()
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(bytes.NewReader([]byte(`{"success": true}`))),
+		Request:    req,
+	}, nil
+}
+
+func TestUnitExtraHeadersOnSessionRequests(t *testing.T) {
+	transport := &headerRecordingTransport{headers: make(map[string]http.Header)}
+	sc, err := buildSnowflakeConn(context.Background(), Config{
+		Account:      "a",
+		Host:         "a.snowflakecomputing.com",
+		Transporter:  transport,
+		ExtraHeaders: map[string]string{"x-tenant-id": "tenant1", "User-Agent": "ignored"},
+		UserAgent:    "custom-agent/1.0",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc.rest.TokenAccessor.SetTokens("token", "master", 1)
+	if err = (&heartbeat{restful: sc.rest}).heartbeatMain(); err != nil {
+		t.Fatal(err)
+	}
+	if err = closeSession(context.Background(), sc.rest, 0); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{heartBeatPath, sessionRequestPath} {
+		header, ok := transport.headers[path]
+		if !ok {
+			t.Fatalf("no request sent to %v", path)
+		}
+		if v := header.Get("X-Tenant-Id"); v != "tenant1" {
+			t.Fatalf("extra header should be sent to %v, got: %v", path, v)
+		}
+		if v := header.Get(httpHeaderUserAgent); v != "custom-agent/1.0" {
+			t.Fatalf("custom user agent should be sent to %v, got: %v", path, v)
+		}
+		if v := header.Get(headerAuthorizationKey); v != fmt.Sprintf(headerSnowflakeToken, "token") {
+			t.Fatalf("authorization header should be kept for %v, got: %v", path, v)
+		}
+	}
+}
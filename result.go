@@ -18,15 +18,51 @@ type SnowflakeResult interface {
 	GetQueryID() string
 	GetStatus() queryStatus
 	GetArrowBatches() ([]*ArrowBatch, error)
+	Warnings() []string
+	QueryStats() *QueryStats
+	SessionContext() SessionContext
+	ChildQueryIDs() []string
+}
+
+// QueryStats are the statistics of a completed query the server returned with its result,
+// e.g. to track the cost of queries without querying ACCOUNT_USAGE.
+type QueryStats struct {
+	BytesScanned      int64   `json:"bytesScanned"`      // bytes read from the storage
+	PartitionsScanned int64   `json:"partitionsScanned"` // micro-partitions read
+	PartitionsTotal   int64   `json:"partitionsTotal"`   // micro-partitions of the tables read, before pruning
+	CreditsUsed       float64 `json:"creditsUsed"`       // credits consumed by the cloud services for the query
+}
+
+// SessionContext is the database, schema, warehouse and role Snowflake used to run a query, as returned
+// with its result. It shows the effect of the USE commands and of the overrides of the query. The fields
+// the server didn't return are empty.
+type SessionContext struct {
+	Database  string
+	Schema    string
+	Warehouse string
+	Role      string
+}
+
+func sessionContextOf(data *execResponseData) SessionContext {
+	return SessionContext{
+		Database:  data.FinalDatabaseName,
+		Schema:    data.FinalSchemaName,
+		Warehouse: data.FinalWarehouseName,
+		Role:      data.FinalRoleName,
+	}
 }
 
 type snowflakeResult struct {
 	affectedRows int64
 	insertID     int64 // Snowflake doesn't support last insert id
 	queryID      string
+	childIDs     []string
 	status       queryStatus
 	err          error
 	errChannel   chan error
+	warnings     []string
+	stats        *QueryStats
+	session      SessionContext
 }
 
 func (res *snowflakeResult) LastInsertId() (int64, error) {
@@ -51,6 +87,40 @@ func (res *snowflakeResult) GetStatus() queryStatus {
 	return res.status
 }
 
+// Warnings returns the warnings the server returned with the result, e.g. about implicit casts.
+func (res *snowflakeResult) Warnings() []string {
+	if err := res.waitForAsyncExecStatus(); err != nil {
+		return nil
+	}
+	return res.warnings
+}
+
+// QueryStats returns the statistics the server returned with the result, or nil if there are none.
+func (res *snowflakeResult) QueryStats() *QueryStats {
+	if err := res.waitForAsyncExecStatus(); err != nil {
+		return nil
+	}
+	return res.stats
+}
+
+// SessionContext returns the database, schema, warehouse and role the server used to run the statement.
+func (res *snowflakeResult) SessionContext() SessionContext {
+	if err := res.waitForAsyncExecStatus(); err != nil {
+		return SessionContext{}
+	}
+	return res.session
+}
+
+// ChildQueryIDs returns the query IDs of the statements of a multi-statement query in their order, so that
+// each of them can be looked up in the query history. GetQueryID returns the ID of the multi-statement query
+// itself. ChildQueryIDs returns nil for a single statement.
+func (res *snowflakeResult) ChildQueryIDs() []string {
+	if err := res.waitForAsyncExecStatus(); err != nil {
+		return nil
+	}
+	return res.childIDs
+}
+
 func (res *snowflakeResult) GetArrowBatches() ([]*ArrowBatch, error) {
 	return nil, &SnowflakeError{
 		Number:  ErrNotImplemented,
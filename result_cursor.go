@@ -0,0 +1,260 @@
+// Copyright (c) 2023 Snowflake Computing Inc. All rights reserved.
+
+package gosnowflake
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ResultCursor reads the result of a finished query one chunk at a time. Unlike sql.Rows, which
+// prefetches chunks in the background, a chunk is only downloaded when NextChunk is called, and
+// the position can be saved with Checkpoint and restored with ResumeResultCursor, e.g. to resume
+// an ETL job reading a very large result after a failure.
+//
+// Chunk 0 is the part of the result sent with the query response; the other chunks are downloaded
+// from the cloud storage. A ResultCursor is not safe for concurrent use.
+//
+// A ResultCursor can also be opened on a query that is still running, e.g. one submitted with
+// WithAsyncMode. Then NextChunk waits for the next chunk to be produced, so that the first chunks
+// can be processed before the query completes.
+type ResultCursor struct {
+	queryID string
+	scd     *snowflakeChunkDownloader
+	loc     *time.Location
+	next    int
+	running bool
+}
+
+// resultCursorPollInterval is the pause between the requests for the chunks of a running query.
+var resultCursorPollInterval = 500 * time.Millisecond
+
+func isQueryInProgress(code string) bool {
+	return code == queryInProgressCode || code == queryInProgressAsyncCode
+}
+
+// ResultCursorCheckpoint is the position of a ResultCursor. It can be serialized as JSON and
+// passed to ResumeResultCursor, possibly on another connection of the same user, to continue
+// reading the result as long as it is available on the server.
+type ResultCursorCheckpoint struct {
+	QueryID    string `json:"queryId"`
+	ChunkIndex int    `json:"chunkIndex"` // index of the next chunk to read
+}
+
+// OpenResultCursor returns a ResultCursor positioned at the first chunk of the result of the
+// query with the given ID.
+func (sc *snowflakeConn) OpenResultCursor(ctx context.Context, queryID string) (*ResultCursor, error) {
+	resp, err := sc.getResultCursorResp(ctx, queryID)
+	if err != nil {
+		return nil, err
+	}
+	rc := &ResultCursor{
+		queryID: queryID,
+		scd: &snowflakeChunkDownloader{
+			sc:                 sc,
+			ctx:                ctx,
+			pool:               getAllocator(ctx),
+			Chunks:             make(map[int][]chunkRowType),
+			ChunksMutex:        &sync.Mutex{},
+			FuncDownloadHelper: downloadChunkHelper,
+			FuncGet:            getChunk,
+		},
+		loc: getCurrentLocation(sc.cfg.Params),
+	}
+	rc.update(resp)
+	return rc, nil
+}
+
+// getResultCursorResp fetches the result of the query, or the part of it produced so far if it is running.
+func (sc *snowflakeConn) getResultCursorResp(ctx context.Context, queryID string) (*execResponse, error) {
+	resp, err := sc.getQueryResultResp(ctx, fmt.Sprintf(urlQueriesResultFmt, queryID))
+	if err != nil {
+		logger.WithContext(ctx).Errorf("error: %v", err)
+		return nil, err
+	}
+	if !resp.Success {
+		code, err := strconv.Atoi(resp.Code)
+		if err != nil {
+			return nil, err
+		}
+		return nil, (&SnowflakeError{
+			Number:   code,
+			SQLState: resp.Data.SQLState,
+			Message:  resp.Message,
+			QueryID:  queryID,
+		}).exceptionTelemetry(sc)
+	}
+	return resp, nil
+}
+
+// update replaces the description of the result with the one of the response. The chunks
+// of a running query are listed as they are produced, so the known chunks are kept.
+func (rc *ResultCursor) update(resp *execResponse) {
+	data := resp.Data
+	rc.running = isQueryInProgress(resp.Code)
+	scd := rc.scd
+	if len(data.RowType) == 0 {
+		// nothing has been produced yet
+		return
+	}
+	if len(data.Chunks) >= len(scd.ChunkMetas) {
+		scd.ChunkMetas = data.Chunks
+	}
+	scd.Total = data.Total
+	scd.CellCount = len(data.RowType)
+	scd.Qrmk = data.Qrmk
+	scd.QueryResultFormat = data.QueryResultFormat
+	scd.ChunkHeader = data.ChunkHeaders
+	scd.RowSet = rowSetType{
+		RowType:      data.RowType,
+		JSON:         data.RowSet,
+		RowSetBase64: data.RowSetBase64,
+	}
+}
+
+// waitForChunks polls the result of the running query until a new chunk is produced or the query completes.
+func (rc *ResultCursor) waitForChunks(ctx context.Context) error {
+	known := rc.ChunkCount()
+	for rc.running && rc.ChunkCount() == known {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(resultCursorPollInterval):
+		}
+		resp, err := rc.scd.sc.getResultCursorResp(ctx, rc.queryID)
+		if err != nil {
+			return err
+		}
+		rc.update(resp)
+	}
+	return nil
+}
+
+// ResumeResultCursor returns a ResultCursor positioned at the chunk saved in the checkpoint.
+// The result is fetched again, so the download URLs of the chunks don't expire with the checkpoint.
+func (sc *snowflakeConn) ResumeResultCursor(ctx context.Context, checkpoint ResultCursorCheckpoint) (*ResultCursor, error) {
+	cursor, err := sc.OpenResultCursor(ctx, checkpoint.QueryID)
+	if err != nil {
+		return nil, err
+	}
+	if checkpoint.ChunkIndex < 0 || !cursor.running && checkpoint.ChunkIndex > cursor.ChunkCount() {
+		return nil, &SnowflakeError{
+			Number:      ErrInvalidResultCursorCheckpoint,
+			Message:     errMsgInvalidResultCursorCheckpoint,
+			MessageArgs: []interface{}{checkpoint.ChunkIndex, cursor.ChunkCount()},
+			QueryID:     checkpoint.QueryID,
+		}
+	}
+	cursor.next = checkpoint.ChunkIndex
+	return cursor, nil
+}
+
+// QueryID returns the ID of the query the result belongs to.
+func (rc *ResultCursor) QueryID() string {
+	return rc.queryID
+}
+
+// Columns returns the column names of the result.
+func (rc *ResultCursor) Columns() []string {
+	columns := make([]string, len(rc.scd.RowSet.RowType))
+	for i, column := range rc.scd.RowSet.RowType {
+		columns[i] = column.Name
+	}
+	return columns
+}
+
+// ChunkCount returns the number of chunks of the result, including the chunk sent with the query response.
+// If the query is still running, only the chunks produced so far are counted.
+func (rc *ResultCursor) ChunkCount() int {
+	if rc.running && len(rc.scd.RowSet.RowType) == 0 {
+		return 0
+	}
+	return len(rc.scd.ChunkMetas) + 1
+}
+
+// Running returns true if the query was still running when the cursor last fetched its result.
+func (rc *ResultCursor) Running() bool {
+	return rc.running
+}
+
+// Checkpoint returns the current position of the cursor.
+func (rc *ResultCursor) Checkpoint() ResultCursorCheckpoint {
+	return ResultCursorCheckpoint{QueryID: rc.queryID, ChunkIndex: rc.next}
+}
+
+// NextChunk downloads the next chunk and returns its rows, converted as they would be by sql.Rows.
+// It returns io.EOF after the last chunk. If an error is returned, the cursor stays at the same
+// chunk, so NextChunk can be called again to retry the download. If the query is still running,
+// NextChunk waits until the next chunk is produced or the query completes, or ctx is canceled.
+func (rc *ResultCursor) NextChunk(ctx context.Context) ([][]driver.Value, error) {
+	for rc.running && rc.next >= rc.ChunkCount() {
+		if err := rc.waitForChunks(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if rc.next >= rc.ChunkCount() {
+		return nil, io.EOF
+	}
+	if rc.scd.getQueryResultFormat() == arrowFormat {
+		// the columns of a running query are known once it produces its first rows
+		projection, err := getColumnProjection(rc.scd.ctx, rc.scd.RowSet.RowType)
+		if err != nil {
+			return nil, err
+		}
+		rc.scd.projection = projection
+	}
+	var chunk []chunkRowType
+	if rc.next == 0 {
+		var err error
+		if chunk, err = rc.firstChunk(); err != nil {
+			return nil, err
+		}
+	} else {
+		idx := rc.next - 1
+		if err := rc.scd.FuncDownloadHelper(ctx, rc.scd, idx); err != nil {
+			return nil, err
+		}
+		rc.scd.ChunksMutex.Lock()
+		chunk = rc.scd.Chunks[idx]
+		delete(rc.scd.Chunks, idx)
+		rc.scd.ChunksMutex.Unlock()
+	}
+	rowType := rc.scd.RowSet.RowType
+	rows := make([][]driver.Value, len(chunk))
+	for i, row := range chunk {
+		dest := make([]driver.Value, len(rowType))
+		if row.ArrowRow != nil {
+			for j := range dest {
+				dest[j] = row.ArrowRow[j]
+			}
+		} else {
+			for j := range dest {
+				if err := stringToValue(&dest[j], rowType[j], row.RowSet[j], rc.loc); err != nil {
+					return nil, err
+				}
+			}
+		}
+		rows[i] = dest
+	}
+	rc.next++
+	return rows, nil
+}
+
+// firstChunk decodes the rows sent with the query response.
+func (rc *ResultCursor) firstChunk() ([]chunkRowType, error) {
+	if rc.scd.getQueryResultFormat() == arrowFormat {
+		if rc.scd.RowSet.RowSetBase64 == "" {
+			return nil, nil
+		}
+		arc := buildFirstArrowChunk(rc.scd.RowSet.RowSetBase64, rc.loc, rc.scd.pool)
+		return arc.decodeArrowChunk(rc.scd.RowSet.RowType, higherPrecisionEnabled(rc.scd.ctx), rc.scd.projection)
+	}
+	chunk := make([]chunkRowType, len(rc.scd.RowSet.JSON))
+	populateJSONRowSet(chunk, rc.scd.RowSet.JSON)
+	return chunk, nil
+}
@@ -0,0 +1,261 @@
+// Copyright (c) 2023 Snowflake Computing Inc. All rights reserved.
+
+package gosnowflake
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// getResultCursorSnowflakeConn returns a connection serving a JSON result with an inline rowset and
+// three chunks of two rows each.
+func getResultCursorSnowflakeConn(t *testing.T, resultRequests *int) *snowflakeConn {
+	sc := getDefaultSnowflakeConn()
+	sc.currentTimeProvider = defaultTimeProvider
+	first, second := "1", "a"
+	sc.rest.FuncGet = func(_ context.Context, _ *snowflakeRestful, u *url.URL,
+		_ map[string]string, _ time.Duration) (*http.Response, error) {
+		if !strings.HasSuffix(u.Path, fmt.Sprintf(urlQueriesResultFmt, "qid")) {
+			t.Fatalf("unexpected URL: %v", u)
+		}
+		*resultRequests++
+		er := &execResponse{
+			Data: execResponseData{
+				QueryID:           "qid",
+				RowType:           []execResponseRowType{{Name: "ID", Type: "fixed"}, {Name: "V", Type: "text"}},
+				RowSet:            [][]*string{{&first, &second}},
+				QueryResultFormat: "json",
+				Total:             7,
+				Chunks: []execResponseChunk{
+					{URL: "chunk0", RowCount: 2},
+					{URL: "chunk1", RowCount: 2},
+					{URL: "chunk2", RowCount: 2},
+				},
+			},
+			Success: true,
+		}
+		ba, err := json.Marshal(er)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(ba))}, nil
+	}
+	return sc
+}
+
+// mockResultCursorChunks serves the chunks of the cursor and records the downloaded URLs.
+func mockResultCursorChunks(cursor *ResultCursor, downloaded *[]string) {
+	bodies := map[string]string{
+		"chunk0": `["2","b"],["3","c"]`,
+		"chunk1": `["4","d"],["5","e"]`,
+		"chunk2": `["6","f"],["7","g"]`,
+	}
+	cursor.scd.FuncGet = func(_ context.Context, _ *snowflakeConn, url string,
+		_ map[string]string, _ time.Duration) (*http.Response, error) {
+		*downloaded = append(*downloaded, url)
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(bodies[url]))}, nil
+	}
+}
+
+func TestResultCursorResumeFromCheckpoint(t *testing.T) {
+	var resultRequests int
+	var downloaded []string
+	sc := getResultCursorSnowflakeConn(t, &resultRequests)
+	ctx := context.Background()
+
+	cursor, err := sc.OpenResultCursor(ctx, "qid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mockResultCursorChunks(cursor, &downloaded)
+	if cursor.ChunkCount() != 4 {
+		t.Fatalf("expected 4 chunks, got: %v", cursor.ChunkCount())
+	}
+	if columns := cursor.Columns(); len(columns) != 2 || columns[0] != "ID" || columns[1] != "V" {
+		t.Fatalf("unexpected columns: %v", columns)
+	}
+	var consumed []string
+	for i := 0; i < cursor.ChunkCount()/2; i++ {
+		rows, err := cursor.NextChunk(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, row := range rows {
+			consumed = append(consumed, fmt.Sprint(row[0], row[1]))
+		}
+	}
+	if len(downloaded) != 1 || downloaded[0] != "chunk0" {
+		t.Fatalf("chunks should be downloaded on demand. downloaded: %v", downloaded)
+	}
+
+	b, err := json.Marshal(cursor.Checkpoint())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `{"queryId":"qid","chunkIndex":2}` {
+		t.Fatalf("unexpected checkpoint: %s", b)
+	}
+	var checkpoint ResultCursorCheckpoint
+	if err = json.Unmarshal(b, &checkpoint); err != nil {
+		t.Fatal(err)
+	}
+	var conn SnowflakeConnection = sc
+	resumed, err := conn.ResumeResultCursor(ctx, checkpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mockResultCursorChunks(resumed, &downloaded)
+	for {
+		rows, err := resumed.NextChunk(ctx)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, row := range rows {
+			consumed = append(consumed, fmt.Sprint(row[0], row[1]))
+		}
+	}
+	expected := []string{"1a", "2b", "3c", "4d", "5e", "6f", "7g"}
+	if strings.Join(consumed, ",") != strings.Join(expected, ",") {
+		t.Fatalf("unexpected rows. expected: %v, got: %v", expected, consumed)
+	}
+	if strings.Join(downloaded, ",") != "chunk0,chunk1,chunk2" {
+		t.Fatalf("each chunk should be downloaded once. downloaded: %v", downloaded)
+	}
+	if resultRequests != 2 {
+		t.Fatalf("the result should be fetched again on resume. requests: %v", resultRequests)
+	}
+	if resumed.Checkpoint().ChunkIndex != resumed.ChunkCount() {
+		t.Fatalf("unexpected checkpoint after the last chunk: %+v", resumed.Checkpoint())
+	}
+}
+
+func TestResultCursorInvalidCheckpoint(t *testing.T) {
+	var resultRequests int
+	sc := getResultCursorSnowflakeConn(t, &resultRequests)
+	_, err := sc.ResumeResultCursor(context.Background(), ResultCursorCheckpoint{QueryID: "qid", ChunkIndex: 5})
+	var se *SnowflakeError
+	if !errors.As(err, &se) || se.Number != ErrInvalidResultCursorCheckpoint {
+		t.Fatalf("expected error %v, got: %v", ErrInvalidResultCursorCheckpoint, err)
+	}
+}
+
+func TestResultCursorOnRunningQuery(t *testing.T) {
+	defer func(interval time.Duration) { resultCursorPollInterval = interval }(resultCursorPollInterval)
+	resultCursorPollInterval = time.Millisecond
+	first, second := "1", "a"
+	rowType := []execResponseRowType{{Name: "ID", Type: "fixed"}, {Name: "V", Type: "text"}}
+	responses := []execResponse{
+		// nothing produced yet
+		{Code: queryInProgressAsyncCode, Success: true, Data: execResponseData{QueryID: "qid"}},
+		// the inline rowset and the first chunk are available
+		{Code: queryInProgressAsyncCode, Success: true, Data: execResponseData{
+			QueryID: "qid", RowType: rowType, RowSet: [][]*string{{&first, &second}}, QueryResultFormat: "json",
+			Chunks: []execResponseChunk{{URL: "chunk0", RowCount: 2}},
+		}},
+		// no progress since the last request
+		{Code: queryInProgressAsyncCode, Success: true, Data: execResponseData{
+			QueryID: "qid", RowType: rowType, RowSet: [][]*string{{&first, &second}}, QueryResultFormat: "json",
+			Chunks: []execResponseChunk{{URL: "chunk0", RowCount: 2}},
+		}},
+		// the query completed
+		{Success: true, Data: execResponseData{
+			QueryID: "qid", RowType: rowType, RowSet: [][]*string{{&first, &second}}, QueryResultFormat: "json", Total: 5,
+			Chunks: []execResponseChunk{{URL: "chunk0", RowCount: 2}, {URL: "chunk1", RowCount: 2}},
+		}},
+	}
+	var resultRequests int
+	sc := getDefaultSnowflakeConn()
+	sc.currentTimeProvider = defaultTimeProvider
+	sc.rest.FuncGet = func(_ context.Context, _ *snowflakeRestful, _ *url.URL,
+		_ map[string]string, _ time.Duration) (*http.Response, error) {
+		er := responses[resultRequests]
+		resultRequests++
+		ba, err := json.Marshal(er)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(ba))}, nil
+	}
+	ctx := context.Background()
+
+	cursor, err := sc.OpenResultCursor(ctx, "qid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var downloaded []string
+	mockResultCursorChunks(cursor, &downloaded)
+	if !cursor.Running() || cursor.ChunkCount() != 0 {
+		t.Fatalf("no chunk should be available yet. running: %v, chunks: %v", cursor.Running(), cursor.ChunkCount())
+	}
+	var consumed []string
+	for i := 0; i < 2; i++ {
+		rows, err := cursor.NextChunk(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, row := range rows {
+			consumed = append(consumed, fmt.Sprint(row[0], row[1]))
+		}
+	}
+	if !cursor.Running() || resultRequests != 2 {
+		t.Fatalf("the first chunks should be read while the query runs. running: %v, requests: %v",
+			cursor.Running(), resultRequests)
+	}
+	for {
+		rows, err := cursor.NextChunk(ctx)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, row := range rows {
+			consumed = append(consumed, fmt.Sprint(row[0], row[1]))
+		}
+	}
+	expected := []string{"1a", "2b", "3c", "4d", "5e"}
+	if strings.Join(consumed, ",") != strings.Join(expected, ",") {
+		t.Fatalf("unexpected rows. expected: %v, got: %v", expected, consumed)
+	}
+	if cursor.Running() || resultRequests != len(responses) {
+		t.Fatalf("the cursor should poll until the query completes. running: %v, requests: %v",
+			cursor.Running(), resultRequests)
+	}
+}
+
+func TestResultCursorOnRunningQueryCanceled(t *testing.T) {
+	sc := getDefaultSnowflakeConn()
+	sc.currentTimeProvider = defaultTimeProvider
+	sc.rest.FuncGet = func(_ context.Context, _ *snowflakeRestful, _ *url.URL,
+		_ map[string]string, _ time.Duration) (*http.Response, error) {
+		ba, err := json.Marshal(&execResponse{Code: queryInProgressAsyncCode, Success: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(ba))}, nil
+	}
+	cursor, err := sc.OpenResultCursor(context.Background(), "qid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err = cursor.NextChunk(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("the wait for a chunk should end with the context, err: %v", err)
+	}
+	if cursor.Checkpoint().ChunkIndex != 0 {
+		t.Fatalf("the cursor should not move, checkpoint: %+v", cursor.Checkpoint())
+	}
+}
@@ -5,16 +5,20 @@ package gosnowflake
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"crypto/x509"
+	"errors"
 	"fmt"
 	"io"
 	"math/rand"
+	"net"
 	"net/http"
 	"net/url"
 	"runtime"
 	"strconv"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 )
 
@@ -29,7 +33,7 @@ const (
 	requestGUIDKey string = "request_guid"
 	// retryCountKey is attached to query-request from the second time
 	retryCountKey string = "retryCount"
-	// retryReasonKey contains last HTTP status or 0 if timeout
+	// retryReasonKey contains last HTTP status, 0 if timeout or one of the retryReason* codes for other transport errors
 	retryReasonKey string = "retryReason"
 	// clientStartTime contains a time when client started request (first request, not retries)
 	clientStartTimeKey string = "clientStartTime"
@@ -37,6 +41,64 @@ const (
 	requestIDKey string = "requestId"
 )
 
+// Retry reasons of requests failed without an HTTP response. They don't overlap with HTTP status codes.
+const (
+	retryReasonTimeout           = 0
+	retryReasonConnectionReset   = 1
+	retryReasonConnectionRefused = 2
+	retryReasonTLS               = 3
+	retryReasonDNS               = 4
+	retryReasonUnexpectedEOF     = 5
+	retryReasonTransportError    = 9
+)
+
+var retryReasonNames = map[int]string{
+	retryReasonTimeout:           "timeout",
+	retryReasonConnectionReset:   "connection reset",
+	retryReasonConnectionRefused: "connection refused",
+	retryReasonTLS:               "TLS error",
+	retryReasonDNS:               "DNS error",
+	retryReasonUnexpectedEOF:     "unexpected EOF",
+	retryReasonTransportError:    "transport error",
+}
+
+// transportRetryReason returns the retry reason of a request failed with err without an HTTP response.
+func transportRetryReason(err error) int {
+	var dnsError *net.DNSError
+	var netError net.Error
+	var recordHeaderError tls.RecordHeaderError
+	var unknownAuthorityError x509.UnknownAuthorityError
+	var certificateInvalidError x509.CertificateInvalidError
+	var hostnameError x509.HostnameError
+	switch {
+	case err == nil:
+		return retryReasonTimeout
+	case errors.As(err, &dnsError):
+		return retryReasonDNS
+	case errors.As(err, &netError) && netError.Timeout():
+		return retryReasonTimeout
+	case errors.Is(err, syscall.ECONNRESET):
+		return retryReasonConnectionReset
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return retryReasonConnectionRefused
+	case errors.As(err, &recordHeaderError), errors.As(err, &unknownAuthorityError),
+		errors.As(err, &certificateInvalidError), errors.As(err, &hostnameError),
+		strings.Contains(err.Error(), "tls: "):
+		return retryReasonTLS
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return retryReasonUnexpectedEOF
+	}
+	return retryReasonTransportError
+}
+
+// retryReasonString describes the retry reason in the logs.
+func retryReasonString(reason int) string {
+	if name, ok := retryReasonNames[reason]; ok {
+		return fmt.Sprintf("%v (%v)", reason, name)
+	}
+	return fmt.Sprintf("%v (HTTP status)", reason)
+}
+
 // This class takes in an url during construction and replaces the value of
 // request_guid every time replace() is called. If the url does not contain
 // request_guid, just return the original url
@@ -57,7 +119,7 @@ func newRequestGUIDReplace(urlPtr *url.URL) requestGUIDReplacer {
 		return &transientReplace{urlPtr}
 	}
 
-	return &requestGUIDReplace{urlPtr, values}
+	return &requestGUIDReplace{urlPtr}
 }
 
 // this replacer does nothing but replace the url
@@ -74,19 +136,20 @@ requestGUIDReplacer is a one-shot object that is created out of the retry loop a
 called with replace to change the retry_guid's value upon every retry
 */
 type requestGUIDReplace struct {
-	urlPtr    *url.URL
-	urlValues url.Values
+	urlPtr *url.URL
 }
 
 /*
 *
 This function would replace they value of the requestGUIDKey in a url with a newly
-generated UUID
+generated UUID. The current query parameters are used so the values set by the other
+updaters are preserved.
 */
 func (replacer *requestGUIDReplace) replace() *url.URL {
-	replacer.urlValues.Del(requestGUIDKey)
-	replacer.urlValues.Add(requestGUIDKey, NewUUID().String())
-	replacer.urlPtr.RawQuery = replacer.urlValues.Encode()
+	urlValues := replacer.urlPtr.Query()
+	urlValues.Del(requestGUIDKey)
+	urlValues.Add(requestGUIDKey, NewUUID().String())
+	replacer.urlPtr.RawQuery = urlValues.Encode()
 	return replacer.urlPtr
 }
 
@@ -95,8 +158,7 @@ type retryCountUpdater interface {
 }
 
 type retryCountUpdate struct {
-	urlPtr    *url.URL
-	urlValues url.Values
+	urlPtr *url.URL
 }
 
 // this replacer does nothing but replace the url
@@ -109,9 +171,10 @@ func (replaceOrAdder *transientRetryCountUpdater) replaceOrAdd(retry int) *url.U
 }
 
 func (replacer *retryCountUpdate) replaceOrAdd(retry int) *url.URL {
-	replacer.urlValues.Del(retryCountKey)
-	replacer.urlValues.Add(retryCountKey, strconv.Itoa(retry))
-	replacer.urlPtr.RawQuery = replacer.urlValues.Encode()
+	urlValues := replacer.urlPtr.Query()
+	urlValues.Del(retryCountKey)
+	urlValues.Add(retryCountKey, strconv.Itoa(retry))
+	replacer.urlPtr.RawQuery = urlValues.Encode()
 	return replacer.urlPtr
 }
 
@@ -120,12 +183,11 @@ func newRetryCountUpdater(urlPtr *url.URL) retryCountUpdater {
 		// nop if not query-request
 		return &transientRetryCountUpdater{urlPtr}
 	}
-	values, err := url.ParseQuery(urlPtr.RawQuery)
-	if err != nil {
+	if _, err := url.ParseQuery(urlPtr.RawQuery); err != nil {
 		// nop if the URL is not valid
 		return &transientRetryCountUpdater{urlPtr}
 	}
-	return &retryCountUpdate{urlPtr, values}
+	return &retryCountUpdate{urlPtr}
 }
 
 type retryReasonUpdater interface {
@@ -178,17 +240,33 @@ func ensureClientStartTimeIsSet(url *url.URL, clientStartTime string) *url.URL {
 	return url
 }
 
+// ensureRequestIDIsPinned keeps the requestId of the first attempt on all retries,
+// so the server can detect a duplicated execution of the same logical request.
+func ensureRequestIDIsPinned(url *url.URL, requestID string) *url.URL {
+	if requestID == "" {
+		return url
+	}
+	query := url.Query()
+	if query.Get(requestIDKey) == requestID {
+		return url
+	}
+	query.Set(requestIDKey, requestID)
+	url.RawQuery = query.Encode()
+	return url
+}
+
 func isQueryRequest(url *url.URL) bool {
 	return strings.HasPrefix(url.Path, queryRequestPath)
 }
 
 type waitAlgo struct {
-	mutex *sync.Mutex   // required for random.Int63n
-	base  time.Duration // base wait time
-	cap   time.Duration // maximum wait time
+	mutex  *sync.Mutex   // required for random.Int63n
+	base   time.Duration // base wait time
+	cap    time.Duration // maximum wait time
+	random *rand.Rand    // source of the jitter. The package random source is used if nil
 }
 
-func randSecondDuration(n time.Duration) time.Duration {
+func randSecondDuration(random *rand.Rand, n time.Duration) time.Duration {
 	return time.Duration(random.Int63n(int64(n/time.Second))) * time.Second
 }
 
@@ -196,12 +274,16 @@ func randSecondDuration(n time.Duration) time.Duration {
 func (w *waitAlgo) decorr(attempt int, sleep time.Duration) time.Duration {
 	w.mutex.Lock()
 	defer w.mutex.Unlock()
+	r := w.random
+	if r == nil {
+		r = random
+	}
 	t := 3*sleep - w.base
 	switch {
 	case t > 0:
-		return durationMin(w.cap, randSecondDuration(t)+w.base)
+		return durationMin(w.cap, randSecondDuration(r, t)+w.base)
 	case t < 0:
-		return durationMin(w.cap, randSecondDuration(-t)+3*sleep)
+		return durationMin(w.cap, randSecondDuration(r, -t)+3*sleep)
 	}
 	return w.base
 }
@@ -212,6 +294,17 @@ var defaultWaitAlgo = &waitAlgo{
 	cap:   160 * time.Second,
 }
 
+// newSeededWaitAlgo returns the default backoff with a jitter source of its own, so that the
+// sequence of sleeps is the same for the same seed.
+func newSeededWaitAlgo(seed int64) *waitAlgo {
+	return &waitAlgo{
+		mutex:  &sync.Mutex{},
+		base:   defaultWaitAlgo.base,
+		cap:    defaultWaitAlgo.cap,
+		random: rand.New(rand.NewSource(seed)),
+	}
+}
+
 type requestFunc func(method, urlStr string, body io.Reader) (*http.Request, error)
 
 type clientInterface interface {
@@ -230,6 +323,41 @@ type retryHTTP struct {
 	raise4XX            bool
 	currentTimeProvider currentTimeProvider
 	cfg                 *Config
+	extraHeaders        map[string]string
+	statusCounts        *httpStatusCounter
+	waitAlgo            *waitAlgo
+}
+
+// httpStatusCounter counts the HTTP responses received by a connection by status code.
+type httpStatusCounter struct {
+	mutex  sync.Mutex
+	counts map[int]int64
+}
+
+func (c *httpStatusCounter) add(statusCode int) {
+	if c == nil {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.counts == nil {
+		c.counts = make(map[int]int64)
+	}
+	c.counts[statusCode]++
+}
+
+// snapshot returns a copy of the counts.
+func (c *httpStatusCounter) snapshot() map[int]int64 {
+	counts := make(map[int]int64)
+	if c == nil {
+		return counts
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for statusCode, count := range c.counts {
+		counts[statusCode] = count
+	}
+	return counts
 }
 
 func newRetryHTTP(ctx context.Context,
@@ -252,6 +380,7 @@ func newRetryHTTP(ctx context.Context,
 	instance.raise4XX = false
 	instance.currentTimeProvider = currentTimeProvider
 	instance.cfg = cfg
+	instance.waitAlgo = defaultWaitAlgo
 	return &instance
 }
 
@@ -260,6 +389,26 @@ func (r *retryHTTP) doRaise4XX(raise4XX bool) *retryHTTP {
 	return r
 }
 
+// withExtraHeaders adds the headers of the connection, see newExtraHeaders, to every request sent.
+func (r *retryHTTP) withExtraHeaders(extraHeaders map[string]string) *retryHTTP {
+	r.extraHeaders = extraHeaders
+	return r
+}
+
+// countStatuses records the status code of every response received, including the retried ones.
+func (r *retryHTTP) countStatuses(statusCounts *httpStatusCounter) *retryHTTP {
+	r.statusCounts = statusCounts
+	return r
+}
+
+// withWaitAlgo replaces the default backoff between the retries. A nil waitAlgo keeps the default.
+func (r *retryHTTP) withWaitAlgo(w *waitAlgo) *retryHTTP {
+	if w != nil {
+		r.waitAlgo = w
+	}
+	return r
+}
+
 func (r *retryHTTP) doPost() *retryHTTP {
 	r.method = "POST"
 	return r
@@ -283,13 +432,19 @@ func (r *retryHTTP) execute() (res *http.Response, err error) {
 	retryCounter := 0
 	sleepTime := time.Duration(0)
 	clientStartTime := strconv.FormatInt(r.currentTimeProvider.currentTime(), 10)
+	// requestId is set once per logical request and must not change between attempts
+	requestID := r.fullURL.Query().Get(requestIDKey)
 
 	var requestGUIDReplacer requestGUIDReplacer
 	var retryCountUpdater retryCountUpdater
 	var retryReasonUpdater retryReasonUpdater
+	breaker := newCircuitBreaker(r.cfg, r.fullURL.Host)
 
 	for {
 		logger.Debugf("retry count: %v", retryCounter)
+		if err = breaker.allow(); err != nil {
+			return nil, err
+		}
 		body, err := r.bodyCreator()
 		if err != nil {
 			return nil, err
@@ -302,7 +457,12 @@ func (r *retryHTTP) execute() (res *http.Response, err error) {
 			// req can be nil in tests
 			req = req.WithContext(r.ctx)
 		}
+		headers := make(map[string]string, len(r.headers))
 		for k, v := range r.headers {
+			headers[k] = v
+		}
+		addExtraHeaders(headers, r.extraHeaders)
+		for k, v := range headers {
 			req.Header.Set(k, v)
 		}
 		res, err = r.client.Do(req)
@@ -310,12 +470,16 @@ func (r *retryHTTP) execute() (res *http.Response, err error) {
 			// check if it can retry.
 			doExit, err := r.isRetryableError(err)
 			if doExit {
+				breaker.abandon()
 				return res, err
 			}
+			breaker.record(true)
 			// cannot just return 4xx and 5xx status as the error can be sporadic. run often helps.
 			logger.WithContext(r.ctx).Warningf(
 				"failed http connection. no response is returned. err: %v. retrying...\n", err)
 		} else {
+			r.statusCounts.add(res.StatusCode)
+			breaker.record(res.StatusCode >= 500 || res.StatusCode == http.StatusTooManyRequests)
 			if res.StatusCode == http.StatusOK || r.raise4XX && res != nil && res.StatusCode >= 400 && res.StatusCode < 500 && res.StatusCode != 429 {
 				// exit if success
 				// or
@@ -323,12 +487,34 @@ func (r *retryHTTP) execute() (res *http.Response, err error) {
 				// This is currently used for Snowflake login. The caller must generate an error object based on HTTP status.
 				break
 			}
+			if res.StatusCode == http.StatusRequestEntityTooLarge {
+				// the same body would be rejected again
+				logger.WithContext(r.ctx).Warningf(
+					"request body is too large. HTTP Status: %v. no more retries", res.StatusCode)
+				break
+			}
+			if !r.cfg.isRetryableHTTPStatus(res.StatusCode) {
+				// excluded from the retries by Config.RetryableHTTPStatuses, e.g. a permanent proxy error
+				logger.WithContext(r.ctx).Warningf(
+					"HTTP Status: %v is not retryable. no more retries", res.StatusCode)
+				break
+			}
+			if r.raise4XX && isPermanentLoginErrorResponse(res) {
+				// retrying the login cannot succeed, e.g. the credentials are incorrect
+				logger.WithContext(r.ctx).Warningf(
+					"login failed permanently. HTTP Status: %v. no more retries", res.StatusCode)
+				break
+			}
 			logger.WithContext(r.ctx).Warningf(
 				"failed http connection. HTTP Status: %v. retrying...\n", res.StatusCode)
 			res.Body.Close()
 		}
+		if breakerErr := breaker.tripped(); breakerErr != nil {
+			// no need to wait for the retry if the failure opened the circuit
+			return nil, breakerErr
+		}
 		// uses decorrelated jitter backoff
-		sleepTime = defaultWaitAlgo.decorr(retryCounter, sleepTime)
+		sleepTime = r.waitAlgo.decorr(retryCounter, sleepTime)
 
 		if totalTimeout > 0 {
 			logger.WithContext(r.ctx).Infof("to timeout: %v", totalTimeout)
@@ -356,14 +542,18 @@ func (r *retryHTTP) execute() (res *http.Response, err error) {
 		if retryReasonUpdater == nil {
 			retryReasonUpdater = newRetryReasonUpdater(r.fullURL, r.cfg)
 		}
-		retryReason := 0
+		var retryReason int
 		if res != nil {
 			retryReason = res.StatusCode
+		} else {
+			retryReason = transportRetryReason(err)
 		}
 		r.fullURL = retryReasonUpdater.replaceOrAdd(retryReason)
 		r.fullURL = ensureClientStartTimeIsSet(r.fullURL, clientStartTime)
+		r.fullURL = ensureRequestIDIsPinned(r.fullURL, requestID)
 		logger.WithContext(r.ctx).Infof("sleeping %v. to timeout: %v. retrying", sleepTime, totalTimeout)
-		logger.WithContext(r.ctx).Infof("retry count: %v, retry reason: %v", retryCounter, retryReason)
+		logger.WithContext(r.ctx).Infof("retry count: %v, retry reason: %v", retryCounter, retryReasonString(retryReason))
+		r.cfg.metricsCollector().RequestRetried(r.ctx, retryCounter, retryReason)
 
 		await := time.NewTimer(sleepTime)
 		select {
@@ -377,6 +567,36 @@ func (r *retryHTTP) execute() (res *http.Response, err error) {
 	return res, err
 }
 
+// isRetryableHTTPStatus checks if a failed response with the status code is retried.
+// Every failed response is retried unless Config.RetryableHTTPStatuses is set.
+func (c *Config) isRetryableHTTPStatus(statusCode int) bool {
+	if c == nil || c.RetryableHTTPStatuses == nil {
+		return true
+	}
+	for _, retryable := range c.RetryableHTTPStatuses {
+		if statusCode == retryable {
+			return true
+		}
+	}
+	return false
+}
+
+// isPermanentLoginErrorResponse checks if the response body contains a permanent login failure.
+// The body is preserved for the caller.
+func isPermanentLoginErrorResponse(res *http.Response) bool {
+	if res.Body == nil {
+		return false
+	}
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	res.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return false
+	}
+	_, ok := parsePermanentLoginError(body)
+	return ok
+}
+
 func (r *retryHTTP) isRetryableError(err error) (bool, error) {
 	urlError, isURLError := err.(*url.Error)
 	if isURLError {
@@ -398,6 +618,16 @@ func (r *retryHTTP) isRetryableError(err error) (bool, error) {
 			// Certificate is self-signed
 			return true, err
 		}
+		var dnsError *net.DNSError
+		if errors.As(urlError.Err, &dnsError) && dnsError.IsNotFound {
+			// Host doesn't exist, most likely a wrong account identifier or region
+			return true, errInvalidAccountURL(dnsError.Name, urlError.Err)
+		}
+		var hostnameError x509.HostnameError
+		if errors.As(urlError.Err, &hostnameError) {
+			// Certificate doesn't match the host, most likely a wrong account identifier or region
+			return true, errInvalidAccountURL(hostnameError.Host, urlError.Err)
+		}
 		errString := urlError.Err.Error()
 		if runtime.GOOS == "darwin" && strings.HasPrefix(errString, "x509:") && strings.HasSuffix(errString, "certificate is expired") {
 			// Certificate is expired
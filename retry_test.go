@@ -5,12 +5,19 @@ package gosnowflake
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
+	"os"
+	"reflect"
 	"strconv"
 	"strings"
+	"sync"
+	"syscall"
 	"testing"
 	"time"
 )
@@ -58,6 +65,8 @@ type fakeHTTPClient struct {
 	timeout             bool                      // timeout
 	body                []byte                    // return body
 	reqBody             []byte                    // last request body
+	reqHeader           http.Header               // last request header
+	reqURLs             []*url.URL                // URLs of all requests
 	statusCode          int                       // status code
 	retryNumber         int                       // consecutive number of  retries
 	expectedQueryParams map[int]map[string]string // expected query params per each retry (0-based)
@@ -71,6 +80,9 @@ func (c *fakeHTTPClient) Do(req *http.Request) (*http.Response, error) {
 		buf := new(bytes.Buffer)
 		buf.ReadFrom(req.Body)
 		c.reqBody = buf.Bytes()
+		c.reqHeader = req.Header
+		reqURL := *req.URL
+		c.reqURLs = append(c.reqURLs, &reqURL)
 	}
 
 	if len(c.expectedQueryParams) > 0 {
@@ -483,3 +495,362 @@ func TestLoginRetry429(t *testing.T) {
 		t.Fatalf("no retry counter should be attached: %v", retryCountKey)
 	}
 }
+
+func TestRetryLoginStopsOnPermanentAuthError(t *testing.T) {
+	body := []byte(`{"code":"390100","message":"Incorrect username or password was specified.","success":false}`)
+	for _, statusCode := range []int{http.StatusTooManyRequests, http.StatusServiceUnavailable} {
+		client := &fakeHTTPClient{
+			cnt:        3,
+			success:    true,
+			statusCode: statusCode,
+			body:       body,
+		}
+		urlPtr, err := url.Parse("https://fakeaccountretrylogin.snowflakecomputing.com:443/login-request?request_id=testid")
+		if err != nil {
+			t.Fatal("failed to parse the test URL")
+		}
+		res, err := newRetryHTTP(context.TODO(),
+			client,
+			emptyRequest, urlPtr, make(map[string]string), 60*time.Second, defaultTimeProvider, nil).doRaise4XX(true).doPost().setBody([]byte{0}).execute()
+		if err != nil {
+			t.Fatalf("failed to run retry. err: %v", err)
+		}
+		if client.retryNumber != 1 {
+			t.Fatalf("expected a single attempt for status %v, got: %v", statusCode, client.retryNumber)
+		}
+		if res.StatusCode != statusCode {
+			t.Fatalf("unexpected status code. expected: %v, got: %v", statusCode, res.StatusCode)
+		}
+		respBody, err := io.ReadAll(res.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(respBody, body) {
+			t.Fatalf("response body should be preserved, got: %s", respBody)
+		}
+	}
+}
+
+func TestRetryLoginContinuesOnOtherErrors(t *testing.T) {
+	client := &fakeHTTPClient{
+		cnt:        3,
+		success:    true,
+		statusCode: http.StatusServiceUnavailable,
+		body:       []byte(`{"code":"390189","message":"Service unavailable","success":false}`),
+	}
+	urlPtr, err := url.Parse("https://fakeaccountretrylogin.snowflakecomputing.com:443/login-request?request_id=testid")
+	if err != nil {
+		t.Fatal("failed to parse the test URL")
+	}
+	_, err = newRetryHTTP(context.TODO(),
+		client,
+		emptyRequest, urlPtr, make(map[string]string), 60*time.Second, defaultTimeProvider, nil).doRaise4XX(true).doPost().setBody([]byte{0}).execute()
+	if err != nil {
+		t.Fatalf("failed to run retry. err: %v", err)
+	}
+	if client.retryNumber != 3 {
+		t.Fatalf("expected 3 attempts, got: %v", client.retryNumber)
+	}
+}
+
+func TestRetryQueryWithExtraHeaders(t *testing.T) {
+	client := &fakeHTTPClient{
+		cnt:     1,
+		success: true,
+	}
+	urlPtr, err := url.Parse("https://fakeaccountretrysuccess.snowflakecomputing.com:443/queries/v1/query-request?" + requestIDKey + "=testid")
+	if err != nil {
+		t.Fatal("failed to parse the test URL")
+	}
+	cfg := &Config{
+		ExtraHeaders: map[string]string{
+			"X-Tenant-Id":   "tenant1",
+			"authorization": "overridden",
+			"Content-Type":  "text/plain",
+		},
+	}
+	headers := getHeaders()
+	headers[headerAuthorizationKey] = fmt.Sprintf(headerSnowflakeToken, "testtoken")
+	_, err = newRetryHTTP(context.TODO(),
+		client,
+		emptyRequest, urlPtr, headers, 60*time.Second, defaultTimeProvider, cfg).withExtraHeaders(newExtraHeaders(cfg)).
+		doPost().setBody([]byte{0}).execute()
+	if err != nil {
+		t.Fatal("failed to run retry")
+	}
+	if v := client.reqHeader.Get("X-Tenant-Id"); v != "tenant1" {
+		t.Fatalf("extra header should be sent. expected: tenant1, got: %v", v)
+	}
+	if v := client.reqHeader.Get(headerAuthorizationKey); v != fmt.Sprintf(headerSnowflakeToken, "testtoken") {
+		t.Fatalf("authorization header should not be overridden, got: %v", v)
+	}
+	if v := client.reqHeader.Get(httpHeaderContentType); v != headerContentTypeApplicationJSON {
+		t.Fatalf("content type header should not be overridden, got: %v", v)
+	}
+	if _, ok := headers["X-Tenant-Id"]; ok {
+		t.Fatalf("input headers should not be modified: %v", headers)
+	}
+}
+
+func TestRetryWithUnresolvableHost(t *testing.T) {
+	host := "wrongaccount.wrong-region.snowflakecomputing.com"
+	client := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(_ context.Context, _, _ string) (net.Conn, error) {
+				return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+			},
+		},
+	}
+	urlPtr, err := url.Parse("https://" + host + ":443/queries/v1/query-request?" + requestIDKey + "=testid")
+	if err != nil {
+		t.Fatal("failed to parse the test URL")
+	}
+	start := time.Now()
+	_, err = newRetryHTTP(context.TODO(),
+		client,
+		http.NewRequest, urlPtr, make(map[string]string), 60*time.Second, defaultTimeProvider, nil).doPost().setBody([]byte{0}).execute()
+	if err == nil {
+		t.Fatal("should have failed")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("unresolvable host should not be retried, took: %v", elapsed)
+	}
+	driverErr, ok := err.(*SnowflakeError)
+	if !ok {
+		t.Fatalf("should be snowflake error, got: %v", err)
+	}
+	if driverErr.Number != ErrCodeInvalidAccountURL {
+		t.Fatalf("unexpected error code: %v", driverErr.Number)
+	}
+	if !strings.Contains(driverErr.Error(), host) {
+		t.Fatalf("error should contain the host, got: %v", driverErr.Error())
+	}
+}
+
+func TestRetryQueryKeepsRequestIDAndRotatesRequestGUID(t *testing.T) {
+	client := &fakeHTTPClient{
+		cnt:        3,
+		success:    true,
+		statusCode: 503,
+	}
+	urlPtr, err := url.Parse("https://fakeaccountretrysuccess.snowflakecomputing.com:443/queries/v1/query-request?" +
+		requestIDKey + "=testid&" + requestGUIDKey + "=testguid")
+	if err != nil {
+		t.Fatal("failed to parse the test URL")
+	}
+	_, err = newRetryHTTP(context.TODO(),
+		client,
+		emptyRequest, urlPtr, make(map[string]string), 60*time.Second, constTimeProvider(123456), nil).doPost().setBody([]byte{0}).execute()
+	if err != nil {
+		t.Fatal("failed to run retry")
+	}
+	if len(client.reqURLs) != 3 {
+		t.Fatalf("expected 3 attempts, got: %v", len(client.reqURLs))
+	}
+	guids := make(map[string]bool)
+	for i, reqURL := range client.reqURLs {
+		values := reqURL.Query()
+		if requestID := values.Get(requestIDKey); requestID != "testid" {
+			t.Fatalf("requestId should be the same on attempt %v, got: %v", i, requestID)
+		}
+		guid := values.Get(requestGUIDKey)
+		if guids[guid] {
+			t.Fatalf("request_guid should be unique on attempt %v, got: %v", i, guid)
+		}
+		guids[guid] = true
+		if i > 0 && values.Get(retryCountKey) != strconv.Itoa(i) {
+			t.Fatalf("unexpected retry count on attempt %v: %v", i, values.Get(retryCountKey))
+		}
+	}
+}
+
+func TestRetryStopsOnRequestTooLarge(t *testing.T) {
+	client := &fakeHTTPClient{
+		cnt:        3,
+		success:    true,
+		statusCode: http.StatusRequestEntityTooLarge,
+	}
+	urlPtr, err := url.Parse("https://fakeaccountretrytoolarge.snowflakecomputing.com:443/queries/v1/query-request?request_id=testid")
+	if err != nil {
+		t.Fatal("failed to parse the test URL")
+	}
+	res, err := newRetryHTTP(context.TODO(),
+		client,
+		emptyRequest, urlPtr, make(map[string]string), 60*time.Second, defaultTimeProvider, nil).doPost().setBody([]byte{0}).execute()
+	if err != nil {
+		t.Fatalf("failed to run retry. err: %v", err)
+	}
+	if client.retryNumber != 1 {
+		t.Fatalf("expected a single attempt, got: %v", client.retryNumber)
+	}
+	if res.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Fatalf("unexpected status code: %v", res.StatusCode)
+	}
+}
+
+func TestRetryableHTTPStatuses(t *testing.T) {
+	cfg := &Config{RetryableHTTPStatuses: []int{http.StatusTooManyRequests, http.StatusServiceUnavailable}}
+	for statusCode, expectedAttempts := range map[int]int{
+		http.StatusBadGateway:         1,
+		http.StatusServiceUnavailable: 3,
+	} {
+		client := &fakeHTTPClient{
+			cnt:        3,
+			success:    true,
+			statusCode: statusCode,
+		}
+		urlPtr, err := url.Parse("https://fakeaccountretrystatuses.snowflakecomputing.com:443/queries/v1/query-request?request_id=testid")
+		if err != nil {
+			t.Fatal("failed to parse the test URL")
+		}
+		res, err := newRetryHTTP(context.TODO(),
+			client,
+			emptyRequest, urlPtr, make(map[string]string), 60*time.Second, defaultTimeProvider, cfg).doPost().setBody([]byte{0}).execute()
+		if err != nil {
+			t.Fatalf("failed to run retry. err: %v", err)
+		}
+		if client.retryNumber != expectedAttempts {
+			t.Fatalf("expected %v attempts for status %v, got: %v", expectedAttempts, statusCode, client.retryNumber)
+		}
+		if expectedAttempts == 1 && res.StatusCode != statusCode {
+			t.Fatalf("unexpected status code. expected: %v, got: %v", statusCode, res.StatusCode)
+		}
+	}
+}
+
+// transportErrorHTTPClient fails the requests with the errors before succeeding and records the URLs.
+type transportErrorHTTPClient struct {
+	errs    []error
+	reqURLs []*url.URL
+}
+
+func (c *transportErrorHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	reqURL := *req.URL
+	c.reqURLs = append(c.reqURLs, &reqURL)
+	if len(c.reqURLs) <= len(c.errs) {
+		return nil, &url.Error{Op: "Post", URL: req.URL.String(), Err: c.errs[len(c.reqURLs)-1]}
+	}
+	return &http.Response{StatusCode: http.StatusOK, Body: &fakeResponseBody{body: []byte{}}}, nil
+}
+
+func TestRetryReasonTransportError(t *testing.T) {
+	client := &transportErrorHTTPClient{
+		errs: []error{
+			&net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)},
+			&net.OpError{Op: "remote error", Net: "tcp", Err: errors.New("tls: bad record MAC")},
+		},
+	}
+	urlPtr, err := url.Parse("https://fakeaccountretrysuccess.snowflakecomputing.com:443/queries/v1/query-request?" + requestIDKey + "=testid")
+	if err != nil {
+		t.Fatal("failed to parse the test URL")
+	}
+	collector := &testMetricsCollector{}
+	_, err = newRetryHTTP(context.TODO(), client, emptyRequest, urlPtr, make(map[string]string), 60*time.Second,
+		constTimeProvider(123456), &Config{MetricsCollector: collector}).doPost().setBody([]byte{0}).execute()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(client.reqURLs) != 3 {
+		t.Fatalf("expected 3 requests, got: %v", len(client.reqURLs))
+	}
+	expected := []string{"", strconv.Itoa(retryReasonConnectionReset), strconv.Itoa(retryReasonTLS)}
+	for i, reqURL := range client.reqURLs {
+		if reason := reqURL.Query().Get(retryReasonKey); reason != expected[i] {
+			t.Fatalf("unexpected retry reason of request %v. expected: %v, got: %v", i, expected[i], reason)
+		}
+	}
+	if !reflect.DeepEqual(collector.retryReasons, []int{retryReasonConnectionReset, retryReasonTLS}) {
+		t.Fatalf("unexpected retry reasons reported to the metrics collector: %v", collector.retryReasons)
+	}
+}
+
+func TestTransportRetryReason(t *testing.T) {
+	testcases := []struct {
+		err    error
+		reason int
+	}{
+		{&fakeHTTPError{err: "timeout", timeout: true}, retryReasonTimeout},
+		{&url.Error{Op: "Post", Err: &net.OpError{Op: "read", Err: os.NewSyscallError("read", syscall.ECONNRESET)}}, retryReasonConnectionReset},
+		{&url.Error{Op: "Post", Err: &net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}}, retryReasonConnectionRefused},
+		{&url.Error{Op: "Post", Err: tls.RecordHeaderError{Msg: "first record does not look like a TLS handshake"}}, retryReasonTLS},
+		{&url.Error{Op: "Post", Err: &net.DNSError{Err: "server misbehaving", Name: "example.com"}}, retryReasonDNS},
+		{&url.Error{Op: "Post", Err: io.ErrUnexpectedEOF}, retryReasonUnexpectedEOF},
+		{&url.Error{Op: "Post", Err: errors.New("something else")}, retryReasonTransportError},
+	}
+	for _, tc := range testcases {
+		if reason := transportRetryReason(tc.err); reason != tc.reason {
+			t.Errorf("unexpected retry reason of %v. expected: %v, got: %v", tc.err, tc.reason, reason)
+		}
+	}
+}
+
+func TestRetryHTTPCountsStatuses(t *testing.T) {
+	fullURL, err := url.Parse("https://status.example.com/queries/v1/query-request?requestId=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc := getDefaultSnowflakeConn()
+	sc.rest.statusCounts = &httpStatusCounter{}
+	clients := []*fakeHTTPClient{
+		{success: true, cnt: 1},
+		{success: true, cnt: 3, statusCode: http.StatusServiceUnavailable},
+		{success: true, cnt: 2, statusCode: http.StatusTooManyRequests},
+	}
+	var wg sync.WaitGroup
+	for _, client := range clients {
+		wg.Add(1)
+		go func(client *fakeHTTPClient) {
+			defer wg.Done()
+			_, err := newRetryHTTP(context.Background(), client, emptyRequest, fullURL,
+				make(map[string]string), 60*time.Second, defaultTimeProvider, nil).
+				countStatuses(sc.rest.statusCounts).doPost().setBody([]byte{0}).execute()
+			if err != nil {
+				t.Error(err)
+			}
+		}(client)
+	}
+	wg.Wait()
+
+	expected := map[int]int64{http.StatusOK: 3, http.StatusServiceUnavailable: 2, http.StatusTooManyRequests: 1}
+	counts := sc.HTTPStatusCounts()
+	if !reflect.DeepEqual(counts, expected) {
+		t.Fatalf("unexpected status counts. expected: %v, got: %v", expected, counts)
+	}
+	counts[http.StatusOK] = 0
+	if sc.HTTPStatusCounts()[http.StatusOK] != 3 {
+		t.Fatal("the returned counts should be a copy")
+	}
+}
+
+func TestSeededWaitAlgoIsReproducible(t *testing.T) {
+	sleeps := func(w *waitAlgo) []time.Duration {
+		var sequence []time.Duration
+		sleep := w.base
+		for attempt := 0; attempt < 10; attempt++ {
+			sleep = w.decorr(attempt, sleep)
+			sequence = append(sequence, sleep)
+		}
+		return sequence
+	}
+	first := sleeps(newSeededWaitAlgo(42))
+	second := sleeps(newSeededWaitAlgo(42))
+	if !reflect.DeepEqual(first, second) {
+		t.Fatalf("the same seed should give the same sleeps. first: %v, second: %v", first, second)
+	}
+	for _, sleep := range first {
+		if sleep < defaultWaitAlgo.base || sleep > defaultWaitAlgo.cap {
+			t.Fatalf("sleep out of the backoff bounds: %v", first)
+		}
+	}
+	if other := sleeps(newSeededWaitAlgo(7)); reflect.DeepEqual(first, other) {
+		t.Fatalf("another seed should give other sleeps: %v", other)
+	}
+
+	sc, err := buildSnowflakeConn(context.Background(), Config{Account: "a", User: "u", Password: "p", RetryJitterSeed: 42})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fromConn := sleeps(sc.rest.waitAlgo); !reflect.DeepEqual(first, fromConn) {
+		t.Fatalf("the connection should use the seeded backoff. expected: %v, got: %v", first, fromConn)
+	}
+}
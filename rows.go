@@ -3,11 +3,20 @@
 package gosnowflake
 
 import (
+	"bytes"
+	"context"
 	"database/sql/driver"
+	"encoding/json"
 	"io"
+	"math"
+	"math/big"
 	"reflect"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/apache/arrow/go/v12/arrow/ipc"
+	"github.com/apache/arrow/go/v12/arrow/memory"
 )
 
 const (
@@ -33,6 +42,15 @@ type SnowflakeRows interface {
 	GetQueryID() string
 	GetStatus() queryStatus
 	GetArrowBatches() ([]*ArrowBatch, error)
+	WriteArrowIPC(w io.Writer) error
+	WriteNDJSON(w io.Writer) error
+	DumpChunks(dir string) error
+	Warnings() []string
+	QueryStats() *QueryStats
+	SessionContext() SessionContext
+	ChildQueryIDs() []string
+	EstimatedSize() (rows int64, bytes int64)
+	All(ctx context.Context) func(yield func([]driver.Value, error) bool)
 }
 
 type snowflakeRows struct {
@@ -40,10 +58,15 @@ type snowflakeRows struct {
 	ChunkDownloader     chunkDownloader
 	tailChunkDownloader chunkDownloader
 	queryID             string
+	childIDs            []string
 	status              queryStatus
 	err                 error
 	errChannel          chan error
 	location            *time.Location
+	warnings            []string
+	stats               *QueryStats
+	session             SessionContext
+	nullToZeroValue     bool // set by WithNullToZeroValue
 }
 
 func (rows *snowflakeRows) getLocation() *time.Location {
@@ -84,6 +107,9 @@ func (rows *snowflakeRows) ColumnTypeDatabaseTypeName(index int) string {
 	if err := rows.waitForAsyncQueryStatus(); err != nil {
 		return err.Error()
 	}
+	if index < 0 || index >= len(rows.ChunkDownloader.getRowType()) {
+		return ""
+	}
 	return strings.ToUpper(rows.ChunkDownloader.getRowType()[index].Type)
 }
 
@@ -92,7 +118,7 @@ func (rows *snowflakeRows) ColumnTypeLength(index int) (length int64, ok bool) {
 	if err := rows.waitForAsyncQueryStatus(); err != nil {
 		return 0, false
 	}
-	if index < 0 || index > len(rows.ChunkDownloader.getRowType()) {
+	if index < 0 || index >= len(rows.ChunkDownloader.getRowType()) {
 		return 0, false
 	}
 	switch rows.ChunkDownloader.getRowType()[index].Type {
@@ -106,7 +132,7 @@ func (rows *snowflakeRows) ColumnTypeNullable(index int) (nullable, ok bool) {
 	if err := rows.waitForAsyncQueryStatus(); err != nil {
 		return false, false
 	}
-	if index < 0 || index > len(rows.ChunkDownloader.getRowType()) {
+	if index < 0 || index >= len(rows.ChunkDownloader.getRowType()) {
 		return false, false
 	}
 	return rows.ChunkDownloader.getRowType()[index].Nullable, true
@@ -117,7 +143,7 @@ func (rows *snowflakeRows) ColumnTypePrecisionScale(index int) (precision, scale
 		return 0, 0, false
 	}
 	rowType := rows.ChunkDownloader.getRowType()
-	if index < 0 || index > len(rowType) {
+	if index < 0 || index >= len(rowType) {
 		return 0, 0, false
 	}
 	switch rowType[index].Type {
@@ -147,6 +173,9 @@ func (rows *snowflakeRows) ColumnTypeScanType(index int) reflect.Type {
 	if err := rows.waitForAsyncQueryStatus(); err != nil {
 		return nil
 	}
+	if index < 0 || index >= len(rows.ChunkDownloader.getRowType()) {
+		return nil
+	}
 	return snowflakeTypeToGo(
 		getSnowflakeType(rows.ChunkDownloader.getRowType()[index].Type),
 		rows.ChunkDownloader.getRowType()[index].Scale)
@@ -160,6 +189,54 @@ func (rows *snowflakeRows) GetStatus() queryStatus {
 	return rows.status
 }
 
+// Warnings returns the warnings the server returned with the result, e.g. about implicit casts.
+func (rows *snowflakeRows) Warnings() []string {
+	if err := rows.waitForAsyncQueryStatus(); err != nil {
+		return nil
+	}
+	return rows.warnings
+}
+
+// QueryStats returns the statistics the server returned with the result, or nil if there are none.
+func (rows *snowflakeRows) QueryStats() *QueryStats {
+	if err := rows.waitForAsyncQueryStatus(); err != nil {
+		return nil
+	}
+	return rows.stats
+}
+
+// SessionContext returns the database, schema, warehouse and role the server used to run the query.
+func (rows *snowflakeRows) SessionContext() SessionContext {
+	if err := rows.waitForAsyncQueryStatus(); err != nil {
+		return SessionContext{}
+	}
+	return rows.session
+}
+
+// ChildQueryIDs returns the query IDs of the statements of a multi-statement query in their order. GetQueryID
+// returns the ID of the multi-statement query itself. ChildQueryIDs returns nil for a single statement.
+func (rows *snowflakeRows) ChildQueryIDs() []string {
+	if err := rows.waitForAsyncQueryStatus(); err != nil {
+		return nil
+	}
+	return rows.childIDs
+}
+
+// EstimatedSize returns the number of rows of the current result set and the uncompressed size in bytes
+// of its chunks, as reported by Snowflake in the first response, so that the application can decide how
+// to consume a large result before any chunk is downloaded. The rows sent with the first response are
+// not included in the size. Both values are -1 if the query failed.
+func (rows *snowflakeRows) EstimatedSize() (int64, int64) {
+	if err := rows.waitForAsyncQueryStatus(); err != nil {
+		return -1, -1
+	}
+	var size int64
+	for _, chunk := range rows.ChunkDownloader.getChunkMetas() {
+		size += chunk.UncompressedSize
+	}
+	return rows.ChunkDownloader.totalRows(), size
+}
+
 // GetArrowBatches returns an array of ArrowBatch objects to retrieve data in arrow.Record format
 func (rows *snowflakeRows) GetArrowBatches() ([]*ArrowBatch, error) {
 	// Wait for all arrow batches before fetching.
@@ -171,6 +248,160 @@ func (rows *snowflakeRows) GetArrowBatches() ([]*ArrowBatch, error) {
 	return rows.ChunkDownloader.getArrowBatches(), nil
 }
 
+// WriteArrowIPC writes the result set to w as a single Arrow IPC stream of the Arrow batches of the result,
+// as returned by GetArrowBatches, so the query must be run with a context set with WithArrowBatches. Each
+// chunk is downloaded once and its records are released once they are written. An empty result is written
+// as a stream with the schema of the columns and no batch.
+func (rows *snowflakeRows) WriteArrowIPC(w io.Writer) error {
+	if err := rows.waitForAsyncQueryStatus(); err != nil {
+		return err
+	}
+	scd, ok := rows.ChunkDownloader.(*snowflakeChunkDownloader)
+	if !ok || scd.getQueryResultFormat() != arrowFormat {
+		return &SnowflakeError{
+			Number:      ErrNotArrowResult,
+			Message:     errMsgNotArrowResult,
+			MessageArgs: []interface{}{rows.ChunkDownloader.getQueryResultFormat()},
+			QueryID:     rows.queryID,
+		}
+	}
+	if !usesArrowBatches(scd.ctx) {
+		return &SnowflakeError{
+			Number:      ErrNotArrowResult,
+			Message:     errMsgNotArrowResult,
+			MessageArgs: []interface{}{"the rows are decoded, run the query with WithArrowBatches"},
+			QueryID:     rows.queryID,
+		}
+	}
+	pool := scd.pool
+	if pool == nil {
+		pool = memory.DefaultAllocator
+	}
+	var writer *ipc.Writer
+	for _, batch := range scd.getArrowBatches() {
+		records, err := batch.WithContext(scd.ctx).Fetch()
+		if err != nil {
+			return err
+		}
+		for _, record := range *records {
+			if writer == nil {
+				writer = ipc.NewWriter(w, ipc.WithSchema(record.Schema()), ipc.WithAllocator(pool))
+			}
+			if err == nil {
+				err = writer.Write(record)
+			}
+			record.Release()
+		}
+		// the released records cannot be fetched anymore, the chunk is downloaded again if needed
+		batch.rec = nil
+		if err != nil {
+			return err
+		}
+	}
+	if writer == nil {
+		// an empty result is written as a stream of its schema only
+		var loc *time.Location
+		if scd.sc != nil && scd.sc.cfg != nil {
+			loc = getCurrentLocation(scd.sc.cfg.Params)
+		}
+		schema := rowTypeToArrowSchema(scd.getRowType(), loc)
+		writer = ipc.NewWriter(w, ipc.WithSchema(schema), ipc.WithAllocator(pool))
+	}
+	return writer.Close()
+}
+
+// WriteNDJSON writes the remaining rows of the result set to w as newline-delimited JSON, one object
+// per row keyed by the column names in the order of the columns. The rows are read with Next, so the
+// chunks are downloaded and written one after another instead of building the whole result in memory.
+//
+// Numbers are written as JSON numbers, booleans as JSON booleans and VARIANT, OBJECT and ARRAY values
+// as embedded JSON. Timestamps are written as RFC 3339 strings, dates as YYYY-MM-DD and times as
+// HH:MM:SS with fractional seconds. NaN and infinite floats are written as strings and binary values
+// as base64 strings.
+func (rows *snowflakeRows) WriteNDJSON(w io.Writer) error {
+	if err := rows.waitForAsyncQueryStatus(); err != nil {
+		return err
+	}
+	rowType := rows.ChunkDownloader.getRowType()
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	// encode writes a value without the trailing newline added by the encoder
+	encode := func(v interface{}) error {
+		if err := encoder.Encode(v); err != nil {
+			return err
+		}
+		buf.Truncate(buf.Len() - 1)
+		return nil
+	}
+	values := make([]driver.Value, len(rowType))
+	for {
+		if err := rows.Next(values); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		buf.Reset()
+		buf.WriteByte('{')
+		for i, value := range values {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := encode(rowType[i].Name); err != nil {
+				return err
+			}
+			buf.WriteByte(':')
+			if err := encode(ndjsonValue(rowType[i].Type, value)); err != nil {
+				return err
+			}
+		}
+		buf.WriteString("}\n")
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			return err
+		}
+	}
+}
+
+// ndjsonValue returns the value of a column of the given Snowflake type as it is encoded by WriteNDJSON.
+func ndjsonValue(typ string, value driver.Value) interface{} {
+	typ = strings.ToLower(typ)
+	switch v := value.(type) {
+	case time.Time:
+		switch typ {
+		case "date":
+			return v.Format("2006-01-02")
+		case "time":
+			return v.Format("15:04:05.999999999")
+		}
+		return v.Format(time.RFC3339Nano)
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return strconv.FormatFloat(v, 'g', -1, 64)
+		}
+	case *big.Int:
+		return json.Number(v.String())
+	case *big.Float:
+		return json.Number(v.Text('g', -1))
+	case string:
+		switch typ {
+		case "fixed", "real":
+			// NaN and infinite values of REAL columns remain strings
+			if v != "" && (v[0] == '-' || (v[0] >= '0' && v[0] <= '9')) && json.Valid([]byte(v)) {
+				return json.Number(v)
+			}
+		case "boolean":
+			if b, err := strconv.ParseBool(v); err == nil {
+				return b
+			}
+		case "variant", "object", "array", "file":
+			if json.Valid([]byte(v)) {
+				return json.RawMessage(v)
+			}
+		}
+	}
+	return value
+}
+
 func (rows *snowflakeRows) Next(dest []driver.Value) (err error) {
 	if err = rows.waitForAsyncQueryStatus(); err != nil {
 		return err
@@ -198,9 +429,58 @@ func (rows *snowflakeRows) Next(dest []driver.Value) (err error) {
 			}
 		}
 	}
+	if rows.nullToZeroValue {
+		rows.nullsToZeroValues(dest)
+	}
 	return err
 }
 
+// nullsToZeroValues replaces the NULL values of a row with the zero value of
+// the scan type of their column, as reported by ColumnTypeScanType.
+func (rows *snowflakeRows) nullsToZeroValues(dest []driver.Value) {
+	rowType := rows.ChunkDownloader.getRowType()
+	for i := range dest {
+		if dest[i] != nil || i >= len(rowType) {
+			continue
+		}
+		dest[i] = reflect.Zero(snowflakeTypeToGo(getSnowflakeType(rowType[i].Type), rowType[i].Scale)).Interface()
+	}
+}
+
+// All returns an iterator over the remaining rows of the result set. Its type is assignable to
+// iter.Seq2[[]driver.Value, error], so that the rows can be ranged over in Go 1.23 or later:
+//
+//	for values, err := range rows.All(ctx) {
+//		if err != nil {
+//			return err
+//		}
+//		fmt.Println(values...)
+//	}
+//
+// Every row gets a new slice of values. The iteration stops after yielding an error,
+// including the error of ctx once it is done. The chunks are downloaded as with Next.
+func (rows *snowflakeRows) All(ctx context.Context) func(yield func([]driver.Value, error) bool) {
+	return func(yield func([]driver.Value, error) bool) {
+		columns := rows.Columns()
+		for {
+			if err := ctx.Err(); err != nil {
+				yield(nil, err)
+				return
+			}
+			values := make([]driver.Value, len(columns))
+			if err := rows.Next(values); err == io.EOF {
+				return
+			} else if err != nil {
+				yield(nil, err)
+				return
+			}
+			if !yield(values, nil) {
+				return
+			}
+		}
+	}
+}
+
 func (rows *snowflakeRows) HasNextResultSet() bool {
 	if err := rows.waitForAsyncQueryStatus(); err != nil {
 		return false
@@ -0,0 +1,24 @@
+// Copyright (c) 2023 Snowflake Computing Inc. All rights reserved.
+
+//go:build go1.23
+
+package gosnowflake
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRowsAllRangeOverFunc(t *testing.T) {
+	rows := newJSONTestRows([]execResponseRowType{{Name: "c1", Type: "TEXT"}}, [][]*string{{strPtr("a")}, {strPtr("b")}})
+	var result []string
+	for values, err := range rows.All(context.Background()) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		result = append(result, values[0].(string))
+	}
+	if len(result) != 2 || result[0] != "a" || result[1] != "b" {
+		t.Fatalf("unexpected rows: %v", result)
+	}
+}
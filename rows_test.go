@@ -3,15 +3,28 @@
 package gosnowflake
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
 	"database/sql"
 	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
 	"sync"
 	"testing"
 	"time"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/ipc"
+	"github.com/apache/arrow/go/v12/arrow/memory"
 )
 
 type RowsExtended struct {
@@ -286,6 +299,87 @@ func TestRowsWithChunkDownloaderError(t *testing.T) {
 	logger.Info("END TESTS")
 }
 
+func TestRowsWithRowLimitPreview(t *testing.T) {
+	numChunks := 12
+	backupMaxChunkDownloadWorkers := MaxChunkDownloadWorkers
+	MaxChunkDownloadWorkers = 2
+	defer func() {
+		MaxChunkDownloadWorkers = backupMaxChunkDownloadWorkers
+	}()
+	cc := make([][]*string, 0)
+	for i := 0; i < 10; i++ {
+		v1 := fmt.Sprintf("%v", i)
+		v2 := fmt.Sprintf("Test%v", i)
+		cc = append(cc, []*string{&v1, &v2})
+	}
+	rt := []execResponseRowType{
+		{Name: "c1", ByteLength: 10, Length: 10, Type: "FIXED", Scale: 0, Nullable: true},
+		{Name: "c2", ByteLength: 100000, Length: 100000, Type: "TEXT", Scale: 0, Nullable: false},
+	}
+	cm := make([]execResponseChunk, 0)
+	for i := 0; i < numChunks; i++ {
+		cm = append(cm, execResponseChunk{URL: fmt.Sprintf("dummyURL%v", i+1), RowCount: rowsInChunk})
+	}
+	var mu sync.Mutex
+	var downloaded []int
+	cancelled := make(chan int, numChunks)
+	downloadChunkPreview := func(ctx context.Context, scd *snowflakeChunkDownloader, idx int) {
+		mu.Lock()
+		downloaded = append(downloaded, idx)
+		mu.Unlock()
+		if idx > 0 {
+			// only the first chunk is needed for the preview, others hang until cancelled
+			<-ctx.Done()
+			cancelled <- idx
+			return
+		}
+		downloadChunkTest(ctx, scd, idx)
+	}
+	previewRows := len(cc) + rowsInChunk/2
+	ctx := WithRowLimitPreview(context.Background(), previewRows)
+	rows := new(snowflakeRows)
+	rows.ChunkDownloader = &snowflakeChunkDownloader{
+		ctx:           ctx,
+		Total:         int64(len(cc) + numChunks*rowsInChunk),
+		ChunkMetas:    cm,
+		TotalRowIndex: int64(-1),
+		RowLimit:      getRowLimitPreview(ctx),
+		FuncDownload:  downloadChunkPreview,
+		RowSet:        rowSetType{RowType: rt, JSON: cc},
+	}
+	if err := rows.ChunkDownloader.start(); err != nil {
+		t.Fatal(err)
+	}
+	cnt := 0
+	dest := make([]driver.Value, 2)
+	for {
+		err := rows.Next(dest)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("failed to get value. err: %v", err)
+		}
+		cnt++
+	}
+	if cnt != previewRows {
+		t.Fatalf("unexpected number of rows. expected: %v, got: %v", previewRows, cnt)
+	}
+	// chunks 1 and 2 were scheduled while the first chunk was consumed
+	for i := 0; i < 2; i++ {
+		select {
+		case <-cancelled:
+		case <-time.After(5 * time.Second):
+			t.Fatal("outstanding chunk download was not cancelled")
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(downloaded) != 3 {
+		t.Fatalf("expected 3 chunk downloads, got: %v", downloaded)
+	}
+}
+
 func downloadChunkTestErrorFail(ctx context.Context, scd *snowflakeChunkDownloader, idx int) {
 	// fail to download 6th and 10th chunk, and retry up to N times and fail
 	// NOTE: zero based index
@@ -502,3 +596,571 @@ func TestLocationChangesAfterAlterSession(t *testing.T) {
 		}
 	})
 }
+
+// arrowIPCStream returns an Arrow IPC stream with a single record batch of the given IDs.
+func arrowIPCStream(t *testing.T, ids []int64, opts ...ipc.Option) []byte {
+	pool := memory.NewGoAllocator()
+	schema := arrow.NewSchema([]arrow.Field{{Name: "ID", Type: arrow.PrimitiveTypes.Int64}}, nil)
+	builder := array.NewRecordBuilder(pool, schema)
+	defer builder.Release()
+	builder.Field(0).(*array.Int64Builder).AppendValues(ids, nil)
+	record := builder.NewRecord()
+	defer record.Release()
+	var buf bytes.Buffer
+	writer := ipc.NewWriter(&buf, append([]ipc.Option{ipc.WithSchema(schema), ipc.WithAllocator(pool)}, opts...)...)
+	if err := writer.Write(record); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestRowsWriteArrowIPC(t *testing.T) {
+	var gzipped bytes.Buffer
+	gw := gzip.NewWriter(&gzipped)
+	if _, err := gw.Write(arrowIPCStream(t, []int64{4, 5, 6, 7})); err != nil {
+		t.Fatal(err)
+	}
+	gw.Close()
+	chunks := map[string][]byte{
+		"dummyURL1": arrowIPCStream(t, []int64{2, 3}),
+		"dummyURL2": gzipped.Bytes(),
+	}
+	downloads := make(map[string]int)
+	sc := getDefaultSnowflakeConn()
+	scd := &snowflakeChunkDownloader{
+		sc:    sc,
+		ctx:   WithArrowBatches(context.Background()),
+		pool:  memory.NewGoAllocator(),
+		Total: 7,
+		ChunkMetas: []execResponseChunk{
+			{URL: "dummyURL1", RowCount: 2},
+			{URL: "dummyURL2", RowCount: 4},
+		},
+		QueryResultFormat: string(arrowFormat),
+		RowSet: rowSetType{
+			RowType:      []execResponseRowType{{Name: "ID", Type: "fixed"}},
+			RowSetBase64: base64.StdEncoding.EncodeToString(arrowIPCStream(t, []int64{1})),
+		},
+		FuncDownloadHelper: downloadChunkHelper,
+		FuncGet: func(_ context.Context, _ *snowflakeConn, url string, _ map[string]string, _ time.Duration) (*http.Response, error) {
+			downloads[url]++
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewReader(chunks[url])),
+			}, nil
+		},
+	}
+	if err := scd.start(); err != nil {
+		t.Fatal(err)
+	}
+	rows := &snowflakeRows{sc: sc, ChunkDownloader: scd}
+
+	var buf bytes.Buffer
+	if err := rows.WriteArrowIPC(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if downloads["dummyURL1"] != 1 || downloads["dummyURL2"] != 1 {
+		t.Fatalf("each chunk should be downloaded once, got: %v", downloads)
+	}
+	reader, err := ipc.NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Release()
+	var ids []int64
+	for reader.Next() {
+		ids = append(ids, reader.Record().Column(0).(*array.Int64).Int64Values()...)
+	}
+	if err = reader.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 7 {
+		t.Fatalf("unexpected number of rows. expected: 7, got: %v", len(ids))
+	}
+	for i, id := range ids {
+		if id != int64(i+1) {
+			t.Fatalf("unexpected rows: %v", ids)
+		}
+	}
+}
+
+func TestRowsWriteArrowIPCEmptyResult(t *testing.T) {
+	sc := getDefaultSnowflakeConn()
+	scd := &snowflakeChunkDownloader{
+		sc:                sc,
+		ctx:               WithArrowBatches(context.Background()),
+		pool:              memory.NewGoAllocator(),
+		QueryResultFormat: string(arrowFormat),
+		RowSet: rowSetType{
+			RowType: []execResponseRowType{
+				{Name: "ID", Type: "fixed"},
+				{Name: "PRICE", Type: "fixed", Scale: 2, Nullable: true},
+				{Name: "NAME", Type: "text", Nullable: true},
+				{Name: "CREATED", Type: "timestamp_ntz"},
+			},
+		},
+		FuncDownloadHelper: downloadChunkHelper,
+	}
+	if err := scd.start(); err != nil {
+		t.Fatal(err)
+	}
+	rows := &snowflakeRows{sc: sc, ChunkDownloader: scd}
+
+	var buf bytes.Buffer
+	if err := rows.WriteArrowIPC(&buf); err != nil {
+		t.Fatal(err)
+	}
+	reader, err := ipc.NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Release()
+	expected := arrow.NewSchema([]arrow.Field{
+		{Name: "ID", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "PRICE", Type: arrow.PrimitiveTypes.Float64, Nullable: true},
+		{Name: "NAME", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "CREATED", Type: &arrow.TimestampType{Unit: arrow.Nanosecond}},
+	}, nil)
+	if !reader.Schema().Equal(expected) {
+		t.Fatalf("unexpected schema.\nexpected: %v\ngot: %v", expected, reader.Schema())
+	}
+	if reader.Next() {
+		t.Fatal("an empty result should have no batch")
+	}
+	if err = reader.Err(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRowsWithColumnProjection(t *testing.T) {
+	pool := memory.NewGoAllocator()
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "ID", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "PAYLOAD", Type: arrow.PrimitiveTypes.Int64},
+	}, nil)
+	builder := array.NewRecordBuilder(pool, schema)
+	defer builder.Release()
+	builder.Field(0).(*array.Int64Builder).AppendValues([]int64{1, 2}, nil)
+	builder.Field(1).(*array.Int64Builder).AppendValues([]int64{10, 20}, nil)
+	record := builder.NewRecord()
+	defer record.Release()
+	var buf bytes.Buffer
+	writer := ipc.NewWriter(&buf, ipc.WithSchema(schema), ipc.WithAllocator(pool))
+	if err := writer.Write(record); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	newRows := func(ctx context.Context) *snowflakeRows {
+		sc := getDefaultSnowflakeConn()
+		return &snowflakeRows{
+			sc: sc,
+			ChunkDownloader: &snowflakeChunkDownloader{
+				sc:                sc,
+				ctx:               ctx,
+				pool:              pool,
+				Total:             2,
+				ChunkMetas:        []execResponseChunk{},
+				QueryResultFormat: string(arrowFormat),
+				RowSet: rowSetType{
+					// PAYLOAD fails to decode, so it must be skipped
+					RowType: []execResponseRowType{
+						{Name: "ID", Type: "fixed"},
+						{Name: "PAYLOAD", Type: "not_supported"},
+					},
+					RowSetBase64: base64.StdEncoding.EncodeToString(buf.Bytes()),
+				},
+			},
+		}
+	}
+
+	if err := newRows(context.Background()).ChunkDownloader.start(); err == nil {
+		t.Fatal("the column of an unknown type should fail to decode")
+	}
+
+	rows := newRows(WithColumnProjection(context.Background(), "id"))
+	if err := rows.ChunkDownloader.start(); err != nil {
+		t.Fatal(err)
+	}
+	dest := make([]driver.Value, 2)
+	for _, id := range []string{"1", "2"} {
+		if err := rows.Next(dest); err != nil {
+			t.Fatal(err)
+		}
+		if fmt.Sprint(dest[0]) != id || dest[1] != nil {
+			t.Fatalf("unexpected row: %v", dest)
+		}
+	}
+	if err := rows.Next(dest); err != io.EOF {
+		t.Fatalf("expected io.EOF, got: %v", err)
+	}
+
+	err := newRows(WithColumnProjection(context.Background(), "ID", "NAME")).ChunkDownloader.start()
+	if se, ok := err.(*SnowflakeError); !ok || se.Number != ErrUnknownProjectedColumn {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cursor := &ResultCursor{
+		scd: newRows(WithColumnProjection(context.Background(), "id")).ChunkDownloader.(*snowflakeChunkDownloader),
+		loc: time.UTC,
+	}
+	chunk, err := cursor.NextChunk(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chunk) != 2 || fmt.Sprint(chunk[1][0]) != "2" || chunk[1][1] != nil {
+		t.Fatalf("unexpected rows of the cursor: %v", chunk)
+	}
+}
+
+func TestRowsWriteArrowIPCNotArrow(t *testing.T) {
+	rows := &snowflakeRows{
+		ChunkDownloader: &snowflakeChunkDownloader{QueryResultFormat: string(jsonFormat)},
+	}
+	err := rows.WriteArrowIPC(io.Discard)
+	if se, ok := err.(*SnowflakeError); !ok || se.Number != ErrNotArrowResult {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rows.ChunkDownloader = &snowflakeChunkDownloader{ctx: context.Background(), QueryResultFormat: string(arrowFormat)}
+	err = rows.WriteArrowIPC(io.Discard)
+	if se, ok := err.(*SnowflakeError); !ok || se.Number != ErrNotArrowResult || !strings.Contains(se.Error(), "WithArrowBatches") {
+		t.Fatalf("the rows decoded for Next should not be written, got: %v", err)
+	}
+}
+
+func newJSONTestRows(rowType []execResponseRowType, rowSet [][]*string) *snowflakeRows {
+	rows := new(snowflakeRows)
+	rows.ChunkDownloader = &snowflakeChunkDownloader{
+		ctx:               context.Background(),
+		Total:             int64(len(rowSet)),
+		ChunkMetas:        []execResponseChunk{},
+		TotalRowIndex:     int64(-1),
+		RowSet:            rowSetType{RowType: rowType, JSON: rowSet},
+		QueryResultFormat: "json",
+	}
+	rows.ChunkDownloader.start()
+	return rows
+}
+
+func TestRowsAll(t *testing.T) {
+	rowType := []execResponseRowType{
+		{Name: "c1", Type: "FIXED", Nullable: true},
+		{Name: "c2", Type: "TEXT", Nullable: true},
+	}
+	rows := newJSONTestRows(rowType, [][]*string{{strPtr("1"), strPtr("a")}, {strPtr("2"), nil}, {strPtr("3"), strPtr("c")}})
+	var result [][]driver.Value
+	rows.All(context.Background())(func(values []driver.Value, err error) bool {
+		if err != nil {
+			t.Fatal(err)
+		}
+		result = append(result, values)
+		return true
+	})
+	expected := [][]driver.Value{{"1", "a"}, {"2", nil}, {"3", "c"}}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("unexpected rows. expected: %v, got: %v", expected, result)
+	}
+
+	rows = newJSONTestRows(rowType, [][]*string{{strPtr("1"), strPtr("a")}, {strPtr("2"), strPtr("b")}})
+	count := 0
+	rows.All(context.Background())(func(values []driver.Value, err error) bool {
+		count++
+		return false
+	})
+	if count != 1 {
+		t.Fatalf("the iteration should stop when yield returns false, got %v rows", count)
+	}
+}
+
+func TestRowsAllContextCanceled(t *testing.T) {
+	rows := newJSONTestRows([]execResponseRowType{{Name: "c1", Type: "FIXED"}}, [][]*string{{strPtr("1")}, {strPtr("2")}})
+	ctx, cancel := context.WithCancel(context.Background())
+	var errs []error
+	rows.All(ctx)(func(values []driver.Value, err error) bool {
+		if err != nil {
+			errs = append(errs, err)
+			return true
+		}
+		cancel()
+		return true
+	})
+	if len(errs) != 1 || !errors.Is(errs[0], context.Canceled) {
+		t.Fatalf("expected a single context canceled error, got: %v", errs)
+	}
+}
+
+func TestEmptyResultColumnMetadata(t *testing.T) {
+	rowType := []execResponseRowType{
+		{Name: "ID", Type: "fixed", Precision: 38, Scale: 0, Nullable: false},
+		{Name: "NAME", Type: "text", Length: 16, Nullable: true},
+		{Name: "CREATED_ON", Type: "timestamp_ntz", Scale: 9, Nullable: true},
+	}
+	for _, format := range []string{"json", "arrow"} {
+		t.Run(format, func(t *testing.T) {
+			postQueryMock := func(_ context.Context, _ *snowflakeRestful, _ *url.Values,
+				_ map[string]string, _ []byte, _ time.Duration, _ UUID, _ *Config) (*execResponse, error) {
+				return &execResponse{
+					Data: execResponseData{
+						QueryID:           "1",
+						RowType:           rowType,
+						StatementTypeID:   statementTypeIDSelect,
+						QueryResultFormat: format,
+					},
+					Success: true,
+				}, nil
+			}
+			sc := getDefaultSnowflakeConn()
+			sc.cfg.KeepSessionAlive = true
+			sc.rest.FuncPostQuery = postQueryMock
+			db := sql.OpenDB(showConnectorMock{sc: sc})
+			defer db.Close()
+
+			rows, err := db.QueryContext(context.Background(), "SELECT id, name, created_on FROM t WHERE 1 = 0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer rows.Close()
+			columns, err := rows.Columns()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(columns, []string{"ID", "NAME", "CREATED_ON"}) {
+				t.Fatalf("unexpected columns: %v", columns)
+			}
+			types, err := rows.ColumnTypes()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(types) != 3 {
+				t.Fatalf("expected 3 column types, got: %v", len(types))
+			}
+			if types[0].DatabaseTypeName() != "FIXED" || types[0].ScanType() != reflect.TypeOf(int64(0)) {
+				t.Fatalf("unexpected type of ID: %v, %v", types[0].DatabaseTypeName(), types[0].ScanType())
+			}
+			if precision, scale, ok := types[0].DecimalSize(); !ok || precision != 38 || scale != 0 {
+				t.Fatalf("unexpected decimal size of ID: %v, %v, %v", precision, scale, ok)
+			}
+			if nullable, ok := types[0].Nullable(); !ok || nullable {
+				t.Fatalf("unexpected nullability of ID: %v, %v", nullable, ok)
+			}
+			if length, ok := types[1].Length(); types[1].DatabaseTypeName() != "TEXT" || !ok || length != 16 {
+				t.Fatalf("unexpected type of NAME: %v, %v", types[1].DatabaseTypeName(), length)
+			}
+			if types[2].DatabaseTypeName() != "TIMESTAMP_NTZ" || types[2].ScanType() != reflect.TypeOf(time.Time{}) {
+				t.Fatalf("unexpected type of CREATED_ON: %v, %v", types[2].DatabaseTypeName(), types[2].ScanType())
+			}
+			if rows.Next() {
+				t.Fatal("expected no rows")
+			}
+			if err = rows.Err(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestColumnTypeIndexOutOfRange(t *testing.T) {
+	rows := newJSONTestRows([]execResponseRowType{{Name: "C", Type: "fixed", Precision: 38}}, nil)
+	if _, ok := rows.ColumnTypeLength(1); ok {
+		t.Fatal("expected no length for an index out of range")
+	}
+	if _, ok := rows.ColumnTypeNullable(1); ok {
+		t.Fatal("expected no nullability for an index out of range")
+	}
+	if _, _, ok := rows.ColumnTypePrecisionScale(1); ok {
+		t.Fatal("expected no precision for an index out of range")
+	}
+	if name := rows.ColumnTypeDatabaseTypeName(1); name != "" {
+		t.Fatalf("expected no type name for an index out of range, got: %v", name)
+	}
+	if scanType := rows.ColumnTypeScanType(1); scanType != nil {
+		t.Fatalf("expected no scan type for an index out of range, got: %v", scanType)
+	}
+}
+
+func TestRowsEstimatedSize(t *testing.T) {
+	var resp execResponse
+	if err := json.Unmarshal([]byte(`{"success": true, "data": {
+		"queryId": "qid", "queryResultFormat": "json", "total": 2501,
+		"rowtype": [{"name": "C", "type": "text"}], "rowset": [["a"]],
+		"chunks": [
+			{"url": "chunk0", "rowCount": 1000, "uncompressedSize": 300000, "compressedSize": 30000},
+			{"url": "chunk1", "rowCount": 1000, "uncompressedSize": 310000, "compressedSize": 31000},
+			{"url": "chunk2", "rowCount": 500, "uncompressedSize": 150000, "compressedSize": 15000}
+		]}}`), &resp); err != nil {
+		t.Fatal(err)
+	}
+	rows := &snowflakeRows{
+		sc:              getDefaultSnowflakeConn(),
+		ChunkDownloader: populateChunkDownloader(context.Background(), getDefaultSnowflakeConn(), resp.Data),
+		queryID:         resp.Data.QueryID,
+	}
+	var sfRows SnowflakeRows = rows
+	if total, size := sfRows.EstimatedSize(); total != 2501 || size != 760000 {
+		t.Fatalf("unexpected estimate. rows: %v, bytes: %v", total, size)
+	}
+
+	failed := &snowflakeRows{status: QueryFailed, err: errors.New("failed")}
+	if total, size := failed.EstimatedSize(); total != -1 || size != -1 {
+		t.Fatalf("expected no estimate for a failed query. rows: %v, bytes: %v", total, size)
+	}
+}
+
+func TestRowsWriteNDJSON(t *testing.T) {
+	rowType := []execResponseRowType{
+		{Name: "ID", Type: "fixed"},
+		{Name: "PRICE", Type: "real"},
+		{Name: "NAME", Type: "text"},
+		{Name: "ACTIVE", Type: "boolean"},
+		{Name: "CREATED", Type: "timestamp_ntz"},
+		{Name: "DAY", Type: "date"},
+		{Name: "PAYLOAD", Type: "variant"},
+	}
+	rows := newJSONTestRows(rowType, [][]*string{
+		{strPtr("42"), strPtr("1.5"), strPtr(`a"<b`), strPtr("true"), strPtr("1672531200.123456789"), strPtr("19358"), strPtr(`{"k": [1, 2]}`)},
+		{nil, strPtr("NaN"), nil, nil, nil, nil, nil},
+	})
+	var buf bytes.Buffer
+	var sfRows SnowflakeRows = rows
+	if err := sfRows.WriteNDJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"ID":42,"PRICE":1.5,"NAME":"a\"<b","ACTIVE":true,"CREATED":"2023-01-01T00:00:00.123456789Z","DAY":"2023-01-01","PAYLOAD":{"k":[1,2]}}` + "\n" +
+		`{"ID":null,"PRICE":"NaN","NAME":null,"ACTIVE":null,"CREATED":null,"DAY":null,"PAYLOAD":null}` + "\n"
+	if buf.String() != expected {
+		t.Fatalf("unexpected NDJSON.\nexpected: %vgot: %v", expected, buf.String())
+	}
+}
+
+func TestRowsWriteNDJSONArray(t *testing.T) {
+	rows := newJSONTestRows([]execResponseRowType{{Name: "IDS", Type: "array"}, {Name: "NAMES", Type: "array"}},
+		[][]*string{{strPtr("[1, 2, 3]"), strPtr(`["a", "b"]`)}})
+	var buf bytes.Buffer
+	if err := rows.WriteNDJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if expected := `{"IDS":[1,2,3],"NAMES":["a","b"]}` + "\n"; buf.String() != expected {
+		t.Fatalf("the ARRAY values should be embedded as JSON.\nexpected: %vgot: %v", expected, buf.String())
+	}
+}
+
+func TestWithPrefetchThreads(t *testing.T) {
+	for _, n := range []int{0, -1, maxPrefetchThreads + 1} {
+		if _, err := WithPrefetchThreads(context.Background(), n); !errors.Is(err, &SnowflakeError{Number: ErrInvalidPrefetchThreads}) {
+			t.Fatalf("%v prefetch threads should be rejected, err: %v", n, err)
+		}
+	}
+	ctx, err := WithPrefetchThreads(context.Background(), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cm := make([]execResponseChunk, 0)
+	for i := 0; i < 8; i++ {
+		cm = append(cm, execResponseChunk{URL: fmt.Sprintf("dummyURL%v", i+1), RowCount: rowsInChunk})
+	}
+	data := execResponseData{
+		RowType:           []execResponseRowType{{Name: "c1", Type: "FIXED"}},
+		Chunks:            cm,
+		QueryResultFormat: "json",
+	}
+	sc := getDefaultSnowflakeConn()
+	if scd := populateChunkDownloader(context.Background(), sc, data).(*snowflakeChunkDownloader); scd.maxWorkers() != MaxChunkDownloadWorkers {
+		t.Fatalf("the other statements should use MaxChunkDownloadWorkers, got: %v", scd.maxWorkers())
+	}
+	scd := populateChunkDownloader(ctx, sc, data).(*snowflakeChunkDownloader)
+	if scd.MaxWorkers != 3 {
+		t.Fatalf("the chunk downloader should use the prefetch threads of the statement, got: %v", scd.MaxWorkers)
+	}
+
+	started := make(chan int, len(cm))
+	scd.FuncDownload = func(ctx context.Context, _ *snowflakeChunkDownloader, idx int) {
+		started <- idx
+		<-ctx.Done()
+	}
+	if err = scd.start(); err != nil {
+		t.Fatal(err)
+	}
+	defer scd.stopDownloads()
+	for i := 0; i < 3; i++ {
+		select {
+		case <-started:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("expected 3 concurrent downloads, got: %v", i)
+		}
+	}
+	select {
+	case idx := <-started:
+		t.Fatalf("no more than 3 chunks should be downloaded at a time, chunk %v started", idx)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestScanNullValues(t *testing.T) {
+	postQueryMock := func(_ context.Context, _ *snowflakeRestful, _ *url.Values,
+		_ map[string]string, _ []byte, _ time.Duration, _ UUID, _ *Config) (*execResponse, error) {
+		return &execResponse{
+			Data: execResponseData{
+				QueryID: "1",
+				RowType: []execResponseRowType{
+					{Name: "QTY", Type: "fixed"},
+					{Name: "NAME", Type: "text"},
+					{Name: "OPENS_AT", Type: "time", Scale: 9},
+				},
+				RowSet:            [][]*string{{nil, nil, nil}},
+				Total:             1,
+				Returned:          1,
+				QueryResultFormat: "json",
+			},
+			Success: true,
+		}, nil
+	}
+	sc := getPooledSnowflakeConn()
+	sc.rest.FuncPostQuery = postQueryMock
+	db := sql.OpenDB(showConnectorMock{sc: sc})
+	defer db.Close()
+
+	var qty int64
+	var name string
+	var opensAt time.Duration
+	err := db.QueryRow("SELECT qty, name, opens_at FROM t").Scan(&qty, &name, ScanDuration(&opensAt))
+	if err == nil || !strings.Contains(err.Error(), `name "QTY"`) {
+		t.Fatalf("the error should name the column, got: %v", err)
+	}
+	err = db.QueryRow("SELECT qty, name, opens_at FROM t").Scan(new(interface{}), new(interface{}), ScanDuration(&opensAt))
+	var se *SnowflakeError
+	if !errors.As(err, &se) || se.Number != ErrNullValue {
+		t.Fatalf("expected ErrNullValue, got: %v", err)
+	}
+	if !strings.Contains(err.Error(), `name "OPENS_AT"`) {
+		t.Fatalf("the error should name the column, got: %v", err)
+	}
+
+	ctx := WithNullToZeroValue(context.Background())
+	qty, name, opensAt = 1, "x", time.Hour
+	if err = db.QueryRowContext(ctx, "SELECT qty, name, opens_at FROM t").Scan(&qty, &name, ScanDuration(&opensAt)); err != nil {
+		t.Fatal(err)
+	}
+	if qty != 0 || name != "" || opensAt != 0 {
+		t.Fatalf("NULL values should be scanned as zero values, got: %v, %q, %v", qty, name, opensAt)
+	}
+	var nullQty sql.NullInt64
+	var namePtr *string
+	if err = db.QueryRowContext(ctx, "SELECT qty, name, opens_at FROM t").Scan(&nullQty, &namePtr, new(interface{})); err != nil {
+		t.Fatal(err)
+	}
+	if !nullQty.Valid || nullQty.Int64 != 0 || namePtr == nil || *namePtr != "" {
+		t.Fatalf("the nullable destinations of the query should receive the zero values too, got: %+v, %v", nullQty, namePtr)
+	}
+	// the other queries of the connection keep their NULL values
+	if err = db.QueryRow("SELECT qty, name, opens_at FROM t").Scan(&nullQty, &namePtr, new(interface{})); err != nil {
+		t.Fatal(err)
+	}
+	if nullQty.Valid || namePtr != nil {
+		t.Fatalf("NULL values should be kept without WithNullToZeroValue, got: %+v, %v", nullQty, namePtr)
+	}
+}
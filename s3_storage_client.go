@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"strings"
 
@@ -55,6 +56,15 @@ func (util *snowflakeS3Client) createClient(info *execResponseStageInfo, useAcce
 	}), nil
 }
 
+// s3HTTPClientOption sets the client of the S3 requests unless it is nil.
+func s3HTTPClientOption(client *http.Client) func(*s3.Options) {
+	return func(o *s3.Options) {
+		if client != nil {
+			o.HTTPClient = client
+		}
+	}
+}
+
 type s3HeaderAPI interface {
 	HeadObject(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error)
 }
@@ -74,7 +84,7 @@ func (util *snowflakeS3Client) getFileHeader(meta *fileMetadata, filename string
 	if meta.mockHeader != nil {
 		s3Cli = meta.mockHeader
 	}
-	out, err := s3Cli.HeadObject(context.Background(), headObjInput)
+	out, err := s3Cli.HeadObject(context.Background(), headObjInput, s3HTTPClientOption(meta.storageHTTPClient(false)))
 	if err != nil {
 		var ae smithy.APIError
 		if errors.As(err, &ae) {
@@ -108,6 +118,7 @@ func (util *snowflakeS3Client) getFileHeader(meta *fileMetadata, filename string
 		out.Metadata[sfcDigest],
 		out.ContentLength,
 		&encMeta,
+		aws.ToString(out.ETag),
 	}, nil
 }
 
@@ -115,6 +126,10 @@ type s3UploadAPI interface {
 	Upload(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*manager.Uploader)) (*manager.UploadOutput, error)
 }
 
+type s3AbortAPI interface {
+	AbortMultipartUpload(ctx context.Context, params *s3.AbortMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error)
+}
+
 // cloudUtil implementation
 func (util *snowflakeS3Client) uploadFile(
 	dataFile string,
@@ -148,6 +163,7 @@ func (util *snowflakeS3Client) uploadFile(
 	uploader = manager.NewUploader(client, func(u *manager.Uploader) {
 		u.Concurrency = maxConcurrency
 		u.PartSize = int64Max(multiPartThreshold, manager.DefaultUploadPartSize)
+		u.ClientOptions = append(u.ClientOptions, s3HTTPClientOption(meta.storageHTTPClient(false)))
 	})
 	// for testing only
 	if meta.mockUploader != nil {
@@ -159,7 +175,7 @@ func (util *snowflakeS3Client) uploadFile(
 		if meta.realSrcStream != nil {
 			uploadStream = meta.realSrcStream
 		}
-		_, err = uploader.Upload(context.Background(), &s3.PutObjectInput{
+		_, err = uploader.Upload(meta.transferContext(), &s3.PutObjectInput{
 			Bucket:   &s3loc.bucketName,
 			Key:      &s3path,
 			Body:     bytes.NewBuffer(uploadStream.Bytes()),
@@ -171,7 +187,7 @@ func (util *snowflakeS3Client) uploadFile(
 		if err != nil {
 			return err
 		}
-		_, err = uploader.Upload(context.Background(), &s3.PutObjectInput{
+		_, err = uploader.Upload(meta.transferContext(), &s3.PutObjectInput{
 			Bucket:   &s3loc.bucketName,
 			Key:      &s3path,
 			Body:     file,
@@ -180,6 +196,20 @@ func (util *snowflakeS3Client) uploadFile(
 	}
 
 	if err != nil {
+		if ctxErr := meta.transferContext().Err(); ctxErr != nil {
+			var mu manager.MultiUploadFailure
+			if errors.As(err, &mu) {
+				var aborter s3AbortAPI = client
+				// for testing only
+				if meta.mockAborter != nil {
+					aborter = meta.mockAborter
+				}
+				util.abortMultipartUpload(aborter, s3loc.bucketName, s3path, mu.UploadID())
+			}
+			meta.lastError = ctxErr
+			meta.resStatus = errStatus
+			return ctxErr
+		}
 		var ae smithy.APIError
 		if errors.As(err, &ae) {
 			if ae.ErrorCode() == expiredToken {
@@ -200,6 +230,23 @@ func (util *snowflakeS3Client) uploadFile(
 	return nil
 }
 
+// abortMultipartUpload discards the parts uploaded so far. The uploader cannot do it
+// with the canceled context of the command, so a new context is used.
+func (util *snowflakeS3Client) abortMultipartUpload(aborter s3AbortAPI, bucket string, key string, uploadID string) {
+	if uploadID == "" {
+		return
+	}
+	if _, err := aborter.AbortMultipartUpload(context.Background(), &s3.AbortMultipartUploadInput{
+		Bucket:   &bucket,
+		Key:      &key,
+		UploadId: &uploadID,
+	}); err != nil {
+		logger.Warnf("failed to abort the multipart upload of %v: %v", key, err)
+		return
+	}
+	logger.Debugf("aborted the multipart upload of %v", key)
+}
+
 type s3DownloadAPI interface {
 	Download(ctx context.Context, w io.WriterAt, params *s3.GetObjectInput, optFns ...func(*manager.Downloader)) (int64, error)
 }
@@ -225,12 +272,13 @@ func (util *snowflakeS3Client) nativeDownloadFile(
 	var downloader s3DownloadAPI
 	downloader = manager.NewDownloader(client, func(u *manager.Downloader) {
 		u.Concurrency = int(maxConcurrency)
+		u.ClientOptions = append(u.ClientOptions, s3HTTPClientOption(meta.storageHTTPClient(true)))
 	})
 	// for testing only
 	if meta.mockDownloader != nil {
 		downloader = meta.mockDownloader
 	}
-	if _, err = downloader.Download(context.Background(), f, &s3.GetObjectInput{
+	if _, err = downloader.Download(meta.transferContext(), f, &s3.GetObjectInput{
 		Bucket: s3Obj.Bucket,
 		Key:    s3Obj.Key,
 	}); err != nil {
@@ -4,12 +4,14 @@ package gosnowflake
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
 	"errors"
 	"io"
 	"os"
 	"path"
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
@@ -672,3 +674,207 @@ func TestS3UploadStreamFailed(t *testing.T) {
 		t.Fatal("should have failed")
 	}
 }
+
+func TestDownloadOneFileFromS3WithGetCache(t *testing.T) {
+	info := execResponseStageInfo{
+		Location:     "sfc-teststage/rwyitestacco/users/1234/",
+		LocationType: "S3",
+	}
+	s3Cli, err := new(snowflakeS3Client).createClient(&info, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	content := []byte("reference data")
+	etag := `"etag1"`
+	downloads := 0
+	cache := newGetCache(1024)
+	download := func() {
+		meta := fileMetadata{
+			name:              "ref.csv",
+			stageLocationType: "S3",
+			noSleepingTime:    true,
+			client:            s3Cli,
+			stageInfo:         &info,
+			dstFileName:       "ref.csv",
+			srcFileName:       "ref.csv",
+			localLocation:     dir,
+			getCache:          cache,
+			options:           &SnowflakeFileTransferOptions{},
+			mockDownloader: mockDownloadObjectAPI(func(ctx context.Context, w io.WriterAt, params *s3.GetObjectInput, optFns ...func(*manager.Downloader)) (int64, error) {
+				downloads++
+				n, err := w.WriteAt(content, 0)
+				return int64(n), err
+			}),
+			mockHeader: mockHeaderAPI(func(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+				return &s3.HeadObjectOutput{ETag: &etag, ContentLength: int64(len(content))}, nil
+			}),
+		}
+		if err := new(remoteStorageUtil).downloadOneFile(&meta); err != nil {
+			t.Fatal(err)
+		}
+		if meta.resStatus != downloaded || meta.dstFileSize != int64(len(content)) {
+			t.Fatalf("unexpected result. status: %v, size: %v", meta.resStatus, meta.dstFileSize)
+		}
+		data, err := os.ReadFile(path.Join(dir, "ref.csv"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(data, content) {
+			t.Fatalf("unexpected file content: %s", data)
+		}
+	}
+
+	download()
+	download()
+	if downloads != 1 {
+		t.Fatalf("expected 1 download, got: %v", downloads)
+	}
+	etag = `"etag2"`
+	content = []byte("updated reference data")
+	download()
+	if downloads != 2 {
+		t.Fatalf("the file should be downloaded again after the ETag changed, downloads: %v", downloads)
+	}
+}
+
+func TestDownloadOneFileFromS3VerifiesGzip(t *testing.T) {
+	info := execResponseStageInfo{
+		Location:     "sfc-teststage/rwyitestacco/users/1234/",
+		LocationType: "S3",
+	}
+	s3Cli, err := new(snowflakeS3Client).createClient(&info, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var gzipped bytes.Buffer
+	gw := gzip.NewWriter(&gzipped)
+	if _, err = gw.Write([]byte("1,a\n2,b\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err = gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	download := func(content []byte) (int, error) {
+		downloads := 0
+		meta := fileMetadata{
+			name:              "data.csv.gz",
+			stageLocationType: "S3",
+			noSleepingTime:    true,
+			client:            s3Cli,
+			stageInfo:         &info,
+			dstFileName:       "data.csv.gz",
+			srcFileName:       "data.csv.gz",
+			localLocation:     t.TempDir(),
+			verifyGzip:        true,
+			options:           &SnowflakeFileTransferOptions{},
+			mockDownloader: mockDownloadObjectAPI(func(ctx context.Context, w io.WriterAt, params *s3.GetObjectInput, optFns ...func(*manager.Downloader)) (int64, error) {
+				downloads++
+				n, err := w.WriteAt(content, 0)
+				return int64(n), err
+			}),
+			mockHeader: mockHeaderAPI(func(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+				return &s3.HeadObjectOutput{ContentLength: int64(len(content))}, nil
+			}),
+		}
+		err := new(remoteStorageUtil).downloadOneFile(&meta)
+		return downloads, err
+	}
+
+	if downloads, err := download(gzipped.Bytes()); err != nil || downloads != 1 {
+		t.Fatalf("a valid gzip file should be downloaded once. downloads: %v, err: %v", downloads, err)
+	}
+	// the length trailer is cut off
+	downloads, err := download(gzipped.Bytes()[:gzipped.Len()-4])
+	var se *SnowflakeError
+	if !errors.As(err, &se) || se.Number != ErrCorruptStageFile {
+		t.Fatalf("expected ErrCorruptStageFile, got: %v", err)
+	}
+	if !strings.Contains(err.Error(), "data.csv.gz") {
+		t.Fatalf("the error should name the file: %v", err)
+	}
+	if downloads != 2 {
+		t.Fatalf("the corrupt file should be downloaded once more, downloads: %v", downloads)
+	}
+}
+
+type mockMultiUploadFailure struct {
+	error
+	uploadID string
+}
+
+func (m mockMultiUploadFailure) UploadID() string {
+	return m.uploadID
+}
+
+type mockAbortMultipartUploadAPI func(ctx context.Context, params *s3.AbortMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error)
+
+func (m mockAbortMultipartUploadAPI) AbortMultipartUpload(
+	ctx context.Context,
+	params *s3.AbortMultipartUploadInput,
+	optFns ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error) {
+	return m(ctx, params, optFns...)
+}
+
+func TestUploadOneFileToS3AbortedOnCancel(t *testing.T) {
+	info := execResponseStageInfo{
+		Location:     "sfc-customer-stage/rwyi-testacco/users/9220/",
+		LocationType: "S3",
+	}
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Error(err)
+	}
+	s3Cli, err := new(snowflakeS3Client).createClient(&info, false)
+	if err != nil {
+		t.Error(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var aborted []string
+	uploadMeta := fileMetadata{
+		name:              "data1.txt.gz",
+		ctx:               ctx,
+		stageLocationType: "S3",
+		noSleepingTime:    true,
+		parallel:          4,
+		client:            s3Cli,
+		sha256Digest:      "123456789abcdef",
+		stageInfo:         &info,
+		dstFileName:       "data1.txt.gz",
+		srcFileName:       path.Join(dir, "/test_data/put_get_1.txt"),
+		overwrite:         true,
+		options: &SnowflakeFileTransferOptions{
+			MultiPartThreshold: dataSizeThreshold,
+		},
+		mockUploader: mockUploadObjectAPI(func(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*manager.Uploader)) (*manager.UploadOutput, error) {
+			// the command is canceled while the parts are uploaded
+			cancel()
+			return nil, mockMultiUploadFailure{error: ctx.Err(), uploadID: "upload-id"}
+		}),
+		mockAborter: mockAbortMultipartUploadAPI(func(ctx context.Context, params *s3.AbortMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error) {
+			if ctx.Err() != nil {
+				t.Error("the abort request should not use the canceled context")
+			}
+			if *params.Bucket != "sfc-customer-stage" || *params.Key != "rwyi-testacco/users/9220/data1.txt.gz" {
+				t.Errorf("unexpected object: %v/%v", *params.Bucket, *params.Key)
+			}
+			aborted = append(aborted, *params.UploadId)
+			return &s3.AbortMultipartUploadOutput{}, nil
+		}),
+	}
+	uploadMeta.realSrcFileName = uploadMeta.srcFileName
+	fi, err := os.Stat(uploadMeta.srcFileName)
+	if err != nil {
+		t.Error(err)
+	}
+	uploadMeta.uploadSize = fi.Size()
+
+	err = new(remoteStorageUtil).uploadOneFile(&uploadMeta)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context canceled, got: %v", err)
+	}
+	if len(aborted) != 1 || aborted[0] != "upload-id" {
+		t.Fatalf("expected the multipart upload to be aborted once, got: %v", aborted)
+	}
+}
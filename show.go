@@ -0,0 +1,337 @@
+// Copyright (c) 2023 Snowflake Computing Inc. All rights reserved.
+
+package gosnowflake
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SQLQueryer runs a query returning rows. It is implemented by *sql.DB, *sql.Conn and *sql.Tx.
+type SQLQueryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// TableInfo describes a table returned by SHOW TABLES.
+type TableInfo struct {
+	CreatedOn     time.Time
+	Name          string
+	DatabaseName  string
+	SchemaName    string
+	Kind          string
+	Comment       string
+	ClusterBy     string
+	Rows          int64
+	Bytes         int64
+	Owner         string
+	RetentionTime int64
+}
+
+// WarehouseInfo describes a warehouse returned by SHOW WAREHOUSES.
+type WarehouseInfo struct {
+	Name            string
+	State           string
+	Type            string
+	Size            string
+	MinClusterCount int64
+	MaxClusterCount int64
+	Running         int64
+	Queued          int64
+	IsDefault       bool
+	IsCurrent       bool
+	AutoSuspend     int64
+	AutoResume      bool
+	CreatedOn       time.Time
+	Owner           string
+	Comment         string
+}
+
+// DatabaseInfo describes a database returned by SHOW DATABASES.
+type DatabaseInfo struct {
+	CreatedOn     time.Time
+	Name          string
+	IsDefault     bool
+	IsCurrent     bool
+	Origin        string
+	Owner         string
+	Comment       string
+	Options       string
+	RetentionTime int64
+	Kind          string
+}
+
+// ShowTables runs SHOW TABLES in the current schema and returns the tables.
+// If like is not empty, only tables matching the LIKE pattern are returned.
+func ShowTables(ctx context.Context, conn SQLQueryer, like string) ([]TableInfo, error) {
+	rows, err := showObjects(ctx, conn, "SHOW TABLES", like)
+	if err != nil {
+		return nil, err
+	}
+	tables := make([]TableInfo, 0, len(rows))
+	for _, row := range rows {
+		tables = append(tables, TableInfo{
+			CreatedOn:     row.time("created_on"),
+			Name:          row.string("name"),
+			DatabaseName:  row.string("database_name"),
+			SchemaName:    row.string("schema_name"),
+			Kind:          row.string("kind"),
+			Comment:       row.string("comment"),
+			ClusterBy:     row.string("cluster_by"),
+			Rows:          row.int("rows"),
+			Bytes:         row.int("bytes"),
+			Owner:         row.string("owner"),
+			RetentionTime: row.int("retention_time"),
+		})
+	}
+	return tables, nil
+}
+
+// ShowWarehouses runs SHOW WAREHOUSES and returns the warehouses.
+// If like is not empty, only warehouses matching the LIKE pattern are returned.
+func ShowWarehouses(ctx context.Context, conn SQLQueryer, like string) ([]WarehouseInfo, error) {
+	rows, err := showObjects(ctx, conn, "SHOW WAREHOUSES", like)
+	if err != nil {
+		return nil, err
+	}
+	warehouses := make([]WarehouseInfo, 0, len(rows))
+	for _, row := range rows {
+		warehouses = append(warehouses, WarehouseInfo{
+			Name:            row.string("name"),
+			State:           row.string("state"),
+			Type:            row.string("type"),
+			Size:            row.string("size"),
+			MinClusterCount: row.int("min_cluster_count"),
+			MaxClusterCount: row.int("max_cluster_count"),
+			Running:         row.int("running"),
+			Queued:          row.int("queued"),
+			IsDefault:       row.bool("is_default"),
+			IsCurrent:       row.bool("is_current"),
+			AutoSuspend:     row.int("auto_suspend"),
+			AutoResume:      row.bool("auto_resume"),
+			CreatedOn:       row.time("created_on"),
+			Owner:           row.string("owner"),
+			Comment:         row.string("comment"),
+		})
+	}
+	return warehouses, nil
+}
+
+// ShowDatabases runs SHOW DATABASES and returns the databases.
+// If like is not empty, only databases matching the LIKE pattern are returned.
+func ShowDatabases(ctx context.Context, conn SQLQueryer, like string) ([]DatabaseInfo, error) {
+	rows, err := showObjects(ctx, conn, "SHOW DATABASES", like)
+	if err != nil {
+		return nil, err
+	}
+	databases := make([]DatabaseInfo, 0, len(rows))
+	for _, row := range rows {
+		databases = append(databases, DatabaseInfo{
+			CreatedOn:     row.time("created_on"),
+			Name:          row.string("name"),
+			IsDefault:     row.bool("is_default"),
+			IsCurrent:     row.bool("is_current"),
+			Origin:        row.string("origin"),
+			Owner:         row.string("owner"),
+			Comment:       row.string("comment"),
+			Options:       row.string("options"),
+			RetentionTime: row.int("retention_time"),
+			Kind:          row.string("kind"),
+		})
+	}
+	return databases, nil
+}
+
+// ColumnInfo describes a column returned by DESCRIBE TABLE.
+type ColumnInfo struct {
+	Name       string
+	Type       string
+	Kind       string
+	Nullable   bool
+	Default    string
+	PrimaryKey bool
+	UniqueKey  bool
+	Comment    string
+}
+
+// DescribeTable runs DESCRIBE TABLE and returns the columns of the table. The name may be qualified
+// with the database and schema, separated by dots. It is used unquoted unless the context is created
+// with WithPreservedIdentifierCase.
+func DescribeTable(ctx context.Context, conn SQLQueryer, table string) ([]ColumnInfo, error) {
+	rows, err := showObjects(ctx, conn, "DESCRIBE TABLE "+objectName(ctx, table), "")
+	if err != nil {
+		return nil, err
+	}
+	columns := make([]ColumnInfo, 0, len(rows))
+	for _, row := range rows {
+		columns = append(columns, ColumnInfo{
+			Name:       row.string("name"),
+			Type:       row.string("type"),
+			Kind:       row.string("kind"),
+			Nullable:   row.bool("null?"),
+			Default:    row.string("default"),
+			PrimaryKey: row.bool("primary key"),
+			UniqueKey:  row.bool("unique key"),
+			Comment:    row.string("comment"),
+		})
+	}
+	return columns, nil
+}
+
+// stageFileTimeFormat is the format of the last_modified column returned by LIST.
+const stageFileTimeFormat = "Mon, 2 Jan 2006 15:04:05 MST"
+
+// StageFile describes a file returned by LIST.
+type StageFile struct {
+	Name         string
+	Size         int64
+	MD5          string
+	LastModified time.Time
+}
+
+// ListStage runs LIST for the stage path, e.g. @my_stage/data/ or @~/loads, and returns the files,
+// e.g. to check that the files uploaded by PUT are staged. The path is used as is in the command.
+func ListStage(ctx context.Context, conn SQLQueryer, stagePath string) ([]StageFile, error) {
+	rows, err := showObjects(ctx, conn, "LIST "+stagePath, "")
+	if err != nil {
+		return nil, err
+	}
+	files := make([]StageFile, 0, len(rows))
+	for _, row := range rows {
+		file := StageFile{
+			Name: row.string("name"),
+			Size: row.int("size"),
+			MD5:  row.string("md5"),
+		}
+		if file.LastModified, err = time.Parse(stageFileTimeFormat, row.string("last_modified")); err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// objectName returns the object name as used in the SQL text of the helpers, quoting each part of a
+// qualified name if the context preserves the identifier case. The parts already quoted are kept as is.
+func objectName(ctx context.Context, name string) string {
+	if !identifierCasePreserved(ctx) {
+		return name
+	}
+	parts := splitQualifiedName(name)
+	for i, part := range parts {
+		if len(part) < 2 || part[0] != '"' || part[len(part)-1] != '"' {
+			parts[i] = quoteIdentifier(part)
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// splitQualifiedName splits a qualified object name on the dots that are not in a quoted identifier.
+func splitQualifiedName(name string) []string {
+	var parts []string
+	quoted := false
+	start := 0
+	for i := 0; i < len(name); i++ {
+		switch {
+		case name[i] == '"' && quoted && i+1 < len(name) && name[i+1] == '"':
+			// an escaped quote, "", is part of the identifier
+			i++
+		case name[i] == '"' && (quoted || i == start):
+			quoted = !quoted
+		case name[i] == '.' && !quoted:
+			parts = append(parts, name[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, name[start:])
+}
+
+// showRow maps lower case column names of a SHOW command result to the values.
+// Columns missing in the result, e.g. in older server versions, yield zero values.
+type showRow map[string]interface{}
+
+func showObjects(ctx context.Context, conn SQLQueryer, command string, like string) ([]showRow, error) {
+	if like != "" {
+		command += " LIKE " + quoteStringLiteral(like)
+	}
+	rows, err := conn.QueryContext(ctx, command)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	var result []showRow
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		dest := make([]interface{}, len(columns))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err = rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		row := make(showRow, len(columns))
+		for i, column := range columns {
+			row[strings.ToLower(column)] = values[i]
+		}
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
+
+// quoteStringLiteral returns s as a single quoted SQL string literal.
+func quoteStringLiteral(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
+
+func (r showRow) string(column string) string {
+	switch v := r[column].(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func (r showRow) int(column string) int64 {
+	switch v := r[column].(type) {
+	case int64:
+		return v
+	case float64:
+		return int64(v)
+	default:
+		i, err := strconv.ParseInt(r.string(column), 10, 64)
+		if err != nil {
+			return 0
+		}
+		return i
+	}
+}
+
+func (r showRow) bool(column string) bool {
+	if v, ok := r[column].(bool); ok {
+		return v
+	}
+	switch strings.ToUpper(r.string(column)) {
+	case "Y", "TRUE":
+		return true
+	}
+	return false
+}
+
+func (r showRow) time(column string) time.Time {
+	if v, ok := r[column].(time.Time); ok {
+		return v
+	}
+	return time.Time{}
+}
@@ -0,0 +1,305 @@
+// Copyright (c) 2023 Snowflake Computing Inc. All rights reserved.
+
+package gosnowflake
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type showConnectorMock struct {
+	sc *snowflakeConn
+}
+
+func (c showConnectorMock) Connect(_ context.Context) (driver.Conn, error) {
+	return c.sc, nil
+}
+
+func (c showConnectorMock) Driver() driver.Driver {
+	return SnowflakeDriver{}
+}
+
+// openShowTestDB returns a database returning the given SHOW result for every query
+// and the list of the executed queries.
+func openShowTestDB(t *testing.T, columns []execResponseRowType, rowSet [][]*string) (*sql.DB, *[]string) {
+	var queries []string
+	postQueryMock := func(_ context.Context, _ *snowflakeRestful, _ *url.Values,
+		_ map[string]string, body []byte, _ time.Duration, _ UUID, _ *Config) (*execResponse, error) {
+		var req execRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Fatal(err)
+		}
+		queries = append(queries, req.SQLText)
+		return &execResponse{
+			Data: execResponseData{
+				QueryID:           "1",
+				RowType:           columns,
+				RowSet:            rowSet,
+				Total:             int64(len(rowSet)),
+				Returned:          int64(len(rowSet)),
+				QueryResultFormat: "json",
+			},
+			Success: true,
+		}, nil
+	}
+	sc := getPooledSnowflakeConn()
+	sc.rest.FuncPostQuery = postQueryMock
+	db := sql.OpenDB(showConnectorMock{sc: sc})
+	t.Cleanup(func() { db.Close() })
+	return db, &queries
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func TestShowTables(t *testing.T) {
+	columns := []execResponseRowType{
+		{Name: "created_on", Type: "timestamp_ltz"},
+		{Name: "name", Type: "text"},
+		{Name: "database_name", Type: "text"},
+		{Name: "schema_name", Type: "text"},
+		{Name: "kind", Type: "text"},
+		{Name: "comment", Type: "text"},
+		{Name: "cluster_by", Type: "text"},
+		{Name: "rows", Type: "fixed"},
+		{Name: "bytes", Type: "fixed"},
+		{Name: "owner", Type: "text"},
+		{Name: "retention_time", Type: "text"},
+	}
+	rowSet := [][]*string{
+		{strPtr("1672531200.000000000"), strPtr("ORDERS"), strPtr("DB"), strPtr("PUBLIC"), strPtr("TABLE"),
+			strPtr("orders"), strPtr(""), strPtr("42"), strPtr("2048"), strPtr("SYSADMIN"), strPtr("1")},
+		{strPtr("1672617600.000000000"), strPtr("ORDERS_TMP"), strPtr("DB"), strPtr("PUBLIC"), strPtr("TEMPORARY"),
+			strPtr(""), nil, nil, nil, strPtr("SYSADMIN"), strPtr("0")},
+	}
+	db, queries := openShowTestDB(t, columns, rowSet)
+
+	tables, err := ShowTables(context.Background(), db, "ORDERS%")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*queries) != 1 || (*queries)[0] != "SHOW TABLES LIKE 'ORDERS%'" {
+		t.Fatalf("unexpected queries: %v", *queries)
+	}
+	if len(tables) != 2 {
+		t.Fatalf("expected 2 tables, got: %v", len(tables))
+	}
+	expected := TableInfo{
+		CreatedOn:     time.Unix(1672531200, 0),
+		Name:          "ORDERS",
+		DatabaseName:  "DB",
+		SchemaName:    "PUBLIC",
+		Kind:          "TABLE",
+		Comment:       "orders",
+		Rows:          42,
+		Bytes:         2048,
+		Owner:         "SYSADMIN",
+		RetentionTime: 1,
+	}
+	if !tables[0].CreatedOn.Equal(expected.CreatedOn) {
+		t.Fatalf("unexpected created_on. expected: %v, got: %v", expected.CreatedOn, tables[0].CreatedOn)
+	}
+	tables[0].CreatedOn = expected.CreatedOn
+	if tables[0] != expected {
+		t.Fatalf("unexpected table. expected: %+v, got: %+v", expected, tables[0])
+	}
+	if tables[1].Name != "ORDERS_TMP" || tables[1].Kind != "TEMPORARY" || tables[1].Rows != 0 || tables[1].ClusterBy != "" {
+		t.Fatalf("unexpected table: %+v", tables[1])
+	}
+}
+
+func TestShowWarehouses(t *testing.T) {
+	columns := []execResponseRowType{
+		{Name: "name", Type: "text"},
+		{Name: "state", Type: "text"},
+		{Name: "type", Type: "text"},
+		{Name: "size", Type: "text"},
+		{Name: "min_cluster_count", Type: "fixed"},
+		{Name: "max_cluster_count", Type: "fixed"},
+		{Name: "running", Type: "fixed"},
+		{Name: "queued", Type: "fixed"},
+		{Name: "is_default", Type: "text"},
+		{Name: "is_current", Type: "text"},
+		{Name: "auto_suspend", Type: "fixed"},
+		{Name: "auto_resume", Type: "text"},
+		{Name: "created_on", Type: "timestamp_ltz"},
+		{Name: "owner", Type: "text"},
+		{Name: "comment", Type: "text"},
+	}
+	rowSet := [][]*string{
+		{strPtr("COMPUTE_WH"), strPtr("STARTED"), strPtr("STANDARD"), strPtr("X-Small"), strPtr("1"), strPtr("2"),
+			strPtr("3"), strPtr("0"), strPtr("Y"), strPtr("N"), strPtr("600"), strPtr("true"),
+			strPtr("1672531200.000000000"), strPtr("ACCOUNTADMIN"), strPtr("default warehouse")},
+	}
+	db, queries := openShowTestDB(t, columns, rowSet)
+
+	warehouses, err := ShowWarehouses(context.Background(), db, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*queries) != 1 || (*queries)[0] != "SHOW WAREHOUSES" {
+		t.Fatalf("unexpected queries: %v", *queries)
+	}
+	if len(warehouses) != 1 {
+		t.Fatalf("expected 1 warehouse, got: %v", len(warehouses))
+	}
+	expected := WarehouseInfo{
+		Name:            "COMPUTE_WH",
+		State:           "STARTED",
+		Type:            "STANDARD",
+		Size:            "X-Small",
+		MinClusterCount: 1,
+		MaxClusterCount: 2,
+		Running:         3,
+		Queued:          0,
+		IsDefault:       true,
+		IsCurrent:       false,
+		AutoSuspend:     600,
+		AutoResume:      true,
+		CreatedOn:       time.Unix(1672531200, 0),
+		Owner:           "ACCOUNTADMIN",
+		Comment:         "default warehouse",
+	}
+	if !warehouses[0].CreatedOn.Equal(expected.CreatedOn) {
+		t.Fatalf("unexpected created_on. expected: %v, got: %v", expected.CreatedOn, warehouses[0].CreatedOn)
+	}
+	warehouses[0].CreatedOn = expected.CreatedOn
+	if warehouses[0] != expected {
+		t.Fatalf("unexpected warehouse. expected: %+v, got: %+v", expected, warehouses[0])
+	}
+}
+
+func TestShowDatabasesEscapesLikePattern(t *testing.T) {
+	db, queries := openShowTestDB(t, []execResponseRowType{{Name: "name", Type: "text"}}, [][]*string{{strPtr("O'DB")}})
+	databases, err := ShowDatabases(context.Background(), db, "O'DB")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*queries) != 1 || (*queries)[0] != `SHOW DATABASES LIKE 'O\'DB'` {
+		t.Fatalf("unexpected queries: %v", *queries)
+	}
+	if len(databases) != 1 || databases[0].Name != "O'DB" || databases[0].IsDefault {
+		t.Fatalf("unexpected databases: %+v", databases)
+	}
+}
+
+func TestSplitQualifiedName(t *testing.T) {
+	testcases := []struct {
+		name     string
+		expected []string
+	}{
+		{"MyDb.PUBLIC.MyTable", []string{"MyDb", "PUBLIC", "MyTable"}},
+		{`My"Table`, []string{`My"Table`}},
+		{`"My.Db".PUBLIC."My""Table"`, []string{`"My.Db"`, "PUBLIC", `"My""Table"`}},
+		{`DB."x""y.z"`, []string{"DB", `"x""y.z"`}},
+		{`"a"".b"`, []string{`"a"".b"`}},
+		{`"a""".b`, []string{`"a"""`, "b"}},
+	}
+	for _, tc := range testcases {
+		if parts := splitQualifiedName(tc.name); !reflect.DeepEqual(parts, tc.expected) {
+			t.Fatalf("unexpected parts of %v. expected: %q, got: %q", tc.name, tc.expected, parts)
+		}
+	}
+}
+
+func TestDescribeTablePreservesIdentifierCase(t *testing.T) {
+	columns := []execResponseRowType{
+		{Name: "name", Type: "text"},
+		{Name: "type", Type: "text"},
+		{Name: "kind", Type: "text"},
+		{Name: "null?", Type: "text"},
+		{Name: "default", Type: "text"},
+		{Name: "primary key", Type: "text"},
+		{Name: "unique key", Type: "text"},
+		{Name: "comment", Type: "text"},
+	}
+	rowSet := [][]*string{{strPtr("Id"), strPtr("NUMBER(38,0)"), strPtr("COLUMN"), strPtr("N"), nil, strPtr("Y"), strPtr("N"), nil}}
+	db, queries := openShowTestDB(t, columns, rowSet)
+
+	result, err := DescribeTable(WithPreservedIdentifierCase(context.Background()), db, "MyDb.PUBLIC.MyTable")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := ColumnInfo{Name: "Id", Type: "NUMBER(38,0)", Kind: "COLUMN", PrimaryKey: true}
+	if len(result) != 1 || result[0] != expected {
+		t.Fatalf("unexpected columns. expected: %+v, got: %+v", expected, result)
+	}
+	if _, err = DescribeTable(WithPreservedIdentifierCase(context.Background()), db, `My"Table`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = DescribeTable(WithPreservedIdentifierCase(context.Background()), db, `"My.Db".PUBLIC."My""Table"`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = DescribeTable(context.Background(), db, "MyTable"); err != nil {
+		t.Fatal(err)
+	}
+	expectedQueries := []string{
+		`DESCRIBE TABLE "MyDb"."PUBLIC"."MyTable"`,
+		`DESCRIBE TABLE "My""Table"`,
+		`DESCRIBE TABLE "My.Db"."PUBLIC"."My""Table"`,
+		`DESCRIBE TABLE MyTable`,
+	}
+	if !reflect.DeepEqual(*queries, expectedQueries) {
+		t.Fatalf("unexpected queries. expected: %v, got: %v", expectedQueries, *queries)
+	}
+}
+
+func TestListStage(t *testing.T) {
+	columns := []execResponseRowType{
+		{Name: "name", Type: "text"},
+		{Name: "size", Type: "fixed"},
+		{Name: "md5", Type: "text"},
+		{Name: "last_modified", Type: "text"},
+	}
+	rowSet := [][]*string{
+		{strPtr("my_stage/orders/data_0.csv.gz"), strPtr("1024"), strPtr("0bca0a3b9e8e5f6c1c1f2e6f1f1e2d3c"),
+			strPtr("Tue, 13 Jun 2023 10:21:45 GMT")},
+		{strPtr("my_stage/orders/data_1.csv.gz"), strPtr("2048"), strPtr("9c8b7a6f5e4d3c2b1a0f9e8d7c6b5a49"),
+			strPtr("Wed, 5 Jul 2023 08:00:00 GMT")},
+	}
+	db, queries := openShowTestDB(t, columns, rowSet)
+
+	files, err := ListStage(context.Background(), db, "@my_stage/orders/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []StageFile{
+		{Name: "my_stage/orders/data_0.csv.gz", Size: 1024, MD5: "0bca0a3b9e8e5f6c1c1f2e6f1f1e2d3c",
+			LastModified: time.Date(2023, 6, 13, 10, 21, 45, 0, time.UTC)},
+		{Name: "my_stage/orders/data_1.csv.gz", Size: 2048, MD5: "9c8b7a6f5e4d3c2b1a0f9e8d7c6b5a49",
+			LastModified: time.Date(2023, 7, 5, 8, 0, 0, 0, time.UTC)},
+	}
+	if len(files) != len(expected) {
+		t.Fatalf("unexpected files: %+v", files)
+	}
+	for i := range expected {
+		if files[i].Name != expected[i].Name || files[i].Size != expected[i].Size || files[i].MD5 != expected[i].MD5 ||
+			!files[i].LastModified.Equal(expected[i].LastModified) {
+			t.Fatalf("unexpected file %v. expected: %+v, got: %+v", i, expected[i], files[i])
+		}
+	}
+	if !reflect.DeepEqual(*queries, []string{"LIST @my_stage/orders/"}) {
+		t.Fatalf("unexpected queries: %v", *queries)
+	}
+}
+
+func TestShowTablesIntegration(t *testing.T) {
+	runDBTest(t, func(dbt *DBTest) {
+		dbt.mustExec("CREATE OR REPLACE TEMPORARY TABLE test_show_tables (c INT) COMMENT = 'show'")
+		defer dbt.mustExec("DROP TABLE IF EXISTS test_show_tables")
+		tables, err := ShowTables(context.Background(), dbt.conn, "TEST_SHOW_TABLES")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(tables) != 1 || tables[0].Name != "TEST_SHOW_TABLES" || tables[0].Comment != "show" {
+			t.Fatalf("unexpected tables: %+v", tables)
+		}
+	})
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2023 Snowflake Computing Inc. All rights reserved.
+
+package gosnowflake
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+type socks5Handshake struct {
+	user, password, target string
+}
+
+// serveSOCKS5Handshake accepts a connection, negotiates the username/password authentication and
+// records the credentials and the requested destination before closing the connection.
+func serveSOCKS5Handshake(l net.Listener, handshakes chan<- socks5Handshake) {
+	conn, err := l.Accept()
+	if err != nil {
+		close(handshakes)
+		return
+	}
+	defer conn.Close()
+	var hs socks5Handshake
+	defer func() { handshakes <- hs }()
+	readBytes := func(n int) []byte {
+		b := make([]byte, n)
+		io.ReadFull(conn, b)
+		return b
+	}
+	// greeting: version, number of methods, methods
+	greeting := readBytes(2)
+	if greeting[0] != 5 {
+		return
+	}
+	readBytes(int(greeting[1]))
+	conn.Write([]byte{5, 2}) // username/password
+	// authentication: version, username, password
+	hs.user = string(readBytes(int(readBytes(2)[1])))
+	hs.password = string(readBytes(int(readBytes(1)[0])))
+	conn.Write([]byte{1, 0})
+	// request: version, CONNECT, reserved, address type, domain name, port
+	if readBytes(4)[3] != 3 {
+		return
+	}
+	host := string(readBytes(int(readBytes(1)[0])))
+	port := binary.BigEndian.Uint16(readBytes(2))
+	hs.target = net.JoinHostPort(host, strconv.Itoa(int(port)))
+}
+
+func TestBuildSnowflakeConnWithSOCKS5Proxy(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	handshakes := make(chan socks5Handshake, 1)
+	go serveSOCKS5Handshake(l, handshakes)
+
+	proxyURL := "socks5://proxy_user:proxy%20secret@" + l.Addr().String()
+	sc, err := buildSnowflakeConn(context.Background(), Config{
+		Account:           "a",
+		Host:              "a.snowflakecomputing.com",
+		DisableOCSPChecks: true,
+		SOCKS5ProxyURL:    proxyURL,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	transport, ok := sc.rest.Client.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("unexpected transport: %T", sc.rest.Client.Transport)
+	}
+	req, err := http.NewRequest("GET", "https://a.snowflakecomputing.com/session", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := transport.Proxy(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u == nil || u.Scheme != "socks5" || u.Host != l.Addr().String() {
+		t.Fatalf("expected the SOCKS5 proxy at %v, got: %v", l.Addr(), u)
+	}
+	if transport == snowflakeNoOCSPTransport {
+		t.Fatal("the default transport should not be modified")
+	}
+
+	// the connections go through the proxy with the credentials of the URL
+	if _, err = sc.rest.Client.Get("https://a.snowflakecomputing.com/session"); err == nil {
+		t.Fatal("the request should fail as the proxy closes the connection")
+	}
+	hs := <-handshakes
+	if hs.user != "proxy_user" || hs.password != "proxy secret" {
+		t.Fatalf("unexpected proxy credentials: %v, %v", hs.user, hs.password)
+	}
+	if hs.target != "a.snowflakecomputing.com:443" {
+		t.Fatalf("unexpected destination requested from the proxy: %v", hs.target)
+	}
+
+	storage, ok := storageTransport(sc.cfg).(*http.Transport)
+	if !ok || storage.Proxy == nil {
+		t.Fatalf("expected a storage transport with the SOCKS5 proxy, got: %v", storageTransport(sc.cfg))
+	}
+	if u, err = storage.Proxy(req); err != nil || u.Host != l.Addr().String() {
+		t.Fatalf("unexpected storage proxy: %v, err: %v", u, err)
+	}
+}
+
+func TestInvalidSOCKS5ProxyURL(t *testing.T) {
+	for _, proxyURL := range []string{"http://proxy:1080", "socks5://proxy", "socks5://:1080", "://"} {
+		err := (&Config{SOCKS5ProxyURL: proxyURL}).Validate()
+		var se *SnowflakeError
+		if !errors.As(err, &se) || se.Number != ErrCodeInvalidSOCKS5ProxyURL {
+			t.Fatalf("expected error %v for %v, got: %v", ErrCodeInvalidSOCKS5ProxyURL, proxyURL, err)
+		}
+	}
+}
@@ -2,6 +2,8 @@
 
 package gosnowflake
 
+import "errors"
+
 const (
 	// SQLStateNumericValueOutOfRange is a SQL State code indicating Numeric value is out of range.
 	SQLStateNumericValueOutOfRange = "22003"
@@ -16,3 +18,16 @@ const (
 	// SQLStateFeatureNotSupported is a SQL State code indicating the feature is not enabled.
 	SQLStateFeatureNotSupported = "0A000"
 )
+
+// IsSQLState reports whether err, or any error it wraps, is a *SnowflakeError with the given SQLState.
+// A two character state matches the whole class, e.g. "08" matches all connection exceptions.
+func IsSQLState(err error, state string) bool {
+	var se *SnowflakeError
+	if state == "" || !errors.As(err, &se) {
+		return false
+	}
+	if len(state) == 2 {
+		return len(se.SQLState) == 5 && se.SQLState[:2] == state
+	}
+	return se.SQLState == state
+}
@@ -5,17 +5,30 @@ package gosnowflake
 import (
 	"context"
 	"database/sql/driver"
+	"strings"
 )
 
 // SnowflakeStmt represents the prepared statement in driver.
 type SnowflakeStmt interface {
 	GetQueryID() string
+	BindParameters(ctx context.Context) ([]ParamInfo, error)
+}
+
+// ParamInfo describes a bind parameter of a prepared statement as inferred by the server.
+type ParamInfo struct {
+	Name      string
+	Type      string // Snowflake data type, e.g. FIXED, TEXT or TIMESTAMP_NTZ
+	Precision int64
+	Scale     int64
+	Length    int64
+	Nullable  bool
 }
 
 type snowflakeStmt struct {
 	sc          *snowflakeConn
 	query       string
 	lastQueryID string
+	params      []ParamInfo
 }
 
 func (stmt *snowflakeStmt) Close() error {
@@ -61,3 +74,34 @@ func (stmt *snowflakeStmt) Query(args []driver.Value) (driver.Rows, error) {
 func (stmt *snowflakeStmt) GetQueryID() string {
 	return stmt.lastQueryID
 }
+
+// BindParameters returns the bind parameters the statement expects. The statement is described
+// by the server on the first call and the result is reused by the subsequent calls, as well as by
+// the statements with the same SQL text prepared later on the connection unless Config.DisablePrepareCache is set.
+func (stmt *snowflakeStmt) BindParameters(ctx context.Context) ([]ParamInfo, error) {
+	if stmt.params != nil {
+		return stmt.params, nil
+	}
+	if stmt.sc == nil || stmt.sc.rest == nil {
+		return nil, driver.ErrBadConn
+	}
+	data, err := stmt.sc.exec(ctx, stmt.query, false /* noResult */, false, /* isInternal */
+		true /* describeOnly */, nil)
+	if err != nil {
+		return nil, err
+	}
+	params := make([]ParamInfo, len(data.Data.MetaDataOfBinds))
+	for i, bind := range data.Data.MetaDataOfBinds {
+		params[i] = ParamInfo{
+			Name:      bind.Name,
+			Type:      strings.ToUpper(bind.Type),
+			Precision: bind.Precision,
+			Scale:     bind.Scale,
+			Length:    bind.Length,
+			Nullable:  bind.Nullable,
+		}
+	}
+	stmt.params = params
+	stmt.sc.prepareCache.put(stmt.sc.prepareCacheKey(stmt.query), params)
+	return params, nil
+}
@@ -7,6 +7,7 @@ import (
 	"context"
 	"database/sql"
 	"database/sql/driver"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -414,3 +415,158 @@ func TestStatementQueryIdForExecs(t *testing.T) {
 		}
 	})
 }
+
+func TestStmtBindParameters(t *testing.T) {
+	requests := 0
+	postQueryMock := func(_ context.Context, _ *snowflakeRestful, _ *url.Values,
+		_ map[string]string, body []byte, _ time.Duration, _ UUID, _ *Config) (*execResponse, error) {
+		requests++
+		var req execRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Fatal(err)
+		}
+		if !req.DescribeOnly {
+			t.Fatal("the statement should be described")
+		}
+		return &execResponse{
+			Data: execResponseData{
+				MetaDataOfBinds: []execResponseRowType{
+					{Name: "1", Type: "fixed", Precision: 38, Nullable: true},
+					{Name: "2", Type: "text", Length: 16777216, Nullable: true},
+				},
+			},
+			Success: true,
+		}, nil
+	}
+	sc := &snowflakeConn{
+		cfg:               &Config{Params: map[string]*string{}},
+		rest:              &snowflakeRestful{FuncPostQuery: postQueryMock},
+		queryContextCache: (&queryContextCache{}).init(),
+	}
+	stmt, err := sc.PrepareContext(context.Background(), "SELECT * FROM orders WHERE id = ? AND status = ?")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		params, err := stmt.(SnowflakeStmt).BindParameters(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(params) != 2 {
+			t.Fatalf("expected 2 parameters, got: %v", len(params))
+		}
+		if params[0].Type != "FIXED" || params[0].Precision != 38 || params[1].Type != "TEXT" || params[1].Length != 16777216 {
+			t.Fatalf("unexpected parameters: %+v", params)
+		}
+	}
+	if requests != 1 {
+		t.Fatalf("the statement should be described once, got %v requests", requests)
+	}
+}
+
+func TestPrepareReusesCachedDescribe(t *testing.T) {
+	requests := 0
+	postQueryMock := func(_ context.Context, _ *snowflakeRestful, _ *url.Values,
+		_ map[string]string, _ []byte, _ time.Duration, _ UUID, _ *Config) (*execResponse, error) {
+		requests++
+		return &execResponse{
+			Data: execResponseData{
+				MetaDataOfBinds: []execResponseRowType{{Name: "1", Type: "fixed", Precision: 38, Nullable: true}},
+			},
+			Success: true,
+		}, nil
+	}
+	const query = "SELECT * FROM orders WHERE id = ?"
+	for _, disabled := range []bool{false, true} {
+		requests = 0
+		sc := &snowflakeConn{
+			cfg:               &Config{Params: map[string]*string{}, DisablePrepareCache: disabled},
+			rest:              &snowflakeRestful{FuncPostQuery: postQueryMock},
+			queryContextCache: (&queryContextCache{}).init(),
+		}
+		if !disabled {
+			sc.prepareCache = newPrepareCache(maxPrepareCacheEntries)
+		}
+		for i := 0; i < 2; i++ {
+			stmt, err := sc.PrepareContext(context.Background(), query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			params, err := stmt.(SnowflakeStmt).BindParameters(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(params) != 1 || params[0].Type != "FIXED" {
+				t.Fatalf("unexpected parameters: %+v", params)
+			}
+		}
+		expected := 1
+		if disabled {
+			expected = 2
+		}
+		if requests != expected {
+			t.Fatalf("expected %v describe requests with DisablePrepareCache=%v, got: %v", expected, disabled, requests)
+		}
+	}
+}
+
+func TestPrepareCacheKeyedBySessionContext(t *testing.T) {
+	requests := 0
+	postQueryMock := func(_ context.Context, _ *snowflakeRestful, _ *url.Values,
+		_ map[string]string, _ []byte, _ time.Duration, _ UUID, _ *Config) (*execResponse, error) {
+		requests++
+		return &execResponse{
+			Data: execResponseData{
+				MetaDataOfBinds: []execResponseRowType{{Name: "1", Type: "fixed", Precision: 38, Nullable: true}},
+			},
+			Success: true,
+		}, nil
+	}
+	sc := &snowflakeConn{
+		cfg:               &Config{Params: map[string]*string{}, Database: "DB", Schema: "PUBLIC", Role: "ANALYST"},
+		rest:              &snowflakeRestful{FuncPostQuery: postQueryMock},
+		queryContextCache: (&queryContextCache{}).init(),
+		prepareCache:      newPrepareCache(maxPrepareCacheEntries),
+	}
+	describe := func() {
+		stmt, err := sc.PrepareContext(context.Background(), "SELECT * FROM orders WHERE id = ?")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = stmt.(SnowflakeStmt).BindParameters(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	describe()
+	for i, change := range []func(){
+		func() { sc.cfg.Database = "OTHER_DB" },
+		func() { sc.cfg.Schema = "OTHER_SCHEMA" },
+		func() { sc.cfg.Role = "OTHER_ROLE" },
+	} {
+		change()
+		describe()
+		if requests != i+2 {
+			t.Fatalf("the statement should be described again in the new session context, got %v requests", requests)
+		}
+	}
+	describe()
+	if requests != 4 {
+		t.Fatalf("the statement should be cached in the current session context, got %v requests", requests)
+	}
+}
+
+func TestPrepareCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	pc := newPrepareCache(2)
+	pc.put(prepareCacheKey{query: "a"}, []ParamInfo{{Name: "1"}})
+	pc.put(prepareCacheKey{query: "b"}, []ParamInfo{{Name: "1"}})
+	pc.get(prepareCacheKey{query: "a"})
+	pc.put(prepareCacheKey{query: "c"}, []ParamInfo{{Name: "1"}})
+	if _, ok := pc.get(prepareCacheKey{query: "b"}); ok {
+		t.Fatal("the least recently used statement should be evicted")
+	}
+	for _, query := range []string{"a", "c"} {
+		if _, ok := pc.get(prepareCacheKey{query: query}); !ok {
+			t.Fatalf("%v should be cached", query)
+		}
+	}
+}
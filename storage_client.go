@@ -3,8 +3,11 @@
 package gosnowflake
 
 import (
+	"bufio"
 	"bytes"
+	"compress/gzip"
 	"fmt"
+	"io"
 	"math"
 	"os"
 	"path"
@@ -58,7 +61,7 @@ func (rsu *remoteStorageUtil) uploadOneFile(meta *fileMetadata) error {
 	var encryptMeta *encryptMetadata
 	var dataFile string
 	var err error
-	if meta.encryptionMaterial != nil {
+	if meta.encryptionMaterial != nil && !isGcsServerSideEncrypted(meta.stageInfo) {
 		if meta.srcStream != nil {
 			var encryptedStream bytes.Buffer
 			srcStream := meta.srcStream
@@ -86,6 +89,9 @@ func (rsu *remoteStorageUtil) uploadOneFile(meta *fileMetadata) error {
 	var lastErr error
 	maxRetry := defaultMaxRetry
 	for retry := 0; retry < maxRetry; retry++ {
+		if err = meta.transferContext().Err(); err != nil {
+			return err
+		}
 		if !meta.overwrite {
 			header, err := utilClass.getFileHeader(meta, meta.dstFileName)
 			if err != nil {
@@ -197,16 +203,38 @@ func (rsu *remoteStorageUtil) downloadOneFile(meta *fileMetadata) error {
 	if header != nil {
 		meta.srcFileSize = header.contentLength
 	}
+	cacheKey := meta.stageInfo.Location + meta.srcFileName
+	cacheable := meta.getCache != nil && header != nil && header.etag != ""
+	if cacheable {
+		if data, ok := meta.getCache.get(cacheKey, header.etag); ok {
+			logger.Debugf("serving %v from the GET cache", meta.srcFileName)
+			if err = os.WriteFile(fullDstFileName, data, readWriteFileMode); err != nil {
+				return err
+			}
+			meta.dstFileSize = int64(len(data))
+			meta.resStatus = downloaded
+			return nil
+		}
+	}
+
+	// counted before the download so that a file over Config.MaxDownloadBytes is not downloaded at all
+	if err = meta.downloads.add(meta.srcFileSize); err != nil {
+		return err
+	}
 
 	maxConcurrency := meta.parallel
 	var lastErr error
+	var corruptFileRetried bool
 	maxRetry := defaultMaxRetry
 	for retry := 0; retry < maxRetry; retry++ {
+		if err = meta.transferContext().Err(); err != nil {
+			return err
+		}
 		if err = utilClass.nativeDownloadFile(meta, fullDstFileName, maxConcurrency); err != nil {
 			return err
 		}
 		if meta.resStatus == downloaded {
-			if meta.encryptionMaterial != nil {
+			if meta.encryptionMaterial != nil && !isGcsServerSideEncrypted(meta.stageInfo) {
 				if meta.presignedURL != nil {
 					header, err = utilClass.getFileHeader(meta, meta.srcFileName)
 					if err != nil {
@@ -222,9 +250,27 @@ func (rsu *remoteStorageUtil) downloadOneFile(meta *fileMetadata) error {
 					return err
 				}
 			}
+			if meta.verifyGzip {
+				if err = verifyGzipFile(fullDstFileName); err != nil {
+					if corruptFileRetried {
+						return errCorruptStageFile(meta.srcFileName, err)
+					}
+					logger.Warnf("the downloaded file %v is corrupt: %v. downloading it once more", meta.srcFileName, err)
+					corruptFileRetried = true
+					if err = meta.downloads.add(meta.srcFileSize); err != nil {
+						return err
+					}
+					continue
+				}
+			}
 			if fi, err := os.Stat(fullDstFileName); err == nil {
 				meta.dstFileSize = fi.Size()
 			}
+			if cacheable && meta.dstFileSize <= meta.getCache.capacity {
+				if data, err := os.ReadFile(fullDstFileName); err == nil {
+					meta.getCache.put(cacheKey, header.etag, data)
+				}
+			}
 			return nil
 		}
 		lastErr = meta.lastError
@@ -234,3 +280,24 @@ func (rsu *remoteStorageUtil) downloadOneFile(meta *fileMetadata) error {
 	}
 	return fmt.Errorf("unkown error downloading %v", fullDstFileName)
 }
+
+// verifyGzipFile reads a gzip file through, so that the CRC and the length in the trailer of each of its
+// members are checked. Files that are not gzip compressed are not checked.
+func verifyGzipFile(fileName string) error {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+	if magic, err := r.Peek(2); err != nil || magic[0] != 0x1f || magic[1] != 0x8b {
+		return nil
+	}
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+	_, err = io.Copy(io.Discard, zr)
+	return err
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2023 Snowflake Computing Inc. All rights reserved.
+
+package gosnowflake
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	defaultStreamInsertFlushSize     = 1000
+	defaultStreamInsertFlushInterval = time.Second
+)
+
+// StreamInsertConfig configures StreamInsert.
+type StreamInsertConfig struct {
+	// FlushSize is the number of accumulated rows that triggers an insert. Defaults to 1000.
+	FlushSize int
+	// FlushInterval is the maximum time a row waits before it is inserted. Defaults to 1 second.
+	FlushInterval time.Duration
+	// OnFlush is called after every insert. If it is not set, StreamInsert stops at the first failed insert.
+	OnFlush func(result StreamFlushResult)
+}
+
+// StreamFlushResult is the result of one insert of StreamInsert.
+type StreamFlushResult struct {
+	// Rows is the number of rows in the batch
+	Rows int
+	// RowsAffected is the number of rows inserted by the batch
+	RowsAffected int64
+	// Err is the error of the insert, if any. The rows of a failed batch are not retried.
+	Err error
+}
+
+// StreamInsert executes the insert statement query for the rows received from the channel.
+// The rows are accumulated and inserted with array binding, one column per bind variable,
+// whenever FlushSize rows are pending or FlushInterval elapsed since the first pending row.
+// Large batches are uploaded to a temporary stage as for any array bind.
+// All the rows of a batch must have the same number of values, and the values of a column
+// must be of the same type or nil. time.Time values are bound as TIMESTAMP_NTZ.
+//
+// StreamInsert returns once the channel is closed and the pending rows are inserted,
+// or when ctx is done, in which case the pending rows are discarded.
+func StreamInsert(ctx context.Context, conn SQLExecutor, query string, rows <-chan []interface{}, config StreamInsertConfig) error {
+	flushSize := config.FlushSize
+	if flushSize <= 0 {
+		flushSize = defaultStreamInsertFlushSize
+	}
+	flushInterval := config.FlushInterval
+	if flushInterval <= 0 {
+		flushInterval = defaultStreamInsertFlushInterval
+	}
+	timer := time.NewTimer(flushInterval)
+	if !timer.Stop() {
+		<-timer.C
+	}
+	defer timer.Stop()
+
+	var batch [][]interface{}
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		result := StreamFlushResult{Rows: len(batch)}
+		result.RowsAffected, result.Err = streamInsertBatch(ctx, conn, query, batch)
+		batch = nil
+		if config.OnFlush != nil {
+			config.OnFlush(result)
+			return nil
+		}
+		return result.Err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case row, ok := <-rows:
+			if !ok {
+				return flush()
+			}
+			if len(batch) == 0 {
+				timer.Reset(flushInterval)
+			}
+			batch = append(batch, row)
+			if len(batch) >= flushSize {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+		case <-timer.C:
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func streamInsertBatch(ctx context.Context, conn SQLExecutor, query string, batch [][]interface{}) (int64, error) {
+	columns := make([][]interface{}, len(batch[0]))
+	for i, row := range batch {
+		if len(row) != len(columns) {
+			return 0, errStreamInsertRowLength(i+1, len(row), len(columns))
+		}
+		for j, v := range row {
+			columns[j] = append(columns[j], v)
+		}
+	}
+	args := make([]interface{}, len(columns))
+	for j := range columns {
+		args[j] = Array(&columns[j], TimestampNTZType)
+	}
+	res, err := conn.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
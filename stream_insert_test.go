@@ -0,0 +1,147 @@
+// Copyright (c) 2023 Snowflake Computing Inc. All rights reserved.
+
+package gosnowflake
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+type streamInsertExecutorMock struct {
+	mu      sync.Mutex
+	batches [][][]interface{}
+	err     error
+}
+
+func (m *streamInsertExecutorMock) ExecContext(_ context.Context, _ string, args ...interface{}) (sql.Result, error) {
+	var columns [][]interface{}
+	for _, arg := range args {
+		binding, ok := arg.(interfaceArrayBinding)
+		if !ok {
+			return nil, errors.New("not an array bind")
+		}
+		columns = append(columns, *binding.timezoneTypeArray.(*[]interface{}))
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.batches = append(m.batches, columns)
+	if m.err != nil {
+		return nil, m.err
+	}
+	return driver.RowsAffected(len(columns[0])), nil
+}
+
+func (m *streamInsertExecutorMock) batchSizes() []int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var sizes []int
+	for _, batch := range m.batches {
+		sizes = append(sizes, len(batch[0]))
+	}
+	return sizes
+}
+
+func TestStreamInsertFlushesBatchesBySize(t *testing.T) {
+	mock := &streamInsertExecutorMock{}
+	rows := make(chan []interface{})
+	var results []StreamFlushResult
+	done := make(chan error)
+	go func() {
+		done <- StreamInsert(context.Background(), mock, "INSERT INTO t VALUES (?, ?)", rows, StreamInsertConfig{
+			FlushSize:     3,
+			FlushInterval: time.Hour,
+			OnFlush:       func(result StreamFlushResult) { results = append(results, result) },
+		})
+	}()
+	for i := 0; i < 7; i++ {
+		rows <- []interface{}{i, nil}
+	}
+	close(rows)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if sizes := mock.batchSizes(); !reflect.DeepEqual(sizes, []int{3, 3, 1}) {
+		t.Fatalf("unexpected batches: %v", sizes)
+	}
+	if !reflect.DeepEqual(mock.batches[1], [][]interface{}{{3, 4, 5}, {nil, nil, nil}}) {
+		t.Fatalf("unexpected columns: %v", mock.batches[1])
+	}
+	if len(results) != 3 || results[2].Rows != 1 || results[2].RowsAffected != 1 || results[2].Err != nil {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+}
+
+func TestStreamInsertFlushesBatchesByInterval(t *testing.T) {
+	mock := &streamInsertExecutorMock{}
+	rows := make(chan []interface{})
+	flushed := make(chan StreamFlushResult, 2)
+	done := make(chan error)
+	go func() {
+		done <- StreamInsert(context.Background(), mock, "INSERT INTO t VALUES (?)", rows, StreamInsertConfig{
+			FlushSize:     100,
+			FlushInterval: 10 * time.Millisecond,
+			OnFlush:       func(result StreamFlushResult) { flushed <- result },
+		})
+	}()
+	rows <- []interface{}{"a"}
+	rows <- []interface{}{"b"}
+	if result := <-flushed; result.Rows != 2 {
+		t.Fatalf("expected 2 rows flushed after the interval, got: %v", result.Rows)
+	}
+	rows <- []interface{}{"c"}
+	close(rows)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if sizes := mock.batchSizes(); !reflect.DeepEqual(sizes, []int{2, 1}) {
+		t.Fatalf("unexpected batches: %v", sizes)
+	}
+}
+
+func TestStreamInsertErrors(t *testing.T) {
+	expectedErr := errors.New("insert failed")
+	mock := &streamInsertExecutorMock{err: expectedErr}
+	rows := make(chan []interface{}, 4)
+	rows <- []interface{}{1}
+	rows <- []interface{}{2}
+	rows <- []interface{}{3, 4}
+	rows <- []interface{}{5}
+	close(rows)
+	var results []StreamFlushResult
+	err := StreamInsert(context.Background(), mock, "INSERT INTO t VALUES (?)", rows, StreamInsertConfig{
+		FlushSize: 2,
+		OnFlush:   func(result StreamFlushResult) { results = append(results, result) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || !errors.Is(results[0].Err, expectedErr) {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+	var se *SnowflakeError
+	if !errors.As(results[1].Err, &se) || se.Number != ErrStreamInsertRowLength {
+		t.Fatalf("expected row length error, got: %v", results[1].Err)
+	}
+
+	rows = make(chan []interface{}, 1)
+	rows <- []interface{}{1}
+	close(rows)
+	if err = StreamInsert(context.Background(), mock, "INSERT INTO t VALUES (?)", rows, StreamInsertConfig{}); !errors.Is(err, expectedErr) {
+		t.Fatalf("expected the insert error without OnFlush, got: %v", err)
+	}
+}
+
+func TestStreamInsertContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := StreamInsert(ctx, &streamInsertExecutorMock{}, "INSERT INTO t VALUES (?)", make(chan []interface{}), StreamInsertConfig{})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context canceled, got: %v", err)
+	}
+}
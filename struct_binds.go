@@ -0,0 +1,177 @@
+// Copyright (c) 2023 Snowflake Computing Inc. All rights reserved.
+
+package gosnowflake
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// structBindGoTypes are the field types of the structs bound with StructArrayBinds, as supported by Array.
+var structBindGoTypes = map[reflect.Type]bool{
+	reflect.TypeOf(int(0)):      true,
+	reflect.TypeOf(int32(0)):    true,
+	reflect.TypeOf(int64(0)):    true,
+	reflect.TypeOf(float32(0)):  true,
+	reflect.TypeOf(float64(0)):  true,
+	reflect.TypeOf(false):       true,
+	reflect.TypeOf(""):          true,
+	reflect.TypeOf([]byte(nil)): true,
+	objectTimeGoType:            true,
+}
+
+// structBindTimeTypes maps the type options of the `sf` tags to the types time.Time fields are bound as.
+var structBindTimeTypes = map[string]timezoneType{
+	"timestamp_ntz": TimestampNTZType,
+	"timestamp_ltz": TimestampLTZType,
+	"timestamp_tz":  TimestampTZType,
+	"date":          DateType,
+	"time":          TimeType,
+}
+
+// structBindColumn is a struct field bound as the array of a column.
+type structBindColumn struct {
+	name   string
+	index  int
+	typ    reflect.Type
+	tzType timezoneType
+}
+
+// StructArrayBinds converts rows, a slice of structs or of pointers to structs, into array binds, one per
+// column, so that all the rows are inserted by a single statement. It returns the names of the columns and
+// their binds in the same order:
+//
+//	columns, binds, err := sf.StructArrayBinds(people)
+//	query := fmt.Sprintf("INSERT INTO people (%v) VALUES (?, ?)", strings.Join(columns, ", "))
+//	_, err = db.ExecContext(ctx, query, binds...)
+//
+// Each exported field is mapped to the column given by its `sf` tag, or to the field name if the tag is
+// missing. Fields tagged with `sf:"-"` are skipped. The supported field types are int, int32, int64, float32,
+// float64, bool, string, []byte, time.Time and pointers to them, a nil pointer being bound as NULL.
+// time.Time fields are bound as TIMESTAMP_NTZ unless the tag has another type after the column name,
+// e.g. `sf:"created_at,timestamp_tz"`; the types are timestamp_ntz, timestamp_ltz, timestamp_tz, date and time.
+//
+// As for any array bind, the values are sent with the statement or uploaded to a temporary stage
+// when their number exceeds the array bind stage threshold.
+func StructArrayBinds(rows interface{}) (columns []string, binds []interface{}, err error) {
+	rv := reflect.ValueOf(rows)
+	if rv.Kind() != reflect.Slice {
+		return nil, nil, errStructArrayBind(fmt.Sprintf("%T", rows), "not a slice of structs")
+	}
+	elemType := rv.Type().Elem()
+	pointers := elemType.Kind() == reflect.Ptr
+	if pointers {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct || elemType == objectTimeGoType {
+		return nil, nil, errStructArrayBind(fmt.Sprintf("%T", rows), "not a slice of structs")
+	}
+	cols, err := structBindColumns(elemType)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	values := make([]reflect.Value, rv.Len())
+	for i := range values {
+		values[i] = rv.Index(i)
+		if pointers {
+			if values[i].IsNil() {
+				return nil, nil, errStructArrayBind(elemType.String(), fmt.Sprintf("row %v is nil", i+1))
+			}
+			values[i] = values[i].Elem()
+		}
+	}
+	for _, col := range cols {
+		columns = append(columns, col.name)
+		binds = append(binds, col.arrayBind(values))
+	}
+	return columns, binds, nil
+}
+
+// InsertStructs inserts rows, a slice of structs or of pointers to structs, into table with a single
+// statement binding the columns returned by StructArrayBinds. The table name is used as is in the statement.
+// It returns the number of inserted rows.
+func InsertStructs(ctx context.Context, conn SQLExecutor, table string, rows interface{}) (int64, error) {
+	columns, binds, err := StructArrayBinds(rows)
+	if err != nil {
+		return 0, err
+	}
+	if reflect.ValueOf(rows).Len() == 0 {
+		return 0, nil
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ")
+	query := fmt.Sprintf("INSERT INTO %v (%v) VALUES (%v)", table, strings.Join(columns, ", "), placeholders)
+	res, err := conn.ExecContext(ctx, query, binds...)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func structBindColumns(t reflect.Type) ([]structBindColumn, error) {
+	var cols []structBindColumn
+	names := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		tag := f.Tag.Get("sf")
+		if tag == "-" {
+			continue
+		}
+		name, option, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = f.Name
+		}
+		baseType := f.Type
+		if baseType.Kind() == reflect.Ptr {
+			baseType = baseType.Elem()
+		}
+		if !structBindGoTypes[baseType] {
+			return nil, errStructArrayBind(t.String(), fmt.Sprintf("unsupported type %v of field %v", f.Type, f.Name))
+		}
+		col := structBindColumn{name: name, index: i, typ: f.Type, tzType: TimestampNTZType}
+		if option != "" {
+			tzType, ok := structBindTimeTypes[strings.ToLower(option)]
+			if !ok || baseType != objectTimeGoType {
+				return nil, errStructArrayBind(t.String(), fmt.Sprintf("invalid option %q of field %v", option, f.Name))
+			}
+			col.tzType = tzType
+		}
+		if names[strings.ToUpper(name)] {
+			return nil, errStructArrayBind(t.String(), fmt.Sprintf("duplicate column %v", name))
+		}
+		names[strings.ToUpper(name)] = true
+		cols = append(cols, col)
+	}
+	if len(cols) == 0 {
+		return nil, errStructArrayBind(t.String(), "no exported fields")
+	}
+	return cols, nil
+}
+
+// arrayBind returns the array bind of the column of the rows. Pointer fields are bound as a []interface{}
+// so that nil values are bound as NULL.
+func (c structBindColumn) arrayBind(rows []reflect.Value) interface{} {
+	if c.typ.Kind() == reflect.Ptr {
+		values := make([]interface{}, len(rows))
+		for i, row := range rows {
+			if fv := row.Field(c.index); !fv.IsNil() {
+				values[i] = fv.Elem().Interface()
+			}
+		}
+		return Array(&values, c.tzType)
+	}
+	values := reflect.MakeSlice(reflect.SliceOf(c.typ), len(rows), len(rows))
+	for i, row := range rows {
+		values.Index(i).Set(row.Field(c.index))
+	}
+	if c.typ == objectTimeGoType {
+		return Array(values.Interface().([]time.Time), c.tzType)
+	}
+	return Array(values.Interface())
+}
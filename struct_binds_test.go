@@ -0,0 +1,162 @@
+// Copyright (c) 2023 Snowflake Computing Inc. All rights reserved.
+
+package gosnowflake
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type structBindPerson struct {
+	ID        int       `sf:"id"`
+	Name      string    `sf:"name"`
+	Score     *float64  `sf:"score"`
+	CreatedAt time.Time `sf:"created_at,timestamp_tz"`
+	Comment   string    `sf:"-"`
+	internal  int
+}
+
+func TestInsertStructs(t *testing.T) {
+	var requests []execRequest
+	sc := getPooledSnowflakeConn()
+	sc.ctx = context.Background()
+	sc.rest.FuncPostQuery = func(_ context.Context, _ *snowflakeRestful, _ *url.Values,
+		_ map[string]string, body []byte, _ time.Duration, _ UUID, _ *Config) (*execResponse, error) {
+		var req execRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Fatal(err)
+		}
+		requests = append(requests, req)
+		inserted := strconv.Itoa(len(req.Bindings["1"].Value.([]interface{})))
+		return &execResponse{
+			Data: execResponseData{
+				StatementTypeID: statementTypeIDDml,
+				RowType:         []execResponseRowType{{Name: "number of rows inserted", Type: "fixed"}},
+				RowSet:          [][]*string{{&inserted}},
+			},
+			Success: true,
+		}, nil
+	}
+	db := sql.OpenDB(showConnectorMock{sc: sc})
+	defer db.Close()
+
+	createdAt := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+	people := make([]structBindPerson, 1000)
+	for i := range people {
+		people[i] = structBindPerson{ID: i, Name: fmt.Sprintf("name%v", i), CreatedAt: createdAt, Comment: "skipped", internal: i}
+		if i%10 != 0 {
+			score := float64(i) / 2
+			people[i].Score = &score
+		}
+	}
+	inserted, err := InsertStructs(context.Background(), db, "people", people)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inserted != 1000 {
+		t.Fatalf("expected 1000 inserted rows, got: %v", inserted)
+	}
+	if len(requests) != 1 {
+		t.Fatalf("the rows should be inserted by a single statement, got: %v", len(requests))
+	}
+	req := requests[0]
+	if req.SQLText != "INSERT INTO people (id, name, score, created_at) VALUES (?, ?, ?, ?)" {
+		t.Fatalf("unexpected query: %v", req.SQLText)
+	}
+	expectedTypes := []snowflakeType{fixedType, textType, realType, timestampTzType}
+	if len(req.Bindings) != len(expectedTypes) {
+		t.Fatalf("unexpected bindings: %v", req.Bindings)
+	}
+	for i, typ := range expectedTypes {
+		binding := req.Bindings[strconv.Itoa(i+1)]
+		if binding.Type != typ.String() {
+			t.Fatalf("binding %v should be %v, got: %v", i+1, typ, binding.Type)
+		}
+		if values := binding.Value.([]interface{}); len(values) != 1000 {
+			t.Fatalf("binding %v should have 1000 values, got: %v", i+1, len(values))
+		}
+	}
+	row := func(i int) []interface{} {
+		var values []interface{}
+		for j := range expectedTypes {
+			values = append(values, req.Bindings[strconv.Itoa(j+1)].Value.([]interface{})[i])
+		}
+		return values
+	}
+	tz := fmt.Sprintf("%v 1440", createdAt.UnixNano())
+	if values := row(41); !reflect.DeepEqual(values, []interface{}{"41", "name41", "20.5", tz}) {
+		t.Fatalf("unexpected values of row 41: %v", values)
+	}
+	if values := row(40); !reflect.DeepEqual(values, []interface{}{"40", "name40", nil, tz}) {
+		t.Fatalf("unexpected values of row 40: %v", values)
+	}
+}
+
+func TestInsertStructsUploadsToStage(t *testing.T) {
+	var requests []execRequest
+	sc := getPooledSnowflakeConn()
+	sc.ctx = context.Background()
+	sc.cfg.ArrayBindStageThreshold = 10
+	sc.rest.FuncPostQuery = bindStagePostQueryMock(t, t.TempDir(), &requests)
+	db := sql.OpenDB(showConnectorMock{sc: sc})
+	defer db.Close()
+
+	people := make([]*structBindPerson, 100)
+	for i := range people {
+		people[i] = &structBindPerson{ID: i, Name: fmt.Sprintf("name%v", i)}
+	}
+	if _, err := InsertStructs(context.Background(), db, "people", people); err != nil {
+		t.Fatal(err)
+	}
+	var insert *execRequest
+	for i := range requests {
+		if strings.HasPrefix(requests[i].SQLText, "INSERT") {
+			insert = &requests[i]
+		}
+	}
+	if insert == nil || insert.Bindings != nil || insert.BindStage == "" {
+		t.Fatalf("the binds should be uploaded to the stage, got: %v", requests)
+	}
+}
+
+func TestStructArrayBindsErrors(t *testing.T) {
+	type unsupported struct {
+		ID    int
+		Flags uint8
+	}
+	type invalidOption struct {
+		Name string `sf:"name,timestamp_tz"`
+	}
+	type duplicate struct {
+		A int `sf:"id"`
+		B int `sf:"ID"`
+	}
+	testcases := []struct {
+		name string
+		rows interface{}
+	}{
+		{"not a slice", structBindPerson{}},
+		{"slice of ints", []int{1}},
+		{"unsupported field", []unsupported{{}}},
+		{"option of a string field", []invalidOption{{}}},
+		{"duplicate column", []duplicate{{}}},
+		{"nil row", []*structBindPerson{nil}},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, err := StructArrayBinds(tc.rows)
+			se, ok := err.(*SnowflakeError)
+			if !ok || se.Number != ErrStructArrayBind {
+				t.Fatalf("expected ErrStructArrayBind, got: %v", err)
+			}
+		})
+	}
+}
@@ -27,6 +27,7 @@ const (
 	reasonKey        = "reason"
 	errorNumberKey   = "ErrorNumber"
 	stacktraceKey    = "Stacktrace"
+	queryTextHashKey = "QueryTextSHA256"
 )
 
 const (
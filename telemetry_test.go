@@ -4,10 +4,12 @@ package gosnowflake
 
 import (
 	"context"
+	"database/sql/driver"
 	"errors"
 	"math/rand"
 	"net/http"
 	"net/url"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -270,3 +272,48 @@ func TestAddLogError(t *testing.T) {
 		}
 	})
 }
+
+func TestTelemetryQueryTextHash(t *testing.T) {
+	postQueryMock := func(_ context.Context, _ *snowflakeRestful, _ *url.Values,
+		_ map[string]string, _ []byte, _ time.Duration, _ UUID, _ *Config) (*execResponse, error) {
+		return &execResponse{
+			Data:    execResponseData{QueryID: "1", SQLState: "42000"},
+			Code:    "1003",
+			Message: "SQL compilation error",
+			Success: false,
+		}, nil
+	}
+	query := "SELECT * FROM t WHERE c = ?"
+	for _, include := range []bool{false, true} {
+		sc := getDefaultSnowflakeConn()
+		sc.cfg.TelemetryIncludeQueryText = include
+		sc.rest.FuncPostQuery = postQueryMock
+		sc.telemetry = &snowflakeTelemetry{
+			sr:        sc.rest,
+			mutex:     &sync.Mutex{},
+			enabled:   true,
+			flushSize: defaultFlushSize,
+		}
+		bindings := []driver.NamedValue{{Ordinal: 1, Value: "secret"}}
+		if _, err := sc.QueryContext(context.Background(), query, bindings); err == nil {
+			t.Fatal("should have failed")
+		}
+		if len(sc.telemetry.logs) == 0 {
+			t.Fatal("expected telemetry events")
+		}
+		for _, event := range sc.telemetry.logs {
+			hash, ok := event.Message[queryTextHashKey]
+			if ok != include {
+				t.Fatalf("unexpected query text hash presence with TelemetryIncludeQueryText=%v: %v", include, event.Message)
+			}
+			if include && hash != "516639b19af9451405296093fd80023acb02db84b5e845ebd2e4cb3f79f3021b" {
+				t.Fatalf("unexpected query text hash: %v", hash)
+			}
+			for key, value := range event.Message {
+				if strings.Contains(value, "secret") || strings.Contains(value, query) {
+					t.Fatalf("telemetry event contains the query text or bind values. %v: %v", key, value)
+				}
+			}
+		}
+	}
+}
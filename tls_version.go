@@ -0,0 +1,81 @@
+// Copyright (c) 2023 Snowflake Computing Inc. All rights reserved.
+
+package gosnowflake
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+)
+
+// tlsVersions maps the values of Config.MinTLSVersion to the TLS versions.
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+type minTLSVersionTransportKey struct {
+	version uint16
+}
+
+// transportWithMinTLSVersion returns a copy of the transport refusing TLS versions older than version.
+func transportWithMinTLSVersion(base *http.Transport, version uint16) *http.Transport {
+	return cachedTransport(base, minTLSVersionTransportKey{version: version}, func(t *http.Transport) {
+		if t.TLSClientConfig == nil {
+			t.TLSClientConfig = &tls.Config{}
+		}
+		t.TLSClientConfig.MinVersion = version
+	})
+}
+
+type rootCAsTransportKey struct {
+	pool *x509.CertPool
+}
+
+// transportWithRootCAs returns a copy of the transport trusting the root CAs of pool instead of those of
+// the base transport. Other TLS settings, e.g. the OCSP check in VerifyPeerCertificate, are kept.
+// The copy is shared by the connections with the same pool, compared by pointer: a pool built for
+// every connection adds a transport that is never released, so build Config.RootCAs once.
+func transportWithRootCAs(base *http.Transport, pool *x509.CertPool) *http.Transport {
+	return cachedTransport(base, rootCAsTransportKey{pool: pool}, func(t *http.Transport) {
+		if t.TLSClientConfig == nil {
+			t.TLSClientConfig = &tls.Config{}
+		}
+		t.TLSClientConfig.RootCAs = pool
+	})
+}
+
+// storageTransport returns the transport of the requests to the cloud storage,
+// or nil if the default transports of the storage SDKs can be used. It applies the TLS,
+// proxy, connect timeout and DNS cache settings of the connection. Config.ResponseHeaderTimeout
+// doesn't apply, as the storage may take long to answer the upload of a large file.
+func storageTransport(cfg *Config) http.RoundTripper {
+	if cfg == nil {
+		return nil
+	}
+	base := http.DefaultTransport.(*http.Transport)
+	t := base
+	if version, ok := tlsVersions[cfg.MinTLSVersion]; ok {
+		t = transportWithMinTLSVersion(t, version)
+	}
+	if cfg.RootCAs != nil {
+		t = transportWithRootCAs(t, cfg.RootCAs)
+	}
+	if cfg.ConnectTimeout > 0 {
+		t = transportWithTimeouts(t, cfg.ConnectTimeout, 0)
+	}
+	if cfg.SOCKS5ProxyURL != "" {
+		if proxyURL, err := parseSOCKS5ProxyURL(cfg.SOCKS5ProxyURL); err == nil {
+			t = transportWithSOCKS5Proxy(t, proxyURL)
+		}
+	}
+	if cfg.DNSCacheTTL > 0 {
+		t = transportWithDNSCache(t, cfg.DNSCacheTTL)
+	}
+	if t == base {
+		return nil
+	}
+	return t
+}
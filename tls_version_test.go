@@ -0,0 +1,118 @@
+// Copyright (c) 2023 Snowflake Computing Inc. All rights reserved.
+
+package gosnowflake
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTransportWithMinTLSVersion(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{MaxVersion: tls.VersionTLS12}
+	server.StartTLS()
+	defer server.Close()
+	base := server.Client().Transport.(*http.Transport)
+
+	resp, err := (&http.Client{Transport: transportWithMinTLSVersion(base, tls.VersionTLS12)}).Get(server.URL)
+	if err != nil {
+		t.Fatalf("TLS 1.2 should be accepted, err: %v", err)
+	}
+	resp.Body.Close()
+	if _, err = (&http.Client{Transport: transportWithMinTLSVersion(base, tls.VersionTLS13)}).Get(server.URL); err == nil {
+		t.Fatal("TLS 1.2 should be refused when TLS 1.3 is the minimum")
+	}
+	if base.TLSClientConfig.MinVersion != 0 {
+		t.Fatalf("the base transport should not be modified, got minimum version: %v", base.TLSClientConfig.MinVersion)
+	}
+}
+
+func TestBuildSnowflakeConnWithMinTLSVersion(t *testing.T) {
+	sc, err := buildSnowflakeConn(context.Background(), Config{
+		Account:       "a",
+		Host:          "a.snowflakecomputing.com",
+		MinTLSVersion: "1.3",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	transport, ok := sc.rest.Client.Transport.(*http.Transport)
+	if !ok || transport.TLSClientConfig.MinVersion != tls.VersionTLS13 {
+		t.Fatalf("expected a transport with TLS 1.3 minimum, got: %v", sc.rest.Client.Transport)
+	}
+	if transport.TLSClientConfig.VerifyPeerCertificate == nil {
+		t.Fatal("OCSP check should be kept")
+	}
+	if SnowflakeTransport.TLSClientConfig.MinVersion != 0 {
+		t.Fatal("the default transport should not be modified")
+	}
+
+	storage, ok := storageTransport(sc.cfg).(*http.Transport)
+	if !ok || storage.TLSClientConfig.MinVersion != tls.VersionTLS13 {
+		t.Fatalf("expected a storage transport with TLS 1.3 minimum, got: %v", storageTransport(sc.cfg))
+	}
+	meta := &fileMetadata{storageTransport: storage}
+	if client := meta.storageHTTPClient(false); client == nil || client.Transport != storage {
+		t.Fatalf("storage requests should use the storage transport, got: %v", client)
+	}
+	if storageTransport(&Config{}) != nil {
+		t.Fatal("the default transports of the storage SDKs should be used without a minimum TLS version")
+	}
+}
+
+func TestBuildSnowflakeConnWithRootCAs(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+	defaultRootCAs := snowflakeNoOCSPTransport.TLSClientConfig.RootCAs
+
+	if _, err := (&http.Client{Transport: snowflakeNoOCSPTransport}).Get(server.URL); err == nil {
+		t.Fatal("the certificate should not be trusted without the custom CA")
+	}
+	sc, err := buildSnowflakeConn(context.Background(), Config{
+		Account:           "a",
+		Host:              "a.snowflakecomputing.com",
+		DisableOCSPChecks: true,
+		RootCAs:           pool,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := sc.rest.Client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("the certificate signed by the custom CA should be trusted, err: %v", err)
+	}
+	resp.Body.Close()
+	if snowflakeNoOCSPTransport.TLSClientConfig.RootCAs != defaultRootCAs {
+		t.Fatal("the default transport should not be modified")
+	}
+	storage, ok := storageTransport(sc.cfg).(*http.Transport)
+	if !ok || storage.TLSClientConfig.RootCAs != pool {
+		t.Fatalf("expected a storage transport with the custom CA, got: %v", storageTransport(sc.cfg))
+	}
+
+	sc, err = buildSnowflakeConn(context.Background(), Config{
+		Account: "a",
+		Host:    "a.snowflakecomputing.com",
+		RootCAs: pool,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	transport, ok := sc.rest.Client.Transport.(*http.Transport)
+	if !ok || transport.TLSClientConfig.RootCAs != pool {
+		t.Fatalf("expected a transport with the custom CA, got: %v", sc.rest.Client.Transport)
+	}
+	if transport.TLSClientConfig.VerifyPeerCertificate == nil {
+		t.Fatal("OCSP check should be kept")
+	}
+}
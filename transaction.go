@@ -6,8 +6,31 @@ import (
 	"context"
 	"database/sql/driver"
 	"errors"
+	"strings"
 )
 
+// TransactionOptions are Snowflake specific options of a transaction that cannot be
+// expressed by sql.TxOptions. Pass them to BeginTx with WithTransactionOptions.
+type TransactionOptions struct {
+	// Name is sent with BEGIN, so that the transaction can be found in the query history
+	Name string
+	// Autocommit overrides the AUTOCOMMIT session parameter for the BEGIN statement
+	Autocommit ConfigBool
+}
+
+// beginStatement returns the BEGIN statement naming the transaction, if a name is given.
+func (opts TransactionOptions) beginStatement() string {
+	if opts.Name == "" {
+		return "BEGIN"
+	}
+	return "BEGIN TRANSACTION NAME " + quoteIdentifier(opts.Name)
+}
+
+// quoteIdentifier returns s as a double quoted, i.e. case sensitive, SQL identifier.
+func quoteIdentifier(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
 type snowflakeTx struct {
 	sc  *snowflakeConn
 	ctx context.Context
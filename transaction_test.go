@@ -5,8 +5,11 @@ package gosnowflake
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"net/url"
 	"testing"
 	"time"
 )
@@ -95,3 +98,48 @@ func withRetry(fn func(context.Context, *sql.Conn) error, numAttempts int, timeo
 		return fmt.Errorf("context deadline exceeded, failed after [%d] attempts", numAttempts)
 	}
 }
+
+func TestBeginWithTransactionOptions(t *testing.T) {
+	var queries []string
+	var autocommit []interface{}
+	postQueryMock := func(_ context.Context, _ *snowflakeRestful, _ *url.Values,
+		_ map[string]string, body []byte, _ time.Duration, _ UUID, _ *Config) (*execResponse, error) {
+		var req execRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Fatal(err)
+		}
+		queries = append(queries, req.SQLText)
+		autocommit = append(autocommit, req.Parameters[string(beginAutocommit)])
+		return &execResponse{Success: true}, nil
+	}
+	sc := &snowflakeConn{
+		cfg:               &Config{Params: map[string]*string{}},
+		rest:              &snowflakeRestful{FuncPostQuery: postQueryMock},
+		queryContextCache: (&queryContextCache{}).init(),
+	}
+	ctx := WithTransactionOptions(context.Background(), TransactionOptions{
+		Name:       `load "orders"`,
+		Autocommit: ConfigBoolFalse,
+	})
+	tx, err := sc.BeginTx(ctx, driver.TxOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if len(queries) != 2 || queries[0] != `BEGIN TRANSACTION NAME "load ""orders"""` || queries[1] != "COMMIT" {
+		t.Fatalf("unexpected queries: %v", queries)
+	}
+	if autocommit[0] != false || autocommit[1] != nil {
+		t.Fatalf("AUTOCOMMIT should only be sent with BEGIN, got: %v", autocommit)
+	}
+
+	queries = nil
+	if _, err = sc.BeginTx(context.Background(), driver.TxOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(queries) != 1 || queries[0] != "BEGIN" {
+		t.Fatalf("unexpected queries: %v", queries)
+	}
+}
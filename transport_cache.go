@@ -0,0 +1,30 @@
+// Copyright (c) 2023 Snowflake Computing Inc. All rights reserved.
+
+package gosnowflake
+
+import (
+	"net/http"
+	"sync"
+)
+
+// cachedTransports holds the copies of the transports made by cachedTransport.
+var cachedTransports sync.Map
+
+type cachedTransportKey struct {
+	base *http.Transport
+	key  interface{}
+}
+
+// cachedTransport returns a copy of base changed by mutate. The copy is made once for base and key,
+// so that the connections with the same settings share its connection pool. key must be comparable
+// and tell apart the changes made by mutate. The copies are never released.
+func cachedTransport(base *http.Transport, key interface{}, mutate func(t *http.Transport)) *http.Transport {
+	k := cachedTransportKey{base: base, key: key}
+	if t, ok := cachedTransports.Load(k); ok {
+		return t.(*http.Transport)
+	}
+	t := base.Clone()
+	mutate(t)
+	actual, _ := cachedTransports.LoadOrStore(k, t)
+	return actual.(*http.Transport)
+}
@@ -0,0 +1,48 @@
+// Copyright (c) 2023 Snowflake Computing Inc. All rights reserved.
+
+package gosnowflake
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestCachedTransport(t *testing.T) {
+	base := &http.Transport{}
+	copies := 0
+	mutate := func(t *http.Transport) {
+		copies++
+		t.ResponseHeaderTimeout = time.Second
+	}
+	first := cachedTransport(base, timeoutsTransportKey{responseHeaderTimeout: time.Second}, mutate)
+	if first == base || first.ResponseHeaderTimeout != time.Second || base.ResponseHeaderTimeout != 0 {
+		t.Fatal("a changed copy of the base transport should be returned")
+	}
+	if cachedTransport(base, timeoutsTransportKey{responseHeaderTimeout: time.Second}, mutate) != first || copies != 1 {
+		t.Fatal("the copy should be shared for the same base and key")
+	}
+	if cachedTransport(base, dnsCacheTransportKey{ttl: time.Second}, mutate) == first {
+		t.Fatal("the keys of other settings should not share the copy")
+	}
+	if cachedTransport(&http.Transport{}, timeoutsTransportKey{responseHeaderTimeout: time.Second}, mutate) == first {
+		t.Fatal("the copies of other base transports should not be shared")
+	}
+}
+
+func TestStorageTransportWithTimeoutsAndDNSCache(t *testing.T) {
+	cfg := &Config{ConnectTimeout: 5 * time.Second, ResponseHeaderTimeout: time.Second, DNSCacheTTL: time.Minute}
+	storage, ok := storageTransport(cfg).(*http.Transport)
+	if !ok {
+		t.Fatalf("expected a storage transport, got: %v", storageTransport(cfg))
+	}
+	if storage.TLSHandshakeTimeout != 5*time.Second {
+		t.Fatalf("the connect timeout should apply to the storage, got: %v", storage.TLSHandshakeTimeout)
+	}
+	if storage.ResponseHeaderTimeout != 0 {
+		t.Fatalf("the response header timeout should not apply to the storage, got: %v", storage.ResponseHeaderTimeout)
+	}
+	if storage.DialContext == nil || storageTransport(cfg) != storage {
+		t.Fatal("the storage transport should resolve the hosts with the DNS cache and be shared")
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright (c) 2023 Snowflake Computing Inc. All rights reserved.
+
+package gosnowflake
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+)
+
+type timeoutsTransportKey struct {
+	connectTimeout        time.Duration
+	responseHeaderTimeout time.Duration
+}
+
+// transportWithTimeouts returns a copy of the transport bounding the dial and the TLS handshake of a new
+// connection by connectTimeout, and the wait for the response headers of a request by responseHeaderTimeout.
+// Zero timeouts keep the settings of the base transport.
+func transportWithTimeouts(base *http.Transport, connectTimeout time.Duration, responseHeaderTimeout time.Duration) *http.Transport {
+	key := timeoutsTransportKey{connectTimeout: connectTimeout, responseHeaderTimeout: responseHeaderTimeout}
+	return cachedTransport(base, key, func(t *http.Transport) {
+		if connectTimeout > 0 {
+			funcDial := base.DialContext
+			if funcDial == nil {
+				funcDial = (&net.Dialer{}).DialContext
+			}
+			t.DialContext = dialContextWithTimeout(funcDial, connectTimeout)
+			t.TLSHandshakeTimeout = connectTimeout
+		}
+		if responseHeaderTimeout > 0 {
+			t.ResponseHeaderTimeout = responseHeaderTimeout
+		}
+	})
+}
+
+// dialContextWithTimeout returns a dial function failing once timeout elapsed. It takes precedence
+// over a longer timeout of the dialer of funcDial.
+func dialContextWithTimeout(funcDial func(ctx context.Context, network, addr string) (net.Conn, error),
+	timeout time.Duration) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		return funcDial(ctx, network, addr)
+	}
+}
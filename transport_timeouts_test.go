@@ -0,0 +1,70 @@
+// Copyright (c) 2023 Snowflake Computing Inc. All rights reserved.
+
+package gosnowflake
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestTransportWithTimeouts(t *testing.T) {
+	var dialDeadline time.Time
+	base := &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			dialDeadline, _ = ctx.Deadline()
+			return nil, errors.New("dial failed")
+		},
+	}
+	transport := transportWithTimeouts(base, 5*time.Second, 30*time.Second)
+	if transport.ResponseHeaderTimeout != 30*time.Second {
+		t.Fatalf("unexpected response header timeout: %v", transport.ResponseHeaderTimeout)
+	}
+	if transport.TLSHandshakeTimeout != 5*time.Second {
+		t.Fatalf("unexpected TLS handshake timeout: %v", transport.TLSHandshakeTimeout)
+	}
+	start := time.Now()
+	if _, err := transport.DialContext(context.Background(), "tcp", "a.snowflakecomputing.com:443"); err == nil {
+		t.Fatal("the dial of the base transport should be used")
+	}
+	if dialDeadline.Before(start.Add(5*time.Second)) || dialDeadline.After(time.Now().Add(5*time.Second)) {
+		t.Fatalf("the dial should time out after 5 seconds, got deadline in: %v", dialDeadline.Sub(start))
+	}
+	if transportWithTimeouts(base, 5*time.Second, 30*time.Second) != transport {
+		t.Fatal("the transports with the same timeouts should be shared")
+	}
+	if base.ResponseHeaderTimeout != 0 || base.TLSHandshakeTimeout != 0 {
+		t.Fatal("the base transport should not be modified")
+	}
+
+	transport = transportWithTimeouts(base, 0, time.Second)
+	if transport.ResponseHeaderTimeout != time.Second || transport.TLSHandshakeTimeout != 0 {
+		t.Fatalf("only the response header timeout should be set, got: %v, %v",
+			transport.ResponseHeaderTimeout, transport.TLSHandshakeTimeout)
+	}
+}
+
+func TestBuildSnowflakeConnWithTimeouts(t *testing.T) {
+	sc, err := buildSnowflakeConn(context.Background(), Config{
+		Account:               "a",
+		Host:                  "a.snowflakecomputing.com",
+		ConnectTimeout:        5 * time.Second,
+		ResponseHeaderTimeout: 30 * time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	transport, ok := sc.rest.Client.Transport.(*http.Transport)
+	if !ok || transport.ResponseHeaderTimeout != 30*time.Second || transport.TLSHandshakeTimeout != 5*time.Second {
+		t.Fatalf("expected a transport with the configured timeouts, got: %v", sc.rest.Client.Transport)
+	}
+	if transport.TLSClientConfig.VerifyPeerCertificate == nil {
+		t.Fatal("OCSP check should be kept")
+	}
+	if SnowflakeTransport.ResponseHeaderTimeout != 0 || SnowflakeTransport.TLSHandshakeTimeout != 0 {
+		t.Fatal("the default transport should not be modified")
+	}
+}
@@ -0,0 +1,168 @@
+// Copyright (c) 2023 Snowflake Computing Inc. All rights reserved.
+
+package gosnowflake
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// UnloadFormat is the file format used by UnloadToWriter and ExportTable.
+type UnloadFormat string
+
+const (
+	// UnloadFormatCSV unloads the query result as uncompressed CSV
+	UnloadFormatCSV UnloadFormat = "CSV"
+	// UnloadFormatParquet unloads the query result as a single Parquet file
+	UnloadFormatParquet UnloadFormat = "PARQUET"
+)
+
+const (
+	unloadStagePrefix   = "UNLOAD_"
+	unloadFilePrefix    = "data"
+	unloadTempDirPrefix = "snowflake_unload"
+	// unloadMaxFileSize is the largest file COPY INTO writes with SINGLE = TRUE, 5 GB.
+	unloadMaxFileSize = 5 << 30
+)
+
+// SQLExecutor executes a statement. It is implemented by *sql.DB, *sql.Conn and *sql.Tx.
+type SQLExecutor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// UnloadToWriter runs COPY INTO a temporary stage for the given query, downloads
+// the unloaded files with GET and writes their content to w. The stage and the local
+// files are removed afterwards. The statements run in one session, so a connection is
+// taken from the pool of a *sql.DB for the whole call. The session must have a current
+// database and schema, unless Config.TempObjectLocation is set. A Parquet result is
+// unloaded into a single file, which cannot be larger than 5 GB. The stage name starts with the prefix set by
+// WithTempStagePrefix or Config.TempStagePrefix, SYSTEM$ by default. The configuration of
+// the connection is not known for a *sql.Tx, so only WithTempStagePrefix applies then.
+func UnloadToWriter(ctx context.Context, conn SQLExecutor, query string, w io.Writer, format UnloadFormat) error {
+	return unloadToWriter(ctx, conn, "("+query+")", w, format)
+}
+
+// ExportTable writes the full content of table to w like UnloadToWriter, unloading the table
+// directly rather than the result of a query. It is much faster than reading a large table row
+// by row. The table name is used as is in the COPY command.
+func ExportTable(ctx context.Context, conn SQLExecutor, table string, w io.Writer, format UnloadFormat) error {
+	return unloadToWriter(ctx, conn, table, w, format)
+}
+
+// unloadToWriter unloads source, a table or a parenthesized query, to w.
+func unloadToWriter(ctx context.Context, conn SQLExecutor, source string, w io.Writer, format UnloadFormat) (err error) {
+	fileFormat, err := unloadFileFormat(format)
+	if err != nil {
+		return err
+	}
+	if db, ok := conn.(*sql.DB); ok {
+		// the temporary stage exists only in the session that created it
+		dbConn, err := db.Conn(ctx)
+		if err != nil {
+			return err
+		}
+		defer dbConn.Close()
+		conn = dbConn
+	}
+	stageName := tempStageName(ctx, executorConfig(conn), unloadStagePrefix+strings.ReplaceAll(NewUUID().String(), "-", "_"))
+	if _, err = conn.ExecContext(ctx, "CREATE TEMPORARY STAGE "+stageName); err != nil {
+		return err
+	}
+	defer func() {
+		if _, dropErr := conn.ExecContext(ctx, "DROP STAGE IF EXISTS "+stageName); dropErr != nil {
+			logger.WithContext(ctx).Warnf("failed to drop unload stage %v: %v", stageName, dropErr)
+		}
+	}()
+
+	if _, err = conn.ExecContext(ctx, fmt.Sprintf("COPY INTO @%v/%v FROM %v %v",
+		stageName, unloadFilePrefix, source, fileFormat)); err != nil {
+		return err
+	}
+
+	tmpDir, err := os.MkdirTemp("", unloadTempDirPrefix)
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+	localPath := strings.ReplaceAll(filepath.ToSlash(tmpDir), "\\", "\\\\")
+	if _, err = conn.ExecContext(ctx, fmt.Sprintf("GET @%v/%v 'file://%v/'",
+		stageName, unloadFilePrefix, localPath)); err != nil {
+		return err
+	}
+	return copyUnloadedFiles(tmpDir, w)
+}
+
+func unloadFileFormat(format UnloadFormat) (string, error) {
+	switch format {
+	case UnloadFormatCSV:
+		return "FILE_FORMAT = (TYPE = CSV COMPRESSION = NONE) OVERWRITE = TRUE", nil
+	case UnloadFormatParquet:
+		// Parquet files cannot be concatenated, so the result is unloaded into a single file,
+		// which is limited to 16 MB unless MAX_FILE_SIZE is set
+		return fmt.Sprintf("FILE_FORMAT = (TYPE = PARQUET) SINGLE = TRUE MAX_FILE_SIZE = %v OVERWRITE = TRUE", unloadMaxFileSize), nil
+	default:
+		return "", &SnowflakeError{
+			Number:      ErrUnsupportedUnloadFormat,
+			Message:     errMsgUnsupportedUnloadFormat,
+			MessageArgs: []interface{}{format},
+		}
+	}
+}
+
+// copyUnloadedFiles writes the files from dir to w in name order.
+func copyUnloadedFiles(dir string, w io.Writer) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err = copyFileTo(filepath.Join(dir, name), w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func copyFileTo(fileName string, w io.Writer) error {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(w, f)
+	return err
+}
+
+// executorConfig returns the configuration of the Snowflake connection conn runs the statements on,
+// or nil if it cannot be told, e.g. for a *sql.Tx.
+func executorConfig(conn SQLExecutor) *Config {
+	raw, ok := conn.(interface {
+		Raw(func(driverConn interface{}) error) error
+	})
+	if !ok {
+		return nil
+	}
+	var cfg *Config
+	if err := raw.Raw(func(driverConn interface{}) error {
+		if sc, ok := driverConn.(*snowflakeConn); ok {
+			cfg = sc.cfg
+		}
+		return nil
+	}); err != nil {
+		return nil
+	}
+	return cfg
+}
@@ -0,0 +1,230 @@
+// Copyright (c) 2023 Snowflake Computing Inc. All rights reserved.
+
+package gosnowflake
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type unloadExecutorMock struct {
+	t       *testing.T
+	queries []string
+	files   map[string]string
+}
+
+func (m *unloadExecutorMock) ExecContext(_ context.Context, query string, _ ...interface{}) (sql.Result, error) {
+	m.queries = append(m.queries, query)
+	if strings.HasPrefix(query, "GET ") {
+		start := strings.Index(query, "'file://")
+		end := strings.LastIndex(query, "'")
+		if start < 0 || end <= start {
+			m.t.Fatalf("no local path in GET command: %v", query)
+		}
+		dir := query[start+len("'file://") : end]
+		for name, content := range m.files {
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+				m.t.Fatal(err)
+			}
+		}
+	}
+	return unloadResultMock{}, nil
+}
+
+// unloadConnMock is an executor exposing its Snowflake connection like a *sql.Conn.
+type unloadConnMock struct {
+	*unloadExecutorMock
+	sc *snowflakeConn
+}
+
+func (m unloadConnMock) Raw(f func(driverConn interface{}) error) error {
+	return f(m.sc)
+}
+
+// unloadSessionMock is a driver connection on which a temporary stage exists only in the session
+// that created it.
+type unloadSessionMock struct {
+	*unloadExecutorMock
+	stages map[string]bool
+}
+
+func (m *unloadSessionMock) ExecContext(ctx context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	if strings.HasPrefix(query, "CREATE TEMPORARY STAGE ") {
+		m.stages[strings.TrimPrefix(query, "CREATE TEMPORARY STAGE ")] = true
+	} else if !strings.HasPrefix(query, "DROP STAGE IF EXISTS ") {
+		found := false
+		for stage := range m.stages {
+			found = found || strings.Contains(query, "@"+stage+"/")
+		}
+		if !found {
+			return nil, fmt.Errorf("stage does not exist in this session: %v", query)
+		}
+	}
+	return m.unloadExecutorMock.ExecContext(ctx, query)
+}
+
+func (m *unloadSessionMock) Prepare(string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (m *unloadSessionMock) Close() error                        { return nil }
+func (m *unloadSessionMock) Begin() (driver.Tx, error)           { return nil, driver.ErrSkip }
+
+type unloadConnectorMock struct {
+	executor *unloadExecutorMock
+}
+
+func (c unloadConnectorMock) Connect(context.Context) (driver.Conn, error) {
+	return &unloadSessionMock{unloadExecutorMock: c.executor, stages: make(map[string]bool)}, nil
+}
+
+func (c unloadConnectorMock) Driver() driver.Driver {
+	return SnowflakeDriver{}
+}
+
+type unloadResultMock struct{}
+
+func (unloadResultMock) LastInsertId() (int64, error) { return 0, nil }
+func (unloadResultMock) RowsAffected() (int64, error) { return 0, nil }
+
+func TestUnloadToWriterCSV(t *testing.T) {
+	mock := &unloadExecutorMock{
+		t: t,
+		files: map[string]string{
+			"data_0_0_1.csv": "3,c\n",
+			"data_0_0_0.csv": "1,a\n2,b\n",
+		},
+	}
+	var buf bytes.Buffer
+	if err := UnloadToWriter(context.Background(), mock, "SELECT * FROM t", &buf, UnloadFormatCSV); err != nil {
+		t.Fatal(err)
+	}
+	expectedPrefixes := []string{"CREATE TEMPORARY STAGE ", "COPY INTO @", "GET @", "DROP STAGE IF EXISTS "}
+	if len(mock.queries) != len(expectedPrefixes) {
+		t.Fatalf("unexpected queries: %v", mock.queries)
+	}
+	for i, prefix := range expectedPrefixes {
+		if !strings.HasPrefix(mock.queries[i], prefix) {
+			t.Fatalf("query %v should start with %q, got: %v", i, prefix, mock.queries[i])
+		}
+	}
+	if !strings.Contains(mock.queries[1], "FROM (SELECT * FROM t)") || !strings.Contains(mock.queries[1], "TYPE = CSV") {
+		t.Fatalf("unexpected COPY command: %v", mock.queries[1])
+	}
+	if buf.String() != "1,a\n2,b\n3,c\n" {
+		t.Fatalf("unexpected unloaded data: %q", buf.String())
+	}
+}
+
+func TestUnloadToWriterParquet(t *testing.T) {
+	mock := &unloadExecutorMock{
+		t:     t,
+		files: map[string]string{"data": "PAR1"},
+	}
+	var buf bytes.Buffer
+	if err := UnloadToWriter(context.Background(), mock, "SELECT 1", &buf, UnloadFormatParquet); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(mock.queries[1], "TYPE = PARQUET") || !strings.Contains(mock.queries[1], "SINGLE = TRUE") ||
+		!strings.Contains(mock.queries[1], "MAX_FILE_SIZE = 5368709120") {
+		t.Fatalf("unexpected COPY command: %v", mock.queries[1])
+	}
+	if buf.String() != "PAR1" {
+		t.Fatalf("unexpected unloaded data: %q", buf.String())
+	}
+}
+
+func TestUnloadToWriterRunsInOneSessionOfDB(t *testing.T) {
+	mock := &unloadExecutorMock{t: t, files: map[string]string{"data_0_0_0.csv": "1,a\n"}}
+	db := sql.OpenDB(unloadConnectorMock{executor: mock})
+	defer db.Close()
+	// every statement run on the *sql.DB itself would open a new session
+	db.SetMaxIdleConns(0)
+	var buf bytes.Buffer
+	if err := UnloadToWriter(context.Background(), db, "SELECT 1", &buf, UnloadFormatCSV); err != nil {
+		t.Fatal(err)
+	}
+	if len(mock.queries) != 4 || buf.String() != "1,a\n" {
+		t.Fatalf("unexpected unload. queries: %v, data: %q", mock.queries, buf.String())
+	}
+	buf.Reset()
+	if err := ExportTable(context.Background(), db, "orders", &buf, UnloadFormatCSV); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "1,a\n" {
+		t.Fatalf("unexpected exported data: %q", buf.String())
+	}
+}
+
+func TestExportTable(t *testing.T) {
+	mock := &unloadExecutorMock{
+		t: t,
+		files: map[string]string{
+			"data_0_1_0.csv": "3,c\n",
+			"data_0_0_0.csv": "1,a\n2,b\n",
+		},
+	}
+	var buf bytes.Buffer
+	if err := ExportTable(context.Background(), mock, "db.s.orders", &buf, UnloadFormatCSV); err != nil {
+		t.Fatal(err)
+	}
+	expectedPrefixes := []string{"CREATE TEMPORARY STAGE ", "COPY INTO @", "GET @", "DROP STAGE IF EXISTS "}
+	if len(mock.queries) != len(expectedPrefixes) {
+		t.Fatalf("unexpected queries: %v", mock.queries)
+	}
+	for i, prefix := range expectedPrefixes {
+		if !strings.HasPrefix(mock.queries[i], prefix) {
+			t.Fatalf("query %v should start with %q, got: %v", i, prefix, mock.queries[i])
+		}
+	}
+	if !strings.Contains(mock.queries[1], " FROM db.s.orders FILE_FORMAT = (TYPE = CSV") {
+		t.Fatalf("the table should be unloaded directly: %v", mock.queries[1])
+	}
+	stage := strings.TrimPrefix(mock.queries[0], "CREATE TEMPORARY STAGE ")
+	if !strings.HasPrefix(mock.queries[2], "GET @"+stage+"/") || mock.queries[3] != "DROP STAGE IF EXISTS "+stage {
+		t.Fatalf("the files should be downloaded from and cleaned up with the stage %v: %v", stage, mock.queries)
+	}
+	if buf.String() != "1,a\n2,b\n3,c\n" {
+		t.Fatalf("unexpected exported data: %q", buf.String())
+	}
+
+	mock = &unloadExecutorMock{t: t, files: map[string]string{"data": "PAR1"}}
+	buf.Reset()
+	if err := ExportTable(context.Background(), mock, "orders", &buf, UnloadFormatParquet); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(mock.queries[1], " FROM orders FILE_FORMAT = (TYPE = PARQUET)") || buf.String() != "PAR1" {
+		t.Fatalf("unexpected Parquet export. COPY command: %v, data: %q", mock.queries[1], buf.String())
+	}
+}
+
+func TestUnloadToWriterUsesTempObjectLocation(t *testing.T) {
+	mock := &unloadExecutorMock{t: t}
+	sc := &snowflakeConn{cfg: &Config{TempObjectLocation: "TOOLS.STAGING", TempStagePrefix: "ETL_"}}
+	if err := UnloadToWriter(context.Background(), unloadConnMock{mock, sc}, "SELECT 1", &bytes.Buffer{}, UnloadFormatCSV); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(mock.queries[0], "CREATE TEMPORARY STAGE TOOLS.STAGING.ETL_UNLOAD_") {
+		t.Fatalf("the stage should be created in the temporary object location, got: %v", mock.queries[0])
+	}
+	if !strings.HasPrefix(mock.queries[len(mock.queries)-1], "DROP STAGE IF EXISTS TOOLS.STAGING.ETL_UNLOAD_") {
+		t.Fatalf("unexpected DROP command: %v", mock.queries[len(mock.queries)-1])
+	}
+}
+
+func TestUnloadToWriterUnsupportedFormat(t *testing.T) {
+	mock := &unloadExecutorMock{t: t}
+	err := UnloadToWriter(context.Background(), mock, "SELECT 1", &bytes.Buffer{}, UnloadFormat("XML"))
+	var se *SnowflakeError
+	if !errors.As(err, &se) || se.Number != ErrUnsupportedUnloadFormat {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(mock.queries) != 0 {
+		t.Fatalf("no query should be executed, got: %v", mock.queries)
+	}
+}
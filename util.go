@@ -32,9 +32,26 @@ const (
 )
 
 const (
-	describeOnly        contextKey = "DESCRIBE_ONLY"
-	cancelRetry         contextKey = "CANCEL_RETRY"
-	streamChunkDownload contextKey = "STREAM_CHUNK_DOWNLOAD"
+	describeOnly           contextKey = "DESCRIBE_ONLY"
+	cancelRetry            contextKey = "CANCEL_RETRY"
+	streamChunkDownload    contextKey = "STREAM_CHUNK_DOWNLOAD"
+	sessionRenewReason     contextKey = "SESSION_RENEW_REASON"
+	rowLimitPreview        contextKey = "ROW_LIMIT_PREVIEW"
+	queryStatusUpdates     contextKey = "QUERY_STATUS_UPDATES"
+	transactionOptions     contextKey = "TRANSACTION_OPTIONS"
+	beginAutocommit        contextKey = "AUTOCOMMIT"
+	queryResultFormat      contextKey = "QUERY_RESULT_FORMAT"
+	statementParameters    contextKey = "STATEMENT_PARAMETERS"
+	preserveIdentifierCase contextKey = "PRESERVE_IDENTIFIER_CASE"
+	queryPriority          contextKey = "QUERY_PRIORITY"
+	tempStagePrefix        contextKey = "TEMP_STAGE_PREFIX"
+	prefetchThreads        contextKey = "PREFETCH_THREADS"
+	ocspSoftFailHook       contextKey = "OCSP_SOFT_FAIL_HOOK"
+	putOverwrite           contextKey = "PUT_OVERWRITE"
+	columnProjection       contextKey = "COLUMN_PROJECTION"
+	requestIDPrefix        contextKey = "REQUEST_ID_PREFIX"
+	chunkDumpDir           contextKey = "CHUNK_DUMP_DIR"
+	nullToZeroValue        contextKey = "NULL_TO_ZERO_VALUE"
 )
 
 var (
@@ -61,6 +78,16 @@ func WithRequestID(ctx context.Context, requestID UUID) context.Context {
 	return context.WithValue(ctx, snowflakeRequestIDKey, requestID)
 }
 
+// WithRequestIDPrefix returns a context that makes the request IDs of the queries start with the given prefix,
+// e.g. taken from a trace ID, instead of Config.RequestIDPrefix. The prefix is up to 12 hex digits; the rest of
+// the ID is random, so that it stays unique. A request ID set with WithRequestID is used as is.
+func WithRequestIDPrefix(ctx context.Context, prefix string) (context.Context, error) {
+	if !isValidRequestIDPrefix(prefix) {
+		return ctx, errInvalidRequestIDPrefix(prefix)
+	}
+	return context.WithValue(ctx, requestIDPrefix, prefix), nil
+}
+
 // WithStreamDownloader returns a context that allows the use of a stream based chunk downloader
 func WithStreamDownloader(ctx context.Context) context.Context {
 	return context.WithValue(ctx, streamChunkDownload, true)
@@ -81,6 +108,62 @@ func WithFileTransferOptions(ctx context.Context, options *SnowflakeFileTransfer
 	return context.WithValue(ctx, fileTransferOptions, options)
 }
 
+// WithOverwrite returns a context that sets the OVERWRITE option of the PUT commands, i.e. whether the
+// files already on the stage are replaced or skipped. It takes precedence over
+// SnowflakeFileTransferOptions.DisablePutOverwrite.
+func WithOverwrite(ctx context.Context, overwrite bool) context.Context {
+	return context.WithValue(ctx, putOverwrite, overwrite)
+}
+
+// getPutOverwrite returns the OVERWRITE option set by WithOverwrite and whether it is set.
+func getPutOverwrite(ctx context.Context) (overwrite bool, ok bool) {
+	if ctx == nil {
+		return false, false
+	}
+	overwrite, ok = ctx.Value(putOverwrite).(bool)
+	return overwrite, ok
+}
+
+// WithColumnProjection returns a context that decodes only the named columns of the Arrow results of the
+// queries. The other columns are skipped during the deserialization and read as NULL. The names are matched
+// case-insensitively with the columns of the result. It applies to the rows read with sql.Rows and to the
+// chunks of a ResultCursor opened with the context; the Arrow batches of WithArrowBatches are decoded in full.
+func WithColumnProjection(ctx context.Context, columns ...string) context.Context {
+	return context.WithValue(ctx, columnProjection, columns)
+}
+
+// WithChunkDump returns a context that writes the result sets of the queries to dir, see LoadChunkDump.
+// The manifest is written when the rows are returned and each raw chunk as it is downloaded to be read,
+// so the dump is complete once all the rows are read. The chunks are not downloaded again for the dump.
+func WithChunkDump(ctx context.Context, dir string) context.Context {
+	return context.WithValue(ctx, chunkDumpDir, dir)
+}
+
+// getColumnProjection returns which columns of rowType are decoded, or nil if all of them are.
+func getColumnProjection(ctx context.Context, rowType []execResponseRowType) ([]bool, error) {
+	if ctx == nil {
+		return nil, nil
+	}
+	columns, ok := ctx.Value(columnProjection).([]string)
+	if !ok {
+		return nil, nil
+	}
+	projection := make([]bool, len(rowType))
+	for _, column := range columns {
+		found := false
+		for i, rt := range rowType {
+			if strings.EqualFold(rt.Name, column) {
+				projection[i] = true
+				found = true
+			}
+		}
+		if !found {
+			return nil, errUnknownProjectedColumn(column)
+		}
+	}
+	return projection, nil
+}
+
 // WithDescribeOnly returns a context that enables a describe only query
 func WithDescribeOnly(ctx context.Context) context.Context {
 	return context.WithValue(ctx, describeOnly, true)
@@ -113,6 +196,160 @@ func WithOriginalTimestamp(ctx context.Context) context.Context {
 	return context.WithValue(ctx, enableOriginalTimestamp, true)
 }
 
+// WithRowLimitPreview returns a context that makes the driver stop fetching the result
+// once n rows are delivered. Chunks that are still being downloaded are cancelled and
+// the remaining chunks are never downloaded. Unlike LIMIT, the query itself is not altered.
+func WithRowLimitPreview(ctx context.Context, n int) context.Context {
+	return context.WithValue(ctx, rowLimitPreview, n)
+}
+
+// WithQueryStatusUpdates returns a context that makes the driver poll the status of an asynchronous
+// query, see WithAsyncMode, and send an update to the channel whenever the state or the statistics change.
+// The last update is the one for which QueryStatusUpdate.Done returns true. The channel is not closed.
+func WithQueryStatusUpdates(ctx context.Context, c chan<- QueryStatusUpdate) context.Context {
+	return context.WithValue(ctx, queryStatusUpdates, c)
+}
+
+// WithTransactionOptions returns a context that makes BeginTx start the transaction
+// with the given Snowflake specific options.
+func WithTransactionOptions(ctx context.Context, options TransactionOptions) context.Context {
+	return context.WithValue(ctx, transactionOptions, options)
+}
+
+// WithResultFormat returns a context that requests the query result in the given format,
+// ResultFormatArrow or ResultFormatJSON, instead of the one set by GO_QUERY_RESULT_FORMAT.
+// Arrow is not requested if the connection has it disabled; a warning is logged instead.
+func WithResultFormat(ctx context.Context, format string) context.Context {
+	return context.WithValue(ctx, queryResultFormat, format)
+}
+
+// WithPreservedIdentifierCase returns a context that makes the helpers accepting object names, e.g.
+// DescribeTable, double quote each part of a name as given. By default the names are used unquoted,
+// so Snowflake converts them to upper case, which doesn't match objects created with quoted mixed-case names.
+// The parts already double quoted, e.g. "my.db", are used as given.
+func WithPreservedIdentifierCase(ctx context.Context) context.Context {
+	return context.WithValue(ctx, preserveIdentifierCase, true)
+}
+
+func identifierCasePreserved(ctx context.Context) bool {
+	v, ok := ctx.Value(preserveIdentifierCase).(bool)
+	return ok && v
+}
+
+// WithNullToZeroValue returns a context that makes the queries run with it return NULL values as the zero
+// value of the scan type of their column, e.g. 0, "" or time.Time{}, so that they can be scanned into
+// non-pointer types. NULL cannot be told apart from the zero value in these queries, so use it only for
+// the queries of code that doesn't read NULL values.
+func WithNullToZeroValue(ctx context.Context) context.Context {
+	return context.WithValue(ctx, nullToZeroValue, true)
+}
+
+func nullToZeroValueEnabled(ctx context.Context) bool {
+	v, ok := ctx.Value(nullToZeroValue).(bool)
+	return ok && v
+}
+
+// QueryPriority is a scheduling hint sent with a query, see WithQueryPriority.
+type QueryPriority string
+
+const (
+	// QueryPriorityLow marks a query, e.g. of a dashboard refresh, that may wait for interactive queries.
+	QueryPriorityLow QueryPriority = "LOW"
+	// QueryPriorityNormal is the priority of queries without a hint.
+	QueryPriorityNormal QueryPriority = "NORMAL"
+	// QueryPriorityHigh marks a query that should not wait for other queries.
+	QueryPriorityHigh QueryPriority = "HIGH"
+)
+
+// WithQueryPriority returns a context that sends the priority as the QUERY_PRIORITY parameter of the
+// query request. It is a hint for the queuing of the query in the warehouse: servers supporting it may
+// schedule the query accordingly, while others ignore it and run the query as usual.
+func WithQueryPriority(ctx context.Context, priority QueryPriority) context.Context {
+	return context.WithValue(ctx, queryPriority, priority)
+}
+
+func getQueryPriority(ctx context.Context) QueryPriority {
+	priority, _ := ctx.Value(queryPriority).(QueryPriority)
+	return priority
+}
+
+// WithTempStagePrefix returns a context that names the temporary stages created by the driver for the
+// statement, e.g. for array binds or UnloadToWriter, with the given prefix instead of SYSTEM$ or
+// Config.TempStagePrefix. The prefix must be a valid unquoted identifier.
+func WithTempStagePrefix(ctx context.Context, prefix string) (context.Context, error) {
+	if !isValidTempStagePrefix(prefix) {
+		return ctx, errInvalidTempStagePrefix(prefix)
+	}
+	return context.WithValue(ctx, tempStagePrefix, prefix), nil
+}
+
+// maxPrefetchThreads is the maximum number of goroutines downloading the chunks of a statement.
+const maxPrefetchThreads = 64
+
+// WithPrefetchThreads returns a context that downloads the result chunks of the statement with n
+// goroutines instead of MaxChunkDownloadWorkers. n must be between 1 and 64.
+func WithPrefetchThreads(ctx context.Context, n int) (context.Context, error) {
+	if n < 1 || n > maxPrefetchThreads {
+		return ctx, errInvalidPrefetchThreads(n)
+	}
+	return context.WithValue(ctx, prefetchThreads, n), nil
+}
+
+// getPrefetchThreads returns the number of goroutines downloading the chunks, or zero if not set.
+func getPrefetchThreads(ctx context.Context) int {
+	n, _ := ctx.Value(prefetchThreads).(int)
+	return n
+}
+
+// WithStatementParameters returns a context that sends the given parameters, e.g. QUERY_TAG or
+// USE_CACHED_RESULT, with the statement. They apply to that statement only and don't change the session.
+// Parameters set by the driver itself, e.g. MULTI_STATEMENT_COUNT, are refused with an error.
+func WithStatementParameters(ctx context.Context, params map[string]string) (context.Context, error) {
+	merged := make(map[string]string, len(params))
+	for name, value := range params {
+		name = strings.ToUpper(name)
+		if reservedStatementParameters[name] {
+			return ctx, errReservedStatementParameter(name)
+		}
+		merged[name] = value
+	}
+	return context.WithValue(ctx, statementParameters, merged), nil
+}
+
+// reservedStatementParameters are the statement parameters set by the driver from the configuration
+// or the other context options.
+var reservedStatementParameters = map[string]bool{
+	string(multiStatementCount):                 true,
+	string(beginAutocommit):                     true,
+	strings.ToUpper(sessionGoQueryResultFormat): true,
+	arrowCompressionParameter:                   true,
+	resultChunkSizeParameter:                    true,
+	string(queryPriority):                       true,
+}
+
+func getStatementParameters(ctx context.Context) map[string]string {
+	params, _ := ctx.Value(statementParameters).(map[string]string)
+	return params
+}
+
+func getTransactionOptions(ctx context.Context) TransactionOptions {
+	options, _ := ctx.Value(transactionOptions).(TransactionOptions)
+	return options
+}
+
+func getQueryStatusUpdates(ctx context.Context) chan<- QueryStatusUpdate {
+	c, _ := ctx.Value(queryStatusUpdates).(chan<- QueryStatusUpdate)
+	return c
+}
+
+func getRowLimitPreview(ctx context.Context) int64 {
+	n, ok := ctx.Value(rowLimitPreview).(int)
+	if !ok || n <= 0 {
+		return 0
+	}
+	return int64(n)
+}
+
 // Get the request ID from the context if specified, otherwise generate one
 func getOrGenerateRequestIDFromContext(ctx context.Context) UUID {
 	requestID, ok := ctx.Value(snowflakeRequestIDKey).(UUID)
@@ -122,6 +359,21 @@ func getOrGenerateRequestIDFromContext(ctx context.Context) UUID {
 	return NewUUID()
 }
 
+// getOrGenerateQueryRequestID returns the request ID of a query set in the context, otherwise generates one
+// starting with the prefix set by WithRequestIDPrefix or Config.RequestIDPrefix. cfg may be nil.
+func getOrGenerateQueryRequestID(ctx context.Context, cfg *Config) UUID {
+	if requestID, ok := ctx.Value(snowflakeRequestIDKey).(UUID); ok && requestID != nilUUID {
+		return requestID
+	}
+	if prefix, ok := ctx.Value(requestIDPrefix).(string); ok {
+		return newUUIDWithPrefix(prefix)
+	}
+	if cfg != nil && cfg.RequestIDPrefix != "" {
+		return newUUIDWithPrefix(cfg.RequestIDPrefix)
+	}
+	return NewUUID()
+}
+
 // integer min
 func intMin(a, b int) int {
 	if a < b {
@@ -9,6 +9,7 @@ import (
 	"math/rand"
 	"os"
 	"strconv"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -113,6 +114,43 @@ func TestGenerateRequestID(t *testing.T) {
 	}
 }
 
+func TestGenerateQueryRequestIDWithPrefix(t *testing.T) {
+	cfg := &Config{RequestIDPrefix: "0af7651916cd"}
+	first := getOrGenerateQueryRequestID(context.Background(), cfg)
+	other := getOrGenerateQueryRequestID(context.Background(), cfg)
+	if !strings.HasPrefix(first.String(), "0af76519-16cd-4") || first == other {
+		t.Fatalf("unexpected request ids: %v, %v", first, other)
+	}
+	if ParseUUID(first.String()) != first {
+		t.Fatalf("the request id should be a valid UUID: %v", first)
+	}
+
+	ctx, err := WithRequestIDPrefix(context.Background(), "ABC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requestID := getOrGenerateQueryRequestID(ctx, cfg); !strings.HasPrefix(requestID.String(), "abc") {
+		t.Fatalf("the prefix of the context should be used, got: %v", requestID)
+	}
+	expected := NewUUID()
+	if requestID := getOrGenerateQueryRequestID(WithRequestID(ctx, expected), cfg); requestID != expected {
+		t.Fatalf("unexpected request id: %v, expected: %v", requestID, expected)
+	}
+}
+
+func TestInvalidRequestIDPrefix(t *testing.T) {
+	for _, prefix := range []string{"", "trace", "0af7651916cd4", "0af7-6519"} {
+		_, err := WithRequestIDPrefix(context.Background(), prefix)
+		if se, ok := err.(*SnowflakeError); !ok || se.Number != ErrCodeInvalidRequestIDPrefix {
+			t.Fatalf("expected error %v for prefix %q, got: %v", ErrCodeInvalidRequestIDPrefix, prefix, err)
+		}
+	}
+	cfg := &Config{RequestIDPrefix: "trace"}
+	if se, ok := cfg.Validate().(*SnowflakeError); !ok || se.Number != ErrCodeInvalidRequestIDPrefix {
+		t.Fatalf("expected error %v", ErrCodeInvalidRequestIDPrefix)
+	}
+}
+
 func TestIntMin(t *testing.T) {
 	testcases := []tcIntMinMax{
 		{1, 3, 1},
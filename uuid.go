@@ -5,11 +5,16 @@ package gosnowflake
 import (
 	"crypto/rand"
 	"fmt"
+	"regexp"
 	"strconv"
 )
 
 const rfc4122 = 0x40
 
+// requestIDPrefixRegexp matches the prefixes of the request IDs, i.e. up to the first 12 hex digits of a UUID.
+// The digits after them hold the version and the variant of the UUID.
+var requestIDPrefixRegexp = regexp.MustCompile(`^[0-9A-Fa-f]{1,12}$`)
+
 // UUID is a RFC4122 compliant uuid type
 type UUID [16]byte
 
@@ -26,6 +31,26 @@ func NewUUID() UUID {
 	return u
 }
 
+// isValidRequestIDPrefix returns true if the prefix can start the request IDs.
+func isValidRequestIDPrefix(prefix string) bool {
+	return requestIDPrefixRegexp.MatchString(prefix)
+}
+
+// newUUIDWithPrefix creates a new snowflake UUID whose first hex digits are the valid prefix.
+// The remaining digits are random, so that the UUIDs sharing a prefix stay unique.
+func newUUIDWithPrefix(prefix string) UUID {
+	u := NewUUID()
+	for i := 0; i < len(prefix); i++ {
+		digit := getChar(prefix[i : i+1])
+		if i%2 == 0 {
+			u[i/2] = digit<<4 | u[i/2]&0x0F
+		} else {
+			u[i/2] = u[i/2]&0xF0 | digit
+		}
+	}
+	return u
+}
+
 func getChar(str string) byte {
 	i, _ := strconv.ParseUint(str, 16, 8)
 	return byte(i)